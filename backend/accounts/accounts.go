@@ -0,0 +1,143 @@
+package accounts
+
+import (
+	"backend/config"
+	"backend/database"
+	"backend/password"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 注册方式（对应配置项 REGISTRATION_MODE）
+const (
+	RegistrationInvite = "invite" // 凭邀请码注册，角色由邀请码决定
+	RegistrationOpen   = "open"   // 允许自助注册员工和讲师，课程大纲制定者仍需邀请码
+	RegistrationClosed = "closed" // 不开放注册，只能由课程大纲制定者创建账号
+)
+
+// 角色代码（接口中使用的英文角色）
+const (
+	RoleEmployee = "employee"
+	RoleTeacher  = "teacher"
+	RolePlanner  = "planner"
+)
+
+// 角色映射：英文 -> 中文（person.role 中保存中文）
+var roleDisplay = map[string]string{
+	RoleEmployee: "员工",
+	RoleTeacher:  "讲师",
+	RolePlanner:  "课程大纲制定者",
+}
+
+// 角色映射：中文 -> 英文
+var roleCode = map[string]string{
+	"员工":      RoleEmployee,
+	"讲师":      RoleTeacher,
+	"课程大纲制定者": RolePlanner,
+}
+
+// RoleDisplay 角色代码对应的中文角色，未知代码返回空字符串
+func RoleDisplay(code string) string {
+	return roleDisplay[code]
+}
+
+// RoleCode 中文角色对应的角色代码，未知角色返回空字符串
+func RoleCode(display string) string {
+	return roleCode[display]
+}
+
+// settings 注册和邀请码的配置
+type settings struct {
+	mode         string
+	inviteExpiry time.Duration
+	inviteURL    string
+}
+
+var opts settings
+
+// Init 按配置初始化注册方式（启动时调用）
+func Init(cfg *config.Config) error {
+	mode := strings.ToLower(cfg.RegistrationMode)
+	switch mode {
+	case "":
+		mode = RegistrationInvite
+	case RegistrationInvite, RegistrationOpen, RegistrationClosed:
+	default:
+		return fmt.Errorf("不支持的注册方式: %s", cfg.RegistrationMode)
+	}
+	if cfg.InvitationExpireHours <= 0 {
+		return fmt.Errorf("INVITATION_EXPIRE_HOURS 必须大于 0")
+	}
+	opts = settings{
+		mode:         mode,
+		inviteExpiry: time.Duration(cfg.InvitationExpireHours) * time.Hour,
+		inviteURL:    cfg.InvitationURL,
+	}
+	return nil
+}
+
+// RegistrationMode 当前的注册方式
+func RegistrationMode() string {
+	return opts.mode
+}
+
+// ErrUsernameTaken 用户名已被使用
+var ErrUsernameTaken = errors.New("用户名已被使用")
+
+// NewAccount 新账号的信息
+type NewAccount struct {
+	Username     string
+	Password     string // 明文密码，调用方已按密码规则检查
+	Name         string
+	Role         string // 角色代码
+	Email        string // 为空表示未绑定邮箱
+	InvitationID *int64 // 凭邀请码注册时使用的邀请码
+}
+
+// Create 在事务 tx 中创建人员和账号记录，返回的账号已加载人员信息
+func Create(tx *gorm.DB, n NewAccount) (*database.Account, error) {
+	display := RoleDisplay(n.Role)
+	if display == "" {
+		return nil, fmt.Errorf("未知的角色: %s", n.Role)
+	}
+
+	var count int64
+	if err := tx.Model(&database.Account{}).Where("login_name = ?", n.Username).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrUsernameTaken
+	}
+
+	hashed, err := password.Hash(n.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	person := database.Person{
+		Name: n.Name,
+		Role: display,
+	}
+	if err := tx.Create(&person).Error; err != nil {
+		return nil, err
+	}
+
+	account := database.Account{
+		PersonID:     person.PersonID,
+		LoginName:    n.Username,
+		PasswordHash: hashed,
+		InvitationID: n.InvitationID,
+	}
+	if n.Email != "" {
+		account.Email = &n.Email
+	}
+	if err := tx.Create(&account).Error; err != nil {
+		return nil, err
+	}
+	account.Person = person
+	return &account, nil
+}
@@ -0,0 +1,150 @@
+package accounts
+
+import (
+	"backend/database"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvitationInvalid 邀请码不存在
+	ErrInvitationInvalid = errors.New("邀请码无效")
+	// ErrInvitationRevoked 邀请码已被撤销
+	ErrInvitationRevoked = errors.New("邀请码已被撤销")
+	// ErrInvitationExpired 邀请码已过期
+	ErrInvitationExpired = errors.New("邀请码已过期")
+	// ErrInvitationUsedUp 邀请码的可注册次数已用完
+	ErrInvitationUsedUp = errors.New("邀请码已被使用")
+)
+
+// 邀请码状态
+const (
+	InvitationActive  = "active"  // 可以使用
+	InvitationUsed    = "used"    // 可注册次数已用完
+	InvitationExpired = "expired" // 已过期
+	InvitationRevoked = "revoked" // 已撤销
+)
+
+// codeHintLength 保存的邀请码前缀长度，用于在列表中辨认邀请码
+const codeHintLength = 4
+
+// normalizeCode 邀请码不区分大小写，忽略首尾空白和分隔用的连字符
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(normalizeCode(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// InvitationStatus 邀请码在 now 时的状态
+func InvitationStatus(inv *database.Invitation, now time.Time) string {
+	switch {
+	case inv.RevokedAt != nil:
+		return InvitationRevoked
+	case inv.UsedCount >= inv.MaxUses:
+		return InvitationUsed
+	case !inv.ExpiresAt.After(now):
+		return InvitationExpired
+	default:
+		return InvitationActive
+	}
+}
+
+// DefaultExpiry 未指定有效期时邀请码的过期时间
+func DefaultExpiry(now time.Time) time.Time {
+	return now.Add(opts.inviteExpiry)
+}
+
+// InvitationLink 邀请码对应的注册链接
+func InvitationLink(code string) string {
+	return opts.inviteURL + url.QueryEscape(code)
+}
+
+// CreateInvitation 生成邀请码，返回邀请码明文（只在创建时返回一次）和邀请码记录
+func CreateInvitation(role, email string, maxUses int, expiresAt time.Time, creatorID int64, now time.Time) (string, *database.Invitation, error) {
+	display := RoleDisplay(role)
+	if display == "" {
+		return "", nil, errors.New("未知的角色: " + role)
+	}
+
+	bytes := make([]byte, 10)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", nil, err
+	}
+	code := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(bytes)
+
+	inv := database.Invitation{
+		CodeHash:  hashCode(code),
+		CodeHint:  code[:codeHintLength],
+		Role:      display,
+		Email:     email,
+		MaxUses:   maxUses,
+		ExpiresAt: expiresAt,
+		CreatorID: creatorID,
+		CreatedAt: now,
+	}
+	if err := database.DB.Create(&inv).Error; err != nil {
+		return "", nil, err
+	}
+	return code, &inv, nil
+}
+
+// FindInvitation 查询可以使用的邀请码，邀请码不可用时返回对应的错误
+func FindInvitation(db *gorm.DB, code string, now time.Time) (*database.Invitation, error) {
+	var inv database.Invitation
+	err := db.Where("code_hash = ?", hashCode(code)).First(&inv).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrInvitationInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch InvitationStatus(&inv, now) {
+	case InvitationRevoked:
+		return nil, ErrInvitationRevoked
+	case InvitationUsed:
+		return nil, ErrInvitationUsedUp
+	case InvitationExpired:
+		return nil, ErrInvitationExpired
+	}
+	return &inv, nil
+}
+
+// RedeemInvitation 在事务 tx 中使用一次邀请码，返回使用后的邀请码记录
+// 并发注册时按条件递增使用次数，不会超过可注册次数
+func RedeemInvitation(tx *gorm.DB, code string, now time.Time) (*database.Invitation, error) {
+	inv, err := FindInvitation(tx, code, now)
+	if err != nil {
+		return nil, err
+	}
+
+	result := tx.Model(&database.Invitation{}).
+		Where("invitation_id = ? AND used_count < max_uses AND revoked_at IS NULL AND expires_at > ?", inv.InvitationID, now).
+		Update("used_count", gorm.Expr("used_count + 1"))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvitationUsedUp
+	}
+	inv.UsedCount++
+	return inv, nil
+}
+
+// RevokeInvitation 撤销邀请码，已注册的账号不受影响；返回是否撤销（已撤销过时为 false）
+func RevokeInvitation(invitationID int64, now time.Time) (bool, error) {
+	result := database.DB.Model(&database.Invitation{}).
+		Where("invitation_id = ? AND revoked_at IS NULL", invitationID).
+		Update("revoked_at", now)
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,225 @@
+package authtoken
+
+import (
+	"backend/database"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRefreshInvalid 刷新令牌不存在或已被撤销（退出登录）
+	ErrRefreshInvalid = errors.New("刷新令牌无效或已被撤销")
+	// ErrRefreshExpired 刷新令牌已过期，需要重新登录
+	ErrRefreshExpired = errors.New("刷新令牌已过期，请重新登录")
+	// ErrRefreshReused 已换下的刷新令牌被再次使用，令牌可能已泄露，整个会话已注销
+	ErrRefreshReused = errors.New("刷新令牌已被使用过，会话已注销，请重新登录")
+)
+
+// revoked 本实例上已注销的会话及其访问令牌最晚的过期时间
+// 鉴权不查询数据库，注销后在此记录，令牌到期前的请求也会被拒绝；多实例部署时其他实例上的令牌在有效期（ACCESS_TOKEN_MINUTES）结束后失效
+var revoked = struct {
+	sync.Mutex
+	sessions map[string]time.Time
+}{sessions: make(map[string]time.Time)}
+
+func markRevoked(sessionIDs []string, now time.Time) {
+	revoked.Lock()
+	defer revoked.Unlock()
+	for _, id := range sessionIDs {
+		revoked.sessions[id] = now.Add(opts.lifetime)
+	}
+}
+
+func isRevoked(sessionID string, now time.Time) bool {
+	revoked.Lock()
+	defer revoked.Unlock()
+	until, ok := revoked.sessions[sessionID]
+	return ok && until.After(now)
+}
+
+// hashRefreshToken 会话表中只保存刷新令牌的 SHA-256 哈希，数据库泄露时令牌也无法直接使用
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newToken 生成 32 字节随机数的十六进制串，用作会话标识和刷新令牌
+func newToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// CreateSession 登录时创建会话，记录客户端 IP 和 User-Agent，返回刷新令牌（只在此时返回明文）和会话
+func CreateSession(personID int64, role, ipAddress, userAgent string, now time.Time) (string, *database.Session, error) {
+	sessionID, err := newToken()
+	if err != nil {
+		return "", nil, err
+	}
+	refreshToken, err := newToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	if ua := []rune(userAgent); len(ua) > 255 {
+		userAgent = string(ua[:255])
+	}
+	session := &database.Session{
+		SessionID:        sessionID,
+		RefreshTokenHash: hashRefreshToken(refreshToken),
+		PersonID:         personID,
+		Role:             role,
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(opts.refresh),
+		LastSeenAt:       &now,
+		IPAddress:        ipAddress,
+		UserAgent:        userAgent,
+	}
+	if err := database.DB.Create(session).Error; err != nil {
+		return "", nil, err
+	}
+	return refreshToken, session, nil
+}
+
+// FindSession 按刷新令牌查找未过期的会话，已过期的会话同时删除
+// 刷新令牌是已换下的旧令牌时注销整个会话，返回 ErrRefreshReused
+func FindSession(refreshToken string, now time.Time) (*database.Session, error) {
+	var session database.Session
+	hash := hashRefreshToken(refreshToken)
+	err := database.DB.Where("refresh_token_hash = ?", hash).First(&session).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, checkRetired(hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !session.ExpiresAt.After(now) {
+		database.DB.Where("session_id = ?", session.SessionID).Delete(&database.Session{})
+		return nil, ErrRefreshExpired
+	}
+	return &session, nil
+}
+
+// checkRetired 当前刷新令牌中找不到 hash 时，检查它是否是已换下的旧令牌：是则注销所属会话并返回 ErrRefreshReused
+func checkRetired(hash string) error {
+	var retired database.RetiredRefreshToken
+	err := database.DB.Where("token_hash = ?", hash).First(&retired).Error
+	if err == gorm.ErrRecordNotFound {
+		return ErrRefreshInvalid
+	}
+	if err != nil {
+		return err
+	}
+	if err := RevokeSession(retired.SessionID); err != nil {
+		return err
+	}
+	return ErrRefreshReused
+}
+
+// RenewSession 刷新访问令牌时更换刷新令牌，旧刷新令牌随即失效并记为已换下，同时把刷新时间记为会话的最近活跃时间
+// （访问令牌有效期内的请求不查询数据库，也不更新最近活跃时间）
+// 开启滑动过期时顺延会话过期时间，但不超过自登录起的最长有效期
+// 同一刷新令牌被并发使用时只有一次成功，其余的也按旧令牌再次使用处理：注销整个会话，返回 ErrRefreshReused
+func RenewSession(session *database.Session, refreshToken string, now time.Time) (string, error) {
+	newRefreshToken, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	oldHash := hashRefreshToken(refreshToken)
+	updates := map[string]interface{}{
+		"refresh_token_hash": hashRefreshToken(newRefreshToken),
+		"last_seen_at":       now,
+	}
+	if opts.sliding {
+		expiresAt := now.Add(opts.refresh)
+		if opts.maxAge > 0 && expiresAt.After(session.CreatedAt.Add(opts.maxAge)) {
+			expiresAt = session.CreatedAt.Add(opts.maxAge)
+		}
+		if expiresAt.After(session.ExpiresAt) {
+			updates["expires_at"] = expiresAt
+		}
+	}
+	renewed := false
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&database.Session{}).
+			Where("session_id = ? AND refresh_token_hash = ?", session.SessionID, oldHash).
+			Updates(updates)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		renewed = true
+		return tx.Create(&database.RetiredRefreshToken{TokenHash: oldHash, SessionID: session.SessionID, RetiredAt: &now}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	if !renewed {
+		if err := RevokeSession(session.SessionID); err != nil {
+			return "", err
+		}
+		return "", ErrRefreshReused
+	}
+	session.RefreshTokenHash = updates["refresh_token_hash"].(string)
+	if expiresAt, ok := updates["expires_at"].(time.Time); ok {
+		session.ExpiresAt = expiresAt
+	}
+	session.LastSeenAt = &now
+	return newRefreshToken, nil
+}
+
+// RevokeSession 删除会话，其刷新令牌随即失效，已签发的访问令牌在本实例上也立即失效
+func RevokeSession(sessionID string) error {
+	if err := database.DB.Where("session_id = ?", sessionID).Delete(&database.Session{}).Error; err != nil {
+		return err
+	}
+	markRevoked([]string{sessionID}, time.Now())
+	return nil
+}
+
+// RevokePersonSessions 删除用户的全部会话（exceptSessionID 不为空时保留该会话），返回删除的会话数
+func RevokePersonSessions(personID int64, exceptSessionID string) (int, error) {
+	query := database.DB.Model(&database.Session{}).Where("person_id = ?", personID)
+	if exceptSessionID != "" {
+		query = query.Where("session_id <> ?", exceptSessionID)
+	}
+	var sessionIDs []string
+	if err := query.Pluck("session_id", &sessionIDs).Error; err != nil {
+		return 0, err
+	}
+	if len(sessionIDs) == 0 {
+		return 0, nil
+	}
+	if err := database.DB.Where("session_id IN ?", sessionIDs).Delete(&database.Session{}).Error; err != nil {
+		return 0, err
+	}
+	markRevoked(sessionIDs, time.Now())
+	return len(sessionIDs), nil
+}
+
+// Sweep 删除已过期的会话和已删除会话换下的刷新令牌，并清理访问令牌均已过期的注销记录，返回删除的会话数
+func Sweep(now time.Time) (int64, error) {
+	revoked.Lock()
+	for id, until := range revoked.sessions {
+		if !until.After(now) {
+			delete(revoked.sessions, id)
+		}
+	}
+	revoked.Unlock()
+
+	result := database.DB.Where("expires_at <= ?", now).Delete(&database.Session{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	// 会话删除后其换下的刷新令牌不再需要检测
+	err := database.DB.Where("session_id NOT IN (?)", database.DB.Model(&database.Session{}).Select("session_id")).
+		Delete(&database.RetiredRefreshToken{}).Error
+	return result.RowsAffected, err
+}
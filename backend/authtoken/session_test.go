@@ -0,0 +1,91 @@
+package authtoken
+
+import (
+	"backend/config"
+	"backend/database"
+	"backend/database/dbtest"
+	"testing"
+	"time"
+)
+
+func TestRefreshTokenReuse(t *testing.T) {
+	dbtest.Setup(t)
+	if err := Init(config.AppConfig); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	first, session, err := CreateSession(dbtest.EmployeeID, "员工", "10.0.0.1", "test", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	access, _, err := Issue(session.PersonID, session.Role, session.SessionID, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 正常刷新：换成新的刷新令牌
+	found, err := FindSession(first, now)
+	if err != nil {
+		t.Fatalf("查找会话失败：%v", err)
+	}
+	second, err := RenewSession(found, first, now)
+	if err != nil {
+		t.Fatalf("刷新失败：%v", err)
+	}
+	third, err := RenewSession(found, second, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("第二次刷新失败：%v", err)
+	}
+
+	// 两代之前换下的令牌再次出现：注销整个会话
+	if _, err := FindSession(first, now.Add(2*time.Minute)); err != ErrRefreshReused {
+		t.Fatalf("再次使用换下的刷新令牌应返回 ErrRefreshReused，实际 %v", err)
+	}
+	if _, err := FindSession(third, now.Add(2*time.Minute)); err != ErrRefreshInvalid {
+		t.Errorf("会话注销后当前刷新令牌应失效，实际 %v", err)
+	}
+	if _, err := Parse(access, now.Add(2*time.Minute)); err == nil {
+		t.Errorf("会话注销后已签发的访问令牌应失效")
+	}
+	var count int64
+	database.DB.Model(&database.Session{}).Where("session_id = ?", session.SessionID).Count(&count)
+	if count != 0 {
+		t.Errorf("会话应已删除")
+	}
+
+	// 清理时删除已删除会话换下的令牌
+	if _, err := Sweep(now); err != nil {
+		t.Fatal(err)
+	}
+	database.DB.Model(&database.RetiredRefreshToken{}).Where("session_id = ?", session.SessionID).Count(&count)
+	if count != 0 {
+		t.Errorf("清理后不应保留已删除会话换下的刷新令牌，实际 %d 条", count)
+	}
+}
+
+func TestRenewSessionConcurrentUse(t *testing.T) {
+	dbtest.Setup(t)
+	if err := Init(config.AppConfig); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	refreshToken, _, err := CreateSession(dbtest.EmployeeID, "员工", "10.0.0.1", "test", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 两个请求都查到了会话，先刷新的成功，另一个使用的已是换下的令牌
+	a, _ := FindSession(refreshToken, now)
+	b, _ := FindSession(refreshToken, now)
+	renewed, err := RenewSession(a, refreshToken, now)
+	if err != nil {
+		t.Fatalf("第一次刷新失败：%v", err)
+	}
+	if _, err := RenewSession(b, refreshToken, now); err != ErrRefreshReused {
+		t.Fatalf("并发的另一次刷新应返回 ErrRefreshReused，实际 %v", err)
+	}
+	if _, err := FindSession(renewed, now); err != ErrRefreshInvalid {
+		t.Errorf("会话注销后新刷新令牌也应失效，实际 %v", err)
+	}
+}
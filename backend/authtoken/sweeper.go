@@ -0,0 +1,62 @@
+package authtoken
+
+import (
+	"backend/config"
+	"log"
+	"sync"
+	"time"
+)
+
+// sweeper 定时删除已过期的会话，避免每次登录新增的会话一直留在表中
+type sweeper struct {
+	interval time.Duration
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+var s *sweeper
+
+// Start 启动过期会话清理任务（启动时调用），间隔不大于 0 时不启动
+func Start(cfg *config.Config) {
+	if cfg.SessionSweepMinutes <= 0 {
+		log.Println("过期会话定时清理已关闭")
+		return
+	}
+
+	s = &sweeper{
+		interval: time.Duration(cfg.SessionSweepMinutes) * time.Minute,
+		stop:     make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.loop()
+
+	log.Printf("过期会话清理任务已启动，间隔: %v", s.interval)
+}
+
+// Stop 停止清理任务，等待进行中的清理完成
+func Stop() {
+	if s == nil {
+		return
+	}
+	close(s.stop)
+	s.wg.Wait()
+}
+
+func (s *sweeper) loop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := Sweep(time.Now()); err != nil {
+			log.Printf("清理过期会话失败: %v", err)
+		} else if n > 0 {
+			log.Printf("已清理 %d 个过期会话", n)
+		}
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package authtoken
+
+import (
+	"backend/config"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalid 令牌格式错误、签名不匹配或签名密钥已不再使用
+	ErrInvalid = errors.New("访问令牌无效")
+	// ErrExpired 令牌签名有效但已过期，需要用刷新令牌换取新的访问令牌
+	ErrExpired = errors.New("访问令牌已过期")
+	// ErrRevoked 令牌所属的会话已被注销（本人注销或被强制下线）
+	ErrRevoked = errors.New("会话已被注销")
+)
+
+// Claims 访问令牌中携带的用户信息，鉴权时不查询数据库
+type Claims struct {
+	PersonID  int64  `json:"pid"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"` // 签发令牌的会话，退出登录时据此删除会话
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// header JWT 头部，kid 标识签名用的密钥，轮换密钥后仍可验证旧密钥签发的令牌
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid"`
+}
+
+// signingKey 签名密钥及其标识
+type signingKey struct {
+	id     string
+	secret []byte
+}
+
+// settings 当前签名密钥、仍可用于验证的全部密钥和令牌有效期
+type settings struct {
+	current  signingKey
+	verify   map[string][]byte
+	lifetime time.Duration // 访问令牌有效期
+	refresh  time.Duration // 刷新令牌（会话）有效期，滑动过期时为最长闲置时间
+	sliding  bool          // 刷新访问令牌时是否顺延会话过期时间
+	maxAge   time.Duration // 滑动过期时会话自登录起的最长有效期，0 表示不限制
+}
+
+var opts *settings
+
+// Init 按配置加载签名密钥（启动时调用）
+// SESSION_SECRET 用于签发新令牌，SESSION_PREVIOUS_SECRETS 中的旧密钥只用于验证，轮换密钥时把原密钥移到其中即可
+func Init(cfg *config.Config) error {
+	if cfg.SessionSecret == "" {
+		return errors.New("SESSION_SECRET 不能为空")
+	}
+	if cfg.AccessTokenMinutes <= 0 {
+		return errors.New("ACCESS_TOKEN_MINUTES 必须大于 0")
+	}
+	if cfg.RefreshTokenHours <= 0 {
+		return errors.New("REFRESH_TOKEN_HOURS 必须大于 0")
+	}
+	if cfg.SessionMaxHours < 0 {
+		return errors.New("SESSION_MAX_HOURS 不能小于 0")
+	}
+	if cfg.SessionSecret == "default-secret-key" {
+		log.Println("警告：SESSION_SECRET 使用默认值，生产环境请设置为随机字符串")
+	}
+
+	r := &settings{
+		current:  newKey(cfg.SessionSecret),
+		verify:   make(map[string][]byte),
+		lifetime: time.Duration(cfg.AccessTokenMinutes) * time.Minute,
+		refresh:  time.Duration(cfg.RefreshTokenHours) * time.Hour,
+		sliding:  cfg.SessionSlidingExpiration,
+		maxAge:   time.Duration(cfg.SessionMaxHours) * time.Hour,
+	}
+	r.verify[r.current.id] = r.current.secret
+	for _, secret := range strings.Split(cfg.SessionPreviousSecrets, ",") {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			key := newKey(secret)
+			r.verify[key.id] = key.secret
+		}
+	}
+	opts = r
+	return nil
+}
+
+// newKey 密钥标识取密钥哈希的前 8 位，不暴露密钥本身
+func newKey(secret string) signingKey {
+	sum := sha256.Sum256([]byte("kid:" + secret))
+	return signingKey{id: hex.EncodeToString(sum[:4]), secret: []byte(secret)}
+}
+
+// Lifetime 访问令牌的有效期
+func Lifetime() time.Duration {
+	return opts.lifetime
+}
+
+// Issue 用当前密钥为会话签发访问令牌（HS256 签名的 JWT），返回令牌和过期时间
+func Issue(personID int64, role, sessionID string, now time.Time) (string, time.Time, error) {
+	expiresAt := now.Add(opts.lifetime)
+	claims := Claims{
+		PersonID:  personID,
+		Role:      role,
+		SessionID: sessionID,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	}
+	headerJSON, err := json.Marshal(header{Alg: "HS256", Typ: "JWT", Kid: opts.current.id})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	signingInput := encode(headerJSON) + "." + encode(claimsJSON)
+	return signingInput + "." + encode(sign(opts.current.secret, signingInput)), expiresAt, nil
+}
+
+// Parse 验证访问令牌的签名和有效期，并确认所属会话未在本实例上被注销（不查询数据库），返回其中的用户信息
+func Parse(token string, now time.Time) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalid
+	}
+
+	var h header
+	if err := decodeJSON(parts[0], &h); err != nil || h.Alg != "HS256" {
+		return nil, ErrInvalid
+	}
+	secret, ok := opts.verify[h.Kid]
+	if !ok {
+		return nil, ErrInvalid
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, sign(secret, parts[0]+"."+parts[1])) {
+		return nil, ErrInvalid
+	}
+
+	var claims Claims
+	if err := decodeJSON(parts[1], &claims); err != nil || claims.PersonID == 0 {
+		return nil, ErrInvalid
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, ErrExpired
+	}
+	if isRevoked(claims.SessionID, now) {
+		return nil, ErrRevoked
+	}
+	return &claims, nil
+}
+
+func sign(secret []byte, input string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(input))
+	return mac.Sum(nil)
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeJSON(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
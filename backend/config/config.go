@@ -1,8 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -16,6 +19,62 @@ type Config struct {
 	DBName        string
 	ServerPort    string
 	SessionSecret string
+	AIProvider    string // AI 提供方：openai / ollama / mock
+	AIBaseURL     string // AI 接口地址，为空时使用提供方默认值
+	AIModel       string // 模型名称，为空时使用提供方默认值
+	AIAPIKey      string // API Key（ollama / mock 不需要）
+
+	AITimeoutSeconds int // 单次 AI 调用超时（秒）
+	AIWorkers        int // 异步评分并发数
+	AIMaxAttempts    int // 单个评分任务最大尝试次数
+
+	DBDriver         string // 数据库驱动：mysql / sqlite / postgres
+	DBDSN            string // 完整连接串（mysql / postgres），设置后忽略 DB_HOST 等分项配置
+	DBPath           string // SQLite 数据库文件路径
+	DBMigrateOnStart bool   // 启动时是否自动执行未执行的迁移
+
+	EnrollmentSyncMinutes int // 报名规则定时同步间隔（分钟），0 表示关闭
+	PlanStatusSyncMinutes int // 计划状态自动推进的检查间隔（分钟），0 表示关闭
+
+	SessionPreviousSecrets string // 轮换前的签名密钥（逗号分隔），只用于验证已签发的访问令牌
+	AccessTokenMinutes     int    // 访问令牌有效期（分钟）
+	RefreshTokenHours      int    // 刷新令牌有效期（小时），过期后需重新登录；滑动过期时为最长闲置时间
+
+	SessionSlidingExpiration bool // 刷新访问令牌时是否顺延会话（刷新令牌）的过期时间
+	SessionMaxHours          int  // 滑动过期时会话自登录起的最长有效期（小时），0 表示不限制
+	SessionSweepMinutes      int  // 清理过期会话的间隔（分钟），0 表示关闭
+
+	PasswordMinLength          int    // 密码最短长度
+	PasswordRequireLetter      bool   // 密码是否必须包含字母
+	PasswordRequireDigit       bool   // 密码是否必须包含数字
+	PasswordRequireSymbol      bool   // 密码是否必须包含特殊字符
+	PasswordResetMinutes       int    // 密码重置令牌有效期（分钟）
+	PasswordResetURL           string // 重置密码页面地址，发送的链接为该地址加上令牌
+	PasswordResetMaxPerAccount int    // 同一账号在统计窗口内最多发送重置链接的次数（只统计发送成功的），0 表示不限制
+	PasswordResetMaxPerIP      int    // 同一 IP 在统计窗口内最多申请重置密码的次数，0 表示不限制
+	PasswordResetWindowMins    int    // 申请次数的统计窗口（分钟），达到上限后在此时间内不能再申请
+
+	Notifier     string // 通知发送方式：mailsink（写入本地目录，开发用）/ smtp / log
+	MailSinkDir  string // mailsink 保存邮件的目录
+	MailFrom     string // 发件人地址
+	SMTPHost     string // SMTP 服务器地址
+	SMTPPort     int    // SMTP 端口
+	SMTPUser     string // SMTP 用户名，为空时不认证
+	SMTPPassword string // SMTP 密码
+
+	LoginGuardStore        string // 登录失败计数的存储：memory（单实例）/ database（多实例共享）
+	LoginMaxFailures       int    // 同一用户名连续失败多少次后锁定，0 表示不限制
+	LoginMaxFailuresPerIP  int    // 同一 IP 连续失败多少次后锁定，0 表示不限制
+	LoginFailureWindowMins int    // 连续失败的统计窗口（分钟），距上次失败超过该时间后重新计数
+	LoginLockoutMinutes    int    // 锁定时长（分钟）
+	LoginDelaySeconds      int    // 第一次失败后需等待的秒数，之后每次失败翻倍，0 表示不限制
+	LoginMaxDelaySeconds   int    // 等待时间的上限（秒）
+
+	RegistrationMode      string // 注册方式：invite（凭邀请码注册）/ open（允许自助注册员工和讲师）/ closed（只能由大纲制定者创建账号）
+	InvitationExpireHours int    // 邀请码默认有效期（小时）
+	InvitationURL         string // 注册页面地址，邀请链接为该地址加上邀请码
+
+	DefaultTimezone string // 默认时区（IANA 名称），地点和计划都未设置时区时使用，Local 表示服务器所在时区
 }
 
 var AppConfig *Config
@@ -27,14 +86,81 @@ func LoadConfig() error {
 		log.Println("未找到 .env 文件，使用默认配置")
 	}
 
+	dbDriver := getEnv("DB_DRIVER", "mysql")
+	defaultDBPort := "3306"
+	if dbDriver == "postgres" {
+		defaultDBPort = "5432"
+	}
+
 	AppConfig = &Config{
 		DBHost:        getEnv("DB_HOST", "localhost"),
-		DBPort:        getEnv("DB_PORT", "3306"),
+		DBPort:        getEnv("DB_PORT", defaultDBPort),
 		DBUser:        getEnv("DB_USER", "root"),
 		DBPassword:    getEnv("DB_PASSWORD", "GreatSQL@2025"),
 		DBName:        getEnv("DB_NAME", "training_system"),
 		ServerPort:    getEnv("SERVER_PORT", "8080"),
 		SessionSecret: getEnv("SESSION_SECRET", "default-secret-key"),
+		AIProvider:    getEnv("AI_PROVIDER", "openai"),
+		AIBaseURL:     getEnv("AI_BASE_URL", ""),
+		AIModel:       getEnv("AI_MODEL", ""),
+		AIAPIKey:      getEnv("AI_API_KEY", os.Getenv("DEEPSEEK_API_KEY")), // 兼容旧的 DEEPSEEK_API_KEY
+
+		AITimeoutSeconds: getEnvInt("AI_TIMEOUT_SECONDS", 30),
+		AIWorkers:        getEnvInt("AI_WORKERS", 4),
+		AIMaxAttempts:    getEnvInt("AI_MAX_ATTEMPTS", 5),
+
+		DBDriver:         dbDriver,
+		DBDSN:            getEnv("DB_DSN", ""),
+		DBPath:           getEnv("DB_PATH", "training_system.db"),
+		DBMigrateOnStart: getEnv("DB_MIGRATE_ON_START", "true") == "true",
+
+		EnrollmentSyncMinutes: getEnvInt("ENROLLMENT_SYNC_MINUTES", 10),
+		PlanStatusSyncMinutes: getEnvInt("PLAN_STATUS_SYNC_MINUTES", 10),
+
+		SessionPreviousSecrets: getEnv("SESSION_PREVIOUS_SECRETS", ""),
+		AccessTokenMinutes:     getEnvInt("ACCESS_TOKEN_MINUTES", 15),
+		RefreshTokenHours:      getEnvInt("REFRESH_TOKEN_HOURS", 168),
+
+		SessionSlidingExpiration: getEnv("SESSION_SLIDING_EXPIRATION", "true") == "true",
+		SessionMaxHours:          getEnvInt("SESSION_MAX_HOURS", 720),
+		SessionSweepMinutes:      getEnvInt("SESSION_SWEEP_MINUTES", 60),
+
+		PasswordMinLength:          getEnvInt("PASSWORD_MIN_LENGTH", 6),
+		PasswordRequireLetter:      getEnv("PASSWORD_REQUIRE_LETTER", "false") == "true",
+		PasswordRequireDigit:       getEnv("PASSWORD_REQUIRE_DIGIT", "false") == "true",
+		PasswordRequireSymbol:      getEnv("PASSWORD_REQUIRE_SYMBOL", "false") == "true",
+		PasswordResetMinutes:       getEnvInt("PASSWORD_RESET_MINUTES", 30),
+		PasswordResetURL:           getEnv("PASSWORD_RESET_URL", "http://localhost:5173/reset-password?token="),
+		PasswordResetMaxPerAccount: getEnvInt("PASSWORD_RESET_MAX_PER_ACCOUNT", 3),
+		PasswordResetMaxPerIP:      getEnvInt("PASSWORD_RESET_MAX_PER_IP", 10),
+		PasswordResetWindowMins:    getEnvInt("PASSWORD_RESET_WINDOW_MINUTES", 60),
+
+		Notifier:     getEnv("NOTIFIER", "mailsink"),
+		MailSinkDir:  getEnv("MAIL_SINK_DIR", "mail"),
+		MailFrom:     getEnv("MAIL_FROM", "noreply@training.local"),
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnvInt("SMTP_PORT", 587),
+		SMTPUser:     getEnv("SMTP_USER", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+
+		LoginGuardStore:        getEnv("LOGIN_GUARD_STORE", "memory"),
+		LoginMaxFailures:       getEnvInt("LOGIN_MAX_FAILURES", 5),
+		LoginMaxFailuresPerIP:  getEnvInt("LOGIN_MAX_FAILURES_PER_IP", 20),
+		LoginFailureWindowMins: getEnvInt("LOGIN_FAILURE_WINDOW_MINUTES", 15),
+		LoginLockoutMinutes:    getEnvInt("LOGIN_LOCKOUT_MINUTES", 15),
+		LoginDelaySeconds:      getEnvInt("LOGIN_DELAY_SECONDS", 1),
+		LoginMaxDelaySeconds:   getEnvInt("LOGIN_MAX_DELAY_SECONDS", 30),
+
+		RegistrationMode:      getEnv("REGISTRATION_MODE", "invite"),
+		InvitationExpireHours: getEnvInt("INVITATION_EXPIRE_HOURS", 72),
+		InvitationURL:         getEnv("INVITATION_URL", "http://localhost:5173/register?code="),
+
+		DefaultTimezone: getEnv("DEFAULT_TIMEZONE", "Local"),
+	}
+
+	// 为 0 时评分任务的 context 立即超时，而 HTTP 客户端不设超时，两者都不可用
+	if AppConfig.AITimeoutSeconds <= 0 {
+		return fmt.Errorf("AI_TIMEOUT_SECONDS 必须大于 0，当前为 %d", AppConfig.AITimeoutSeconds)
 	}
 
 	log.Println("配置加载成功")
@@ -49,3 +175,17 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getEnvInt 获取整数类型的环境变量，不存在或格式错误时返回默认值
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// AITimeout 单次 AI 调用的超时时间，评分任务和 HTTP 客户端使用同一个值
+func (c *Config) AITimeout() time.Duration {
+	return time.Duration(c.AITimeoutSeconds) * time.Second
+}
@@ -3,10 +3,13 @@ package database
 import (
 	"fmt"
 	"log"
+	"strings"
 	"time"
 	"backend/config"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 
@@ -14,25 +17,39 @@ import (
 
 var DB *gorm.DB
 
-// InitDB 初始化数据库连接
+// InitDB 初始化数据库连接，并按配置执行未执行的迁移
 func InitDB() error {
-	cfg := config.AppConfig
+	if err := Connect(); err != nil {
+		return err
+	}
+
+	if !config.AppConfig.DBMigrateOnStart {
+		log.Println("已关闭启动时迁移，请使用 migrate 命令手动执行")
+		return nil
+	}
+
+	count, err := MigrateUp()
+	if err != nil {
+		return fmt.Errorf("数据库迁移失败: %v", err)
+	}
+	log.Printf("数据库迁移完成，本次执行 %d 个迁移", count)
 
-	// 构建 DSN (Data Source Name)
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		cfg.DBUser,
-		cfg.DBPassword,
-		cfg.DBHost,
-		cfg.DBPort,
-		cfg.DBName,
-	)
+	return nil
+}
+
+// Connect 连接数据库（不执行迁移）
+func Connect() error {
+	dialector, err := openDialector(config.AppConfig)
+	if err != nil {
+		return err
+	}
 
 	// 配置 GORM 日志
 	gormLogger := logger.Default.LogMode(logger.Info)
 
 	// 连接数据库（禁用外键约束自动创建）
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: gormLogger,
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:                                   gormLogger,
 		DisableForeignKeyConstraintWhenMigrating: true, // 禁用外键约束
 	})
 	if err != nil {
@@ -51,43 +68,51 @@ func InitDB() error {
 	sqlDB.SetConnMaxLifetime(time.Hour) // 连接最大生命周期
 
 	DB = db
-	log.Println("数据库连接成功")
-
-	// 自动迁移表结构
-	if err := AutoMigrate(); err != nil {
-		return fmt.Errorf("数据库表迁移失败: %v", err)
-	}
+	log.Printf("数据库连接成功（%s）", db.Dialector.Name())
 
 	return nil
 }
 
-// AutoMigrate 自动迁移数据库表结构
-func AutoMigrate() error {
-	log.Println("开始自动迁移数据库表...")
-
-	// 按照依赖顺序创建表，先创建基础表，再创建有外键的表
-	// 1. 基础表（无外键依赖）
-	if err := DB.AutoMigrate(&Person{}); err != nil {
-		return err
-	}
-	
-	// 2. 依赖 Person 的表
-	if err := DB.AutoMigrate(&Account{}, &TrainingPlan{}, &Course{}); err != nil {
-		return err
+// openDialector 根据 DB_DRIVER 创建对应的 GORM 驱动
+func openDialector(cfg *config.Config) (gorm.Dialector, error) {
+	switch cfg.DBDriver {
+	case "", DriverMySQL:
+		if cfg.DBDSN != "" {
+			return mysql.Open(cfg.DBDSN), nil
+		}
+		// 构建 DSN (Data Source Name)
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			cfg.DBUser,
+			cfg.DBPassword,
+			cfg.DBHost,
+			cfg.DBPort,
+			cfg.DBName,
+		)
+		return mysql.Open(dsn), nil
+	case DriverPostgres:
+		if cfg.DBDSN != "" {
+			return postgres.Open(cfg.DBDSN), nil
+		}
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+			cfg.DBHost,
+			cfg.DBPort,
+			cfg.DBUser,
+			cfg.DBPassword,
+			cfg.DBName,
+		)
+		return postgres.Open(dsn), nil
+	case DriverSQLite:
+		// busy_timeout 避免评分 worker 与请求并发写入时立即返回 database is locked
+		// DB_PATH 也可以是带参数的 URI，如测试用的内存数据库 file:test?mode=memory&cache=shared
+		separator := "?"
+		if strings.Contains(cfg.DBPath, "?") {
+			separator = "&"
+		}
+		dsn := cfg.DBPath + separator + "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+		return sqlite.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("不支持的数据库驱动: %s", cfg.DBDriver)
 	}
-	
-	// 3. 依赖多个表的表
-	if err := DB.AutoMigrate(&PlanCourseItem{}); err != nil {
-		return err
-	}
-	
-	// 4. 关联表
-	if err := DB.AutoMigrate(&AttendanceEvaluation{}, &PlanEmployee{}, &Session{}); err != nil {
-		return err
-	}
-
-	log.Println("数据库表迁移完成")
-	return nil
 }
 
 // CloseDB 关闭数据库连接
@@ -0,0 +1,253 @@
+// Package dbtest 接口测试用的数据库、测试数据和请求工具
+package dbtest
+
+import (
+	"bytes"
+	"backend/config"
+	"backend/database"
+	"backend/utils"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// databases 已创建的内存数据库数，每个测试使用独立命名的数据库
+var databases int64
+
+// Setup 为测试准备数据库：执行全部迁移、插入测试账号（见 database.SeedTestAccounts），
+// 并使用模拟 AI 提供方；测试结束时关闭连接。
+//
+// 默认使用独立的内存 SQLite 数据库，测试结束后随连接删除。环境变量 DB_DRIVER 为 mysql 或 postgres 时
+// 改用 DB_DSN（或 DB_HOST 等分项配置）指向的数据库，迁移后清空全部业务数据；多个测试共用该数据库，需以 go test -p 1 串行执行
+func Setup(t *testing.T) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	if err := config.LoadConfig(); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	switch driver := os.Getenv("DB_DRIVER"); driver {
+	case "", database.DriverSQLite:
+		config.AppConfig.DBDriver = database.DriverSQLite
+		config.AppConfig.DBPath = fmt.Sprintf("file:dbtest%d?mode=memory&cache=shared", atomic.AddInt64(&databases, 1))
+	case database.DriverMySQL, database.DriverPostgres:
+		config.AppConfig.DBDriver = driver
+	default:
+		t.Fatalf("不支持的数据库驱动: %s", driver)
+	}
+
+	if err := database.Connect(); err != nil {
+		t.Fatalf("连接数据库失败: %v", err)
+	}
+	database.DB.Logger = logger.Discard
+	t.Cleanup(func() { database.CloseDB() })
+
+	if _, err := database.MigrateUp(); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+	if err := truncate(); err != nil {
+		t.Fatalf("清空测试数据失败: %v", err)
+	}
+	if err := database.SeedTestAccounts(); err != nil {
+		t.Fatalf("插入测试账号失败: %v", err)
+	}
+	utils.SetAIProvider(&utils.MockProvider{})
+}
+
+// truncate 清空共用数据库中除迁移记录外的全部表并重置自增主键，内存 SQLite 数据库本就为空
+func truncate() error {
+	if database.Dialect() == database.DriverSQLite {
+		return nil
+	}
+	tables, err := database.DB.Migrator().GetTables()
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, table := range tables {
+		if table != "schema_migrations" {
+			names = append(names, database.DB.Statement.Quote(table))
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	if database.Dialect() == database.DriverPostgres {
+		return database.DB.Exec("TRUNCATE TABLE " + strings.Join(names, ", ") + " RESTART IDENTITY CASCADE").Error
+	}
+	// MySQL 不允许 TRUNCATE 被外键引用的表，需在同一连接上暂时关闭外键检查
+	return database.DB.Connection(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET FOREIGN_KEY_CHECKS = 0").Error; err != nil {
+			return err
+		}
+		defer tx.Exec("SET FOREIGN_KEY_CHECKS = 1")
+		for _, name := range names {
+			if err := tx.Exec("TRUNCATE TABLE " + name).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// 测试账号的人员编号（见 database.SeedTestAccounts）
+const (
+	PlannerID   int64 = 1
+	TeacherID   int64 = 2
+	Teacher2ID  int64 = 3
+	EmployeeID  int64 = 4
+	Employee2ID int64 = 5
+)
+
+// Date 相对今天 days 天的日期（YYYY-MM-DD）
+func Date(days int) string {
+	return time.Now().AddDate(0, 0, days).Format("2006-01-02")
+}
+
+// Near 判断两个分数是否相等，允许 MySQL 单精度 FLOAT 分数列带来的误差
+func Near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-3
+}
+
+func create(t *testing.T, value interface{}) {
+	t.Helper()
+	if err := database.DB.Create(value).Error; err != nil {
+		t.Fatalf("插入测试数据失败: %v", err)
+	}
+}
+
+// CreatePlan 创建进行中的培训计划（时间为 start 到 end 两天的 08:00 到 18:00）并加入员工
+func CreatePlan(t *testing.T, name, start, end string, employeeIDs ...int64) database.TrainingPlan {
+	t.Helper()
+	begin, _ := time.ParseInLocation("2006-01-02 15:04:05", start+" 08:00:00", time.Local)
+	finish, _ := time.ParseInLocation("2006-01-02 15:04:05", end+" 18:00:00", time.Local)
+	plan := database.TrainingPlan{
+		PlanName:          name,
+		PlanStatus:        database.PlanStatusInProgress,
+		PlanStartDatetime: begin,
+		PlanEndDatetime:   finish,
+		CreatorID:         PlannerID,
+	}
+	create(t, &plan)
+	for _, id := range employeeIDs {
+		create(t, &database.PlanEmployee{PlanID: plan.PlanID, PersonID: id, Source: database.PlanEmployeeSourceManual})
+	}
+	return plan
+}
+
+// CreateCourse 创建讲师主讲的课程
+func CreateCourse(t *testing.T, name, class string, teacherID int64) database.Course {
+	t.Helper()
+	course := database.Course{CourseName: name, CourseDesc: name + "课程", CourseClass: class, TeacherID: teacherID}
+	create(t, &course)
+	return course
+}
+
+// CreateItem 创建课程安排，date 为 YYYY-MM-DD，begin、end 为 HH:mm:ss
+func CreateItem(t *testing.T, plan database.TrainingPlan, course database.Course, date, begin, end, timezone string) database.PlanCourseItem {
+	t.Helper()
+	classDate, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		t.Fatalf("上课日期格式错误: %v", err)
+	}
+	item := database.PlanCourseItem{
+		PlanID:         plan.PlanID,
+		CourseID:       course.CourseID,
+		ClassDate:      classDate,
+		ClassBeginTime: begin,
+		ClassEndTime:   end,
+		Location:       "培训楼A101",
+		Timezone:       timezone,
+	}
+	create(t, &item)
+	return item
+}
+
+// CreateEvaluation 插入员工对课程安排的评价（自评和讲师评分）
+func CreateEvaluation(t *testing.T, evaluation database.AttendanceEvaluation) database.AttendanceEvaluation {
+	t.Helper()
+	create(t, &evaluation)
+	return evaluation
+}
+
+// Response 接口的统一响应
+type Response struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Decode 把响应的 data 解析到 v
+func (r Response) Decode(t *testing.T, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(r.Data, v); err != nil {
+		t.Fatalf("解析响应数据失败: %v\n%s", err, r.Data)
+	}
+}
+
+// Get 以 personID、role 的身份（与鉴权中间件写入的上下文相同）请求 handler，target 为带查询参数的路径
+func Get(t *testing.T, handler gin.HandlerFunc, personID int64, role, target string) Response {
+	t.Helper()
+	return GetRoute(t, "/*path", handler, personID, role, target)
+}
+
+// GetRoute 与 Get 相同，但把 handler 注册在带路径参数的 route（如 /employees/:employeeId/scores）上
+func GetRoute(t *testing.T, route string, handler gin.HandlerFunc, personID int64, role, target string) Response {
+	t.Helper()
+	return serve(t, http.MethodGet, route, handler, personID, role, target, nil)
+}
+
+// Post 以 personID、role 的身份向 handler 发送 JSON 请求体，要求返回 code 200
+func Post(t *testing.T, handler gin.HandlerFunc, personID int64, role, target string, body interface{}) Response {
+	t.Helper()
+	return PostRoute(t, "/*path", handler, personID, role, target, body)
+}
+
+// PostRoute 与 Post 相同，但把 handler 注册在带路径参数的 route 上
+func PostRoute(t *testing.T, route string, handler gin.HandlerFunc, personID int64, role, target string, body interface{}) Response {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("序列化请求体失败: %v", err)
+	}
+	return serve(t, http.MethodPost, route, handler, personID, role, target, bytes.NewReader(payload))
+}
+
+func serve(t *testing.T, method, route string, handler gin.HandlerFunc, personID int64, role, target string, body io.Reader) Response {
+	t.Helper()
+	router := gin.New()
+	router.Handle(method, route, func(c *gin.Context) {
+		c.Set("personId", personID)
+		c.Set("role", role)
+		c.Next()
+	}, handler)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, target, body)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	router.ServeHTTP(recorder, request)
+
+	var resp Response
+	if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("解析响应失败: %v\n%s", err, recorder.Body.String())
+	}
+	if recorder.Code != http.StatusOK || resp.Code != 200 {
+		t.Fatalf("请求 %s 失败：HTTP %d，%s", target, recorder.Code, recorder.Body.String())
+	}
+	return resp
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// 支持的数据库驱动（对应配置项 DB_DRIVER）
+const (
+	DriverMySQL    = "mysql"    // MySQL / GreatSQL
+	DriverSQLite   = "sqlite"   // SQLite 文件数据库，用于本地开发与测试
+	DriverPostgres = "postgres" // PostgreSQL
+)
+
+// Dialect 返回当前连接的数据库方言名称
+func Dialect() string {
+	return DB.Dialector.Name()
+}
+
+// 以下函数生成与数据库方言相关的 SQL 片段，column 为列名或表达式。
+// SQLite 中日期时间以 "2006-01-02 15:04:05..." 格式的文本保存，直接截取前缀即可。
+
+// FormatDate 将日期列格式化为 YYYY-MM-DD 文本（用于 SELECT）
+func FormatDate(column string) string {
+	switch Dialect() {
+	case DriverSQLite:
+		return fmt.Sprintf("substr(%s, 1, 10)", column)
+	case DriverPostgres:
+		return fmt.Sprintf("to_char(%s, 'YYYY-MM-DD')", column)
+	}
+	return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d')", column)
+}
+
+// FormatDateTime 将日期时间列格式化为 YYYY-MM-DD HH:MM:SS 文本（用于 SELECT）
+func FormatDateTime(column string) string {
+	switch Dialect() {
+	case DriverSQLite:
+		return fmt.Sprintf("substr(%s, 1, 19)", column)
+	case DriverPostgres:
+		return fmt.Sprintf("to_char(%s, 'YYYY-MM-DD HH24:MI:SS')", column)
+	}
+	return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d %%H:%%i:%%s')", column)
+}
+
+// 以下函数生成按日期筛选的条件（用于 WHERE），date 为 "2006-01-02" 格式的日期。
+// 条件直接比较列本身，某一天即 [当天, 次日) 的半开区间，不在列上套用函数，可以使用列上的索引；
+// 日期列和日期时间列都适用（SQLite 中按文本比较，日期时间文本以日期开头，结果相同）。
+
+// OnDate 列的日期等于 date
+func OnDate(column, date string) clause.Expr {
+	return clause.Expr{SQL: column + " >= ? AND " + column + " < ?", Vars: []interface{}{date, nextDay(date)}}
+}
+
+// DateFrom 列的日期不早于 date
+func DateFrom(column, date string) clause.Expr {
+	return clause.Expr{SQL: column + " >= ?", Vars: []interface{}{date}}
+}
+
+// DateBefore 列的日期早于 date
+func DateBefore(column, date string) clause.Expr {
+	return clause.Expr{SQL: column + " < ?", Vars: []interface{}{date}}
+}
+
+// DateUntil 列的日期不晚于 date
+func DateUntil(column, date string) clause.Expr {
+	return DateBefore(column, nextDay(date))
+}
+
+// DateBetween 列的日期在 from 和 to 之间（包含两端）
+func DateBetween(column, from, to string) clause.Expr {
+	return clause.Expr{SQL: column + " >= ? AND " + column + " < ?", Vars: []interface{}{from, nextDay(to)}}
+}
+
+// nextDay date 的次日，调用方应已校验日期格式；无法解析时原样返回
+func nextDay(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return t.AddDate(0, 0, 1).Format("2006-01-02")
+}
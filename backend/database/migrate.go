@@ -0,0 +1,452 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 迁移文件按数据库方言分目录存放，文件名格式：<版本号>_<名称>.up.sql / <版本号>_<名称>.down.sql
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+const (
+	migrationLockName = "training_system_schema_migrations"
+	migrationLockWait = 60 // 等待其他实例释放迁移锁的秒数
+)
+
+// Migration 一个版本化迁移
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // up 脚本的 SHA-256，用于发现已执行的迁移文件被修改
+}
+
+// SchemaMigration 已执行迁移记录表
+type SchemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false;column:version"`
+	Name      string    `gorm:"column:name;size:100;not null"`
+	Checksum  string    `gorm:"column:checksum;size:64;not null"`
+	AppliedAt time.Time `gorm:"column:applied_at;not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationState 迁移状态（migrate status 输出）
+type MigrationState struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	Modified  bool // 已执行后迁移文件内容发生变化
+	Missing   bool // 数据库中有记录但二进制中已没有对应的迁移文件
+}
+
+// LoadMigrations 读取内嵌的迁移文件，按版本号升序返回
+func LoadMigrations() ([]Migration, error) {
+	dir := path.Join("migrations", Dialect())
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移目录失败: %v", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(fileName, "."+direction+".sql")
+		parts := strings.SplitN(base, "_", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("迁移文件名格式错误: %s", fileName)
+		}
+		version, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("迁移文件版本号错误: %s", fileName)
+		}
+
+		content, err := migrationFiles.ReadFile(path.Join(dir, fileName))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[1]}
+			byVersion[version] = m
+		} else if m.Name != parts[1] {
+			return nil, fmt.Errorf("迁移版本号重复: %d", version)
+		}
+		if direction == "up" {
+			m.Up = string(content)
+			sum := sha256.Sum256(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("迁移 %d_%s 缺少 up 脚本", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// MigrateUp 执行所有未执行的迁移，返回本次执行的迁移数
+func MigrateUp() (int, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = withMigrationLock(func() error {
+		applied, err := appliedMigrations()
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if record, ok := applied[m.Version]; ok {
+				if record.Checksum != m.Checksum {
+					log.Printf("警告: 迁移 %d_%s 执行后文件已被修改", m.Version, m.Name)
+				}
+				continue
+			}
+
+			log.Printf("执行迁移 %d_%s", m.Version, m.Name)
+			if err := DB.Transaction(func(tx *gorm.DB) error {
+				if err := execScript(tx, m.Up); err != nil {
+					return err
+				}
+				return tx.Create(&SchemaMigration{
+					Version:   m.Version,
+					Name:      m.Name,
+					Checksum:  m.Checksum,
+					AppliedAt: time.Now(),
+				}).Error
+			}); err != nil {
+				return fmt.Errorf("迁移 %d_%s 执行失败: %v", m.Version, m.Name, err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// MigrateDown 回滚最近执行的 steps 个迁移，返回实际回滚的迁移数
+func MigrateDown(steps int) (int, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return 0, err
+	}
+	byVersion := make(map[int64]Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+
+	count := 0
+	err = withMigrationLock(func() error {
+		var records []SchemaMigration
+		if err := DB.Order("version DESC").Limit(steps).Find(&records).Error; err != nil {
+			return err
+		}
+
+		for _, record := range records {
+			m, ok := byVersion[record.Version]
+			if !ok {
+				return fmt.Errorf("找不到迁移 %d_%s 的文件，无法回滚", record.Version, record.Name)
+			}
+			if m.Down == "" {
+				return fmt.Errorf("迁移 %d_%s 没有 down 脚本，无法回滚", m.Version, m.Name)
+			}
+
+			log.Printf("回滚迁移 %d_%s", m.Version, m.Name)
+			if err := DB.Transaction(func(tx *gorm.DB) error {
+				if err := execScript(tx, m.Down); err != nil {
+					return err
+				}
+				return tx.Delete(&SchemaMigration{}, "version = ?", m.Version).Error
+			}); err != nil {
+				return fmt.Errorf("迁移 %d_%s 回滚失败: %v", m.Version, m.Name, err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// MigrationStatus 返回所有迁移（含数据库中有记录但文件已缺失的）的执行状态
+func MigrationStatus() ([]MigrationState, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureMigrationTable(); err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, m := range migrations {
+		state := MigrationState{Version: m.Version, Name: m.Name}
+		if record, ok := applied[m.Version]; ok {
+			appliedAt := record.AppliedAt
+			state.Applied = true
+			state.AppliedAt = &appliedAt
+			state.Modified = record.Checksum != m.Checksum
+			delete(applied, m.Version)
+		}
+		states = append(states, state)
+	}
+	for _, record := range applied {
+		appliedAt := record.AppliedAt
+		states = append(states, MigrationState{
+			Version:   record.Version,
+			Name:      record.Name,
+			Applied:   true,
+			AppliedAt: &appliedAt,
+			Missing:   true,
+		})
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Version < states[j].Version
+	})
+	return states, nil
+}
+
+// RunMigrateCommand 执行命令行迁移命令：up / down [步数] / status
+func RunMigrateCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: migrate up | down [步数] | status")
+	}
+
+	switch args[0] {
+	case "up":
+		count, err := MigrateUp()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("已执行 %d 个迁移\n", count)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("回滚步数必须为正整数: %s", args[1])
+			}
+			steps = n
+		}
+		count, err := MigrateDown(steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("已回滚 %d 个迁移\n", count)
+	case "status":
+		states, err := MigrationStatus()
+		if err != nil {
+			return err
+		}
+		for _, s := range states {
+			status := "未执行"
+			if s.Applied {
+				status = "已执行 " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Modified {
+				status += "（文件已修改）"
+			}
+			if s.Missing {
+				status += "（文件缺失）"
+			}
+			fmt.Printf("%04d  %-30s  %s\n", s.Version, s.Name, status)
+		}
+	default:
+		return fmt.Errorf("未知的迁移命令: %s", args[0])
+	}
+	return nil
+}
+
+// withMigrationLock 持有数据库级的迁移锁执行 fn，保证多个实例同时启动时只有一个在执行迁移
+func withMigrationLock(fn func() error) error {
+	// SQLite 仅用于单实例的本地开发与测试，不加锁
+	if Dialect() == DriverSQLite {
+		if err := ensureMigrationTable(); err != nil {
+			return err
+		}
+		return fn()
+	}
+
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+
+	// 锁与会话绑定，必须固定在同一个连接上获取和释放
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("获取数据库连接失败: %v", err)
+	}
+	defer conn.Close()
+
+	if err := acquireMigrationLock(ctx, conn); err != nil {
+		return err
+	}
+	defer releaseMigrationLock(ctx, conn)
+
+	if err := ensureMigrationTable(); err != nil {
+		return err
+	}
+	return fn()
+}
+
+// acquireMigrationLock 获取迁移锁，最多等待 migrationLockWait 秒
+func acquireMigrationLock(ctx context.Context, conn *sql.Conn) error {
+	if Dialect() == DriverPostgres {
+		// PostgreSQL 咨询锁没有等待超时参数，轮询尝试获取
+		deadline := time.Now().Add(migrationLockWait * time.Second)
+		for {
+			var acquired bool
+			if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", migrationLockName).Scan(&acquired); err != nil {
+				return fmt.Errorf("获取迁移锁失败: %v", err)
+			}
+			if acquired {
+				return nil
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("等待迁移锁超时，可能有其他实例正在执行迁移")
+			}
+			time.Sleep(time.Second)
+		}
+	}
+
+	var acquired int
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLockName, migrationLockWait).Scan(&acquired); err != nil {
+		return fmt.Errorf("获取迁移锁失败: %v", err)
+	}
+	if acquired != 1 {
+		return fmt.Errorf("等待迁移锁超时，可能有其他实例正在执行迁移")
+	}
+	return nil
+}
+
+// releaseMigrationLock 释放迁移锁
+func releaseMigrationLock(ctx context.Context, conn *sql.Conn) {
+	if Dialect() == DriverPostgres {
+		conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", migrationLockName)
+		return
+	}
+	conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", migrationLockName)
+}
+
+// ensureMigrationTable 创建迁移记录表
+func ensureMigrationTable() error {
+	timeType := "DATETIME"
+	if Dialect() == DriverPostgres {
+		timeType = "TIMESTAMPTZ"
+	}
+	return DB.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT NOT NULL PRIMARY KEY,
+		name VARCHAR(100) NOT NULL,
+		checksum VARCHAR(64) NOT NULL,
+		applied_at ` + timeType + ` NOT NULL
+	)`).Error
+}
+
+// appliedMigrations 查询已执行的迁移
+func appliedMigrations() (map[int64]SchemaMigration, error) {
+	var records []SchemaMigration
+	if err := DB.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]SchemaMigration, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+// execScript 逐条执行迁移脚本中的语句
+// 注意：MySQL 的 DDL 会隐式提交事务，包含 DDL 的迁移失败时需要手动处理已执行的部分；
+// PostgreSQL 与 SQLite 的 DDL 支持事务，失败时整个迁移回滚
+func execScript(tx *gorm.DB, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements 按分号拆分 SQL 脚本，忽略引号内的分号和 -- 注释
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	var quote rune
+
+	lines := strings.Split(script, "\n")
+	for _, line := range lines {
+		if quote == 0 && strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		for _, ch := range line {
+			switch {
+			case quote != 0:
+				if ch == quote {
+					quote = 0
+				}
+			case ch == '\'' || ch == '"' || ch == '`':
+				quote = ch
+			case ch == ';':
+				if stmt := strings.TrimSpace(current.String()); stmt != "" {
+					statements = append(statements, stmt)
+				}
+				current.Reset()
+				continue
+			}
+			current.WriteRune(ch)
+		}
+		current.WriteRune('\n')
+	}
+	if stmt := strings.TrimSpace(current.String()); stmt != "" {
+		statements = append(statements, stmt)
+	}
+	return statements
+}
@@ -0,0 +1,134 @@
+-- 初始表结构（与改用版本化迁移前 AutoMigrate 生成的结构一致）
+-- 使用 IF NOT EXISTS，已由 AutoMigrate 建好表的旧库执行本迁移时不会报错
+
+-- 1. 基础表
+CREATE TABLE IF NOT EXISTS person (
+    person_id BIGINT NOT NULL AUTO_INCREMENT,
+    name VARCHAR(20) NOT NULL,
+    role VARCHAR(7) NOT NULL COMMENT '角色：员工/讲师/课程大纲制定者',
+    PRIMARY KEY (person_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 2. 依赖 person 的表
+CREATE TABLE IF NOT EXISTS account (
+    account_id BIGINT NOT NULL AUTO_INCREMENT,
+    person_id BIGINT NOT NULL,
+    login_name VARCHAR(20) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    PRIMARY KEY (account_id),
+    UNIQUE KEY idx_account_login_name (login_name),
+    KEY idx_account_person_id (person_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS training_plan (
+    plan_id BIGINT NOT NULL AUTO_INCREMENT,
+    plan_name VARCHAR(50) NOT NULL,
+    plan_status VARCHAR(3) NOT NULL COMMENT '规划中/进行中/已完成',
+    plan_start_datetime DATETIME(3) NOT NULL,
+    plan_end_datetime DATETIME(3) NOT NULL,
+    creator_id BIGINT NOT NULL,
+    PRIMARY KEY (plan_id),
+    KEY idx_training_plan_creator_id (creator_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS course (
+    course_id BIGINT NOT NULL AUTO_INCREMENT,
+    course_name VARCHAR(50) NOT NULL,
+    course_desc VARCHAR(100),
+    course_require VARCHAR(500),
+    course_class VARCHAR(20) NOT NULL,
+    teacher_id BIGINT NOT NULL,
+    PRIMARY KEY (course_id),
+    KEY idx_course_teacher_id (teacher_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 3. 课程安排
+CREATE TABLE IF NOT EXISTS plan_course_item (
+    item_id BIGINT NOT NULL AUTO_INCREMENT,
+    plan_id BIGINT NOT NULL,
+    course_id BIGINT NOT NULL,
+    class_date DATE NOT NULL,
+    class_begin_time VARCHAR(8) NOT NULL,
+    class_end_time VARCHAR(8) NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    PRIMARY KEY (item_id),
+    KEY idx_plan_course_item_plan_id (plan_id),
+    KEY idx_plan_course_item_course_id (course_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 4. 关联表
+CREATE TABLE IF NOT EXISTS attendance_evaluation (
+    person_id BIGINT NOT NULL,
+    item_id BIGINT NOT NULL,
+    self_score FLOAT,
+    self_comment TEXT,
+    teacher_score FLOAT,
+    teacher_comment TEXT,
+    understanding SMALLINT DEFAULT 0 COMMENT '理解程度1-5，0表示未填写',
+    difficulty SMALLINT DEFAULT 0 COMMENT '难度感受1-5，0表示未填写',
+    satisfaction SMALLINT DEFAULT 0 COMMENT '满意度1-5，0表示未填写',
+    self_score_rationale TEXT COMMENT 'AI自评评分理由',
+    teacher_score_rationale TEXT COMMENT 'AI讲师评分理由',
+    self_scoring_status VARCHAR(10) COMMENT '自评AI评分状态',
+    teacher_scoring_status VARCHAR(10) COMMENT '讲师AI评分状态',
+    score_ratio FLOAT COMMENT '讲师评分占比',
+    PRIMARY KEY (person_id, item_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS plan_employee (
+    plan_id BIGINT NOT NULL,
+    person_id BIGINT NOT NULL,
+    PRIMARY KEY (plan_id, person_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sessions (
+    session_id VARCHAR(64) NOT NULL,
+    person_id BIGINT NOT NULL,
+    role VARCHAR(10) NOT NULL,
+    created_at DATETIME(3),
+    expires_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (session_id),
+    KEY idx_sessions_person_id (person_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 5. 日志与任务表
+CREATE TABLE IF NOT EXISTS ai_scoring_log (
+    log_id BIGINT NOT NULL AUTO_INCREMENT,
+    person_id BIGINT NOT NULL,
+    item_id BIGINT NOT NULL,
+    score_type VARCHAR(10) NOT NULL COMMENT 'self/teacher',
+    provider VARCHAR(20),
+    prompt TEXT,
+    raw_output TEXT,
+    parsed_score FLOAT COMMENT '模型输出解析出的分数',
+    final_score FLOAT COMMENT '最终采用的分数',
+    rationale TEXT,
+    used_fallback BOOLEAN NOT NULL DEFAULT FALSE,
+    latency_ms BIGINT,
+    error_message TEXT,
+    created_at DATETIME(3),
+    PRIMARY KEY (log_id),
+    KEY idx_ai_scoring_log_eval (person_id, item_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS ai_scoring_job (
+    job_id BIGINT NOT NULL AUTO_INCREMENT,
+    person_id BIGINT NOT NULL,
+    item_id BIGINT NOT NULL,
+    score_type VARCHAR(10) NOT NULL COMMENT 'self/teacher',
+    status VARCHAR(10) NOT NULL COMMENT 'pending/running/done/failed/cancelled',
+    course_name VARCHAR(50),
+    comment TEXT,
+    understanding BIGINT,
+    difficulty BIGINT,
+    satisfaction BIGINT,
+    attempts BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_run_at DATETIME(3) NOT NULL,
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    finished_at DATETIME(3),
+    PRIMARY KEY (job_id),
+    KEY idx_ai_scoring_job_eval (person_id, item_id),
+    KEY idx_ai_scoring_job_due (status, next_run_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
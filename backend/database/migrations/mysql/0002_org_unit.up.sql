@@ -0,0 +1,12 @@
+-- 组织架构：公司 → 部门 → 班组/船舶，人员归属到某个节点
+CREATE TABLE IF NOT EXISTS org_unit (
+    unit_id BIGINT NOT NULL AUTO_INCREMENT,
+    unit_name VARCHAR(50) NOT NULL,
+    unit_type VARCHAR(4) NOT NULL COMMENT '公司/部门/班组/船舶',
+    parent_id BIGINT NULL COMMENT '上级节点，为空表示根节点',
+    PRIMARY KEY (unit_id),
+    KEY idx_org_unit_parent_id (parent_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE person ADD COLUMN org_unit_id BIGINT NULL COMMENT '所属组织节点，为空表示未分配';
+CREATE INDEX idx_person_org_unit_id ON person (org_unit_id);
@@ -0,0 +1,33 @@
+-- 自动报名规则：人员职级/入职日期/证书，计划报名规则与排除名单
+ALTER TABLE person ADD COLUMN job_rank VARCHAR(20) NOT NULL DEFAULT '' COMMENT '职级，如船长/大副/水手';
+ALTER TABLE person ADD COLUMN hire_date DATE NULL COMMENT '入职日期';
+
+CREATE TABLE IF NOT EXISTS person_certificate (
+    cert_id BIGINT NOT NULL AUTO_INCREMENT,
+    person_id BIGINT NOT NULL,
+    cert_name VARCHAR(50) NOT NULL,
+    expires_on DATE NULL COMMENT '有效期至，为空表示长期有效',
+    PRIMARY KEY (cert_id),
+    UNIQUE KEY idx_person_certificate_name (person_id, cert_name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE plan_employee ADD COLUMN source VARCHAR(10) NOT NULL DEFAULT 'manual' COMMENT 'manual/rule';
+
+CREATE TABLE IF NOT EXISTS plan_enrollment_rule (
+    rule_id BIGINT NOT NULL AUTO_INCREMENT,
+    plan_id BIGINT NOT NULL,
+    org_unit_id BIGINT NULL COMMENT '组织节点（含下级节点）',
+    job_rank VARCHAR(20) NOT NULL DEFAULT '' COMMENT '职级',
+    hired_after DATE NULL COMMENT '入职日期晚于',
+    missing_certificate VARCHAR(50) NOT NULL DEFAULT '' COMMENT '未持有（或已过期）的证书',
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (rule_id),
+    KEY idx_plan_enrollment_rule_plan_id (plan_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS plan_enrollment_exclusion (
+    plan_id BIGINT NOT NULL,
+    person_id BIGINT NOT NULL,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (plan_id, person_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
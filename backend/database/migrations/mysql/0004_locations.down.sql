@@ -0,0 +1,3 @@
+DROP INDEX idx_plan_course_item_location_id ON plan_course_item;
+ALTER TABLE plan_course_item DROP COLUMN location_id;
+DROP TABLE IF EXISTS location;
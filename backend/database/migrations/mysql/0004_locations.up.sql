@@ -0,0 +1,19 @@
+-- 培训地点：课程安排引用地点，用于教室冲突和容量检查
+CREATE TABLE IF NOT EXISTS location (
+    location_id BIGINT NOT NULL AUTO_INCREMENT,
+    location_name VARCHAR(100) NOT NULL,
+    building VARCHAR(50) NOT NULL DEFAULT '' COMMENT '所在楼宇或船舶',
+    capacity BIGINT NOT NULL DEFAULT 0 COMMENT '容纳人数，0表示不限',
+    equipment VARCHAR(500) NOT NULL DEFAULT '' COMMENT '设备，逗号分隔',
+    PRIMARY KEY (location_id),
+    UNIQUE KEY idx_location_location_name (location_name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE plan_course_item ADD COLUMN location_id BIGINT NULL COMMENT '上课地点，location 字段保存地点名称';
+CREATE INDEX idx_plan_course_item_location_id ON plan_course_item (location_id);
+
+-- 已有课程安排的地点文本登记为不限容量的地点
+INSERT INTO location (location_name)
+SELECT DISTINCT location FROM plan_course_item WHERE location <> '';
+UPDATE plan_course_item
+SET location_id = (SELECT l.location_id FROM location l WHERE l.location_name = plan_course_item.location);
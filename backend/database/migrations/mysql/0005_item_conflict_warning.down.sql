@@ -0,0 +1 @@
+ALTER TABLE plan_course_item DROP COLUMN conflict_warning;
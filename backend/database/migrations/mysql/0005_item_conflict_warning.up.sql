@@ -0,0 +1,2 @@
+-- 员工时间冲突时强制保存的课程安排记录冲突警告
+ALTER TABLE plan_course_item ADD COLUMN conflict_warning VARCHAR(1000) NOT NULL DEFAULT '' COMMENT '强制保存时记录的员工时间冲突';
@@ -0,0 +1,19 @@
+-- 重复课程系列：按 RRULE 展开的课程安排通过 series_id 关联
+CREATE TABLE IF NOT EXISTS course_item_series (
+    series_id BIGINT NOT NULL AUTO_INCREMENT,
+    plan_id BIGINT NOT NULL,
+    course_id BIGINT NOT NULL,
+    rrule VARCHAR(255) NOT NULL COMMENT 'RFC 5545 重复规则',
+    start_date DATE NOT NULL,
+    class_begin_time VARCHAR(8) NOT NULL,
+    class_end_time VARCHAR(8) NOT NULL,
+    location_id BIGINT NULL,
+    location VARCHAR(100) NOT NULL,
+    exclude_dates VARCHAR(4000) NOT NULL DEFAULT '' COMMENT '不上课的日期，逗号分隔',
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (series_id),
+    KEY idx_course_item_series_plan_id (plan_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE plan_course_item ADD COLUMN series_id BIGINT NULL COMMENT '所属重复课程系列，单次安排为空';
+CREATE INDEX idx_plan_course_item_series_id ON plan_course_item (series_id);
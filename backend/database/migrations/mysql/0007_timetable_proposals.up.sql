@@ -0,0 +1,11 @@
+-- 自动排课方案：生成后保存，规划者可整体或部分采纳
+CREATE TABLE IF NOT EXISTS timetable_proposal (
+    proposal_id BIGINT NOT NULL AUTO_INCREMENT,
+    plan_id BIGINT NOT NULL,
+    sessions TEXT NOT NULL COMMENT '方案中的上课（JSON）',
+    unplaced TEXT NOT NULL COMMENT '未能排入的课程（JSON）',
+    creator_id BIGINT NOT NULL,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (proposal_id),
+    KEY idx_timetable_proposal_plan_id (plan_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
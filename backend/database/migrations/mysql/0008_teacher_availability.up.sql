@@ -0,0 +1,23 @@
+-- 讲师每周可授课时间段和不可授课时间段
+CREATE TABLE IF NOT EXISTS teacher_availability (
+    availability_id BIGINT NOT NULL AUTO_INCREMENT,
+    teacher_id BIGINT NOT NULL,
+    weekday TINYINT NOT NULL COMMENT '星期，1-7 表示周一到周日',
+    begin_time VARCHAR(8) NOT NULL,
+    end_time VARCHAR(8) NOT NULL,
+    PRIMARY KEY (availability_id),
+    KEY idx_teacher_availability_teacher_id (teacher_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS teacher_blackout (
+    blackout_id BIGINT NOT NULL AUTO_INCREMENT,
+    teacher_id BIGINT NOT NULL,
+    start_date DATE NOT NULL,
+    begin_time VARCHAR(8) NOT NULL,
+    end_date DATE NOT NULL,
+    end_time VARCHAR(8) NOT NULL,
+    reason VARCHAR(200) NOT NULL DEFAULT '',
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (blackout_id),
+    KEY idx_teacher_blackout_teacher_id (teacher_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,15 @@
+-- 工作日历：法定节假日、公司停工和按楼宇/船舶的工作日调整
+CREATE TABLE IF NOT EXISTS calendar_entry (
+    entry_id BIGINT NOT NULL AUTO_INCREMENT,
+    kind VARCHAR(20) NOT NULL COMMENT 'holiday 法定节假日 / shutdown 公司停工 / workday 照常工作',
+    name VARCHAR(100) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    site VARCHAR(50) NOT NULL DEFAULT '' COMMENT '适用的楼宇或船舶（对应 location.building），为空表示全部地点',
+    policy VARCHAR(10) NOT NULL DEFAULT '' COMMENT '排课时 warn 提醒 / block 禁止，workday 为空',
+    source VARCHAR(10) NOT NULL DEFAULT 'manual' COMMENT 'manual 手动登记 / ical 导入',
+    uid VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'iCal 事件 UID，重复导入时用于更新',
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (entry_id),
+    KEY idx_calendar_entry_site (site)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,25 @@
+-- 日历订阅：个人订阅令牌、课程安排修改次数和已删除课程安排的快照
+CREATE TABLE IF NOT EXISTS calendar_feed (
+    person_id BIGINT NOT NULL,
+    token VARCHAR(64) NOT NULL COMMENT '订阅地址中的密钥，重置后旧地址失效',
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (person_id),
+    UNIQUE KEY idx_calendar_feed_token (token)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE plan_course_item ADD COLUMN sequence INT NOT NULL DEFAULT 0 COMMENT '修改次数，日历订阅中作为事件的 SEQUENCE';
+
+CREATE TABLE IF NOT EXISTS course_item_cancellation (
+    item_id BIGINT NOT NULL COMMENT '已删除的课程安排ID',
+    plan_id BIGINT NOT NULL,
+    course_id BIGINT NOT NULL,
+    class_date DATE NOT NULL,
+    class_begin_time VARCHAR(8) NOT NULL,
+    class_end_time VARCHAR(8) NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    sequence INT NOT NULL DEFAULT 0,
+    cancelled_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (item_id),
+    KEY idx_course_item_cancellation_plan_id (plan_id),
+    KEY idx_course_item_cancellation_course_id (course_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
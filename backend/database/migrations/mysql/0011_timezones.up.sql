@@ -0,0 +1,5 @@
+-- 时区：地点和计划可设置 IANA 时区，课程安排保存上课时间所在的时区
+ALTER TABLE location ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'IANA 时区，为空时使用计划或系统默认时区';
+ALTER TABLE training_plan ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'IANA 时区，为空时使用系统默认时区';
+ALTER TABLE plan_course_item ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '' COMMENT '上课日期和时间所在的 IANA 时区，为空表示系统默认时区';
+ALTER TABLE course_item_cancellation ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '';
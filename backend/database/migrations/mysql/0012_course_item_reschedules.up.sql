@@ -0,0 +1,16 @@
+-- 课程安排改期记录：改期原因和每条课程安排改期前后的时间
+CREATE TABLE IF NOT EXISTS course_item_reschedule (
+    reschedule_id BIGINT NOT NULL AUTO_INCREMENT,
+    plan_id BIGINT NOT NULL,
+    item_id BIGINT NULL COMMENT '单条课程安排改期时为该安排，整个计划顺延时为空',
+    shift_days INT NOT NULL DEFAULT 0 COMMENT '顺延天数，改到指定时间段时为0',
+    reason VARCHAR(500) NOT NULL,
+    moves TEXT NOT NULL COMMENT '每条课程安排改期前后的时间（JSON）',
+    plan_start_datetime DATETIME(3) NULL COMMENT '整个计划顺延前的开始时间',
+    plan_end_datetime DATETIME(3) NULL COMMENT '整个计划顺延前的结束时间',
+    operator_id BIGINT NOT NULL,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (reschedule_id),
+    KEY idx_course_item_reschedule_plan_id (plan_id),
+    KEY idx_course_item_reschedule_item_id (item_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
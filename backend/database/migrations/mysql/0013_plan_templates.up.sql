@@ -0,0 +1,16 @@
+-- 培训计划模板：课程安排（相对计划开始日期的天数）、上课时间、地点和报名规则
+CREATE TABLE IF NOT EXISTS plan_template (
+    template_id BIGINT NOT NULL AUTO_INCREMENT,
+    template_name VARCHAR(50) NOT NULL,
+    description VARCHAR(200) NOT NULL DEFAULT '',
+    source_plan_id BIGINT NULL COMMENT '保存模板时的培训计划',
+    duration_days INT NOT NULL COMMENT '计划结束日期与开始日期相差的天数',
+    plan_start_time VARCHAR(8) NOT NULL,
+    plan_end_time VARCHAR(8) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    items TEXT NOT NULL COMMENT '课程安排（JSON）',
+    enrollment_rules TEXT NOT NULL COMMENT '报名规则（JSON）',
+    creator_id BIGINT NOT NULL,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (template_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
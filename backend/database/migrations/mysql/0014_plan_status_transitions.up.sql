@@ -0,0 +1,13 @@
+-- 培训计划状态变更记录
+CREATE TABLE IF NOT EXISTS plan_status_transition (
+    transition_id BIGINT NOT NULL AUTO_INCREMENT,
+    plan_id BIGINT NOT NULL,
+    from_status VARCHAR(3) NOT NULL DEFAULT '' COMMENT '变更前的状态，创建计划时为空',
+    to_status VARCHAR(3) NOT NULL,
+    actor_id BIGINT NULL COMMENT '操作人，定时任务自动变更时为空',
+    source VARCHAR(10) NOT NULL COMMENT 'create/manual/auto',
+    reason VARCHAR(200) NOT NULL DEFAULT '',
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (transition_id),
+    KEY idx_plan_status_transition_plan_id (plan_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
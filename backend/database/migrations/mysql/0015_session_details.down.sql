@@ -0,0 +1,4 @@
+DROP INDEX idx_sessions_expires_at ON sessions;
+ALTER TABLE sessions DROP COLUMN user_agent;
+ALTER TABLE sessions DROP COLUMN ip_address;
+ALTER TABLE sessions DROP COLUMN last_seen_at;
@@ -0,0 +1,5 @@
+-- 会话管理：记录登录设备和最近活跃时间，按过期时间定时清理
+ALTER TABLE sessions ADD COLUMN last_seen_at DATETIME(3) NULL COMMENT '最近一次登录或刷新访问令牌的时间';
+ALTER TABLE sessions ADD COLUMN ip_address VARCHAR(45) NOT NULL DEFAULT '' COMMENT '登录时的客户端 IP';
+ALTER TABLE sessions ADD COLUMN user_agent VARCHAR(255) NOT NULL DEFAULT '' COMMENT '登录时的 User-Agent';
+CREATE INDEX idx_sessions_expires_at ON sessions (expires_at);
@@ -0,0 +1,14 @@
+-- 找回密码：账号绑定的邮箱，以及一次性的密码重置令牌
+ALTER TABLE account ADD COLUMN email VARCHAR(100) NULL COMMENT '找回密码时接收重置链接的邮箱';
+CREATE TABLE IF NOT EXISTS password_reset_token (
+    token_id BIGINT NOT NULL AUTO_INCREMENT,
+    account_id BIGINT NOT NULL,
+    token_hash VARCHAR(64) NOT NULL COMMENT '重置令牌的 SHA-256 哈希',
+    requested_ip VARCHAR(45) NOT NULL DEFAULT '' COMMENT '申请重置时的客户端 IP',
+    created_at DATETIME(3) NULL,
+    expires_at DATETIME(3) NOT NULL,
+    used_at DATETIME(3) NULL COMMENT '使用时间，为空表示未使用；申请新令牌时旧令牌也记为已使用',
+    PRIMARY KEY (token_id),
+    UNIQUE KEY idx_password_reset_token_hash (token_hash),
+    KEY idx_password_reset_token_account_id (account_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,21 @@
+-- 登录防暴力破解：按用户名和 IP 统计的连续失败次数（LOGIN_GUARD_STORE=database 时多实例共享），以及每次失败登录的审计记录
+CREATE TABLE IF NOT EXISTS login_attempt (
+    attempt_key VARCHAR(120) NOT NULL COMMENT 'user:<用户名> 或 ip:<客户端 IP>',
+    failures INT NOT NULL DEFAULT 0 COMMENT '统计窗口内连续失败次数，锁定后清零',
+    last_failure_at DATETIME(3) NULL,
+    locked_until DATETIME(3) NULL COMMENT '锁定截止时间，为空表示未锁定',
+    PRIMARY KEY (attempt_key)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+CREATE TABLE IF NOT EXISTS login_failure (
+    failure_id BIGINT NOT NULL AUTO_INCREMENT,
+    login_name VARCHAR(50) NOT NULL COMMENT '尝试登录的用户名（超长时截断）',
+    person_id BIGINT NULL COMMENT '用户名对应的人员，账号不存在时为空',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    reason VARCHAR(20) NOT NULL COMMENT 'unknown_user/wrong_password/throttled/locked',
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (failure_id),
+    KEY idx_login_failure_login_name (login_name),
+    KEY idx_login_failure_ip_address (ip_address),
+    KEY idx_login_failure_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,18 @@
+-- 邀请注册：按角色发放的邀请码，账号记录使用的邀请码和停用时间
+CREATE TABLE IF NOT EXISTS invitation (
+    invitation_id BIGINT NOT NULL AUTO_INCREMENT,
+    code_hash VARCHAR(64) NOT NULL COMMENT '邀请码的 SHA-256 哈希',
+    code_hint VARCHAR(8) NOT NULL DEFAULT '' COMMENT '邀请码前几位，便于辨认',
+    role VARCHAR(7) NOT NULL COMMENT '注册后的角色：员工/讲师/课程大纲制定者',
+    email VARCHAR(100) NOT NULL DEFAULT '' COMMENT '受邀人邮箱，填写时发送邀请邮件',
+    max_uses INT NOT NULL DEFAULT 1 COMMENT '可注册的账号数',
+    used_count INT NOT NULL DEFAULT 0 COMMENT '已注册的账号数',
+    expires_at DATETIME(3) NOT NULL,
+    revoked_at DATETIME(3) NULL COMMENT '撤销时间，为空表示未撤销',
+    creator_id BIGINT NOT NULL,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (invitation_id),
+    UNIQUE KEY idx_invitation_code_hash (code_hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+ALTER TABLE account ADD COLUMN invitation_id BIGINT NULL COMMENT '注册时使用的邀请码，自助注册或管理员创建时为空';
+ALTER TABLE account ADD COLUMN deactivated_at DATETIME(3) NULL COMMENT '停用时间，为空表示正常';
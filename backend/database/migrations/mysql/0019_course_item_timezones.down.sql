@@ -0,0 +1 @@
+-- 补齐的时区无法区分原来是否为空，回滚时保留
@@ -0,0 +1,4 @@
+-- 回滚后会话标识重新作为刷新令牌的哈希使用，已轮换过刷新令牌的会话需要重新登录
+DELETE FROM sessions WHERE session_id <> refresh_token_hash;
+DROP INDEX idx_sessions_refresh_token_hash ON sessions;
+ALTER TABLE sessions DROP COLUMN refresh_token_hash;
@@ -0,0 +1,4 @@
+-- 刷新令牌轮换：会话标识不再随刷新令牌变化，刷新令牌的哈希单独保存，每次刷新换成新的哈希
+ALTER TABLE sessions ADD COLUMN refresh_token_hash VARCHAR(64) NOT NULL DEFAULT '' COMMENT '当前刷新令牌的 SHA-256 哈希，刷新后旧令牌失效';
+UPDATE sessions SET refresh_token_hash = session_id WHERE refresh_token_hash = '';
+CREATE UNIQUE INDEX idx_sessions_refresh_token_hash ON sessions (refresh_token_hash);
@@ -0,0 +1,8 @@
+-- 刷新令牌重复使用检测：保存已被换下的刷新令牌哈希，再次出现时注销整个会话
+CREATE TABLE IF NOT EXISTS retired_refresh_tokens (
+    token_hash VARCHAR(64) NOT NULL COMMENT '已换下的刷新令牌的 SHA-256 哈希',
+    session_id VARCHAR(64) NOT NULL,
+    retired_at DATETIME(3) NULL COMMENT '换下的时间',
+    PRIMARY KEY (token_hash),
+    KEY idx_retired_refresh_tokens_session_id (session_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,14 @@
+-- 组织架构：公司 → 部门 → 班组/船舶，人员归属到某个节点
+CREATE TABLE IF NOT EXISTS org_unit (
+    unit_id BIGSERIAL PRIMARY KEY,
+    unit_name VARCHAR(50) NOT NULL,
+    unit_type VARCHAR(4) NOT NULL,
+    parent_id BIGINT
+);
+CREATE INDEX IF NOT EXISTS idx_org_unit_parent_id ON org_unit (parent_id);
+COMMENT ON COLUMN org_unit.unit_type IS '公司/部门/班组/船舶';
+COMMENT ON COLUMN org_unit.parent_id IS '上级节点，为空表示根节点';
+
+ALTER TABLE person ADD COLUMN IF NOT EXISTS org_unit_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_person_org_unit_id ON person (org_unit_id);
+COMMENT ON COLUMN person.org_unit_id IS '所属组织节点，为空表示未分配';
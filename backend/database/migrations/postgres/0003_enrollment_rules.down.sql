@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS plan_enrollment_exclusion;
+DROP TABLE IF EXISTS plan_enrollment_rule;
+ALTER TABLE plan_employee DROP COLUMN IF EXISTS source;
+DROP TABLE IF EXISTS person_certificate;
+ALTER TABLE person DROP COLUMN IF EXISTS hire_date;
+ALTER TABLE person DROP COLUMN IF EXISTS job_rank;
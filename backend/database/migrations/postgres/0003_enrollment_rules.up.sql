@@ -0,0 +1,38 @@
+-- 自动报名规则：人员职级/入职日期/证书，计划报名规则与排除名单
+ALTER TABLE person ADD COLUMN IF NOT EXISTS job_rank VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE person ADD COLUMN IF NOT EXISTS hire_date DATE;
+COMMENT ON COLUMN person.job_rank IS '职级，如船长/大副/水手';
+COMMENT ON COLUMN person.hire_date IS '入职日期';
+
+CREATE TABLE IF NOT EXISTS person_certificate (
+    cert_id BIGSERIAL PRIMARY KEY,
+    person_id BIGINT NOT NULL,
+    cert_name VARCHAR(50) NOT NULL,
+    expires_on DATE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_person_certificate_name ON person_certificate (person_id, cert_name);
+COMMENT ON COLUMN person_certificate.expires_on IS '有效期至，为空表示长期有效';
+
+ALTER TABLE plan_employee ADD COLUMN IF NOT EXISTS source VARCHAR(10) NOT NULL DEFAULT 'manual';
+COMMENT ON COLUMN plan_employee.source IS 'manual/rule';
+
+CREATE TABLE IF NOT EXISTS plan_enrollment_rule (
+    rule_id BIGSERIAL PRIMARY KEY,
+    plan_id BIGINT NOT NULL,
+    org_unit_id BIGINT,
+    job_rank VARCHAR(20) NOT NULL DEFAULT '',
+    hired_after DATE,
+    missing_certificate VARCHAR(50) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_plan_enrollment_rule_plan_id ON plan_enrollment_rule (plan_id);
+COMMENT ON COLUMN plan_enrollment_rule.org_unit_id IS '组织节点（含下级节点）';
+COMMENT ON COLUMN plan_enrollment_rule.hired_after IS '入职日期晚于';
+COMMENT ON COLUMN plan_enrollment_rule.missing_certificate IS '未持有（或已过期）的证书';
+
+CREATE TABLE IF NOT EXISTS plan_enrollment_exclusion (
+    plan_id BIGINT NOT NULL,
+    person_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ,
+    PRIMARY KEY (plan_id, person_id)
+);
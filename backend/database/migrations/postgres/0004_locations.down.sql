@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_plan_course_item_location_id;
+ALTER TABLE plan_course_item DROP COLUMN IF EXISTS location_id;
+DROP TABLE IF EXISTS location;
@@ -0,0 +1,22 @@
+-- 培训地点：课程安排引用地点，用于教室冲突和容量检查
+CREATE TABLE IF NOT EXISTS location (
+    location_id BIGSERIAL PRIMARY KEY,
+    location_name VARCHAR(100) NOT NULL,
+    building VARCHAR(50) NOT NULL DEFAULT '',
+    capacity BIGINT NOT NULL DEFAULT 0,
+    equipment VARCHAR(500) NOT NULL DEFAULT ''
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_location_location_name ON location (location_name);
+COMMENT ON COLUMN location.building IS '所在楼宇或船舶';
+COMMENT ON COLUMN location.capacity IS '容纳人数，0表示不限';
+COMMENT ON COLUMN location.equipment IS '设备，逗号分隔';
+
+ALTER TABLE plan_course_item ADD COLUMN IF NOT EXISTS location_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_plan_course_item_location_id ON plan_course_item (location_id);
+COMMENT ON COLUMN plan_course_item.location_id IS '上课地点，location 字段保存地点名称';
+
+-- 已有课程安排的地点文本登记为不限容量的地点
+INSERT INTO location (location_name)
+SELECT DISTINCT location FROM plan_course_item WHERE location <> '';
+UPDATE plan_course_item
+SET location_id = (SELECT l.location_id FROM location l WHERE l.location_name = plan_course_item.location);
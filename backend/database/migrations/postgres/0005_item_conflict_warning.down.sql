@@ -0,0 +1 @@
+ALTER TABLE plan_course_item DROP COLUMN IF EXISTS conflict_warning;
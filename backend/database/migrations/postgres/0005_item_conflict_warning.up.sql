@@ -0,0 +1,3 @@
+-- 员工时间冲突时强制保存的课程安排记录冲突警告
+ALTER TABLE plan_course_item ADD COLUMN IF NOT EXISTS conflict_warning VARCHAR(1000) NOT NULL DEFAULT '';
+COMMENT ON COLUMN plan_course_item.conflict_warning IS '强制保存时记录的员工时间冲突';
@@ -0,0 +1,21 @@
+-- 重复课程系列：按 RRULE 展开的课程安排通过 series_id 关联
+CREATE TABLE IF NOT EXISTS course_item_series (
+    series_id BIGSERIAL PRIMARY KEY,
+    plan_id BIGINT NOT NULL,
+    course_id BIGINT NOT NULL,
+    rrule VARCHAR(255) NOT NULL,
+    start_date DATE NOT NULL,
+    class_begin_time VARCHAR(8) NOT NULL,
+    class_end_time VARCHAR(8) NOT NULL,
+    location_id BIGINT,
+    location VARCHAR(100) NOT NULL,
+    exclude_dates VARCHAR(4000) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_course_item_series_plan_id ON course_item_series (plan_id);
+COMMENT ON COLUMN course_item_series.rrule IS 'RFC 5545 重复规则';
+COMMENT ON COLUMN course_item_series.exclude_dates IS '不上课的日期，逗号分隔';
+
+ALTER TABLE plan_course_item ADD COLUMN IF NOT EXISTS series_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_plan_course_item_series_id ON plan_course_item (series_id);
+COMMENT ON COLUMN plan_course_item.series_id IS '所属重复课程系列，单次安排为空';
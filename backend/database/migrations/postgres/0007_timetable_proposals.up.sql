@@ -0,0 +1,12 @@
+-- 自动排课方案：生成后保存，规划者可整体或部分采纳
+CREATE TABLE IF NOT EXISTS timetable_proposal (
+    proposal_id BIGSERIAL PRIMARY KEY,
+    plan_id BIGINT NOT NULL,
+    sessions TEXT NOT NULL,
+    unplaced TEXT NOT NULL,
+    creator_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_timetable_proposal_plan_id ON timetable_proposal (plan_id);
+COMMENT ON COLUMN timetable_proposal.sessions IS '方案中的上课（JSON）';
+COMMENT ON COLUMN timetable_proposal.unplaced IS '未能排入的课程（JSON）';
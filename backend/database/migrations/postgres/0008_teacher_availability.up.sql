@@ -0,0 +1,22 @@
+-- 讲师每周可授课时间段和不可授课时间段
+CREATE TABLE IF NOT EXISTS teacher_availability (
+    availability_id BIGSERIAL PRIMARY KEY,
+    teacher_id BIGINT NOT NULL,
+    weekday SMALLINT NOT NULL,
+    begin_time VARCHAR(8) NOT NULL,
+    end_time VARCHAR(8) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_teacher_availability_teacher_id ON teacher_availability (teacher_id);
+COMMENT ON COLUMN teacher_availability.weekday IS '星期，1-7 表示周一到周日';
+
+CREATE TABLE IF NOT EXISTS teacher_blackout (
+    blackout_id BIGSERIAL PRIMARY KEY,
+    teacher_id BIGINT NOT NULL,
+    start_date DATE NOT NULL,
+    begin_time VARCHAR(8) NOT NULL,
+    end_date DATE NOT NULL,
+    end_time VARCHAR(8) NOT NULL,
+    reason VARCHAR(200) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_teacher_blackout_teacher_id ON teacher_blackout (teacher_id);
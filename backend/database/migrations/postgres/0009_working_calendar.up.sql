@@ -0,0 +1,19 @@
+-- 工作日历：法定节假日、公司停工和按楼宇/船舶的工作日调整
+CREATE TABLE IF NOT EXISTS calendar_entry (
+    entry_id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    site VARCHAR(50) NOT NULL DEFAULT '',
+    policy VARCHAR(10) NOT NULL DEFAULT '',
+    source VARCHAR(10) NOT NULL DEFAULT 'manual',
+    uid VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_calendar_entry_site ON calendar_entry (site);
+COMMENT ON COLUMN calendar_entry.kind IS 'holiday 法定节假日 / shutdown 公司停工 / workday 照常工作';
+COMMENT ON COLUMN calendar_entry.site IS '适用的楼宇或船舶（对应 location.building），为空表示全部地点';
+COMMENT ON COLUMN calendar_entry.policy IS '排课时 warn 提醒 / block 禁止，workday 为空';
+COMMENT ON COLUMN calendar_entry.source IS 'manual 手动登记 / ical 导入';
+COMMENT ON COLUMN calendar_entry.uid IS 'iCal 事件 UID，重复导入时用于更新';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS course_item_cancellation;
+ALTER TABLE plan_course_item DROP COLUMN IF EXISTS sequence;
+DROP TABLE IF EXISTS calendar_feed;
@@ -0,0 +1,26 @@
+-- 日历订阅：个人订阅令牌、课程安排修改次数和已删除课程安排的快照
+CREATE TABLE IF NOT EXISTS calendar_feed (
+    person_id BIGINT PRIMARY KEY,
+    token VARCHAR(64) NOT NULL,
+    created_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_feed_token ON calendar_feed (token);
+COMMENT ON COLUMN calendar_feed.token IS '订阅地址中的密钥，重置后旧地址失效';
+
+ALTER TABLE plan_course_item ADD COLUMN IF NOT EXISTS sequence INTEGER NOT NULL DEFAULT 0;
+COMMENT ON COLUMN plan_course_item.sequence IS '修改次数，日历订阅中作为事件的 SEQUENCE';
+
+CREATE TABLE IF NOT EXISTS course_item_cancellation (
+    item_id BIGINT PRIMARY KEY,
+    plan_id BIGINT NOT NULL,
+    course_id BIGINT NOT NULL,
+    class_date DATE NOT NULL,
+    class_begin_time VARCHAR(8) NOT NULL,
+    class_end_time VARCHAR(8) NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    sequence INTEGER NOT NULL DEFAULT 0,
+    cancelled_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_course_item_cancellation_plan_id ON course_item_cancellation (plan_id);
+CREATE INDEX IF NOT EXISTS idx_course_item_cancellation_course_id ON course_item_cancellation (course_id);
+COMMENT ON COLUMN course_item_cancellation.item_id IS '已删除的课程安排ID';
@@ -0,0 +1,4 @@
+ALTER TABLE course_item_cancellation DROP COLUMN IF EXISTS timezone;
+ALTER TABLE plan_course_item DROP COLUMN IF EXISTS timezone;
+ALTER TABLE training_plan DROP COLUMN IF EXISTS timezone;
+ALTER TABLE location DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,8 @@
+-- 时区：地点和计划可设置 IANA 时区，课程安排保存上课时间所在的时区
+ALTER TABLE location ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE training_plan ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE plan_course_item ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE course_item_cancellation ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT '';
+COMMENT ON COLUMN location.timezone IS 'IANA 时区，为空时使用计划或系统默认时区';
+COMMENT ON COLUMN training_plan.timezone IS 'IANA 时区，为空时使用系统默认时区';
+COMMENT ON COLUMN plan_course_item.timezone IS '上课日期和时间所在的 IANA 时区，为空表示系统默认时区';
@@ -0,0 +1,20 @@
+-- 课程安排改期记录：改期原因和每条课程安排改期前后的时间
+CREATE TABLE IF NOT EXISTS course_item_reschedule (
+    reschedule_id BIGSERIAL PRIMARY KEY,
+    plan_id BIGINT NOT NULL,
+    item_id BIGINT,
+    shift_days INTEGER NOT NULL DEFAULT 0,
+    reason VARCHAR(500) NOT NULL,
+    moves TEXT NOT NULL,
+    plan_start_datetime TIMESTAMPTZ,
+    plan_end_datetime TIMESTAMPTZ,
+    operator_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_course_item_reschedule_plan_id ON course_item_reschedule (plan_id);
+CREATE INDEX IF NOT EXISTS idx_course_item_reschedule_item_id ON course_item_reschedule (item_id);
+COMMENT ON COLUMN course_item_reschedule.item_id IS '单条课程安排改期时为该安排，整个计划顺延时为空';
+COMMENT ON COLUMN course_item_reschedule.shift_days IS '顺延天数，改到指定时间段时为0';
+COMMENT ON COLUMN course_item_reschedule.moves IS '每条课程安排改期前后的时间（JSON）';
+COMMENT ON COLUMN course_item_reschedule.plan_start_datetime IS '整个计划顺延前的开始时间';
+COMMENT ON COLUMN course_item_reschedule.plan_end_datetime IS '整个计划顺延前的结束时间';
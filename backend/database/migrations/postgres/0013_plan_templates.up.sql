@@ -0,0 +1,19 @@
+-- 培训计划模板：课程安排（相对计划开始日期的天数）、上课时间、地点和报名规则
+CREATE TABLE IF NOT EXISTS plan_template (
+    template_id BIGSERIAL PRIMARY KEY,
+    template_name VARCHAR(50) NOT NULL,
+    description VARCHAR(200) NOT NULL DEFAULT '',
+    source_plan_id BIGINT,
+    duration_days INTEGER NOT NULL,
+    plan_start_time VARCHAR(8) NOT NULL,
+    plan_end_time VARCHAR(8) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    items TEXT NOT NULL,
+    enrollment_rules TEXT NOT NULL,
+    creator_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ
+);
+COMMENT ON COLUMN plan_template.source_plan_id IS '保存模板时的培训计划';
+COMMENT ON COLUMN plan_template.duration_days IS '计划结束日期与开始日期相差的天数';
+COMMENT ON COLUMN plan_template.items IS '课程安排（JSON）';
+COMMENT ON COLUMN plan_template.enrollment_rules IS '报名规则（JSON）';
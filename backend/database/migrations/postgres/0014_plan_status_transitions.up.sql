@@ -0,0 +1,15 @@
+-- 培训计划状态变更记录
+CREATE TABLE IF NOT EXISTS plan_status_transition (
+    transition_id BIGSERIAL PRIMARY KEY,
+    plan_id BIGINT NOT NULL,
+    from_status VARCHAR(3) NOT NULL DEFAULT '',
+    to_status VARCHAR(3) NOT NULL,
+    actor_id BIGINT,
+    source VARCHAR(10) NOT NULL,
+    reason VARCHAR(200) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_plan_status_transition_plan_id ON plan_status_transition (plan_id);
+COMMENT ON COLUMN plan_status_transition.from_status IS '变更前的状态，创建计划时为空';
+COMMENT ON COLUMN plan_status_transition.actor_id IS '操作人，定时任务自动变更时为空';
+COMMENT ON COLUMN plan_status_transition.source IS 'create/manual/auto';
@@ -0,0 +1,8 @@
+-- 会话管理：记录登录设备和最近活跃时间，按过期时间定时清理
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ;
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS ip_address VARCHAR(45) NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS user_agent VARCHAR(255) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions (expires_at);
+COMMENT ON COLUMN sessions.last_seen_at IS '最近一次登录或刷新访问令牌的时间';
+COMMENT ON COLUMN sessions.ip_address IS '登录时的客户端 IP';
+COMMENT ON COLUMN sessions.user_agent IS '登录时的 User-Agent';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS password_reset_token;
+ALTER TABLE account DROP COLUMN IF EXISTS email;
@@ -0,0 +1,17 @@
+-- 找回密码：账号绑定的邮箱，以及一次性的密码重置令牌
+ALTER TABLE account ADD COLUMN IF NOT EXISTS email VARCHAR(100);
+CREATE TABLE IF NOT EXISTS password_reset_token (
+    token_id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    requested_ip VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_password_reset_token_hash ON password_reset_token (token_hash);
+CREATE INDEX IF NOT EXISTS idx_password_reset_token_account_id ON password_reset_token (account_id);
+COMMENT ON COLUMN account.email IS '找回密码时接收重置链接的邮箱';
+COMMENT ON COLUMN password_reset_token.token_hash IS '重置令牌的 SHA-256 哈希';
+COMMENT ON COLUMN password_reset_token.requested_ip IS '申请重置时的客户端 IP';
+COMMENT ON COLUMN password_reset_token.used_at IS '使用时间，为空表示未使用；申请新令牌时旧令牌也记为已使用';
@@ -0,0 +1,25 @@
+-- 登录防暴力破解：按用户名和 IP 统计的连续失败次数（LOGIN_GUARD_STORE=database 时多实例共享），以及每次失败登录的审计记录
+CREATE TABLE IF NOT EXISTS login_attempt (
+    attempt_key VARCHAR(120) NOT NULL PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMPTZ,
+    locked_until TIMESTAMPTZ
+);
+CREATE TABLE IF NOT EXISTS login_failure (
+    failure_id BIGSERIAL PRIMARY KEY,
+    login_name VARCHAR(50) NOT NULL,
+    person_id BIGINT,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    reason VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_login_failure_login_name ON login_failure (login_name);
+CREATE INDEX IF NOT EXISTS idx_login_failure_ip_address ON login_failure (ip_address);
+CREATE INDEX IF NOT EXISTS idx_login_failure_created_at ON login_failure (created_at);
+COMMENT ON COLUMN login_attempt.attempt_key IS 'user:<用户名> 或 ip:<客户端 IP>';
+COMMENT ON COLUMN login_attempt.failures IS '统计窗口内连续失败次数，锁定后清零';
+COMMENT ON COLUMN login_attempt.locked_until IS '锁定截止时间，为空表示未锁定';
+COMMENT ON COLUMN login_failure.login_name IS '尝试登录的用户名（超长时截断）';
+COMMENT ON COLUMN login_failure.person_id IS '用户名对应的人员，账号不存在时为空';
+COMMENT ON COLUMN login_failure.reason IS 'unknown_user/wrong_password/throttled/locked';
@@ -0,0 +1,3 @@
+ALTER TABLE account DROP COLUMN IF EXISTS deactivated_at;
+ALTER TABLE account DROP COLUMN IF EXISTS invitation_id;
+DROP TABLE IF EXISTS invitation;
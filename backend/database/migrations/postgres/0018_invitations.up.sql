@@ -0,0 +1,26 @@
+-- 邀请注册：按角色发放的邀请码，账号记录使用的邀请码和停用时间
+CREATE TABLE IF NOT EXISTS invitation (
+    invitation_id BIGSERIAL PRIMARY KEY,
+    code_hash VARCHAR(64) NOT NULL,
+    code_hint VARCHAR(8) NOT NULL DEFAULT '',
+    role VARCHAR(7) NOT NULL,
+    email VARCHAR(100) NOT NULL DEFAULT '',
+    max_uses INTEGER NOT NULL DEFAULT 1,
+    used_count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    creator_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invitation_code_hash ON invitation (code_hash);
+ALTER TABLE account ADD COLUMN IF NOT EXISTS invitation_id BIGINT;
+ALTER TABLE account ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMPTZ;
+COMMENT ON COLUMN invitation.code_hash IS '邀请码的 SHA-256 哈希';
+COMMENT ON COLUMN invitation.code_hint IS '邀请码前几位，便于辨认';
+COMMENT ON COLUMN invitation.role IS '注册后的角色：员工/讲师/课程大纲制定者';
+COMMENT ON COLUMN invitation.email IS '受邀人邮箱，填写时发送邀请邮件';
+COMMENT ON COLUMN invitation.max_uses IS '可注册的账号数';
+COMMENT ON COLUMN invitation.used_count IS '已注册的账号数';
+COMMENT ON COLUMN invitation.revoked_at IS '撤销时间，为空表示未撤销';
+COMMENT ON COLUMN account.invitation_id IS '注册时使用的邀请码，自助注册或管理员创建时为空';
+COMMENT ON COLUMN account.deactivated_at IS '停用时间，为空表示正常';
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS ai_scoring_job;
+DROP TABLE IF EXISTS ai_scoring_log;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS plan_employee;
+DROP TABLE IF EXISTS attendance_evaluation;
+DROP TABLE IF EXISTS plan_course_item;
+DROP TABLE IF EXISTS course;
+DROP TABLE IF EXISTS training_plan;
+DROP TABLE IF EXISTS account;
+DROP TABLE IF EXISTS person;
@@ -0,0 +1,125 @@
+-- 初始表结构（SQLite 版本，与 mysql/0001_init.up.sql 保持一致）
+
+-- 1. 基础表
+CREATE TABLE IF NOT EXISTS person (
+    person_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(20) NOT NULL,
+    role VARCHAR(7) NOT NULL
+);
+
+-- 2. 依赖 person 的表
+CREATE TABLE IF NOT EXISTS account (
+    account_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    person_id INTEGER NOT NULL,
+    login_name VARCHAR(20) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_account_login_name ON account (login_name);
+CREATE INDEX IF NOT EXISTS idx_account_person_id ON account (person_id);
+
+CREATE TABLE IF NOT EXISTS training_plan (
+    plan_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    plan_name VARCHAR(50) NOT NULL,
+    plan_status VARCHAR(3) NOT NULL,
+    plan_start_datetime DATETIME NOT NULL,
+    plan_end_datetime DATETIME NOT NULL,
+    creator_id INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_training_plan_creator_id ON training_plan (creator_id);
+
+CREATE TABLE IF NOT EXISTS course (
+    course_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    course_name VARCHAR(50) NOT NULL,
+    course_desc VARCHAR(100),
+    course_require VARCHAR(500),
+    course_class VARCHAR(20) NOT NULL,
+    teacher_id INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_course_teacher_id ON course (teacher_id);
+
+-- 3. 课程安排
+CREATE TABLE IF NOT EXISTS plan_course_item (
+    item_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    plan_id INTEGER NOT NULL,
+    course_id INTEGER NOT NULL,
+    class_date DATE NOT NULL,
+    class_begin_time VARCHAR(8) NOT NULL,
+    class_end_time VARCHAR(8) NOT NULL,
+    location VARCHAR(100) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_plan_course_item_plan_id ON plan_course_item (plan_id);
+CREATE INDEX IF NOT EXISTS idx_plan_course_item_course_id ON plan_course_item (course_id);
+
+-- 4. 关联表
+CREATE TABLE IF NOT EXISTS attendance_evaluation (
+    person_id INTEGER NOT NULL,
+    item_id INTEGER NOT NULL,
+    self_score REAL,
+    self_comment TEXT,
+    teacher_score REAL,
+    teacher_comment TEXT,
+    understanding SMALLINT DEFAULT 0,
+    difficulty SMALLINT DEFAULT 0,
+    satisfaction SMALLINT DEFAULT 0,
+    self_score_rationale TEXT,
+    teacher_score_rationale TEXT,
+    self_scoring_status VARCHAR(10),
+    teacher_scoring_status VARCHAR(10),
+    score_ratio REAL,
+    PRIMARY KEY (person_id, item_id)
+);
+
+CREATE TABLE IF NOT EXISTS plan_employee (
+    plan_id INTEGER NOT NULL,
+    person_id INTEGER NOT NULL,
+    PRIMARY KEY (plan_id, person_id)
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    session_id VARCHAR(64) NOT NULL PRIMARY KEY,
+    person_id INTEGER NOT NULL,
+    role VARCHAR(10) NOT NULL,
+    created_at DATETIME,
+    expires_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_person_id ON sessions (person_id);
+
+-- 5. 日志与任务表
+CREATE TABLE IF NOT EXISTS ai_scoring_log (
+    log_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    person_id INTEGER NOT NULL,
+    item_id INTEGER NOT NULL,
+    score_type VARCHAR(10) NOT NULL,
+    provider VARCHAR(20),
+    prompt TEXT,
+    raw_output TEXT,
+    parsed_score REAL,
+    final_score REAL,
+    rationale TEXT,
+    used_fallback BOOLEAN NOT NULL DEFAULT 0,
+    latency_ms INTEGER,
+    error_message TEXT,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_ai_scoring_log_eval ON ai_scoring_log (person_id, item_id);
+
+CREATE TABLE IF NOT EXISTS ai_scoring_job (
+    job_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    person_id INTEGER NOT NULL,
+    item_id INTEGER NOT NULL,
+    score_type VARCHAR(10) NOT NULL,
+    status VARCHAR(10) NOT NULL,
+    course_name VARCHAR(50),
+    comment TEXT,
+    understanding INTEGER,
+    difficulty INTEGER,
+    satisfaction INTEGER,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_run_at DATETIME NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME,
+    finished_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_ai_scoring_job_eval ON ai_scoring_job (person_id, item_id);
+CREATE INDEX IF NOT EXISTS idx_ai_scoring_job_due ON ai_scoring_job (status, next_run_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_person_org_unit_id;
+ALTER TABLE person DROP COLUMN org_unit_id;
+DROP TABLE IF EXISTS org_unit;
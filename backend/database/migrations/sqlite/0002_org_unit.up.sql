@@ -0,0 +1,11 @@
+-- 组织架构：公司 → 部门 → 班组/船舶，人员归属到某个节点
+CREATE TABLE IF NOT EXISTS org_unit (
+    unit_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    unit_name VARCHAR(50) NOT NULL,
+    unit_type VARCHAR(4) NOT NULL,
+    parent_id INTEGER
+);
+CREATE INDEX IF NOT EXISTS idx_org_unit_parent_id ON org_unit (parent_id);
+
+ALTER TABLE person ADD COLUMN org_unit_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_person_org_unit_id ON person (org_unit_id);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS plan_enrollment_exclusion;
+DROP TABLE IF EXISTS plan_enrollment_rule;
+ALTER TABLE plan_employee DROP COLUMN source;
+DROP TABLE IF EXISTS person_certificate;
+ALTER TABLE person DROP COLUMN hire_date;
+ALTER TABLE person DROP COLUMN job_rank;
@@ -0,0 +1,31 @@
+-- 自动报名规则：人员职级/入职日期/证书，计划报名规则与排除名单
+ALTER TABLE person ADD COLUMN job_rank VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE person ADD COLUMN hire_date DATE;
+
+CREATE TABLE IF NOT EXISTS person_certificate (
+    cert_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    person_id INTEGER NOT NULL,
+    cert_name VARCHAR(50) NOT NULL,
+    expires_on DATE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_person_certificate_name ON person_certificate (person_id, cert_name);
+
+ALTER TABLE plan_employee ADD COLUMN source VARCHAR(10) NOT NULL DEFAULT 'manual';
+
+CREATE TABLE IF NOT EXISTS plan_enrollment_rule (
+    rule_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    plan_id INTEGER NOT NULL,
+    org_unit_id INTEGER,
+    job_rank VARCHAR(20) NOT NULL DEFAULT '',
+    hired_after DATE,
+    missing_certificate VARCHAR(50) NOT NULL DEFAULT '',
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_plan_enrollment_rule_plan_id ON plan_enrollment_rule (plan_id);
+
+CREATE TABLE IF NOT EXISTS plan_enrollment_exclusion (
+    plan_id INTEGER NOT NULL,
+    person_id INTEGER NOT NULL,
+    created_at DATETIME,
+    PRIMARY KEY (plan_id, person_id)
+);
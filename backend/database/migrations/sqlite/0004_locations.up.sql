@@ -0,0 +1,18 @@
+-- 培训地点：课程安排引用地点，用于教室冲突和容量检查
+CREATE TABLE IF NOT EXISTS location (
+    location_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    location_name VARCHAR(100) NOT NULL,
+    building VARCHAR(50) NOT NULL DEFAULT '',
+    capacity INTEGER NOT NULL DEFAULT 0,
+    equipment VARCHAR(500) NOT NULL DEFAULT ''
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_location_location_name ON location (location_name);
+
+ALTER TABLE plan_course_item ADD COLUMN location_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_plan_course_item_location_id ON plan_course_item (location_id);
+
+-- 已有课程安排的地点文本登记为不限容量的地点
+INSERT INTO location (location_name)
+SELECT DISTINCT location FROM plan_course_item WHERE location <> '';
+UPDATE plan_course_item
+SET location_id = (SELECT l.location_id FROM location l WHERE l.location_name = plan_course_item.location);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_plan_course_item_series_id;
+ALTER TABLE plan_course_item DROP COLUMN series_id;
+DROP TABLE IF EXISTS course_item_series;
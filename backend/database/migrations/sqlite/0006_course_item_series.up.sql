@@ -0,0 +1,18 @@
+-- 重复课程系列：按 RRULE 展开的课程安排通过 series_id 关联
+CREATE TABLE IF NOT EXISTS course_item_series (
+    series_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    plan_id INTEGER NOT NULL,
+    course_id INTEGER NOT NULL,
+    rrule VARCHAR(255) NOT NULL,
+    start_date DATE NOT NULL,
+    class_begin_time VARCHAR(8) NOT NULL,
+    class_end_time VARCHAR(8) NOT NULL,
+    location_id INTEGER,
+    location VARCHAR(100) NOT NULL,
+    exclude_dates VARCHAR(4000) NOT NULL DEFAULT '',
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_course_item_series_plan_id ON course_item_series (plan_id);
+
+ALTER TABLE plan_course_item ADD COLUMN series_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_plan_course_item_series_id ON plan_course_item (series_id);
@@ -0,0 +1,10 @@
+-- 自动排课方案：生成后保存，规划者可整体或部分采纳
+CREATE TABLE IF NOT EXISTS timetable_proposal (
+    proposal_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    plan_id INTEGER NOT NULL,
+    sessions TEXT NOT NULL,
+    unplaced TEXT NOT NULL,
+    creator_id INTEGER NOT NULL,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_timetable_proposal_plan_id ON timetable_proposal (plan_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS teacher_blackout;
+DROP TABLE IF EXISTS teacher_availability;
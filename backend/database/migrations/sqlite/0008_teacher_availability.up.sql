@@ -0,0 +1,21 @@
+-- 讲师每周可授课时间段和不可授课时间段
+CREATE TABLE IF NOT EXISTS teacher_availability (
+    availability_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    teacher_id INTEGER NOT NULL,
+    weekday INTEGER NOT NULL,
+    begin_time VARCHAR(8) NOT NULL,
+    end_time VARCHAR(8) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_teacher_availability_teacher_id ON teacher_availability (teacher_id);
+
+CREATE TABLE IF NOT EXISTS teacher_blackout (
+    blackout_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    teacher_id INTEGER NOT NULL,
+    start_date DATE NOT NULL,
+    begin_time VARCHAR(8) NOT NULL,
+    end_date DATE NOT NULL,
+    end_time VARCHAR(8) NOT NULL,
+    reason VARCHAR(200) NOT NULL DEFAULT '',
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_teacher_blackout_teacher_id ON teacher_blackout (teacher_id);
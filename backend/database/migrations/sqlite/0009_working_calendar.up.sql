@@ -0,0 +1,14 @@
+-- 工作日历：法定节假日、公司停工和按楼宇/船舶的工作日调整
+CREATE TABLE IF NOT EXISTS calendar_entry (
+    entry_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    site VARCHAR(50) NOT NULL DEFAULT '',
+    policy VARCHAR(10) NOT NULL DEFAULT '',
+    source VARCHAR(10) NOT NULL DEFAULT 'manual',
+    uid VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_calendar_entry_site ON calendar_entry (site);
@@ -0,0 +1,23 @@
+-- 日历订阅：个人订阅令牌、课程安排修改次数和已删除课程安排的快照
+CREATE TABLE IF NOT EXISTS calendar_feed (
+    person_id INTEGER PRIMARY KEY,
+    token VARCHAR(64) NOT NULL,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_feed_token ON calendar_feed (token);
+
+ALTER TABLE plan_course_item ADD COLUMN sequence INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS course_item_cancellation (
+    item_id INTEGER PRIMARY KEY,
+    plan_id INTEGER NOT NULL,
+    course_id INTEGER NOT NULL,
+    class_date DATE NOT NULL,
+    class_begin_time VARCHAR(8) NOT NULL,
+    class_end_time VARCHAR(8) NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    sequence INTEGER NOT NULL DEFAULT 0,
+    cancelled_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_course_item_cancellation_plan_id ON course_item_cancellation (plan_id);
+CREATE INDEX IF NOT EXISTS idx_course_item_cancellation_course_id ON course_item_cancellation (course_id);
@@ -0,0 +1,4 @@
+ALTER TABLE course_item_cancellation DROP COLUMN timezone;
+ALTER TABLE plan_course_item DROP COLUMN timezone;
+ALTER TABLE training_plan DROP COLUMN timezone;
+ALTER TABLE location DROP COLUMN timezone;
@@ -0,0 +1,5 @@
+-- 时区：地点和计划可设置 IANA 时区，课程安排保存上课时间所在的时区
+ALTER TABLE location ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE training_plan ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE plan_course_item ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE course_item_cancellation ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '';
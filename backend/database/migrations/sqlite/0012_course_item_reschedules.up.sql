@@ -0,0 +1,15 @@
+-- 课程安排改期记录：改期原因和每条课程安排改期前后的时间
+CREATE TABLE IF NOT EXISTS course_item_reschedule (
+    reschedule_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    plan_id INTEGER NOT NULL,
+    item_id INTEGER,
+    shift_days INTEGER NOT NULL DEFAULT 0,
+    reason VARCHAR(500) NOT NULL,
+    moves TEXT NOT NULL,
+    plan_start_datetime DATETIME,
+    plan_end_datetime DATETIME,
+    operator_id INTEGER NOT NULL,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_course_item_reschedule_plan_id ON course_item_reschedule (plan_id);
+CREATE INDEX IF NOT EXISTS idx_course_item_reschedule_item_id ON course_item_reschedule (item_id);
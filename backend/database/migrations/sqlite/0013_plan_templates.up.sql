@@ -0,0 +1,15 @@
+-- 培训计划模板：课程安排（相对计划开始日期的天数）、上课时间、地点和报名规则
+CREATE TABLE IF NOT EXISTS plan_template (
+    template_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    template_name VARCHAR(50) NOT NULL,
+    description VARCHAR(200) NOT NULL DEFAULT '',
+    source_plan_id INTEGER,
+    duration_days INTEGER NOT NULL,
+    plan_start_time VARCHAR(8) NOT NULL,
+    plan_end_time VARCHAR(8) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    items TEXT NOT NULL,
+    enrollment_rules TEXT NOT NULL,
+    creator_id INTEGER NOT NULL,
+    created_at DATETIME
+);
@@ -0,0 +1,12 @@
+-- 培训计划状态变更记录
+CREATE TABLE IF NOT EXISTS plan_status_transition (
+    transition_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    plan_id INTEGER NOT NULL,
+    from_status VARCHAR(3) NOT NULL DEFAULT '',
+    to_status VARCHAR(3) NOT NULL,
+    actor_id INTEGER,
+    source VARCHAR(10) NOT NULL,
+    reason VARCHAR(200) NOT NULL DEFAULT '',
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_plan_status_transition_plan_id ON plan_status_transition (plan_id);
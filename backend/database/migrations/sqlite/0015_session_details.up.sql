@@ -0,0 +1,5 @@
+-- 会话管理：记录登录设备和最近活跃时间，按过期时间定时清理
+ALTER TABLE sessions ADD COLUMN last_seen_at DATETIME;
+ALTER TABLE sessions ADD COLUMN ip_address VARCHAR(45) NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN user_agent VARCHAR(255) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions (expires_at);
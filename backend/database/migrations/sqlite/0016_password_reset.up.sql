@@ -0,0 +1,13 @@
+-- 找回密码：账号绑定的邮箱，以及一次性的密码重置令牌
+ALTER TABLE account ADD COLUMN email VARCHAR(100);
+CREATE TABLE IF NOT EXISTS password_reset_token (
+    token_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    account_id INTEGER NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    requested_ip VARCHAR(45) NOT NULL DEFAULT '',
+    created_at DATETIME,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_password_reset_token_hash ON password_reset_token (token_hash);
+CREATE INDEX IF NOT EXISTS idx_password_reset_token_account_id ON password_reset_token (account_id);
@@ -0,0 +1,19 @@
+-- 登录防暴力破解：按用户名和 IP 统计的连续失败次数（LOGIN_GUARD_STORE=database 时多实例共享），以及每次失败登录的审计记录
+CREATE TABLE IF NOT EXISTS login_attempt (
+    attempt_key VARCHAR(120) NOT NULL PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at DATETIME,
+    locked_until DATETIME
+);
+CREATE TABLE IF NOT EXISTS login_failure (
+    failure_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    login_name VARCHAR(50) NOT NULL,
+    person_id INTEGER,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    reason VARCHAR(20) NOT NULL,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_login_failure_login_name ON login_failure (login_name);
+CREATE INDEX IF NOT EXISTS idx_login_failure_ip_address ON login_failure (ip_address);
+CREATE INDEX IF NOT EXISTS idx_login_failure_created_at ON login_failure (created_at);
@@ -0,0 +1,3 @@
+ALTER TABLE account DROP COLUMN deactivated_at;
+ALTER TABLE account DROP COLUMN invitation_id;
+DROP TABLE IF EXISTS invitation;
@@ -0,0 +1,17 @@
+-- 邀请注册：按角色发放的邀请码，账号记录使用的邀请码和停用时间
+CREATE TABLE IF NOT EXISTS invitation (
+    invitation_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code_hash VARCHAR(64) NOT NULL,
+    code_hint VARCHAR(8) NOT NULL DEFAULT '',
+    role VARCHAR(7) NOT NULL,
+    email VARCHAR(100) NOT NULL DEFAULT '',
+    max_uses INTEGER NOT NULL DEFAULT 1,
+    used_count INTEGER NOT NULL DEFAULT 0,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME,
+    creator_id INTEGER NOT NULL,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invitation_code_hash ON invitation (code_hash);
+ALTER TABLE account ADD COLUMN invitation_id INTEGER;
+ALTER TABLE account ADD COLUMN deactivated_at DATETIME;
@@ -0,0 +1,9 @@
+-- 课程安排总是保存具体的 IANA 时区：按地点、计划的时区补齐未保存时区的课程安排
+-- 地点和计划都未设置时区的课程安排在启动时补上系统默认时区（DEFAULT_TIMEZONE 只有运行时才知道）
+UPDATE plan_course_item
+SET timezone = COALESCE(
+    (SELECT NULLIF(l.timezone, '') FROM location l WHERE l.location_id = plan_course_item.location_id),
+    (SELECT NULLIF(tp.timezone, '') FROM training_plan tp WHERE tp.plan_id = plan_course_item.plan_id),
+    ''
+)
+WHERE timezone = '';
@@ -0,0 +1,7 @@
+-- 刷新令牌重复使用检测：保存已被换下的刷新令牌哈希，再次出现时注销整个会话
+CREATE TABLE IF NOT EXISTS retired_refresh_tokens (
+    token_hash VARCHAR(64) NOT NULL PRIMARY KEY,
+    session_id VARCHAR(64) NOT NULL,
+    retired_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_retired_refresh_tokens_session_id ON retired_refresh_tokens (session_id);
@@ -7,28 +7,143 @@ import (
 
 // Person 人员表
 type Person struct {
-	PersonID int64  `gorm:"primaryKey;column:person_id" json:"personId"`
-	Name     string `gorm:"column:name;size:20;not null" json:"name"`
-	Role     string `gorm:"column:role;size:7;not null;comment:角色：员工/讲师/课程大纲制定者" json:"role"`
+	PersonID  int64      `gorm:"primaryKey;column:person_id" json:"personId"`
+	Name      string     `gorm:"column:name;size:20;not null" json:"name"`
+	Role      string     `gorm:"column:role;size:7;not null;comment:角色：员工/讲师/课程大纲制定者" json:"role"`
+	OrgUnitID *int64     `gorm:"column:org_unit_id;index;comment:所属组织节点，为空表示未分配" json:"orgUnitId"`
+	JobRank   string     `gorm:"column:job_rank;size:20;not null;default:'';comment:职级，如船长/大副/水手" json:"jobRank"`
+	HireDate  *time.Time `gorm:"column:hire_date;type:date;comment:入职日期" json:"hireDate"`
 }
 
 func (Person) TableName() string {
 	return "person"
 }
 
+// 组织节点类型（org_unit.unit_type）
+const (
+	OrgUnitTypeCompany    = "公司"
+	OrgUnitTypeDepartment = "部门"
+	OrgUnitTypeTeam       = "班组"
+	OrgUnitTypeVessel     = "船舶"
+)
+
+// OrgUnit 组织架构表（公司 → 部门 → 班组/船舶，树形结构）
+type OrgUnit struct {
+	UnitID   int64  `gorm:"primaryKey;column:unit_id" json:"unitId"`
+	UnitName string `gorm:"column:unit_name;size:50;not null" json:"unitName"`
+	UnitType string `gorm:"column:unit_type;size:4;not null;comment:公司/部门/班组/船舶" json:"unitType"`
+	ParentID *int64 `gorm:"column:parent_id;index;comment:上级节点，为空表示根节点" json:"parentId"`
+}
+
+func (OrgUnit) TableName() string {
+	return "org_unit"
+}
+
+// PersonCertificate 人员持有的证书表
+type PersonCertificate struct {
+	CertID    int64      `gorm:"primaryKey;column:cert_id" json:"certId"`
+	PersonID  int64      `gorm:"column:person_id;not null;uniqueIndex:idx_person_certificate_name" json:"personId"`
+	CertName  string     `gorm:"column:cert_name;size:50;not null;uniqueIndex:idx_person_certificate_name" json:"certName"`
+	ExpiresOn *time.Time `gorm:"column:expires_on;type:date;comment:有效期至，为空表示长期有效" json:"expiresOn"`
+}
+
+func (PersonCertificate) TableName() string {
+	return "person_certificate"
+}
+
 // Account 账号表
 type Account struct {
-	AccountID    int64  `gorm:"primaryKey;column:account_id" json:"accountId"`
-	PersonID     int64  `gorm:"column:person_id;not null;index" json:"personId"`
-	LoginName    string `gorm:"column:login_name;size:20;not null;uniqueIndex" json:"loginName"`
-	PasswordHash string `gorm:"column:password_hash;size:255;not null" json:"-"`
-	Person       Person `gorm:"foreignKey:PersonID;references:PersonID;constraint:OnDelete:CASCADE"`
+	AccountID     int64      `gorm:"primaryKey;column:account_id" json:"accountId"`
+	PersonID      int64      `gorm:"column:person_id;not null;index" json:"personId"`
+	LoginName     string     `gorm:"column:login_name;size:20;not null;uniqueIndex" json:"loginName"`
+	PasswordHash  string     `gorm:"column:password_hash;size:255;not null" json:"-"`
+	Email         *string    `gorm:"column:email;size:100;comment:找回密码时接收重置链接的邮箱" json:"email"`
+	InvitationID  *int64     `gorm:"column:invitation_id;comment:注册时使用的邀请码，自助注册或管理员创建时为空" json:"invitationId"`
+	DeactivatedAt *time.Time `gorm:"column:deactivated_at;comment:停用时间，为空表示正常" json:"deactivatedAt"`
+	Person        Person     `gorm:"foreignKey:PersonID;references:PersonID;constraint:OnDelete:CASCADE"`
 }
 
 func (Account) TableName() string {
 	return "account"
 }
 
+// Invitation 注册邀请码表：按角色发放，凭邀请码注册的账号获得邀请的角色
+type Invitation struct {
+	InvitationID int64      `gorm:"primaryKey;column:invitation_id" json:"invitationId"`
+	CodeHash     string     `gorm:"column:code_hash;size:64;not null;uniqueIndex;comment:邀请码的 SHA-256 哈希" json:"-"`
+	CodeHint     string     `gorm:"column:code_hint;size:8;not null;default:'';comment:邀请码前几位，便于辨认" json:"codeHint"`
+	Role         string     `gorm:"column:role;size:7;not null;comment:注册后的角色：员工/讲师/课程大纲制定者" json:"role"`
+	Email        string     `gorm:"column:email;size:100;not null;default:'';comment:受邀人邮箱，填写时发送邀请邮件" json:"email"`
+	MaxUses      int        `gorm:"column:max_uses;not null;default:1;comment:可注册的账号数" json:"maxUses"`
+	UsedCount    int        `gorm:"column:used_count;not null;default:0;comment:已注册的账号数" json:"usedCount"`
+	ExpiresAt    time.Time  `gorm:"column:expires_at;not null" json:"expiresAt"`
+	RevokedAt    *time.Time `gorm:"column:revoked_at;comment:撤销时间，为空表示未撤销" json:"revokedAt"`
+	CreatorID    int64      `gorm:"column:creator_id;not null" json:"creatorId"`
+	CreatedAt    time.Time  `gorm:"column:created_at" json:"createdAt"`
+}
+
+func (Invitation) TableName() string {
+	return "invitation"
+}
+
+// PasswordResetToken 密码重置令牌表：一次性使用，有效期由 PASSWORD_RESET_MINUTES 配置
+type PasswordResetToken struct {
+	TokenID     int64      `gorm:"primaryKey;column:token_id" json:"tokenId"`
+	AccountID   int64      `gorm:"column:account_id;not null;index" json:"accountId"`
+	TokenHash   string     `gorm:"column:token_hash;size:64;not null;uniqueIndex;comment:重置令牌的 SHA-256 哈希" json:"-"`
+	RequestedIP string     `gorm:"column:requested_ip;size:45;not null;default:'';comment:申请重置时的客户端 IP" json:"requestedIp"`
+	CreatedAt   time.Time  `gorm:"column:created_at" json:"createdAt"`
+	ExpiresAt   time.Time  `gorm:"column:expires_at;not null" json:"expiresAt"`
+	UsedAt      *time.Time `gorm:"column:used_at;comment:使用时间，为空表示未使用" json:"usedAt"`
+}
+
+func (PasswordResetToken) TableName() string {
+	return "password_reset_token"
+}
+
+// 失败登录原因（login_failure.reason）
+const (
+	LoginFailureUnknownUser   = "unknown_user"   // 用户名不存在
+	LoginFailureWrongPassword = "wrong_password" // 密码错误
+	LoginFailureThrottled     = "throttled"      // 距上次失败未到等待时间，未验证密码
+	LoginFailureLocked        = "locked"         // 用户名或 IP 已被锁定，未验证密码
+	LoginFailureDeactivated   = "deactivated"    // 密码正确，但账号已停用
+)
+
+// LoginAttempt 登录失败计数表：按用户名（user:）和客户端 IP（ip:）统计，LOGIN_GUARD_STORE=database 时使用
+type LoginAttempt struct {
+	AttemptKey    string     `gorm:"primaryKey;column:attempt_key;size:120;comment:user:<用户名> 或 ip:<客户端 IP>，申请重置密码的次数加 reset: 前缀" json:"attemptKey"`
+	Failures      int        `gorm:"column:failures;not null;default:0;comment:统计窗口内连续失败次数，锁定后清零" json:"failures"`
+	LastFailureAt *time.Time `gorm:"column:last_failure_at" json:"lastFailureAt"`
+	LockedUntil   *time.Time `gorm:"column:locked_until;comment:锁定截止时间，为空表示未锁定" json:"lockedUntil"`
+}
+
+func (LoginAttempt) TableName() string {
+	return "login_attempt"
+}
+
+// LoginFailure 失败登录审计表：每次失败或被拦截的登录一条
+type LoginFailure struct {
+	FailureID int64     `gorm:"primaryKey;column:failure_id" json:"failureId"`
+	LoginName string    `gorm:"column:login_name;size:50;not null;index;comment:尝试登录的用户名（超长时截断）" json:"loginName"`
+	PersonID  *int64    `gorm:"column:person_id;comment:用户名对应的人员，账号不存在时为空" json:"personId"`
+	IPAddress string    `gorm:"column:ip_address;size:45;not null;default:'';index" json:"ipAddress"`
+	UserAgent string    `gorm:"column:user_agent;size:255;not null;default:''" json:"userAgent"`
+	Reason    string    `gorm:"column:reason;size:20;not null;comment:unknown_user/wrong_password/throttled/locked/deactivated" json:"reason"`
+	CreatedAt time.Time `gorm:"column:created_at;index" json:"createdAt"`
+}
+
+func (LoginFailure) TableName() string {
+	return "login_failure"
+}
+
+// 培训计划状态（training_plan.plan_status），变更规则见 planstatus 包
+const (
+	PlanStatusPlanning   = "规划中"
+	PlanStatusInProgress = "进行中"
+	PlanStatusCompleted  = "已完成"
+)
+
 // TrainingPlan 培训计划表
 type TrainingPlan struct {
 	PlanID            int64     `gorm:"primaryKey;column:plan_id" json:"planId"`
@@ -37,6 +152,7 @@ type TrainingPlan struct {
 	PlanStartDatetime time.Time `gorm:"column:plan_start_datetime;not null" json:"planStartDatetime"`
 	PlanEndDatetime   time.Time `gorm:"column:plan_end_datetime;not null" json:"planEndDatetime"`
 	CreatorID         int64     `gorm:"column:creator_id;not null;index" json:"creatorId"`
+	Timezone          string    `gorm:"column:timezone;size:64;not null;default:'';comment:IANA 时区，为空时使用系统默认时区" json:"timezone"`
 	Creator           Person    `gorm:"foreignKey:CreatorID;references:PersonID"`
 }
 
@@ -61,42 +177,216 @@ func (Course) TableName() string {
 
 // PlanCourseItem 培训课程安排表
 type PlanCourseItem struct {
-	ItemID         int64         `gorm:"primaryKey;column:item_id" json:"itemId"`
-	PlanID         int64         `gorm:"column:plan_id;not null;index" json:"planId"`
-	CourseID       int64         `gorm:"column:course_id;not null;index" json:"courseId"`
-	ClassDate      time.Time     `gorm:"column:class_date;type:date;not null" json:"classDate"`
-	ClassBeginTime string        `gorm:"column:class_begin_time;type:varchar(8);not null" json:"classBeginTime"`
-	ClassEndTime   string        `gorm:"column:class_end_time;type:varchar(8);not null" json:"classEndTime"`
-	Location       string        `gorm:"column:location;size:100;not null" json:"location"`
-	Plan           TrainingPlan  `gorm:"foreignKey:PlanID;references:PlanID"`
-	Course         Course        `gorm:"foreignKey:CourseID;references:CourseID"`
+	ItemID          int64        `gorm:"primaryKey;column:item_id" json:"itemId"`
+	PlanID          int64        `gorm:"column:plan_id;not null;index" json:"planId"`
+	CourseID        int64        `gorm:"column:course_id;not null;index" json:"courseId"`
+	ClassDate       time.Time    `gorm:"column:class_date;type:date;not null" json:"classDate"`
+	ClassBeginTime  string       `gorm:"column:class_begin_time;type:varchar(8);not null" json:"classBeginTime"`
+	ClassEndTime    string       `gorm:"column:class_end_time;type:varchar(8);not null" json:"classEndTime"`
+	Location        string       `gorm:"column:location;size:100;not null" json:"location"`
+	LocationID      *int64       `gorm:"column:location_id;index;comment:上课地点，location 字段保存地点名称" json:"locationId"`
+	ConflictWarning string       `gorm:"column:conflict_warning;size:1000;not null;default:'';comment:强制保存时记录的员工时间冲突" json:"conflictWarning"`
+	SeriesID        *int64       `gorm:"column:series_id;index;comment:所属重复课程系列，单次安排为空" json:"seriesId"`
+	Sequence        int          `gorm:"column:sequence;not null;default:0;comment:修改次数，日历订阅中作为事件的 SEQUENCE" json:"sequence"`
+	Timezone        string       `gorm:"column:timezone;size:64;not null;default:'';comment:上课日期和时间所在的 IANA 时区" json:"timezone"`
+	Plan            TrainingPlan `gorm:"foreignKey:PlanID;references:PlanID"`
+	Course          Course       `gorm:"foreignKey:CourseID;references:CourseID"`
 }
 
 func (PlanCourseItem) TableName() string {
 	return "plan_course_item"
 }
 
+// CourseItemSeries 重复课程系列，按重复规则展开为多条课程安排
+type CourseItemSeries struct {
+	SeriesID       int64     `gorm:"primaryKey;column:series_id" json:"seriesId"`
+	PlanID         int64     `gorm:"column:plan_id;not null;index" json:"planId"`
+	CourseID       int64     `gorm:"column:course_id;not null" json:"courseId"`
+	RRule          string    `gorm:"column:rrule;size:255;not null;comment:RFC 5545 重复规则" json:"rrule"`
+	StartDate      time.Time `gorm:"column:start_date;type:date;not null" json:"startDate"`
+	ClassBeginTime string    `gorm:"column:class_begin_time;type:varchar(8);not null" json:"classBeginTime"`
+	ClassEndTime   string    `gorm:"column:class_end_time;type:varchar(8);not null" json:"classEndTime"`
+	LocationID     *int64    `gorm:"column:location_id" json:"locationId"`
+	Location       string    `gorm:"column:location;size:100;not null" json:"location"`
+	ExcludeDates   string    `gorm:"column:exclude_dates;size:4000;not null;default:'';comment:不上课的日期，逗号分隔" json:"excludeDates"`
+	CreatedAt      time.Time `gorm:"column:created_at" json:"createdAt"`
+}
+
+func (CourseItemSeries) TableName() string {
+	return "course_item_series"
+}
+
+// TimetableProposal 自动排课方案，可整体或部分采纳为课程安排
+type TimetableProposal struct {
+	ProposalID int64     `gorm:"primaryKey;column:proposal_id" json:"proposalId"`
+	PlanID     int64     `gorm:"column:plan_id;not null;index" json:"planId"`
+	Sessions   string    `gorm:"column:sessions;type:text;not null;comment:方案中的上课（JSON）" json:"sessions"`
+	Unplaced   string    `gorm:"column:unplaced;type:text;not null;comment:未能排入的课程（JSON）" json:"unplaced"`
+	CreatorID  int64     `gorm:"column:creator_id;not null" json:"creatorId"`
+	CreatedAt  time.Time `gorm:"column:created_at" json:"createdAt"`
+}
+
+func (TimetableProposal) TableName() string {
+	return "timetable_proposal"
+}
+
+// CourseItemReschedule 课程安排改期记录：改期原因，以及每条课程安排改期前后的时间
+type CourseItemReschedule struct {
+	RescheduleID      int64      `gorm:"primaryKey;column:reschedule_id" json:"rescheduleId"`
+	PlanID            int64      `gorm:"column:plan_id;not null;index" json:"planId"`
+	ItemID            *int64     `gorm:"column:item_id;index;comment:单条课程安排改期时为该安排，整个计划顺延时为空" json:"itemId"`
+	ShiftDays         int        `gorm:"column:shift_days;not null;default:0;comment:顺延天数，改到指定时间段时为0" json:"shiftDays"`
+	Reason            string     `gorm:"column:reason;size:500;not null" json:"reason"`
+	Moves             string     `gorm:"column:moves;type:text;not null;comment:每条课程安排改期前后的时间（JSON）" json:"moves"`
+	PlanStartDatetime *time.Time `gorm:"column:plan_start_datetime;comment:整个计划顺延前的开始时间" json:"planStartDatetime"`
+	PlanEndDatetime   *time.Time `gorm:"column:plan_end_datetime;comment:整个计划顺延前的结束时间" json:"planEndDatetime"`
+	OperatorID        int64      `gorm:"column:operator_id;not null" json:"operatorId"`
+	CreatedAt         time.Time  `gorm:"column:created_at" json:"createdAt"`
+}
+
+func (CourseItemReschedule) TableName() string {
+	return "course_item_reschedule"
+}
+
+// 计划状态变更的来源（plan_status_transition.source）
+const (
+	PlanStatusSourceCreate = "create" // 创建计划时的初始状态
+	PlanStatusSourceManual = "manual" // 大纲制定者修改
+	PlanStatusSourceAuto   = "auto"   // 定时任务按计划时间和课程进度自动变更
+)
+
+// PlanStatusTransition 培训计划状态变更记录
+type PlanStatusTransition struct {
+	TransitionID int64     `gorm:"primaryKey;column:transition_id" json:"transitionId"`
+	PlanID       int64     `gorm:"column:plan_id;not null;index" json:"planId"`
+	FromStatus   string    `gorm:"column:from_status;size:3;not null;default:'';comment:变更前的状态，创建计划时为空" json:"fromStatus"`
+	ToStatus     string    `gorm:"column:to_status;size:3;not null" json:"toStatus"`
+	ActorID      *int64    `gorm:"column:actor_id;comment:操作人，定时任务自动变更时为空" json:"actorId"`
+	Source       string    `gorm:"column:source;size:10;not null;comment:create/manual/auto" json:"source"`
+	Reason       string    `gorm:"column:reason;size:200;not null;default:''" json:"reason"`
+	CreatedAt    time.Time `gorm:"column:created_at" json:"createdAt"`
+}
+
+func (PlanStatusTransition) TableName() string {
+	return "plan_status_transition"
+}
+
+// PlanTemplate 培训计划模板：课程安排（相对计划开始日期的天数）、上课时间、地点和报名规则
+type PlanTemplate struct {
+	TemplateID      int64     `gorm:"primaryKey;column:template_id" json:"templateId"`
+	TemplateName    string    `gorm:"column:template_name;size:50;not null" json:"templateName"`
+	Description     string    `gorm:"column:description;size:200;not null;default:''" json:"description"`
+	SourcePlanID    *int64    `gorm:"column:source_plan_id;comment:保存模板时的培训计划" json:"sourcePlanId"`
+	DurationDays    int       `gorm:"column:duration_days;not null;comment:计划结束日期与开始日期相差的天数" json:"durationDays"`
+	PlanStartTime   string    `gorm:"column:plan_start_time;type:varchar(8);not null" json:"planStartTime"`
+	PlanEndTime     string    `gorm:"column:plan_end_time;type:varchar(8);not null" json:"planEndTime"`
+	Timezone        string    `gorm:"column:timezone;size:64;not null;default:''" json:"timezone"`
+	Items           string    `gorm:"column:items;type:text;not null;comment:课程安排（JSON）" json:"items"`
+	EnrollmentRules string    `gorm:"column:enrollment_rules;type:text;not null;comment:报名规则（JSON）" json:"enrollmentRules"`
+	CreatorID       int64     `gorm:"column:creator_id;not null" json:"creatorId"`
+	CreatedAt       time.Time `gorm:"column:created_at" json:"createdAt"`
+}
+
+func (PlanTemplate) TableName() string {
+	return "plan_template"
+}
+
+// TeacherAvailability 讲师每周可授课的时间段，讲师没有登记时不限制
+type TeacherAvailability struct {
+	AvailabilityID int64  `gorm:"primaryKey;column:availability_id" json:"availabilityId"`
+	TeacherID      int64  `gorm:"column:teacher_id;not null;index" json:"teacherId"`
+	Weekday        int    `gorm:"column:weekday;not null;comment:星期，1-7 表示周一到周日" json:"weekday"`
+	BeginTime      string `gorm:"column:begin_time;type:varchar(8);not null" json:"beginTime"`
+	EndTime        string `gorm:"column:end_time;type:varchar(8);not null" json:"endTime"`
+}
+
+func (TeacherAvailability) TableName() string {
+	return "teacher_availability"
+}
+
+// TeacherBlackout 讲师不可授课的时间段（请假、随船出海等），从开始日期的开始时间到结束日期的结束时间
+type TeacherBlackout struct {
+	BlackoutID int64     `gorm:"primaryKey;column:blackout_id" json:"blackoutId"`
+	TeacherID  int64     `gorm:"column:teacher_id;not null;index" json:"teacherId"`
+	StartDate  time.Time `gorm:"column:start_date;type:date;not null" json:"startDate"`
+	BeginTime  string    `gorm:"column:begin_time;type:varchar(8);not null" json:"beginTime"`
+	EndDate    time.Time `gorm:"column:end_date;type:date;not null" json:"endDate"`
+	EndTime    string    `gorm:"column:end_time;type:varchar(8);not null" json:"endTime"`
+	Reason     string    `gorm:"column:reason;size:200;not null;default:''" json:"reason"`
+	CreatedAt  time.Time `gorm:"column:created_at" json:"createdAt"`
+}
+
+func (TeacherBlackout) TableName() string {
+	return "teacher_blackout"
+}
+
+// CalendarEntry 工作日历：法定节假日、公司停工和工作日调整，site 为空表示适用于全部地点
+type CalendarEntry struct {
+	EntryID   int64     `gorm:"primaryKey;column:entry_id" json:"entryId"`
+	Kind      string    `gorm:"column:kind;size:20;not null;comment:holiday 法定节假日 / shutdown 公司停工 / workday 照常工作" json:"kind"`
+	Name      string    `gorm:"column:name;size:100;not null" json:"name"`
+	StartDate time.Time `gorm:"column:start_date;type:date;not null" json:"startDate"`
+	EndDate   time.Time `gorm:"column:end_date;type:date;not null" json:"endDate"`
+	Site      string    `gorm:"column:site;size:50;not null;default:'';index;comment:适用的楼宇或船舶（对应 location.building），为空表示全部地点" json:"site"`
+	Policy    string    `gorm:"column:policy;size:10;not null;default:'';comment:排课时 warn 提醒 / block 禁止，workday 为空" json:"policy"`
+	Source    string    `gorm:"column:source;size:10;not null;default:'manual';comment:manual 手动登记 / ical 导入" json:"source"`
+	UID       string    `gorm:"column:uid;size:255;not null;default:'';comment:iCal 事件 UID，重复导入时用于更新" json:"uid"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"createdAt"`
+}
+
+func (CalendarEntry) TableName() string {
+	return "calendar_entry"
+}
+
+// Location 培训地点表（教室、会议室、船上场所等）
+type Location struct {
+	LocationID   int64  `gorm:"primaryKey;column:location_id" json:"locationId"`
+	LocationName string `gorm:"column:location_name;size:100;not null;uniqueIndex" json:"locationName"`
+	Building     string `gorm:"column:building;size:50;not null;default:'';comment:所在楼宇或船舶" json:"building"`
+	Capacity     int    `gorm:"column:capacity;not null;default:0;comment:容纳人数，0表示不限" json:"capacity"`
+	Equipment    string `gorm:"column:equipment;size:500;not null;default:'';comment:设备，逗号分隔" json:"equipment"`
+	Timezone     string `gorm:"column:timezone;size:64;not null;default:'';comment:IANA 时区，为空时使用计划或系统默认时区" json:"timezone"`
+}
+
+func (Location) TableName() string {
+	return "location"
+}
+
 // AttendanceEvaluation 参与和评价表
 type AttendanceEvaluation struct {
-	PersonID       int64   `gorm:"primaryKey;column:person_id" json:"personId"`
-	ItemID         int64   `gorm:"primaryKey;column:item_id" json:"itemId"`
-	SelfScore      float64 `gorm:"column:self_score;type:float" json:"selfScore"`
-	SelfComment    string  `gorm:"column:self_comment;type:text" json:"selfComment"`
-	TeacherScore   float64 `gorm:"column:teacher_score;type:float" json:"teacherScore"`
-	TeacherComment string  `gorm:"column:teacher_comment;type:text" json:"teacherComment"`
-	ScoreRatio     float64 `gorm:"column:score_ratio;type:float;comment:讲师评分占比" json:"scoreRatio"`
-	Person         Person  `gorm:"foreignKey:PersonID;references:PersonID"`
-	Item           PlanCourseItem `gorm:"foreignKey:ItemID;references:ItemID"`
+	PersonID              int64          `gorm:"primaryKey;column:person_id" json:"personId"`
+	ItemID                int64          `gorm:"primaryKey;column:item_id" json:"itemId"`
+	SelfScore             float64        `gorm:"column:self_score;type:float" json:"selfScore"`
+	SelfComment           string         `gorm:"column:self_comment;type:text" json:"selfComment"`
+	TeacherScore          float64        `gorm:"column:teacher_score;type:float" json:"teacherScore"`
+	TeacherComment        string         `gorm:"column:teacher_comment;type:text" json:"teacherComment"`
+	Understanding         int            `gorm:"column:understanding;type:smallint;default:0;comment:理解程度1-5，0表示未填写" json:"understanding"`
+	Difficulty            int            `gorm:"column:difficulty;type:smallint;default:0;comment:难度感受1-5，0表示未填写" json:"difficulty"`
+	Satisfaction          int            `gorm:"column:satisfaction;type:smallint;default:0;comment:满意度1-5，0表示未填写" json:"satisfaction"`
+	SelfScoreRationale    string         `gorm:"column:self_score_rationale;type:text;comment:AI自评评分理由" json:"selfScoreRationale"`
+	TeacherScoreRationale string         `gorm:"column:teacher_score_rationale;type:text;comment:AI讲师评分理由" json:"teacherScoreRationale"`
+	SelfScoringStatus     string         `gorm:"column:self_scoring_status;size:10;comment:自评AI评分状态" json:"selfScoringStatus"`
+	TeacherScoringStatus  string         `gorm:"column:teacher_scoring_status;size:10;comment:讲师AI评分状态" json:"teacherScoringStatus"`
+	ScoreRatio            float64        `gorm:"column:score_ratio;type:float;comment:讲师评分占比" json:"scoreRatio"`
+	Person                Person         `gorm:"foreignKey:PersonID;references:PersonID"`
+	Item                  PlanCourseItem `gorm:"foreignKey:ItemID;references:ItemID"`
 }
 
 func (AttendanceEvaluation) TableName() string {
 	return "attendance_evaluation"
 }
 
+// 计划员工的加入方式（plan_employee.source）
+const (
+	PlanEmployeeSourceManual = "manual" // 大纲制定者手动添加
+	PlanEmployeeSourceRule   = "rule"   // 按报名规则自动加入，不再匹配时会被自动移除
+)
+
 // PlanEmployee 规划员工表
 type PlanEmployee struct {
 	PlanID   int64        `gorm:"primaryKey;column:plan_id" json:"planId"`
 	PersonID int64        `gorm:"primaryKey;column:person_id" json:"personId"`
+	Source   string       `gorm:"column:source;size:10;not null;default:manual;comment:manual/rule" json:"source"`
 	Plan     TrainingPlan `gorm:"foreignKey:PlanID;references:PlanID"`
 	Person   Person       `gorm:"foreignKey:PersonID;references:PersonID"`
 }
@@ -105,15 +395,149 @@ func (PlanEmployee) TableName() string {
 	return "plan_employee"
 }
 
-// Session 会话表（用于简单鉴权）
-type Session struct {
-	SessionID string    `gorm:"primaryKey;column:session_id;size:64" json:"sessionId"`
-	PersonID  int64     `gorm:"column:person_id;not null;index" json:"personId"`
-	Role      string    `gorm:"column:role;size:10;not null" json:"role"`
+// PlanEnrollmentRule 培训计划自动报名规则表
+// 单条规则内已设置的条件需同时满足；同一计划的多条规则满足任意一条即可
+type PlanEnrollmentRule struct {
+	RuleID             int64      `gorm:"primaryKey;column:rule_id" json:"ruleId"`
+	PlanID             int64      `gorm:"column:plan_id;not null;index" json:"planId"`
+	OrgUnitID          *int64     `gorm:"column:org_unit_id;comment:组织节点（含下级节点）" json:"orgUnitId"`
+	JobRank            string     `gorm:"column:job_rank;size:20;not null;default:'';comment:职级" json:"jobRank"`
+	HiredAfter         *time.Time `gorm:"column:hired_after;type:date;comment:入职日期晚于" json:"hiredAfter"`
+	MissingCertificate string     `gorm:"column:missing_certificate;size:50;not null;default:'';comment:未持有（或已过期）的证书" json:"missingCertificate"`
+	CreatedAt          time.Time  `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+}
+
+func (PlanEnrollmentRule) TableName() string {
+	return "plan_enrollment_rule"
+}
+
+// PlanEnrollmentExclusion 自动报名排除表（手动移出计划的员工不再被规则自动加入）
+type PlanEnrollmentExclusion struct {
+	PlanID    int64     `gorm:"primaryKey;column:plan_id" json:"planId"`
+	PersonID  int64     `gorm:"primaryKey;column:person_id" json:"personId"`
 	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
-	ExpiresAt time.Time `gorm:"column:expires_at;not null" json:"expiresAt"`
+}
+
+func (PlanEnrollmentExclusion) TableName() string {
+	return "plan_enrollment_exclusion"
+}
+
+// CalendarFeed 个人日历订阅令牌，订阅地址为 /api/calendar/{token}.ics
+type CalendarFeed struct {
+	PersonID  int64     `gorm:"primaryKey;autoIncrement:false;column:person_id" json:"personId"`
+	Token     string    `gorm:"column:token;size:64;not null;uniqueIndex" json:"token"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"createdAt"`
+}
+
+func (CalendarFeed) TableName() string {
+	return "calendar_feed"
+}
+
+// CourseItemCancellation 已删除的课程安排快照，日历订阅据此输出已取消的事件
+type CourseItemCancellation struct {
+	ItemID         int64     `gorm:"primaryKey;autoIncrement:false;column:item_id" json:"itemId"`
+	PlanID         int64     `gorm:"column:plan_id;not null;index" json:"planId"`
+	CourseID       int64     `gorm:"column:course_id;not null;index" json:"courseId"`
+	ClassDate      time.Time `gorm:"column:class_date;type:date;not null" json:"classDate"`
+	ClassBeginTime string    `gorm:"column:class_begin_time;type:varchar(8);not null" json:"classBeginTime"`
+	ClassEndTime   string    `gorm:"column:class_end_time;type:varchar(8);not null" json:"classEndTime"`
+	Location       string    `gorm:"column:location;size:100;not null" json:"location"`
+	Timezone       string    `gorm:"column:timezone;size:64;not null;default:''" json:"timezone"`
+	Sequence       int       `gorm:"column:sequence;not null;default:0" json:"sequence"`
+	CancelledAt    time.Time `gorm:"column:cancelled_at;not null" json:"cancelledAt"`
+}
+
+func (CourseItemCancellation) TableName() string {
+	return "course_item_cancellation"
+}
+
+// Session 会话表：每次登录一条，访问令牌中记录 SessionID，刷新令牌只保存 SHA-256 哈希，每次刷新后更换
+type Session struct {
+	SessionID        string     `gorm:"primaryKey;column:session_id;size:64" json:"sessionId"`
+	RefreshTokenHash string     `gorm:"column:refresh_token_hash;size:64;not null;default:'';uniqueIndex;comment:当前刷新令牌的 SHA-256 哈希，刷新后旧令牌失效" json:"-"`
+	PersonID         int64      `gorm:"column:person_id;not null;index" json:"personId"`
+	Role             string     `gorm:"column:role;size:10;not null" json:"role"`
+	CreatedAt        time.Time  `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+	ExpiresAt        time.Time  `gorm:"column:expires_at;not null;index" json:"expiresAt"`
+	LastSeenAt       *time.Time `gorm:"column:last_seen_at;comment:最近一次登录或刷新访问令牌的时间" json:"lastSeenAt"`
+	IPAddress        string     `gorm:"column:ip_address;size:45;not null;default:'';comment:登录时的客户端 IP" json:"ipAddress"`
+	UserAgent        string     `gorm:"column:user_agent;size:255;not null;default:'';comment:登录时的 User-Agent" json:"userAgent"`
 }
 
 func (Session) TableName() string {
 	return "sessions"
 }
+
+// RetiredRefreshToken 已换下的刷新令牌：刷新时旧令牌的哈希记录在此，再次使用说明令牌可能已泄露，注销整个会话
+type RetiredRefreshToken struct {
+	TokenHash string     `gorm:"primaryKey;column:token_hash;size:64;comment:已换下的刷新令牌的 SHA-256 哈希" json:"-"`
+	SessionID string     `gorm:"column:session_id;size:64;not null;index" json:"sessionId"`
+	RetiredAt *time.Time `gorm:"column:retired_at;comment:换下的时间" json:"retiredAt"`
+}
+
+func (RetiredRefreshToken) TableName() string {
+	return "retired_refresh_tokens"
+}
+
+// AIScoringLog AI评分审计日志表（记录每次AI评分的提示词、原始输出与结果）
+type AIScoringLog struct {
+	LogID        int64     `gorm:"primaryKey;column:log_id" json:"logId"`
+	PersonID     int64     `gorm:"column:person_id;not null;index:idx_ai_scoring_log_eval" json:"personId"`
+	ItemID       int64     `gorm:"column:item_id;not null;index:idx_ai_scoring_log_eval" json:"itemId"`
+	ScoreType    string    `gorm:"column:score_type;size:10;not null;comment:self/teacher" json:"scoreType"`
+	Provider     string    `gorm:"column:provider;size:20" json:"provider"`
+	Prompt       string    `gorm:"column:prompt;type:text" json:"prompt"`
+	RawOutput    string    `gorm:"column:raw_output;type:text" json:"rawOutput"`
+	ParsedScore  *float64  `gorm:"column:parsed_score;type:float;comment:模型输出解析出的分数" json:"parsedScore"`
+	FinalScore   float64   `gorm:"column:final_score;type:float;comment:最终采用的分数" json:"finalScore"`
+	Rationale    string    `gorm:"column:rationale;type:text" json:"rationale"`
+	UsedFallback bool      `gorm:"column:used_fallback;not null;default:false" json:"usedFallback"`
+	LatencyMs    int64     `gorm:"column:latency_ms" json:"latencyMs"`
+	ErrorMessage string    `gorm:"column:error_message;type:text" json:"errorMessage"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+}
+
+func (AIScoringLog) TableName() string {
+	return "ai_scoring_log"
+}
+
+// AI评分状态（attendance_evaluation.self_scoring_status / teacher_scoring_status）
+// 为空表示未使用AI评分（如讲师直接打分）
+const (
+	ScoringStatusPending  = "pending"  // 已提交，等待AI评分
+	ScoringStatusDone     = "done"     // AI评分完成
+	ScoringStatusFallback = "fallback" // AI多次失败，已使用备用算法评分
+)
+
+// AI评分任务状态（ai_scoring_job.status）
+const (
+	JobStatusPending   = "pending"   // 等待执行（含等待重试）
+	JobStatusRunning   = "running"   // 执行中
+	JobStatusDone      = "done"      // 执行成功
+	JobStatusFailed    = "failed"    // 重试次数用尽，已使用备用评分
+	JobStatusCancelled = "cancelled" // 评价被重新提交，任务作废
+)
+
+// AIScoringJob AI评分任务表（异步评分队列，保存评分所需的输入快照）
+type AIScoringJob struct {
+	JobID         int64      `gorm:"primaryKey;column:job_id" json:"jobId"`
+	PersonID      int64      `gorm:"column:person_id;not null;index:idx_ai_scoring_job_eval" json:"personId"`
+	ItemID        int64      `gorm:"column:item_id;not null;index:idx_ai_scoring_job_eval" json:"itemId"`
+	ScoreType     string     `gorm:"column:score_type;size:10;not null;comment:self/teacher" json:"scoreType"`
+	Status        string     `gorm:"column:status;size:10;not null;index:idx_ai_scoring_job_due;comment:pending/running/done/failed/cancelled" json:"status"`
+	CourseName    string     `gorm:"column:course_name;size:50" json:"courseName"`
+	Comment       string     `gorm:"column:comment;type:text" json:"-"`
+	Understanding int        `gorm:"column:understanding" json:"-"`
+	Difficulty    int        `gorm:"column:difficulty" json:"-"`
+	Satisfaction  int        `gorm:"column:satisfaction" json:"-"`
+	Attempts      int        `gorm:"column:attempts;not null;default:0" json:"attempts"`
+	LastError     string     `gorm:"column:last_error;type:text" json:"lastError"`
+	NextRunAt     time.Time  `gorm:"column:next_run_at;not null;index:idx_ai_scoring_job_due" json:"nextRunAt"`
+	CreatedAt     time.Time  `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"updatedAt"`
+	FinishedAt    *time.Time `gorm:"column:finished_at" json:"finishedAt"`
+}
+
+func (AIScoringJob) TableName() string {
+	return "ai_scoring_job"
+}
@@ -0,0 +1,38 @@
+package database
+
+// OrgUnitSubtreeIDs 返回指定组织节点及其全部下级节点的ID（含自身）
+// 组织架构规模很小，一次读出所有节点后在内存中遍历，避免依赖各数据库的递归查询语法
+func OrgUnitSubtreeIDs(unitID int64) ([]int64, error) {
+	var units []OrgUnit
+	if err := DB.Select("unit_id, parent_id").Find(&units).Error; err != nil {
+		return nil, err
+	}
+
+	children := make(map[int64][]int64)
+	for _, u := range units {
+		if u.ParentID != nil {
+			children[*u.ParentID] = append(children[*u.ParentID], u.UnitID)
+		}
+	}
+
+	ids := []int64{unitID}
+	visited := map[int64]bool{unitID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !visited[child] {
+				visited[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// IsValidOrgUnitType 判断组织节点类型是否合法
+func IsValidOrgUnitType(unitType string) bool {
+	switch unitType {
+	case OrgUnitTypeCompany, OrgUnitTypeDepartment, OrgUnitTypeTeam, OrgUnitTypeVessel:
+		return true
+	}
+	return false
+}
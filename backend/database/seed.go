@@ -37,6 +37,13 @@ func SeedTestAccounts() error {
 		return err
 	}
 
+	// PostgreSQL 的自增序列不会因显式指定主键而前进，需要手动同步，否则之后注册的用户会主键冲突
+	if Dialect() == DriverPostgres {
+		if err := DB.Exec("SELECT setval(pg_get_serial_sequence('person', 'person_id'), (SELECT MAX(person_id) FROM person))").Error; err != nil {
+			return err
+		}
+	}
+
 	// 插入账号数据（使用哈希后的密码）
 	accounts := []Account{
 		{PersonID: 1, LoginName: "planner", PasswordHash: string(hashedPassword)},
@@ -49,6 +56,31 @@ func SeedTestAccounts() error {
 		return err
 	}
 
+	// 插入示例组织架构，员工分别归属到部门班组和船舶
+	company := OrgUnit{UnitName: "远洋航运公司", UnitType: OrgUnitTypeCompany}
+	if err := DB.Create(&company).Error; err != nil {
+		return err
+	}
+	department := OrgUnit{UnitName: "安全管理部", UnitType: OrgUnitTypeDepartment, ParentID: &company.UnitID}
+	vessel := OrgUnit{UnitName: "远航一号", UnitType: OrgUnitTypeVessel, ParentID: &company.UnitID}
+	if err := DB.Create(&department).Error; err != nil {
+		return err
+	}
+	if err := DB.Create(&vessel).Error; err != nil {
+		return err
+	}
+	DB.Model(&Person{}).Where("person_id = ?", 4).Update("org_unit_id", department.UnitID)
+	DB.Model(&Person{}).Where("person_id = ?", 5).Update("org_unit_id", vessel.UnitID)
+
+	// 插入示例培训地点
+	locations := []Location{
+		{LocationName: "培训楼A101", Building: "培训楼", Capacity: 30, Equipment: "投影仪,白板"},
+		{LocationName: "远航一号会议室", Building: "远航一号", Capacity: 12, Equipment: "投影仪,救生衣"},
+	}
+	if err := DB.Create(&locations).Error; err != nil {
+		return err
+	}
+
 	log.Println("测试账号插入完成！")
 	log.Println("测试账号列表（密码均为 123456）：")
 	log.Println("  - planner (课程大纲制定者)")
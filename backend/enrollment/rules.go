@@ -0,0 +1,302 @@
+package enrollment
+
+import (
+	"backend/database"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Member 同步结果中涉及的员工
+type Member struct {
+	PersonID   int64  `json:"personId"`
+	PersonName string `json:"personName"`
+}
+
+// Result 按报名规则计算出的名单变化
+type Result struct {
+	PlanID       int64    `json:"planId"`
+	RuleCount    int      `json:"ruleCount"`
+	MatchedCount int      `json:"matchedCount"` // 匹配规则的员工数（不含已手动移出的）
+	ToAdd        []Member `json:"toAdd"`        // 匹配规则但尚未加入计划
+	ToRemove     []Member `json:"toRemove"`     // 由规则加入、现已不再匹配
+	Retained     []Member `json:"retained"`     // 不再匹配但已有评价记录，保留在计划中
+	Excluded     []Member `json:"excluded"`     // 匹配规则但已被手动移出计划
+	Applied      bool     `json:"applied"`      // 是否已写入 plan_employee
+}
+
+// Preview 计算按给定规则同步后的名单变化，不写入数据库（rules 为 nil 时使用计划已保存的规则）
+func Preview(planID int64, rules []database.PlanEnrollmentRule) (*Result, error) {
+	if rules == nil {
+		if err := database.DB.Where("plan_id = ?", planID).Find(&rules).Error; err != nil {
+			return nil, err
+		}
+	}
+	return diff(database.DB, planID, rules)
+}
+
+// Sync 按计划已保存的规则同步员工名单
+func Sync(planID int64) (*Result, error) {
+	var result *Result
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var rules []database.PlanEnrollmentRule
+		if err := tx.Where("plan_id = ?", planID).Find(&rules).Error; err != nil {
+			return err
+		}
+		var err error
+		result, err = diff(tx, planID, rules)
+		if err != nil {
+			return err
+		}
+		return apply(tx, result)
+	})
+	return result, err
+}
+
+// SyncPerson 立即同步员工由规则加入、且尚未完成的计划（如账号停用后移出这些计划）
+func SyncPerson(personID int64) error {
+	var planIDs []int64
+	err := database.DB.Model(&database.PlanEmployee{}).
+		Joins("JOIN training_plan tp ON tp.plan_id = plan_employee.plan_id").
+		Where("plan_employee.person_id = ? AND plan_employee.source = ?", personID, database.PlanEmployeeSourceRule).
+		Where("tp.plan_status != ?", database.PlanStatusCompleted).
+		Pluck("plan_employee.plan_id", &planIDs).Error
+	if err != nil {
+		return err
+	}
+	for _, planID := range planIDs {
+		if _, err := Sync(planID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReplaceRules 用新规则替换计划的全部报名规则，并立即同步员工名单
+func ReplaceRules(planID int64, rules []database.PlanEnrollmentRule) (*Result, error) {
+	var result *Result
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = ReplaceRulesIn(tx, planID, rules)
+		return err
+	})
+	return result, err
+}
+
+// ReplaceRulesIn 在调用方的事务中替换报名规则并同步员工名单（如按模板创建计划时与课程安排一起写入）
+func ReplaceRulesIn(tx *gorm.DB, planID int64, rules []database.PlanEnrollmentRule) (*Result, error) {
+	if err := tx.Where("plan_id = ?", planID).Delete(&database.PlanEnrollmentRule{}).Error; err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		rules[i].RuleID = 0
+		rules[i].PlanID = planID
+	}
+	if len(rules) > 0 {
+		if err := tx.Create(&rules).Error; err != nil {
+			return nil, err
+		}
+	}
+	result, err := diff(tx, planID, rules)
+	if err != nil {
+		return nil, err
+	}
+	return result, apply(tx, result)
+}
+
+// diff 对比规则匹配结果与计划当前名单
+// 手动添加的员工不受规则影响；由规则加入的员工不再匹配时移除，但已有评价记录的保留
+func diff(tx *gorm.DB, planID int64, rules []database.PlanEnrollmentRule) (*Result, error) {
+	result := &Result{
+		PlanID:    planID,
+		RuleCount: len(rules),
+		ToAdd:     []Member{},
+		ToRemove:  []Member{},
+		Retained:  []Member{},
+		Excluded:  []Member{},
+	}
+
+	matched, err := matchEmployees(tx, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	var current []database.PlanEmployee
+	if err := tx.Where("plan_id = ?", planID).Find(&current).Error; err != nil {
+		return nil, err
+	}
+	currentSource := make(map[int64]string, len(current))
+	for _, pe := range current {
+		currentSource[pe.PersonID] = pe.Source
+	}
+
+	var excludedIDs []int64
+	if err := tx.Model(&database.PlanEnrollmentExclusion{}).Where("plan_id = ?", planID).Pluck("person_id", &excludedIDs).Error; err != nil {
+		return nil, err
+	}
+	excluded := make(map[int64]bool, len(excludedIDs))
+	for _, id := range excludedIDs {
+		excluded[id] = true
+	}
+
+	matchedSet := make(map[int64]bool, len(matched))
+	for _, p := range matched {
+		if excluded[p.PersonID] {
+			result.Excluded = append(result.Excluded, Member{p.PersonID, p.Name})
+			continue
+		}
+		matchedSet[p.PersonID] = true
+		result.MatchedCount++
+		if _, ok := currentSource[p.PersonID]; !ok {
+			result.ToAdd = append(result.ToAdd, Member{p.PersonID, p.Name})
+		}
+	}
+
+	// 由规则加入但不再匹配的员工
+	var staleIDs []int64
+	for _, pe := range current {
+		if pe.Source == database.PlanEmployeeSourceRule && !matchedSet[pe.PersonID] {
+			staleIDs = append(staleIDs, pe.PersonID)
+		}
+	}
+	if len(staleIDs) == 0 {
+		return result, nil
+	}
+
+	var stale []database.Person
+	if err := tx.Where("person_id IN ?", staleIDs).Order("person_id").Find(&stale).Error; err != nil {
+		return nil, err
+	}
+	var evaluatedIDs []int64
+	if err := tx.Table("attendance_evaluation").
+		Joins("JOIN plan_course_item ON attendance_evaluation.item_id = plan_course_item.item_id").
+		Where("plan_course_item.plan_id = ? AND attendance_evaluation.person_id IN ?", planID, staleIDs).
+		Distinct().
+		Pluck("attendance_evaluation.person_id", &evaluatedIDs).Error; err != nil {
+		return nil, err
+	}
+	evaluated := make(map[int64]bool, len(evaluatedIDs))
+	for _, id := range evaluatedIDs {
+		evaluated[id] = true
+	}
+	for _, p := range stale {
+		if evaluated[p.PersonID] {
+			result.Retained = append(result.Retained, Member{p.PersonID, p.Name})
+		} else {
+			result.ToRemove = append(result.ToRemove, Member{p.PersonID, p.Name})
+		}
+	}
+	return result, nil
+}
+
+// apply 将名单变化写入 plan_employee
+func apply(tx *gorm.DB, result *Result) error {
+	for _, m := range result.ToAdd {
+		pe := database.PlanEmployee{
+			PlanID:   result.PlanID,
+			PersonID: m.PersonID,
+			Source:   database.PlanEmployeeSourceRule,
+		}
+		// 多实例同时同步时可能重复插入，忽略主键冲突
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&pe).Error; err != nil {
+			return err
+		}
+	}
+	if len(result.ToRemove) > 0 {
+		ids := make([]int64, 0, len(result.ToRemove))
+		for _, m := range result.ToRemove {
+			ids = append(ids, m.PersonID)
+		}
+		if err := tx.Where("plan_id = ? AND person_id IN ? AND source = ?", result.PlanID, ids, database.PlanEmployeeSourceRule).
+			Delete(&database.PlanEmployee{}).Error; err != nil {
+			return err
+		}
+	}
+	result.Applied = true
+	return nil
+}
+
+// matchEmployees 返回满足任意一条规则的员工（按 person_id 排序）
+func matchEmployees(tx *gorm.DB, rules []database.PlanEnrollmentRule) ([]database.Person, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	// 账号已停用的员工不匹配任何规则
+	var employees []database.Person
+	err := tx.Where("role = ?", "员工").
+		Where("person_id NOT IN (?)", tx.Model(&database.Account{}).Select("person_id").Where("deactivated_at IS NOT NULL")).
+		Order("person_id").
+		Find(&employees).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 各规则组织节点的子树
+	subtrees := make([]map[int64]bool, len(rules))
+	needCerts := false
+	for i, r := range rules {
+		if r.MissingCertificate != "" {
+			needCerts = true
+		}
+		if r.OrgUnitID == nil {
+			continue
+		}
+		ids, err := database.OrgUnitSubtreeIDs(*r.OrgUnitID)
+		if err != nil {
+			return nil, err
+		}
+		subtrees[i] = make(map[int64]bool, len(ids))
+		for _, id := range ids {
+			subtrees[i][id] = true
+		}
+	}
+
+	// 员工持有的有效证书
+	validCerts := make(map[int64]map[string]bool)
+	if needCerts {
+		var certs []database.PersonCertificate
+		if err := tx.Find(&certs).Error; err != nil {
+			return nil, err
+		}
+		today := time.Now().Format("2006-01-02")
+		for _, cert := range certs {
+			if cert.ExpiresOn != nil && cert.ExpiresOn.Format("2006-01-02") < today {
+				continue
+			}
+			if validCerts[cert.PersonID] == nil {
+				validCerts[cert.PersonID] = make(map[string]bool)
+			}
+			validCerts[cert.PersonID][cert.CertName] = true
+		}
+	}
+
+	var matched []database.Person
+	for _, p := range employees {
+		for i, r := range rules {
+			if ruleMatches(r, subtrees[i], p, validCerts[p.PersonID]) {
+				matched = append(matched, p)
+				break
+			}
+		}
+	}
+	return matched, nil
+}
+
+// ruleMatches 判断员工是否满足单条规则的全部条件
+func ruleMatches(r database.PlanEnrollmentRule, subtree map[int64]bool, p database.Person, certs map[string]bool) bool {
+	if subtree != nil && (p.OrgUnitID == nil || !subtree[*p.OrgUnitID]) {
+		return false
+	}
+	if r.JobRank != "" && p.JobRank != r.JobRank {
+		return false
+	}
+	if r.HiredAfter != nil && (p.HireDate == nil || p.HireDate.Format("2006-01-02") <= r.HiredAfter.Format("2006-01-02")) {
+		return false
+	}
+	if r.MissingCertificate != "" && certs[r.MissingCertificate] {
+		return false
+	}
+	return true
+}
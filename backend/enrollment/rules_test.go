@@ -0,0 +1,57 @@
+package enrollment
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"testing"
+	"time"
+)
+
+func TestDeactivatedEmployeesLeaveRulePlans(t *testing.T) {
+	dbtest.Setup(t)
+
+	var company database.OrgUnit
+	if err := database.DB.Where("unit_name = ?", "远洋航运公司").First(&company).Error; err != nil {
+		t.Fatal(err)
+	}
+	plan := dbtest.CreatePlan(t, "安全培训", dbtest.Date(1), dbtest.Date(9))
+	if _, err := ReplaceRules(plan.PlanID, []database.PlanEnrollmentRule{{OrgUnitID: &company.UnitID}}); err != nil {
+		t.Fatal(err)
+	}
+	assertRoster(t, plan.PlanID, dbtest.EmployeeID, dbtest.Employee2ID)
+
+	now := time.Now()
+	if err := database.DB.Model(&database.Account{}).Where("person_id = ?", dbtest.Employee2ID).Update("deactivated_at", &now).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := SyncPerson(dbtest.Employee2ID); err != nil {
+		t.Fatal(err)
+	}
+	assertRoster(t, plan.PlanID, dbtest.EmployeeID)
+
+	// 停用的员工不再匹配规则，之后的同步也不会重新加入
+	result, err := Sync(plan.PlanID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.MatchedCount != 1 || len(result.ToAdd) != 0 {
+		t.Errorf("停用的员工不应匹配规则，实际 %+v", result)
+	}
+	assertRoster(t, plan.PlanID, dbtest.EmployeeID)
+}
+
+func assertRoster(t *testing.T, planID int64, want ...int64) {
+	t.Helper()
+	var got []int64
+	if err := database.DB.Model(&database.PlanEmployee{}).Where("plan_id = ?", planID).Order("person_id").Pluck("person_id", &got).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("计划 %d 的员工应为 %v，实际 %v", planID, want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("计划 %d 的员工应为 %v，实际 %v", planID, want, got)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package enrollment
+
+import (
+	"backend/config"
+	"backend/database"
+	"log"
+	"sync"
+	"time"
+)
+
+// syncer 定时按报名规则重新同步各计划的员工名单，人员信息变动时也可提前唤醒
+type syncer struct {
+	interval time.Duration
+	wake     chan struct{}
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+var s *syncer
+
+// Start 启动报名规则同步任务（启动时调用），同步间隔不大于 0 时不启动
+func Start(cfg *config.Config) {
+	if cfg.EnrollmentSyncMinutes <= 0 {
+		log.Println("报名规则定时同步已关闭")
+		return
+	}
+
+	s = &syncer{
+		interval: time.Duration(cfg.EnrollmentSyncMinutes) * time.Minute,
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.loop()
+
+	log.Printf("报名规则同步任务已启动，间隔: %v", s.interval)
+}
+
+// Stop 停止同步任务，等待进行中的同步完成
+func Stop() {
+	if s == nil {
+		return
+	}
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// Notify 人员的组织节点、职级、入职日期或证书变动后调用，尽快重新同步
+func Notify() {
+	if s == nil {
+		return
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *syncer) loop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.syncAll()
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// syncAll 同步所有设置了规则（或仍有规则加入的员工）且未完成的计划
+func (s *syncer) syncAll() {
+	var planIDs []int64
+	err := database.DB.Model(&database.TrainingPlan{}).
+		Where("plan_status != ?", database.PlanStatusCompleted).
+		Where("plan_id IN (?) OR plan_id IN (?)",
+			database.DB.Model(&database.PlanEnrollmentRule{}).Select("plan_id"),
+			database.DB.Model(&database.PlanEmployee{}).Select("plan_id").Where("source = ?", database.PlanEmployeeSourceRule)).
+		Pluck("plan_id", &planIDs).Error
+	if err != nil {
+		log.Printf("查询需同步的计划失败: %v", err)
+		return
+	}
+
+	for _, planID := range planIDs {
+		result, err := Sync(planID)
+		if err != nil {
+			log.Printf("计划 %d 报名规则同步失败: %v", planID, err)
+			continue
+		}
+		if len(result.ToAdd) > 0 || len(result.ToRemove) > 0 {
+			log.Printf("计划 %d 报名规则同步：加入 %d 人，移除 %d 人", planID, len(result.ToAdd), len(result.ToRemove))
+		}
+	}
+}
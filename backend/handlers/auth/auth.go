@@ -1,14 +1,19 @@
 package auth
 
 import (
+	"backend/accounts"
+	"backend/authtoken"
 	"backend/database"
-	"crypto/rand"
-	"encoding/hex"
+	"backend/enrollment"
+	"backend/loginguard"
+	"backend/password"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // LoginRequest 登录请求结构
@@ -19,24 +24,12 @@ type LoginRequest struct {
 
 // RegisterRequest 注册请求结构
 type RegisterRequest struct {
-	Username string `json:"username" binding:"required,min=3,max=20"`
-	Password string `json:"password" binding:"required,min=6,max=20"`
-	Name     string `json:"name" binding:"required,min=2,max=20"`
-	Role     string `json:"role" binding:"required,oneof=employee teacher planner"`
-}
-
-// 角色映射：英文 -> 中文
-var roleMap = map[string]string{
-	"employee": "员工",
-	"teacher":  "讲师",
-	"planner":  "课程大纲制定者",
-}
-
-// 角色映射：中文 -> 英文
-var reverseRoleMap = map[string]string{
-	"员工":       "employee",
-	"讲师":       "teacher",
-	"课程大纲制定者": "planner",
+	Username   string `json:"username" binding:"required,min=3,max=20"`
+	Password   string `json:"password" binding:"required"` // 长度和强度按密码规则（PASSWORD_* 配置）检查
+	Name       string `json:"name" binding:"required,min=2,max=20"`
+	Role       string `json:"role" binding:"omitempty,oneof=employee teacher planner"` // 不使用邀请码时必填（课程大纲制定者只能凭邀请码注册）；使用邀请码时忽略，角色由邀请码决定
+	Email      string `json:"email" binding:"omitempty,email,max=100"`                 // 选填，用于找回密码；使用指定了邮箱的邀请码时默认为该邮箱
+	InviteCode string `json:"inviteCode"`                                              // 邀请码，REGISTRATION_MODE=invite 时必填
 }
 
 // Login 用户登录
@@ -47,16 +40,35 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	// 用户名或 IP 被锁定、或距上次失败未到等待时间时，不验证密码
+	now := time.Now()
+	if !checkLoginAllowed(c, req.Username, now) {
+		return
+	}
+
 	// 1. 查询账号
 	var account database.Account
 	if err := database.DB.Where("login_name = ?", req.Username).First(&account).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "用户名或密码错误", "data": nil})
+		// 同样比较一次密码，响应时间不暴露用户名是否存在
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(req.Password))
+		loginFailed(c, req.Username, nil, database.LoginFailureUnknownUser, now)
 		return
 	}
 
 	// 2. 验证密码（bcrypt）
 	if err := bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(req.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "用户名或密码错误", "data": nil})
+		loginFailed(c, req.Username, &account.PersonID, database.LoginFailureWrongPassword, now)
+		return
+	}
+	if err := loginguard.Success(req.Username, c.ClientIP()); err != nil {
+		log.Printf("清除登录失败次数失败: %v", err)
+	}
+	// 停用的账号密码正确也不能登录，不计入失败次数
+	if account.DeactivatedAt != nil {
+		if err := loginguard.Audit(req.Username, &account.PersonID, c.ClientIP(), c.Request.UserAgent(), database.LoginFailureDeactivated, now); err != nil {
+			log.Printf("记录失败登录失败: %v", err)
+		}
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": "账号已停用，请联系课程大纲制定者", "data": nil})
 		return
 	}
 
@@ -67,37 +79,36 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	// 4. 创建会话
-	sessionID := generateSessionID()
-	session := database.Session{
-		SessionID: sessionID,
-		PersonID:  person.PersonID,
-		Role:      person.Role,
-		ExpiresAt: time.Now().Add(24 * time.Hour), // 24小时过期
-	}
-	if err := database.DB.Create(&session).Error; err != nil {
+	// 4. 创建会话（刷新令牌）并签发访问令牌
+	refreshToken, session, err := authtoken.CreateSession(person.PersonID, person.Role, c.ClientIP(), c.Request.UserAgent(), now)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建会话失败", "data": nil})
 		return
 	}
+	data, err := tokenResponse(session, refreshToken, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "签发访问令牌失败", "data": nil})
+		return
+	}
 
 	// 5. 返回结果
+	data["user"] = gin.H{
+		"id":          person.PersonID,
+		"name":        person.Name,
+		"role":        accounts.RoleCode(person.Role),
+		"roleDisplay": person.Role,
+		"accountId":   account.AccountID,
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "登录成功",
-		"data": gin.H{
-			"token": sessionID, // 前端存储为Session-ID
-			"user": gin.H{
-				"id":          person.PersonID,
-				"name":        person.Name,
-				"role":        reverseRoleMap[person.Role],
-				"roleDisplay": person.Role,
-				"accountId":   account.AccountID,
-			},
-		},
+		"data":    data,
 	})
 }
 
 // Register 用户注册
+// 按 REGISTRATION_MODE 决定是否开放注册：invite 时必须使用邀请码；open 时可自助注册员工和讲师；closed 时不开放注册
+// 使用邀请码注册时，角色由邀请码决定
 func Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -105,69 +116,100 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	// 1. 检查用户名是否已存在
-	var count int64
-	database.DB.Model(&database.Account{}).Where("login_name = ?", req.Username).Count(&count)
-	if count > 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "用户名已被使用", "data": nil})
+	// 1. 检查注册方式
+	mode := accounts.RegistrationMode()
+	if mode == accounts.RegistrationClosed {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": "系统未开放注册，请联系课程大纲制定者创建账号", "data": nil})
 		return
 	}
-
-	// 2. 哈希密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "密码加密失败", "data": nil})
-		return
+	if req.InviteCode == "" {
+		if mode == accounts.RegistrationInvite {
+			c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": "需要邀请码才能注册", "data": nil})
+			return
+		}
+		if req.Role == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请选择角色", "data": nil})
+			return
+		}
+		if req.Role == accounts.RolePlanner {
+			c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": "课程大纲制定者账号需要邀请码才能注册", "data": nil})
+			return
+		}
 	}
 
-	// 3. 创建人员记录
-	person := database.Person{
-		Name: req.Name,
-		Role: roleMap[req.Role],
-	}
-	if err := database.DB.Create(&person).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建用户信息失败", "data": nil})
+	if msg := password.CurrentPolicy().Check(req.Password); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": msg, "data": nil})
 		return
 	}
 
-	// 4. 创建账号记录
-	account := database.Account{
-		PersonID:     person.PersonID,
-		LoginName:    req.Username,
-		PasswordHash: string(hashedPassword),
-	}
-	if err := database.DB.Create(&account).Error; err != nil {
-		// 回滚：删除已创建的 person
-		database.DB.Delete(&person)
+	// 2. 使用邀请码并创建人员和账号记录，任一步失败时全部回滚（邀请码不计使用次数）
+	var account *database.Account
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		newAccount := accounts.NewAccount{
+			Username: req.Username,
+			Password: req.Password,
+			Name:     req.Name,
+			Role:     req.Role,
+			Email:    req.Email,
+		}
+		if req.InviteCode != "" {
+			inv, err := accounts.RedeemInvitation(tx, req.InviteCode, time.Now())
+			if err != nil {
+				return err
+			}
+			newAccount.Role = accounts.RoleCode(inv.Role)
+			newAccount.InvitationID = &inv.InvitationID
+			if newAccount.Email == "" {
+				newAccount.Email = inv.Email
+			}
+		}
+		var err error
+		account, err = accounts.Create(tx, newAccount)
+		return err
+	})
+	switch err {
+	case nil:
+	case accounts.ErrUsernameTaken, accounts.ErrInvitationInvalid, accounts.ErrInvitationRevoked,
+		accounts.ErrInvitationExpired, accounts.ErrInvitationUsedUp:
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error(), "data": nil})
+		return
+	default:
 		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建账号失败", "data": nil})
 		return
 	}
 
-	// 5. 返回结果
+	// 新员工可能匹配计划的自动报名规则
+	enrollment.Notify()
+
+	// 3. 返回结果
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "注册成功",
 		"data": gin.H{
-			"personId":    person.PersonID,
+			"personId":    account.PersonID,
 			"accountId":   account.AccountID,
 			"username":    account.LoginName,
-			"name":        person.Name,
-			"role":        req.Role,
-			"roleDisplay": person.Role,
+			"email":       account.Email,
+			"name":        account.Person.Name,
+			"role":        accounts.RoleCode(account.Person.Role),
+			"roleDisplay": account.Person.Role,
 		},
 	})
 }
 
-// Logout 退出登录
+// Logout 退出登录：删除访问令牌所属的会话，刷新令牌随即失效
 func Logout(c *gin.Context) {
-	sessionID := c.GetHeader("Session-ID")
+	sessionID := c.GetString("sessionId")
 	if sessionID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "未登录或登录已过期", "data": nil})
 		return
 	}
 
 	// 删除会话
-	database.DB.Where("session_id = ?", sessionID).Delete(&database.Session{})
+	if err := authtoken.RevokeSession(sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "退出登录失败", "data": nil})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "退出成功", "data": nil})
 }
@@ -175,7 +217,7 @@ func Logout(c *gin.Context) {
 // GetCurrentUser 获取当前用户信息
 func GetCurrentUser(c *gin.Context) {
 	// 从中间件设置的上下文获取用户信息
-	personID, exists := c.Get("person_id")
+	personID, exists := c.Get("personId")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "未登录或登录已过期", "data": nil})
 		return
@@ -201,10 +243,11 @@ func GetCurrentUser(c *gin.Context) {
 		"data": gin.H{
 			"personId":    person.PersonID,
 			"name":        person.Name,
-			"role":        reverseRoleMap[person.Role],
+			"role":        accounts.RoleCode(person.Role),
 			"roleDisplay": person.Role,
 			"accountId":   account.AccountID,
 			"username":    account.LoginName,
+			"email":       account.Email,
 			"statistics":  statistics,
 		},
 	})
@@ -247,7 +290,7 @@ func getEmployeeStatistics(personID int64) gin.H {
 
 	// 平均得分（加权计算）
 	database.DB.Model(&database.AttendanceEvaluation{}).
-		Select("AVG(COALESCE(self_score, 0) * (1 - COALESCE(score_ratio, 0.5)) + COALESCE(teacher_score, 0) * COALESCE(score_ratio, 0.5))").
+		Select("COALESCE(AVG(COALESCE(self_score, 0) * (1 - COALESCE(score_ratio, 0.5)) + COALESCE(teacher_score, 0) * COALESCE(score_ratio, 0.5)), 0)").
 		Where("person_id = ?", personID).
 		Scan(&avgScore)
 
@@ -285,7 +328,7 @@ func getTeacherStatistics(personID int64) gin.H {
 
 	// 平均教学评分（学员给的分数）
 	database.DB.Table("attendance_evaluation").
-		Select("AVG(COALESCE(teacher_score, 0))").
+		Select("COALESCE(AVG(COALESCE(teacher_score, 0)), 0)").
 		Joins("JOIN plan_course_item ON attendance_evaluation.item_id = plan_course_item.item_id").
 		Joins("JOIN course ON plan_course_item.course_id = course.course_id").
 		Where("course.teacher_id = ? AND teacher_score IS NOT NULL", personID).
@@ -323,7 +366,7 @@ func getPlannerStatistics(personID int64) gin.H {
 
 	// 所有培训计划的平均得分
 	database.DB.Table("attendance_evaluation").
-		Select("AVG(COALESCE(self_score, 0) * (1 - COALESCE(score_ratio, 0.5)) + COALESCE(teacher_score, 0) * COALESCE(score_ratio, 0.5))").
+		Select("COALESCE(AVG(COALESCE(self_score, 0) * (1 - COALESCE(score_ratio, 0.5)) + COALESCE(teacher_score, 0) * COALESCE(score_ratio, 0.5)), 0)").
 		Joins("JOIN plan_course_item ON attendance_evaluation.item_id = plan_course_item.item_id").
 		Joins("JOIN training_plan ON plan_course_item.plan_id = training_plan.plan_id").
 		Where("training_plan.creator_id = ?", personID).
@@ -336,10 +379,3 @@ func getPlannerStatistics(personID int64) gin.H {
 		"averagePlanScore":   avgScore,
 	}
 }
-
-// generateSessionID 生成随机会话ID
-func generateSessionID() string {
-	bytes := make([]byte, 32)
-	rand.Read(bytes)
-	return hex.EncodeToString(bytes)
-}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"backend/accounts"
+	"backend/database"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetInvitation 查询邀请码：注册页面打开邀请链接时显示邀请的角色和邮箱
+func GetInvitation(c *gin.Context) {
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请提供邀请码", "data": nil})
+		return
+	}
+	if accounts.RegistrationMode() == accounts.RegistrationClosed {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": "系统未开放注册，请联系课程大纲制定者创建账号", "data": nil})
+		return
+	}
+
+	inv, err := accounts.FindInvitation(database.DB, code, time.Now())
+	switch err {
+	case nil:
+	case accounts.ErrInvitationInvalid, accounts.ErrInvitationRevoked, accounts.ErrInvitationExpired, accounts.ErrInvitationUsedUp:
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error(), "data": nil})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询邀请码失败", "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "邀请码有效",
+		"data": gin.H{
+			"role":          accounts.RoleCode(inv.Role),
+			"roleDisplay":   inv.Role,
+			"email":         inv.Email,
+			"expiresAt":     inv.ExpiresAt.Format("2006-01-02 15:04:05"),
+			"remainingUses": inv.MaxUses - inv.UsedCount,
+		},
+	})
+}
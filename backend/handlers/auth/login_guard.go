@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"backend/database"
+	"backend/loginguard"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyPasswordHash 用户名不存在时用来比较密码的哈希，使响应时间与密码错误时相同
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+// checkLoginAllowed 占用一次登录尝试，不允许时记录审计并返回 429
+// 允许时之后必须调用 loginFailed 或 loginguard.Success 结束本次尝试
+func checkLoginAllowed(c *gin.Context, username string, now time.Time) bool {
+	decision, err := loginguard.Reserve(username, c.ClientIP(), now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询登录失败记录失败", "data": nil})
+		return false
+	}
+	if decision.Allowed {
+		return true
+	}
+
+	var personID *int64
+	var account database.Account
+	if database.DB.Where("login_name = ?", username).First(&account).Error == nil {
+		personID = &account.PersonID
+	}
+	if err := loginguard.Audit(username, personID, c.ClientIP(), c.Request.UserAgent(), decision.Reason, now); err != nil {
+		log.Printf("记录失败登录失败: %v", err)
+	}
+	retryAfter := int64((decision.RetryAfter + time.Second - 1) / time.Second)
+	c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"code":    429,
+		"message": decision.Message,
+		"data": gin.H{
+			"reason":     decision.Reason,
+			"retryAfter": retryAfter, // 需要等待的秒数
+		},
+	})
+	return false
+}
+
+// loginFailed 记录一次失败登录（计数和审计），返回 401；本次失败导致锁定时在提示中说明
+// 用户名不存在和密码错误返回相同的提示，也同样计数，避免借此探测账号
+func loginFailed(c *gin.Context, username string, personID *int64, reason string, now time.Time) {
+	if err := loginguard.Audit(username, personID, c.ClientIP(), c.Request.UserAgent(), reason, now); err != nil {
+		log.Printf("记录失败登录失败: %v", err)
+	}
+	lockedUntil, err := loginguard.Failure(username, c.ClientIP(), now)
+	if err != nil {
+		log.Printf("记录登录失败次数失败: %v", err)
+	}
+
+	message := "用户名或密码错误"
+	if !lockedUntil.IsZero() {
+		minutes := int((lockedUntil.Sub(now) + time.Minute - 1) / time.Minute)
+		message += "，连续失败次数过多，账号已被临时锁定" + strconv.Itoa(minutes) + "分钟"
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": message, "data": nil})
+}
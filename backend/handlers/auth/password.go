@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"backend/authtoken"
+	"backend/database"
+	"backend/password"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChangePasswordRequest 修改密码请求结构
+type ChangePasswordRequest struct {
+	OldPassword string `json:"oldPassword" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required"`
+}
+
+// UpdateEmailRequest 绑定邮箱请求结构
+type UpdateEmailRequest struct {
+	Email    string `json:"email" binding:"required,email,max=100"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ChangePassword 修改当前用户的密码，需要验证原密码；修改后其他设备上的会话全部注销
+func ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误：" + err.Error(), "data": nil})
+		return
+	}
+
+	personID := c.GetInt64("personId")
+	var account database.Account
+	if err := database.DB.Where("person_id = ?", personID).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "账号不存在", "data": nil})
+		return
+	}
+	if !password.Matches(account.PasswordHash, req.OldPassword) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "原密码错误", "data": nil})
+		return
+	}
+	if req.NewPassword == req.OldPassword {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "新密码不能与原密码相同", "data": nil})
+		return
+	}
+	if msg := password.CurrentPolicy().Check(req.NewPassword); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": msg, "data": nil})
+		return
+	}
+
+	hashed, err := password.Hash(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "密码加密失败", "data": nil})
+		return
+	}
+	if err := database.DB.Model(&database.Account{}).Where("account_id = ?", account.AccountID).Update("password_hash", hashed).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "修改密码失败", "data": nil})
+		return
+	}
+
+	// 保留当前会话，其他设备需要用新密码重新登录
+	revoked, err := authtoken.RevokePersonSessions(personID, c.GetString("sessionId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "密码已修改，但注销其他会话失败", "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "密码修改成功",
+		"data": gin.H{
+			"revokedCount": revoked,
+		},
+	})
+}
+
+// UpdateEmail 绑定或修改找回密码用的邮箱，需要验证密码
+func UpdateEmail(c *gin.Context) {
+	var req UpdateEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误：" + err.Error(), "data": nil})
+		return
+	}
+
+	var account database.Account
+	if err := database.DB.Where("person_id = ?", c.GetInt64("personId")).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "账号不存在", "data": nil})
+		return
+	}
+	if !password.Matches(account.PasswordHash, req.Password) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "密码错误", "data": nil})
+		return
+	}
+
+	if err := database.DB.Model(&database.Account{}).Where("account_id = ?", account.AccountID).Update("email", req.Email).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "绑定邮箱失败", "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "邮箱绑定成功",
+		"data": gin.H{
+			"email": req.Email,
+		},
+	})
+}
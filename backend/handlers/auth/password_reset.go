@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"backend/authtoken"
+	"backend/config"
+	"backend/database"
+	"backend/loginguard"
+	"backend/notify"
+	"backend/password"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ForgotPasswordRequest 申请重置密码请求结构
+type ForgotPasswordRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+// ResetPasswordRequest 重置密码请求结构
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required"`
+}
+
+// forgotPasswordMessage 无论账号是否存在、是否绑定邮箱都返回相同的提示，避免借此探测账号
+const forgotPasswordMessage = "如果账号存在且已绑定邮箱，重置链接已发送到该邮箱"
+
+// resetMails 后台发送中的重置密码邮件，退出前等待发送完成
+var resetMails sync.WaitGroup
+
+// WaitResetMails 等待后台发送中的重置密码邮件（退出前调用）
+func WaitResetMails() {
+	resetMails.Wait()
+}
+
+// ForgotPassword 申请重置密码：生成一次性重置令牌，通过通知发送方把重置链接发到账号绑定的邮箱
+// 同一 IP 的申请次数有上限；查询账号、生成令牌和发送邮件都在后台完成，
+// 响应前的处理与账号是否存在无关，响应内容和时间都不会暴露账号是否存在
+func ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误：" + err.Error(), "data": nil})
+		return
+	}
+
+	now := time.Now()
+	ip := c.ClientIP()
+	decision, err := loginguard.ReserveReset(ip, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询申请记录失败", "data": nil})
+		return
+	}
+	if !decision.Allowed {
+		retryAfter := int64((decision.RetryAfter + time.Second - 1) / time.Second)
+		c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"code":    429,
+			"message": decision.Message,
+			"data": gin.H{
+				"reason":     decision.Reason,
+				"retryAfter": retryAfter, // 需要等待的秒数
+			},
+		})
+		return
+	}
+
+	resetMails.Add(1)
+	go func() {
+		defer resetMails.Done()
+		sendResetLink(req.Username, ip, now)
+	}()
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": forgotPasswordMessage, "data": nil})
+}
+
+// sendResetLink 账号存在、已绑定邮箱且未停用时生成重置令牌并发送重置链接，否则不做任何事
+// 账号在统计窗口内发送的链接数达到上限时不再发送，之前发送的最新链接仍然有效
+func sendResetLink(username, ip string, now time.Time) {
+	var account database.Account
+	err := database.DB.Preload("Person").Where("login_name = ?", username).First(&account).Error
+	if err != nil || account.Email == nil || *account.Email == "" || account.DeactivatedAt != nil {
+		return
+	}
+
+	decision, err := loginguard.ReserveResetLink(account.LoginName, now)
+	if err != nil {
+		log.Printf("查询重置链接发送次数失败（账号 %d）: %v", account.AccountID, err)
+		return
+	}
+	if !decision.Allowed {
+		log.Printf("账号 %d 发送重置链接过于频繁，本次未发送，已发送的最新链接仍然有效（IP %s）", account.AccountID, ip)
+		return
+	}
+
+	token, expiresAt, err := password.CreateResetToken(account.AccountID, ip, now)
+	if err != nil {
+		log.Printf("生成重置令牌失败（账号 %d）: %v", account.AccountID, err)
+		releaseResetLink(account)
+		return
+	}
+
+	msg := notify.Message{
+		To:      *account.Email,
+		Subject: "重置密码",
+		Body: account.Person.Name + "，您好：\n\n" +
+			"我们收到了重置账号 " + account.LoginName + " 密码的申请，请在 " + expiresAt.Format("2006-01-02 15:04") +
+			" 前打开以下链接设置新密码（" + strconv.Itoa(config.AppConfig.PasswordResetMinutes) + " 分钟内有效，只能使用一次）：\n\n" +
+			config.AppConfig.PasswordResetURL + url.QueryEscape(token) + "\n\n" +
+			"如果不是您本人的操作，请忽略本邮件，原密码仍然有效。\n",
+	}
+	if err := notify.Send(msg); err != nil {
+		log.Printf("发送重置密码邮件失败（账号 %d）: %v", account.AccountID, err)
+		releaseResetLink(account)
+	}
+}
+
+// releaseResetLink 未能发送重置链接时撤销占用的发送次数，发送失败不计入上限
+func releaseResetLink(account database.Account) {
+	if err := loginguard.ReleaseResetLink(account.LoginName); err != nil {
+		log.Printf("撤销重置链接发送次数失败（账号 %d）: %v", account.AccountID, err)
+	}
+}
+
+// ResetPassword 用重置令牌设置新密码，令牌只能使用一次；重置后该账号的全部会话注销
+func ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误：" + err.Error(), "data": nil})
+		return
+	}
+	if msg := password.CurrentPolicy().Check(req.NewPassword); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": msg, "data": nil})
+		return
+	}
+	hashed, err := password.Hash(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "密码加密失败", "data": nil})
+		return
+	}
+
+	// 令牌作废和密码修改在同一事务中完成
+	var account database.Account
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		record, err := password.ConsumeResetToken(tx, req.Token, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("account_id = ?", record.AccountID).First(&account).Error; err != nil {
+			return err
+		}
+		return tx.Model(&database.Account{}).Where("account_id = ?", account.AccountID).Update("password_hash", hashed).Error
+	})
+	if err == password.ErrResetInvalid || err == password.ErrResetExpired {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error(), "data": nil})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "重置密码失败", "data": nil})
+		return
+	}
+
+	// 原有会话全部注销，包括可能被他人盗用的会话
+	revoked, err := authtoken.RevokePersonSessions(account.PersonID, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "密码已重置，但注销原有会话失败", "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "密码已重置，请使用新密码登录",
+		"data": gin.H{
+			"username":     account.LoginName,
+			"revokedCount": revoked,
+		},
+	})
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"backend/authtoken"
+	"backend/database"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RefreshRequest 刷新访问令牌请求结构
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// tokenResponse 为会话签发访问令牌，返回登录和刷新接口共用的令牌数据
+func tokenResponse(session *database.Session, refreshToken string, now time.Time) (gin.H, error) {
+	token, expiresAt, err := authtoken.Issue(session.PersonID, session.Role, session.SessionID, now)
+	if err != nil {
+		return nil, err
+	}
+	return gin.H{
+		"token":            token, // 访问令牌，放在 Authorization: Bearer 请求头（兼容 Session-ID 请求头）
+		"expiresIn":        int64(authtoken.Lifetime() / time.Second),
+		"expiresAt":        expiresAt.Format("2006-01-02 15:04:05"),
+		"refreshToken":     refreshToken,
+		"refreshExpiresAt": session.ExpiresAt.Format("2006-01-02 15:04:05"),
+	}, nil
+}
+
+// Refresh 用刷新令牌换取新的访问令牌和新的刷新令牌，角色按当前用户信息重新读取
+func Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误：" + err.Error(), "data": nil})
+		return
+	}
+
+	now := time.Now()
+	session, err := authtoken.FindSession(req.RefreshToken, now)
+	if err == authtoken.ErrRefreshReused {
+		refreshReused(c)
+		return
+	}
+	if err == authtoken.ErrRefreshInvalid || err == authtoken.ErrRefreshExpired {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": err.Error(), "data": nil})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询会话失败", "data": nil})
+		return
+	}
+
+	// 用户已删除时会话作废
+	var person database.Person
+	if err := database.DB.Where("person_id = ?", session.PersonID).First(&person).Error; err != nil {
+		authtoken.RevokeSession(session.SessionID)
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "用户不存在，请重新登录", "data": nil})
+		return
+	}
+	// 账号停用后不再续期
+	var account database.Account
+	if err := database.DB.Where("person_id = ?", session.PersonID).First(&account).Error; err == nil && account.DeactivatedAt != nil {
+		authtoken.RevokeSession(session.SessionID)
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "账号已停用", "data": nil})
+		return
+	}
+	if person.Role != session.Role {
+		session.Role = person.Role
+		database.DB.Model(&database.Session{}).Where("session_id = ?", session.SessionID).Update("role", person.Role)
+	}
+
+	// 更换刷新令牌（旧令牌随即失效），记录最近活跃时间，开启滑动过期时顺延会话过期时间
+	refreshToken, err := authtoken.RenewSession(session, req.RefreshToken, now)
+	if err == authtoken.ErrRefreshReused {
+		refreshReused(c)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "更新会话失败", "data": nil})
+		return
+	}
+
+	data, err := tokenResponse(session, refreshToken, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "签发访问令牌失败", "data": nil})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "刷新成功",
+		"data":    data,
+	})
+}
+
+// refreshReused 已换下的刷新令牌被再次使用：会话已注销，记录日志并返回 401
+func refreshReused(c *gin.Context) {
+	log.Printf("已换下的刷新令牌被再次使用，所属会话已注销（IP %s）", c.ClientIP())
+	c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": authtoken.ErrRefreshReused.Error(), "data": nil})
+}
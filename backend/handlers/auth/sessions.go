@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"backend/authtoken"
+	"backend/database"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListSessions 获取当前用户未过期的登录会话，按最近活跃时间倒序
+func ListSessions(c *gin.Context) {
+	personID := c.GetInt64("personId")
+	currentID := c.GetString("sessionId")
+
+	var sessions []database.Session
+	err := database.DB.Where("person_id = ? AND expires_at > ?", personID, time.Now()).
+		Order("last_seen_at DESC, created_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询会话失败", "data": nil})
+		return
+	}
+
+	list := make([]gin.H, 0, len(sessions))
+	for _, session := range sessions {
+		lastSeenAt := ""
+		if session.LastSeenAt != nil {
+			lastSeenAt = session.LastSeenAt.Format("2006-01-02 15:04:05")
+		}
+		list = append(list, gin.H{
+			"sessionId":  session.SessionID,
+			"createdAt":  session.CreatedAt.Format("2006-01-02 15:04:05"),
+			"lastSeenAt": lastSeenAt,
+			"expiresAt":  session.ExpiresAt.Format("2006-01-02 15:04:05"),
+			"ipAddress":  session.IPAddress,
+			"userAgent":  session.UserAgent,
+			"current":    session.SessionID == currentID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"total":    len(list),
+			"sessions": list,
+		},
+	})
+}
+
+// RevokeSession 注销当前用户的某个会话，该会话的刷新令牌和访问令牌随即失效
+func RevokeSession(c *gin.Context) {
+	var session database.Session
+	err := database.DB.Where("session_id = ? AND person_id = ?", c.Param("sessionId"), c.GetInt64("personId")).First(&session).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "会话不存在", "data": nil})
+		return
+	}
+
+	if err := authtoken.RevokeSession(session.SessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "注销会话失败", "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "注销成功",
+		"data": gin.H{
+			"sessionId": session.SessionID,
+			"current":   session.SessionID == c.GetString("sessionId"),
+		},
+	})
+}
+
+// RevokeAllSessions 注销当前用户的全部会话，exceptCurrent=true 时保留当前会话（即"退出其他设备"）
+func RevokeAllSessions(c *gin.Context) {
+	exceptID := ""
+	if c.Query("exceptCurrent") == "true" {
+		exceptID = c.GetString("sessionId")
+	}
+
+	revoked, err := authtoken.RevokePersonSessions(c.GetInt64("personId"), exceptID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "注销会话失败", "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "注销成功",
+		"data": gin.H{
+			"revokedCount": revoked,
+		},
+	})
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"testing"
+)
+
+func TestGetStatisticsByRole(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", dbtest.Date(-3), dbtest.Date(2), dbtest.EmployeeID, dbtest.Employee2ID)
+	dbtest.CreatePlan(t, "船员培训", dbtest.Date(5), dbtest.Date(9), dbtest.EmployeeID)
+	course := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	first := dbtest.CreateItem(t, plan, course, dbtest.Date(-2), "09:00:00", "10:00:00", "Asia/Shanghai")
+	second := dbtest.CreateItem(t, plan, course, dbtest.Date(-1), "09:00:00", "10:00:00", "Asia/Shanghai")
+
+	// 80*0.4+90*0.6=86，70*0.5+0*0.5=35，60*0.5+80*0.5=70
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: first.ItemID, SelfScore: 80, TeacherScore: 90, ScoreRatio: 0.6,
+	})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: second.ItemID, SelfScore: 70, ScoreRatio: 0.5,
+	})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.Employee2ID, ItemID: first.ItemID, SelfScore: 60, TeacherScore: 80, ScoreRatio: 0.5,
+	})
+
+	tests := []struct {
+		role     string
+		personID int64
+		want     map[string]float64
+	}{
+		{"员工", dbtest.EmployeeID, map[string]float64{
+			"trainingPlanCount": 2, "totalCourseCount": 2, "completedCourseCount": 2, "averageScore": 60.5,
+		}},
+		{"讲师", dbtest.TeacherID, map[string]float64{
+			"courseCount": 1, "classCount": 2, "studentCount": 2, "averageTeachingScore": 170.0 / 3,
+		}},
+		{"课程大纲制定者", dbtest.PlannerID, map[string]float64{
+			"planCount": 2, "totalCourseCount": 2, "totalStudentCount": 3, "averagePlanScore": 191.0 / 3,
+		}},
+	}
+	for _, tt := range tests {
+		stats := getStatisticsByRole(tt.personID, tt.role)
+		for key, want := range tt.want {
+			var got float64
+			switch v := stats[key].(type) {
+			case int64:
+				got = float64(v)
+			case float64:
+				got = v
+			default:
+				t.Errorf("%s 统计项 %s 的类型不正确：%T", tt.role, key, stats[key])
+				continue
+			}
+			if !dbtest.Near(got, want) {
+				t.Errorf("%s 统计项 %s 应为 %v，实际为 %v", tt.role, key, want, got)
+			}
+		}
+	}
+}
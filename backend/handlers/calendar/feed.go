@@ -0,0 +1,179 @@
+package calendar
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/database"
+	"backend/scheduling"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 订阅中包含的日期范围：过去30天到未来180天
+const (
+	feedPastDays   = 30
+	feedFutureDays = 180
+)
+
+// feedScope 按订阅者角色限定课程安排：员工看参加的计划，讲师看自己讲授的课程，大纲制定者看自己制定的计划
+func feedScope(person database.Person) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		switch person.Role {
+		case "员工":
+			return db.Where("plan_id IN (?)",
+				database.DB.Model(&database.PlanEmployee{}).Select("plan_id").Where("person_id = ?", person.PersonID))
+		case "讲师":
+			return db.Where("course_id IN (?)",
+				database.DB.Model(&database.Course{}).Select("course_id").Where("teacher_id = ?", person.PersonID))
+		case "课程大纲制定者":
+			return db.Where("plan_id IN (?)",
+				database.DB.Model(&database.TrainingPlan{}).Select("plan_id").Where("creator_id = ?", person.PersonID))
+		}
+		return db.Where("1 = 0")
+	}
+}
+
+// feedEventTime 上课日期和时间对应的时刻（按课程安排所在时区）
+func feedEventTime(date time.Time, clock, tz string) time.Time {
+	return scheduling.ClassTime(date.Format("2006-01-02"), clock, tz)
+}
+
+// feedEventUID 课程安排对应的事件 UID，修改和取消时保持不变
+func feedEventUID(itemID int64) string {
+	return "course-item-" + strconv.FormatInt(itemID, 10) + "@training-system"
+}
+
+// feedDescription 事件说明：培训计划和讲师
+func feedDescription(planName, teacherName string) string {
+	lines := []string{}
+	if planName != "" {
+		lines = append(lines, "培训计划："+planName)
+	}
+	if teacherName != "" {
+		lines = append(lines, "讲师："+teacherName)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// GetFeed 日历订阅（iCal 格式），无需登录，凭订阅地址中的令牌访问
+// 课程安排修改后事件的 SEQUENCE 增加，删除后输出为已取消（STATUS:CANCELLED）
+func GetFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("feed"), ".ics")
+
+	var feed database.CalendarFeed
+	if token == "" || database.DB.Where("token = ?", token).First(&feed).Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "订阅地址无效或已重置",
+			"data":    nil,
+		})
+		return
+	}
+	var person database.Person
+	if err := database.DB.Where("person_id = ?", feed.PersonID).First(&person).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "订阅地址无效或已重置",
+			"data":    nil,
+		})
+		return
+	}
+
+	now := time.Now()
+	startDate := now.AddDate(0, 0, -feedPastDays).Format("2006-01-02")
+	endDate := now.AddDate(0, 0, feedFutureDays).Format("2006-01-02")
+
+	var items []database.PlanCourseItem
+	err := database.DB.
+		Preload("Course.Teacher").
+		Preload("Plan").
+		Scopes(feedScope(person)).
+		Where(database.DateBetween("class_date", startDate, endDate)).
+		Order("class_date ASC, class_begin_time ASC").
+		Find(&items).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询课程安排失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	var cancellations []database.CourseItemCancellation
+	err = database.DB.
+		Scopes(feedScope(person)).
+		Where(database.DateBetween("class_date", startDate, endDate)).
+		Order("class_date ASC, class_begin_time ASC").
+		Find(&cancellations).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询课程安排失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	events := make([]scheduling.ICalFeedEvent, 0, len(items)+len(cancellations))
+	for _, item := range items {
+		events = append(events, scheduling.ICalFeedEvent{
+			UID:         feedEventUID(item.ItemID),
+			Summary:     item.Course.CourseName,
+			Description: feedDescription(item.Plan.PlanName, item.Course.Teacher.Name),
+			Location:    item.Location,
+			Start:       feedEventTime(item.ClassDate, item.ClassBeginTime, item.Timezone),
+			End:         feedEventTime(item.ClassDate, item.ClassEndTime, item.Timezone),
+			Sequence:    item.Sequence,
+		})
+	}
+
+	// 已删除的课程安排：课程和计划可能已被删除，名称取不到时留空
+	if len(cancellations) > 0 {
+		courseIDs := make([]int64, 0, len(cancellations))
+		planIDs := make([]int64, 0, len(cancellations))
+		for _, cc := range cancellations {
+			courseIDs = append(courseIDs, cc.CourseID)
+			planIDs = append(planIDs, cc.PlanID)
+		}
+		var courses []database.Course
+		database.DB.Preload("Teacher").Where("course_id IN ?", courseIDs).Find(&courses)
+		courseMap := make(map[int64]database.Course, len(courses))
+		for _, course := range courses {
+			courseMap[course.CourseID] = course
+		}
+		var plans []database.TrainingPlan
+		database.DB.Where("plan_id IN ?", planIDs).Find(&plans)
+		planNames := make(map[int64]string, len(plans))
+		for _, plan := range plans {
+			planNames[plan.PlanID] = plan.PlanName
+		}
+
+		for _, cc := range cancellations {
+			course := courseMap[cc.CourseID]
+			summary := course.CourseName
+			if summary == "" {
+				summary = "已取消的课程"
+			}
+			events = append(events, scheduling.ICalFeedEvent{
+				UID:         feedEventUID(cc.ItemID),
+				Summary:     summary,
+				Description: feedDescription(planNames[cc.PlanID], course.Teacher.Name),
+				Location:    cc.Location,
+				Start:       feedEventTime(cc.ClassDate, cc.ClassBeginTime, cc.Timezone),
+				End:         feedEventTime(cc.ClassDate, cc.ClassEndTime, cc.Timezone),
+				Sequence:    cc.Sequence,
+				Cancelled:   true,
+			})
+		}
+	}
+
+	body := scheduling.WriteICalFeed(person.Name+"的培训课表", events, now)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Content-Disposition", `inline; filename="schedule.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(body))
+}
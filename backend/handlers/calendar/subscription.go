@@ -0,0 +1,75 @@
+package calendar
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"backend/database"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// generateFeedToken 生成随机订阅令牌
+func generateFeedToken() string {
+	bytes := make([]byte, 32)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
+
+// feedURL 根据请求地址拼出完整的订阅地址
+func feedURL(c *gin.Context, token string) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + c.Request.Host + "/api/calendar/" + token + ".ics"
+}
+
+// formatSubscription 格式化订阅信息
+func formatSubscription(c *gin.Context, feed database.CalendarFeed) gin.H {
+	return gin.H{
+		"token":     feed.Token,
+		"url":       feedURL(c, feed.Token),
+		"createdAt": feed.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// GetSubscription 获取当前用户的日历订阅地址，首次获取时生成
+func GetSubscription(c *gin.Context) {
+	personID, exists := c.Get("personId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "未登录",
+			"data":    nil,
+		})
+		return
+	}
+
+	var feed database.CalendarFeed
+	err := database.DB.Where("person_id = ?", personID.(int64)).First(&feed).Error
+	if err == gorm.ErrRecordNotFound {
+		feed = database.CalendarFeed{PersonID: personID.(int64), Token: generateFeedToken(), CreatedAt: time.Now()}
+		err = database.DB.Create(&feed).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取日历订阅失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    formatSubscription(c, feed),
+	})
+}
@@ -0,0 +1,39 @@
+package calendar
+
+import (
+	"net/http"
+	"time"
+
+	"backend/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RotateSubscription 重置当前用户的日历订阅地址，旧地址立即失效
+func RotateSubscription(c *gin.Context) {
+	personID, exists := c.Get("personId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "未登录",
+			"data":    nil,
+		})
+		return
+	}
+
+	feed := database.CalendarFeed{PersonID: personID.(int64), Token: generateFeedToken(), CreatedAt: time.Now()}
+	if err := database.DB.Save(&feed).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "重置日历订阅失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "重置成功，旧的订阅地址已失效",
+		"data":    formatSubscription(c, feed),
+	})
+}
@@ -4,7 +4,8 @@ import (
 	"net/http"
 	"time"
 	"backend/database"
-	"backend/utils"
+	"backend/scheduling"
+	"backend/scoring"
 
 	"github.com/gin-gonic/gin"
 
@@ -20,6 +21,7 @@ type PendingEvaluationResponse struct {
 	ClassDate      string `json:"classDate"`
 	ClassBeginTime string `json:"classBeginTime"`
 	ClassEndTime   string `json:"classEndTime"`
+	Timezone       string `json:"timezone"`
 	Location       string `json:"location"`
 	PlanID         int    `json:"planId"`
 	PlanName       string `json:"planName"`
@@ -50,12 +52,10 @@ func GetPendingEvaluations(c *gin.Context) {
 	
 	userID := personID.(int64)
 
-	var courses []PendingEvaluationResponse
+	var candidates []PendingEvaluationResponse
 
-	// 查询已完成但未自评的课程
+	// 查询已完成但未自评的课程：先按日期粗筛，再按课程所在时区判断是否已结束
 	now := time.Now()
-	today := now.Format("2006-01-02")
-	currentTime := now.Format("15:04:05")
 
 	err := database.DB.Table("plan_course_item pci").
 		Select(`
@@ -64,9 +64,10 @@ func GetPendingEvaluations(c *gin.Context) {
 			c.course_name,
 			c.course_desc,
 			c.course_class,
-			pci.class_date,
-			TIME_FORMAT(pci.class_begin_time, '%H:%i:%s') as class_begin_time,
-			TIME_FORMAT(pci.class_end_time, '%H:%i:%s') as class_end_time,
+			` + database.FormatDate("pci.class_date") + ` as class_date,
+			pci.class_begin_time,
+			pci.class_end_time,
+			pci.timezone,
 			pci.location,
 			pci.plan_id,
 			tp.plan_name,
@@ -78,11 +79,10 @@ func GetPendingEvaluations(c *gin.Context) {
 		Joins("JOIN person p ON c.teacher_id = p.person_id").
 		Joins("JOIN plan_employee pe ON tp.plan_id = pe.plan_id AND pe.person_id = ?", userID).
 		Joins("LEFT JOIN attendance_evaluation ae ON pci.item_id = ae.item_id AND ae.person_id = ?", userID).
-		Where("(pci.class_date < ? OR (pci.class_date = ? AND pci.class_end_time < ?))", 
-			today, today, currentTime).
+		Where(database.DateUntil("pci.class_date", scheduling.EndedBefore(now))).
 		Where("(ae.self_comment IS NULL OR ae.self_comment = '')").
 		Order("pci.class_date DESC, pci.class_begin_time DESC").
-		Scan(&courses).Error
+		Scan(&candidates).Error
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -93,6 +93,15 @@ func GetPendingEvaluations(c *gin.Context) {
 		return
 	}
 
+	courses := make([]PendingEvaluationResponse, 0, len(candidates))
+	for _, course := range candidates {
+		if !scheduling.ClassEnded(course.ClassDate, course.ClassEndTime, course.Timezone, now) {
+			continue
+		}
+		course.Timezone = scheduling.TimezoneName(course.Timezone)
+		courses = append(courses, course)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "获取成功",
@@ -138,31 +147,8 @@ func SubmitEvaluation(c *gin.Context) {
 		return
 	}
 
-	// 检查课程是否已结束
-	now := time.Now()
-	// 解析时间字符串
-	classEndTime, err := time.Parse("15:04:05", item.ClassEndTime)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "时间格式错误",
-			"data":    nil,
-		})
-		return
-	}
-	
-	classDateTime := time.Date(
-		item.ClassDate.Year(),
-		item.ClassDate.Month(),
-		item.ClassDate.Day(),
-		classEndTime.Hour(),
-		classEndTime.Minute(),
-		classEndTime.Second(),
-		0,
-		time.Local,
-	)
-	
-	if now.Before(classDateTime) {
+	// 检查课程是否已结束（按课程安排所在时区）
+	if !scheduling.ClassEnded(item.ClassDate.Format("2006-01-02"), item.ClassEndTime, item.Timezone, time.Now()) {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
 			"message": "课程尚未结束，无法提交自评",
@@ -173,8 +159,8 @@ func SubmitEvaluation(c *gin.Context) {
 
 	// 检查是否已经自评过
 	var existingEval database.AttendanceEvaluation
-	err = database.DB.Where("item_id = ? AND person_id = ?", req.ItemID, userID).First(&existingEval).Error
-	
+	err := database.DB.Where("item_id = ? AND person_id = ?", req.ItemID, userID).First(&existingEval).Error
+
 	if err == nil && existingEval.SelfComment != "" {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
@@ -195,30 +181,15 @@ func SubmitEvaluation(c *gin.Context) {
 		return
 	}
 
-	// 调用AI生成分数
-	score, aiErr := utils.GenerateEvaluationScore(
-		req.SelfComment,
-		req.Understanding,
-		req.Difficulty,
-		req.Satisfaction,
-		course.CourseName,
-	)
-	
-	if aiErr != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "AI评分失败: " + aiErr.Error(),
-			"data":    nil,
-		})
-		return
-	}
-
-	// 创建或更新自评记录
+	// 创建或更新自评记录（分数由异步评分队列填写）
 	evaluation := database.AttendanceEvaluation{
-		ItemID:      int64(req.ItemID),
-		PersonID:    userID,
-		SelfComment: req.SelfComment,
-		SelfScore:   score,
+		ItemID:            int64(req.ItemID),
+		PersonID:          userID,
+		SelfComment:       req.SelfComment,
+		Understanding:     req.Understanding,
+		Difficulty:        req.Difficulty,
+		Satisfaction:      req.Satisfaction,
+		SelfScoringStatus: database.ScoringStatusPending,
 	}
 
 	// 使用事务确保数据一致性
@@ -229,8 +200,13 @@ func SubmitEvaluation(c *gin.Context) {
 		if err := tx.Model(&database.AttendanceEvaluation{}).
 			Where("person_id = ? AND item_id = ?", userID, req.ItemID).
 			Updates(map[string]interface{}{
-				"self_comment": req.SelfComment,
-				"self_score":   score,
+				"self_comment":         req.SelfComment,
+				"understanding":        req.Understanding,
+				"difficulty":           req.Difficulty,
+				"satisfaction":         req.Satisfaction,
+				"self_score":           0,
+				"self_score_rationale": "",
+				"self_scoring_status":  database.ScoringStatusPending,
 			}).Error; err != nil {
 			tx.Rollback()
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -253,16 +229,31 @@ func SubmitEvaluation(c *gin.Context) {
 		}
 	}
 
+	// 创建AI评分任务
+	job, err := scoring.EnqueueSelfScoring(tx, userID, int64(req.ItemID),
+		req.SelfComment, req.Understanding, req.Difficulty, req.Satisfaction, course.CourseName)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "创建评分任务失败",
+			"data":    nil,
+		})
+		return
+	}
+
 	tx.Commit()
+	scoring.Notify()
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "提交成功",
 		"data": gin.H{
-			"personId":     userID,
-			"itemId":       req.ItemID,
-			"aiScore":      score,
-			"submittedAt":  time.Now(),
+			"personId":      userID,
+			"itemId":        req.ItemID,
+			"scoringStatus": database.ScoringStatusPending,
+			"scoringJobId":  job.JobID,
+			"submittedAt":   time.Now(),
 		},
 	})
 }
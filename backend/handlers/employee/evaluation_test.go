@@ -0,0 +1,70 @@
+package employee
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"testing"
+)
+
+func TestGetPendingEvaluations(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", dbtest.Date(-3), dbtest.Date(2), dbtest.EmployeeID, dbtest.Employee2ID)
+	otherPlan := dbtest.CreatePlan(t, "船员培训", dbtest.Date(-3), dbtest.Date(2), dbtest.Employee2ID)
+	course := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+
+	ended := dbtest.CreateItem(t, plan, course, dbtest.Date(-1), "09:00:00", "10:00:00", "Asia/Shanghai")
+	unrated := dbtest.CreateItem(t, plan, course, dbtest.Date(-3), "14:00:00", "15:30:00", "Asia/Singapore")
+	evaluated := dbtest.CreateItem(t, plan, course, dbtest.Date(-2), "09:00:00", "10:00:00", "Asia/Shanghai")
+	dbtest.CreateItem(t, plan, course, dbtest.Date(2), "09:00:00", "10:00:00", "Asia/Shanghai")
+	dbtest.CreateItem(t, otherPlan, course, dbtest.Date(-1), "09:00:00", "10:00:00", "Asia/Shanghai")
+
+	// 已有评价记录但自评为空的课程仍待自评，已自评的不再出现
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{PersonID: dbtest.EmployeeID, ItemID: unrated.ItemID, ScoreRatio: 0.5})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{PersonID: dbtest.EmployeeID, ItemID: evaluated.ItemID, SelfComment: "学到了很多", SelfScore: 85})
+
+	resp := dbtest.Get(t, GetPendingEvaluations, dbtest.EmployeeID, "员工", "/employee/pending-evaluations")
+	var data struct {
+		Count   int                         `json:"count"`
+		Courses []PendingEvaluationResponse `json:"courses"`
+	}
+	resp.Decode(t, &data)
+
+	if data.Count != 2 || len(data.Courses) != 2 {
+		t.Fatalf("待自评课程应为 2 门，实际 %d 门：%+v", data.Count, data.Courses)
+	}
+	want := []struct {
+		itemID   int64
+		date     string
+		begin    string
+		end      string
+		timezone string
+	}{
+		{ended.ItemID, dbtest.Date(-1), "09:00:00", "10:00:00", "Asia/Shanghai"},
+		{unrated.ItemID, dbtest.Date(-3), "14:00:00", "15:30:00", "Asia/Singapore"},
+	}
+	for i, w := range want {
+		got := data.Courses[i]
+		if int64(got.ItemID) != w.itemID {
+			t.Errorf("第 %d 门课程应为课程安排 %d，实际为 %d", i+1, w.itemID, got.ItemID)
+		}
+		if got.ClassDate != w.date {
+			t.Errorf("classDate 应为 %q（YYYY-MM-DD），实际为 %q", w.date, got.ClassDate)
+		}
+		if got.ClassBeginTime != w.begin || got.ClassEndTime != w.end {
+			t.Errorf("上课时间应为 %s-%s，实际为 %s-%s", w.begin, w.end, got.ClassBeginTime, got.ClassEndTime)
+		}
+		if got.Timezone != w.timezone {
+			t.Errorf("timezone 应为 %q，实际为 %q", w.timezone, got.Timezone)
+		}
+		if got.CourseName != "消防" || got.CourseClass != "安全" || got.PlanName != "安全培训" {
+			t.Errorf("课程或计划信息不正确：%+v", got)
+		}
+		if int64(got.TeacherID) != dbtest.TeacherID || got.TeacherName != "李老师" {
+			t.Errorf("讲师应为李老师（%d），实际为 %s（%d）", dbtest.TeacherID, got.TeacherName, got.TeacherID)
+		}
+		if got.Location != "培训楼A101" {
+			t.Errorf("location 应为培训楼A101，实际为 %q", got.Location)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package employee
 
 import (
 	"backend/database"
+	"backend/scheduling"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -36,10 +38,24 @@ func GetSchedule(c *gin.Context) {
 		return
 	}
 
+	// 查看者时区：课程按该时区的日期和时间展示
+	viewer, ok := scheduling.ViewerTimezone(c.Query("timezone"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无法识别的时区", "data": nil})
+		return
+	}
+
 	// 设置时间范围
 	startTime = time.Date(startTime.Year(), startTime.Month(), startTime.Day(), 0, 0, 0, 0, startTime.Location())
 	endTime = time.Date(endTime.Year(), endTime.Month(), endTime.Day(), 23, 59, 59, 999999999, endTime.Location())
 
+	// 查询工作日历，用于标记非工作日
+	cal, err := scheduling.LoadCalendar(startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询工作日历失败", "data": nil})
+		return
+	}
+
 	// 1. 查询员工参与的培训计划ID
 	var planEmployees []database.PlanEmployee
 	if err := database.DB.Where("person_id = ?", personID).Find(&planEmployees).Error; err != nil {
@@ -54,13 +70,14 @@ func GetSchedule(c *gin.Context) {
 
 	if len(planIDs) == 0 {
 		// 没有参与任何培训计划，返回空日程
-		schedule := buildEmptySchedule(startTime, endTime)
+		schedule := buildEmptySchedule(startTime, endTime, cal)
 		c.JSON(http.StatusOK, gin.H{
 			"code":    200,
 			"message": "获取成功",
 			"data": gin.H{
 				"startDate":    startDate,
 				"endDate":      endDate,
+				"timezone":     viewer.String(),
 				"totalCourses": 0,
 				"courses":      schedule,
 			},
@@ -68,13 +85,14 @@ func GetSchedule(c *gin.Context) {
 		return
 	}
 
-	// 2. 查询日期范围内的课程安排（预加载关联）
+	// 2. 查询日期范围内的课程安排（预加载关联），前后各多查一天，换算时区后可能落入范围
 	var courseItems []database.PlanCourseItem
 	if err := database.DB.
 		Preload("Course.Teacher").
 		Preload("Plan").
 		Where("plan_id IN ?", planIDs).
-		Where("class_date >= ? AND class_date <= ?", startTime, endTime).
+		Where(database.DateBetween("class_date",
+			startTime.AddDate(0, 0, -1).Format("2006-01-02"), endTime.AddDate(0, 0, 1).Format("2006-01-02"))).
 		Order("class_date ASC, class_begin_time ASC").
 		Find(&courseItems).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "查询失败", "data": nil})
@@ -96,19 +114,29 @@ func GetSchedule(c *gin.Context) {
 		}
 	}
 
-	// 4. 按日期组织课程
+	// 4. 按查看者时区的日期组织课程
 	scheduleMap := make(map[string][]map[string]interface{})
+	totalCourses := 0
 	for _, item := range courseItems {
-		dateStr := item.ClassDate.Format("2006-01-02")
-		
+		local := scheduling.ViewerSlot(item.ClassDate.Format("2006-01-02"), item.ClassBeginTime, item.ClassEndTime, item.Timezone, viewer)
+		if local.Date < startDate || local.Date > endDate {
+			continue
+		}
+		startsAt, endsAt := scheduling.ItemInterval(item)
+		dateStr := local.Date
+		totalCourses++
+
 		course := map[string]interface{}{
 			"itemId":         item.ItemID,
 			"courseId":       item.CourseID,
 			"courseName":     item.Course.CourseName,
 			"courseDesc":     item.Course.CourseDesc,
 			"courseClass":    item.Course.CourseClass,
-			"classBeginTime": item.ClassBeginTime,
-			"classEndTime":   item.ClassEndTime,
+			"classBeginTime": local.Begin,
+			"classEndTime":   local.End,
+			"classTimezone":  scheduling.TimezoneName(item.Timezone),
+			"startsAt":       startsAt.Format(time.RFC3339),
+			"endsAt":         endsAt.Format(time.RFC3339),
 			"location":       item.Location,
 			"planId":         item.PlanID,
 			"planName":       item.Plan.PlanName,
@@ -119,6 +147,11 @@ func GetSchedule(c *gin.Context) {
 		
 		scheduleMap[dateStr] = append(scheduleMap[dateStr], course)
 	}
+	for _, courses := range scheduleMap {
+		sort.SliceStable(courses, func(i, j int) bool {
+			return courses[i]["classBeginTime"].(string) < courses[j]["classBeginTime"].(string)
+		})
+	}
 
 	// 5. 构建完整日程（包含没有课程的日期）
 	schedule := []map[string]interface{}{}
@@ -130,9 +163,11 @@ func GetSchedule(c *gin.Context) {
 		}
 
 		schedule = append(schedule, map[string]interface{}{
-			"date":        dateStr,
-			"courseCount": len(courses),
-			"courses":     courses,
+			"date":            dateStr,
+			"courseCount":     len(courses),
+			"courses":         courses,
+			"isWorkingDay":    cal.Resolve(dateStr, "") == nil,
+			"calendarEntries": cal.Marks(dateStr),
 		})
 	}
 
@@ -143,20 +178,24 @@ func GetSchedule(c *gin.Context) {
 		"data": gin.H{
 			"startDate":    startDate,
 			"endDate":      endDate,
-			"totalCourses": len(courseItems),
+			"timezone":     viewer.String(),
+			"totalCourses": totalCourses,
 			"courses":      schedule,
 		},
 	})
 }
 
-// buildEmptySchedule 构建空日程
-func buildEmptySchedule(startTime, endTime time.Time) []map[string]interface{} {
+// buildEmptySchedule 构建空日程（标记工作日历中的非工作日）
+func buildEmptySchedule(startTime, endTime time.Time, cal scheduling.WorkingCalendar) []map[string]interface{} {
 	schedule := []map[string]interface{}{}
 	for d := startTime; !d.After(endTime); d = d.AddDate(0, 0, 1) {
+		dateStr := d.Format("2006-01-02")
 		schedule = append(schedule, map[string]interface{}{
-			"date":        d.Format("2006-01-02"),
-			"courseCount": 0,
-			"courses":     []interface{}{},
+			"date":            dateStr,
+			"courseCount":     0,
+			"courses":         []interface{}{},
+			"isWorkingDay":    cal.Resolve(dateStr, "") == nil,
+			"calendarEntries": cal.Marks(dateStr),
 		})
 	}
 	return schedule
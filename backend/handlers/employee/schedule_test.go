@@ -0,0 +1,91 @@
+package employee
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"testing"
+	"time"
+)
+
+func TestGetSchedule(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", dbtest.Date(-3), dbtest.Date(5), dbtest.EmployeeID)
+	otherPlan := dbtest.CreatePlan(t, "船员培训", dbtest.Date(-3), dbtest.Date(5), dbtest.Employee2ID)
+	course := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+
+	local := dbtest.CreateItem(t, plan, course, dbtest.Date(1), "09:00:00", "10:00:00", "Asia/Shanghai")
+	// UTC 前一天 20:00 即上海当天 04:00，按查看者时区落入范围
+	shifted := dbtest.CreateItem(t, plan, course, dbtest.Date(-1), "20:00:00", "21:00:00", "UTC")
+	// UTC 18:00 即上海次日 02:00，落在范围之外
+	dbtest.CreateItem(t, plan, course, dbtest.Date(2), "18:00:00", "19:00:00", "UTC")
+	dbtest.CreateItem(t, plan, course, dbtest.Date(3), "09:00:00", "10:00:00", "Asia/Shanghai")
+	dbtest.CreateItem(t, otherPlan, course, dbtest.Date(1), "14:00:00", "15:00:00", "Asia/Shanghai")
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{PersonID: dbtest.EmployeeID, ItemID: local.ItemID, SelfScore: 80})
+
+	type scheduled struct {
+		ItemID         int64  `json:"itemId"`
+		ClassBeginTime string `json:"classBeginTime"`
+		ClassEndTime   string `json:"classEndTime"`
+		ClassTimezone  string `json:"classTimezone"`
+		StartsAt       string `json:"startsAt"`
+		PlanName       string `json:"planName"`
+		TeacherName    string `json:"teacherName"`
+		HasEvaluated   bool   `json:"hasEvaluated"`
+	}
+	var data struct {
+		Timezone     string `json:"timezone"`
+		TotalCourses int    `json:"totalCourses"`
+		Courses      []struct {
+			Date        string      `json:"date"`
+			CourseCount int         `json:"courseCount"`
+			Courses     []scheduled `json:"courses"`
+		} `json:"courses"`
+	}
+	target := "/employee/schedule?startDate=" + dbtest.Date(0) + "&endDate=" + dbtest.Date(2) + "&timezone=Asia/Shanghai"
+	dbtest.Get(t, GetSchedule, dbtest.EmployeeID, "员工", target).Decode(t, &data)
+
+	if data.Timezone != "Asia/Shanghai" || data.TotalCourses != 2 || len(data.Courses) != 3 {
+		t.Fatalf("应按 Asia/Shanghai 返回 3 天共 2 节课，实际 %+v", data)
+	}
+	want := []struct {
+		date     string
+		itemID   int64
+		begin    string
+		end      string
+		timezone string
+		startsAt string
+		rated    bool
+	}{
+		{dbtest.Date(0), shifted.ItemID, "04:00:00", "05:00:00", "UTC", dbtest.Date(-1) + "T20:00:00Z", false},
+		{dbtest.Date(1), local.ItemID, "09:00:00", "10:00:00", "Asia/Shanghai", dbtest.Date(1) + "T09:00:00+08:00", true},
+		{dbtest.Date(2), 0, "", "", "", "", false},
+	}
+	for i, w := range want {
+		day := data.Courses[i]
+		if day.Date != w.date {
+			t.Errorf("第 %d 天应为 %s，实际为 %s", i+1, w.date, day.Date)
+		}
+		if w.itemID == 0 {
+			if day.CourseCount != 0 || len(day.Courses) != 0 {
+				t.Errorf("%s 应没有课程，实际 %+v", w.date, day.Courses)
+			}
+			continue
+		}
+		if day.CourseCount != 1 || len(day.Courses) != 1 {
+			t.Fatalf("%s 应有 1 节课，实际 %+v", w.date, day.Courses)
+		}
+		got := day.Courses[0]
+		if got.ItemID != w.itemID || got.ClassBeginTime != w.begin || got.ClassEndTime != w.end || got.ClassTimezone != w.timezone {
+			t.Errorf("%s 应为课程安排 %d（%s-%s，%s），实际 %+v", w.date, w.itemID, w.begin, w.end, w.timezone, got)
+		}
+		startsAt, err := time.Parse(time.RFC3339, got.StartsAt)
+		wantStart, _ := time.Parse(time.RFC3339, w.startsAt)
+		if err != nil || !startsAt.Equal(wantStart) {
+			t.Errorf("课程安排 %d 的 startsAt 应为 %s，实际为 %q", w.itemID, w.startsAt, got.StartsAt)
+		}
+		if got.PlanName != "安全培训" || got.TeacherName != "李老师" || got.HasEvaluated != w.rated {
+			t.Errorf("课程安排 %d 的计划、讲师或自评状态不正确：%+v", w.itemID, got)
+		}
+	}
+}
@@ -24,30 +24,34 @@ func GetScores(c *gin.Context) {
 	// 暂不实现复杂筛选，先实现基本列表
 
 	var results []struct {
-		ItemID         int64   `json:"itemId"`
-		ClassDate      string  `json:"classDate"`
-		ClassBeginTime string  `json:"classBeginTime"`
-		ClassEndTime   string  `json:"classEndTime"`
-		Location       string  `json:"location"`
-		PlanID         int64   `json:"planId"`
-		PlanName       string  `json:"planName"`
-		CourseID       int64   `json:"courseId"`
-		CourseName     string  `json:"courseName"`
-		CourseClass    string  `json:"courseClass"`
-		TeacherName    string  `json:"teacherName"`
-		SelfScore      float64 `json:"selfScore"`
-		TeacherScore   float64 `json:"teacherScore"`
-		ScoreRatio     float64 `json:"scoreRatio"`
-		SelfComment    string  `json:"selfComment"`
-		TeacherComment string  `json:"teacherComment"`
+		ItemID                int64   `json:"itemId"`
+		ClassDate             string  `json:"classDate"`
+		ClassBeginTime        string  `json:"classBeginTime"`
+		ClassEndTime          string  `json:"classEndTime"`
+		Location              string  `json:"location"`
+		PlanID                int64   `json:"planId"`
+		PlanName              string  `json:"planName"`
+		CourseID              int64   `json:"courseId"`
+		CourseName            string  `json:"courseName"`
+		CourseClass           string  `json:"courseClass"`
+		TeacherName           string  `json:"teacherName"`
+		SelfScore             float64 `json:"selfScore"`
+		TeacherScore          float64 `json:"teacherScore"`
+		ScoreRatio            float64 `json:"scoreRatio"`
+		SelfComment           string  `json:"selfComment"`
+		TeacherComment        string  `json:"teacherComment"`
+		SelfScoreRationale    string  `json:"selfScoreRationale"`
+		TeacherScoreRationale string  `json:"teacherScoreRationale"`
+		SelfScoringStatus     string  `json:"selfScoringStatus"`
+		TeacherScoringStatus  string  `json:"teacherScoringStatus"`
 	}
 
 	err := database.DB.Table("attendance_evaluation").
 		Select(`
 			plan_course_item.item_id,
-			DATE_FORMAT(plan_course_item.class_date, '%Y-%m-%d') as class_date,
-			TIME_FORMAT(plan_course_item.class_begin_time, '%H:%i:%s') as class_begin_time,
-			TIME_FORMAT(plan_course_item.class_end_time, '%H:%i:%s') as class_end_time,
+			` + database.FormatDate("plan_course_item.class_date") + ` as class_date,
+			plan_course_item.class_begin_time,
+			plan_course_item.class_end_time,
 			plan_course_item.location,
 			training_plan.plan_id,
 			training_plan.plan_name,
@@ -59,7 +63,11 @@ func GetScores(c *gin.Context) {
 			attendance_evaluation.teacher_score,
 			attendance_evaluation.score_ratio,
 			attendance_evaluation.self_comment,
-			attendance_evaluation.teacher_comment
+			attendance_evaluation.teacher_comment,
+			attendance_evaluation.self_score_rationale,
+			attendance_evaluation.teacher_score_rationale,
+			attendance_evaluation.self_scoring_status,
+			attendance_evaluation.teacher_scoring_status
 		`).
 		Joins("JOIN plan_course_item ON attendance_evaluation.item_id = plan_course_item.item_id").
 		Joins("JOIN course ON plan_course_item.course_id = course.course_id").
@@ -82,28 +90,33 @@ func GetScores(c *gin.Context) {
 
 	for _, r := range results {
 		item := map[string]interface{}{
-			"itemId":         r.ItemID,
-			"classDate":      r.ClassDate,
-			"classBeginTime": r.ClassBeginTime,
-			"classEndTime":   r.ClassEndTime,
-			"location":       r.Location,
-			"planId":         r.PlanID,
-			"planName":       r.PlanName,
-			"courseId":       r.CourseID,
-			"courseName":     r.CourseName,
-			"courseClass":    r.CourseClass,
-			"teacherName":    r.TeacherName,
-			"selfScore":      r.SelfScore,
-			"teacherScore":   r.TeacherScore,
-			"scoreRatio":     r.ScoreRatio,
-			"selfComment":    r.SelfComment,
-			"teacherComment": r.TeacherComment,
-			"hasEvaluated":   true,
+			"itemId":                r.ItemID,
+			"classDate":             r.ClassDate,
+			"classBeginTime":        r.ClassBeginTime,
+			"classEndTime":          r.ClassEndTime,
+			"location":              r.Location,
+			"planId":                r.PlanID,
+			"planName":              r.PlanName,
+			"courseId":              r.CourseID,
+			"courseName":            r.CourseName,
+			"courseClass":           r.CourseClass,
+			"teacherName":           r.TeacherName,
+			"selfScore":             r.SelfScore,
+			"teacherScore":          r.TeacherScore,
+			"scoreRatio":            r.ScoreRatio,
+			"selfComment":           r.SelfComment,
+			"teacherComment":        r.TeacherComment,
+			"selfScoreRationale":    r.SelfScoreRationale,
+			"teacherScoreRationale": r.TeacherScoreRationale,
+			"selfScoringStatus":     r.SelfScoringStatus,
+			"teacherScoringStatus":  r.TeacherScoringStatus,
+			"hasEvaluated":          true,
 		}
 
 		// 计算综合得分（只有当讲师已评分时才计算）
 		var weightedScore float64
-		hasTeacherGraded := r.TeacherScore > 0 || r.TeacherComment != ""
+		hasTeacherGraded := (r.TeacherScore > 0 || r.TeacherComment != "") &&
+			r.SelfScoringStatus != database.ScoringStatusPending && r.TeacherScoringStatus != database.ScoringStatusPending
 		item["hasTeacherScore"] = hasTeacherGraded
 		
 		if hasTeacherGraded {
@@ -264,7 +277,9 @@ func GetLearningProgress(c *gin.Context) {
 	// 这里需要先查出员工参与的所有计划，然后遍历计算（或者复杂SQL）
 	// 简单起见，先查计划
 	database.DB.Table("training_plan").
-		Select("training_plan.plan_id, training_plan.plan_name, training_plan.plan_status, training_plan.plan_start_datetime, training_plan.plan_end_datetime").
+		Select("training_plan.plan_id, training_plan.plan_name, training_plan.plan_status, "+
+			database.FormatDate("training_plan.plan_start_datetime")+" AS start_date, "+
+			database.FormatDate("training_plan.plan_end_datetime")+" AS end_date").
 		Joins("JOIN plan_employee ON training_plan.plan_id = plan_employee.plan_id").
 		Where("plan_employee.person_id = ?", personID).
 		Scan(&plans)
@@ -298,7 +313,7 @@ func GetLearningProgress(c *gin.Context) {
 		// 计算平均分
 		var avgScore float64
 		database.DB.Table("attendance_evaluation").
-			Select("AVG(self_score * (1 - score_ratio) + teacher_score * score_ratio)").
+			Select("COALESCE(AVG(self_score * (1 - score_ratio) + teacher_score * score_ratio), 0)").
 			Joins("JOIN plan_course_item ON attendance_evaluation.item_id = plan_course_item.item_id").
 			Where("attendance_evaluation.person_id = ? AND plan_course_item.plan_id = ? AND attendance_evaluation.teacher_comment != ''", personID, plans[i].PlanID).
 			Scan(&avgScore)
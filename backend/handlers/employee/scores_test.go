@@ -0,0 +1,239 @@
+package employee
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"testing"
+)
+
+func TestGetScores(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", dbtest.Date(-5), dbtest.Date(0), dbtest.EmployeeID, dbtest.Employee2ID)
+	course := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	graded := dbtest.CreateItem(t, plan, course, dbtest.Date(-3), "09:00:00", "10:00:00", "Asia/Shanghai")
+	scoreOnly := dbtest.CreateItem(t, plan, course, dbtest.Date(-2), "09:00:00", "10:00:00", "Asia/Shanghai")
+	selfOnly := dbtest.CreateItem(t, plan, course, dbtest.Date(-1), "14:00:00", "15:00:00", "Asia/Shanghai")
+
+	done := database.ScoringStatusDone
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: graded.ItemID,
+		SelfScore: 80, SelfComment: "学到了很多", TeacherScore: 90, TeacherComment: "认真",
+		ScoreRatio: 0.6, SelfScoringStatus: done, TeacherScoringStatus: done,
+	})
+	// 讲师只打分未写评语也算已评分
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: scoreOnly.ItemID,
+		SelfScore: 70, SelfComment: "还需练习", TeacherScore: 60,
+		ScoreRatio: 0.5, SelfScoringStatus: done, TeacherScoringStatus: done,
+	})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: selfOnly.ItemID,
+		SelfScore: 75, SelfComment: "一般", ScoreRatio: 0.5, SelfScoringStatus: done,
+	})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.Employee2ID, ItemID: graded.ItemID,
+		SelfScore: 50, SelfComment: "其他员工", TeacherScore: 50, TeacherComment: "其他员工", ScoreRatio: 0.5,
+	})
+
+	resp := dbtest.Get(t, GetScores, dbtest.EmployeeID, "员工", "/employee/scores")
+	var data struct {
+		Statistics struct {
+			TotalCourses     int     `json:"totalCourses"`
+			CompletedCourses int     `json:"completedCourses"`
+			AverageScore     float64 `json:"averageScore"`
+			MaxScore         float64 `json:"maxScore"`
+			MinScore         float64 `json:"minScore"`
+		} `json:"statistics"`
+		Scores []struct {
+			ItemID          int64    `json:"itemId"`
+			ClassDate       string   `json:"classDate"`
+			ClassBeginTime  string   `json:"classBeginTime"`
+			ClassEndTime    string   `json:"classEndTime"`
+			PlanName        string   `json:"planName"`
+			CourseName      string   `json:"courseName"`
+			TeacherName     string   `json:"teacherName"`
+			SelfScore       float64  `json:"selfScore"`
+			TeacherScore    float64  `json:"teacherScore"`
+			TeacherComment  string   `json:"teacherComment"`
+			HasTeacherScore bool     `json:"hasTeacherScore"`
+			WeightedScore   *float64 `json:"weightedScore"`
+		} `json:"scores"`
+	}
+	resp.Decode(t, &data)
+
+	stats := data.Statistics
+	if stats.TotalCourses != 3 || stats.CompletedCourses != 2 {
+		t.Fatalf("应有 3 门已评价课程、2 门已评分，实际 %d、%d", stats.TotalCourses, stats.CompletedCourses)
+	}
+	// 80*0.4+90*0.6=86，70*0.5+60*0.5=65
+	if !dbtest.Near(stats.MaxScore, 86) || !dbtest.Near(stats.MinScore, 65) || !dbtest.Near(stats.AverageScore, 75.5) {
+		t.Errorf("统计应为最高 86、最低 65、平均 75.5，实际 %v、%v、%v", stats.MaxScore, stats.MinScore, stats.AverageScore)
+	}
+
+	if len(data.Scores) != 3 {
+		t.Fatalf("应返回 3 条成绩，实际 %d 条", len(data.Scores))
+	}
+	want := map[int64]struct {
+		date     string
+		begin    string
+		graded   bool
+		weighted float64
+	}{
+		graded.ItemID:    {dbtest.Date(-3), "09:00:00", true, 86},
+		scoreOnly.ItemID: {dbtest.Date(-2), "09:00:00", true, 65},
+		selfOnly.ItemID:  {dbtest.Date(-1), "14:00:00", false, 0},
+	}
+	for _, s := range data.Scores {
+		w, ok := want[s.ItemID]
+		if !ok {
+			t.Errorf("返回了不属于该员工的成绩：课程安排 %d", s.ItemID)
+			continue
+		}
+		if s.ClassDate != w.date {
+			t.Errorf("课程安排 %d 的 classDate 应为 %q（YYYY-MM-DD），实际为 %q", s.ItemID, w.date, s.ClassDate)
+		}
+		if s.ClassBeginTime != w.begin {
+			t.Errorf("课程安排 %d 的 classBeginTime 应为 %q，实际为 %q", s.ItemID, w.begin, s.ClassBeginTime)
+		}
+		if s.PlanName != "安全培训" || s.CourseName != "消防" || s.TeacherName != "李老师" {
+			t.Errorf("课程安排 %d 的计划、课程或讲师不正确：%+v", s.ItemID, s)
+		}
+		if s.HasTeacherScore != w.graded {
+			t.Errorf("课程安排 %d 的 hasTeacherScore 应为 %v", s.ItemID, w.graded)
+		}
+		switch {
+		case w.graded && (s.WeightedScore == nil || !dbtest.Near(*s.WeightedScore, w.weighted)):
+			t.Errorf("课程安排 %d 的 weightedScore 应为 %v，实际为 %v", s.ItemID, w.weighted, s.WeightedScore)
+		case !w.graded && s.WeightedScore != nil:
+			t.Errorf("课程安排 %d 未评分，weightedScore 应为 null，实际为 %v", s.ItemID, *s.WeightedScore)
+		}
+	}
+}
+
+func TestGetCourseTypeScores(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", dbtest.Date(-5), dbtest.Date(0), dbtest.EmployeeID)
+	fire := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	aid := dbtest.CreateCourse(t, "急救", "安全", dbtest.Teacher2ID)
+	english := dbtest.CreateCourse(t, "航海英语", "语言", dbtest.TeacherID)
+	fireItem := dbtest.CreateItem(t, plan, fire, dbtest.Date(-3), "09:00:00", "10:00:00", "Asia/Shanghai")
+	aidItem := dbtest.CreateItem(t, plan, aid, dbtest.Date(-2), "09:00:00", "10:00:00", "Asia/Shanghai")
+	englishItem := dbtest.CreateItem(t, plan, english, dbtest.Date(-1), "09:00:00", "10:00:00", "Asia/Shanghai")
+	pendingItem := dbtest.CreateItem(t, plan, english, dbtest.Date(0), "09:00:00", "10:00:00", "Asia/Shanghai")
+
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: fireItem.ItemID, SelfScore: 80, TeacherScore: 90, ScoreRatio: 0.6,
+	})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: aidItem.ItemID, SelfScore: 90, TeacherScore: 90, TeacherComment: "熟练", ScoreRatio: 0.5,
+	})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: englishItem.ItemID, SelfScore: 70, TeacherScore: 60, ScoreRatio: 0.5,
+	})
+	// 讲师尚未评分的不计入
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: pendingItem.ItemID, SelfScore: 100, ScoreRatio: 0.5,
+	})
+
+	var data struct {
+		CourseTypeScores []struct {
+			CourseClass string  `json:"courseClass"`
+			AvgScore    float64 `json:"avgScore"`
+			CourseCount int     `json:"courseCount"`
+			MaxScore    float64 `json:"maxScore"`
+			MinScore    float64 `json:"minScore"`
+		} `json:"courseTypeScores"`
+		RadarData struct {
+			Values []float64 `json:"values"`
+		} `json:"radarData"`
+	}
+	dbtest.Get(t, GetCourseTypeScores, dbtest.EmployeeID, "员工", "/employee/scores/course-types").Decode(t, &data)
+
+	if len(data.CourseTypeScores) != 2 || len(data.RadarData.Values) != 2 {
+		t.Fatalf("应有安全、语言 2 类课程成绩，实际 %+v", data)
+	}
+	for _, s := range data.CourseTypeScores {
+		switch s.CourseClass {
+		case "安全":
+			// 80*0.4+90*0.6=86，90
+			if s.CourseCount != 2 || !dbtest.Near(s.AvgScore, 88) || !dbtest.Near(s.MaxScore, 90) || !dbtest.Near(s.MinScore, 86) {
+				t.Errorf("安全类应为 2 门、平均 88、最高 90、最低 86，实际 %+v", s)
+			}
+		case "语言":
+			if s.CourseCount != 1 || !dbtest.Near(s.AvgScore, 65) || !dbtest.Near(s.MaxScore, 65) || !dbtest.Near(s.MinScore, 65) {
+				t.Errorf("语言类应为 1 门、65 分，实际 %+v", s)
+			}
+		default:
+			t.Errorf("不应出现课程类型 %q", s.CourseClass)
+		}
+	}
+}
+
+func TestGetLearningProgress(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", dbtest.Date(-3), dbtest.Date(2), dbtest.EmployeeID, dbtest.Employee2ID)
+	dbtest.CreatePlan(t, "船员培训", dbtest.Date(5), dbtest.Date(9), dbtest.EmployeeID)
+	course := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	graded := dbtest.CreateItem(t, plan, course, dbtest.Date(-2), "09:00:00", "10:00:00", "Asia/Shanghai")
+	selfOnly := dbtest.CreateItem(t, plan, course, dbtest.Date(-1), "09:00:00", "10:00:00", "Asia/Shanghai")
+	dbtest.CreateItem(t, plan, course, dbtest.Date(1), "09:00:00", "10:00:00", "Asia/Shanghai")
+
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: graded.ItemID, SelfScore: 80, TeacherScore: 90, TeacherComment: "认真", ScoreRatio: 0.6,
+	})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: selfOnly.ItemID, SelfScore: 75, SelfComment: "一般", ScoreRatio: 0.5,
+	})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.Employee2ID, ItemID: graded.ItemID, SelfScore: 50, TeacherScore: 50, TeacherComment: "其他员工", ScoreRatio: 0.5,
+	})
+
+	var data struct {
+		OverallProgress struct {
+			TotalPlans       int   `json:"totalPlans"`
+			TotalCourses     int64 `json:"totalCourses"`
+			CompletedCourses int64 `json:"completedCourses"`
+			EvaluatedCourses int64 `json:"evaluatedCourses"`
+		} `json:"overallProgress"`
+		PlanProgress []struct {
+			PlanID             int64   `json:"planId"`
+			PlanName           string  `json:"planName"`
+			StartDate          string  `json:"startDate"`
+			EndDate            string  `json:"endDate"`
+			TotalCourses       int     `json:"totalCourses"`
+			CompletedCourses   int     `json:"completedCourses"`
+			EvaluatedCourses   int     `json:"evaluatedCourses"`
+			ProgressPercentage float64 `json:"progressPercentage"`
+			AverageScore       float64 `json:"averageScore"`
+		} `json:"planProgress"`
+	}
+	dbtest.Get(t, GetLearningProgress, dbtest.EmployeeID, "员工", "/employee/learning-progress").Decode(t, &data)
+
+	overall := data.OverallProgress
+	if overall.TotalPlans != 2 || overall.TotalCourses != 3 || overall.CompletedCourses != 1 || overall.EvaluatedCourses != 2 {
+		t.Errorf("总体进度应为 2 个计划、3 门课程、1 门已评分、2 门已自评，实际 %+v", overall)
+	}
+
+	if len(data.PlanProgress) != 2 {
+		t.Fatalf("应返回 2 个计划的进度，实际 %+v", data.PlanProgress)
+	}
+	for _, p := range data.PlanProgress {
+		if p.PlanID != plan.PlanID {
+			if p.TotalCourses != 0 || p.AverageScore != 0 {
+				t.Errorf("船员培训没有课程，进度应为空，实际 %+v", p)
+			}
+			continue
+		}
+		if p.StartDate != dbtest.Date(-3) || p.EndDate != dbtest.Date(2) {
+			t.Errorf("startDate、endDate 应为 %s、%s（YYYY-MM-DD），实际为 %q、%q", dbtest.Date(-3), dbtest.Date(2), p.StartDate, p.EndDate)
+		}
+		// 80*0.4+90*0.6=86
+		if p.TotalCourses != 3 || p.CompletedCourses != 1 || p.EvaluatedCourses != 2 ||
+			!dbtest.Near(p.ProgressPercentage, 100.0/3) || !dbtest.Near(p.AverageScore, 86) {
+			t.Errorf("安全培训应为 3 门课程、1 门已评分、2 门已自评、平均 86，实际 %+v", p)
+		}
+	}
+}
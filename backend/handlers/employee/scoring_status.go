@@ -0,0 +1,32 @@
+package employee
+
+import (
+	"backend/scoring"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetScoringStatus 查询自评的AI评分状态（提交自评后轮询）
+func GetScoringStatus(c *gin.Context) {
+	personID, exists := c.Get("personId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "未登录", "data": nil})
+		return
+	}
+
+	itemID, err := strconv.ParseInt(c.Query("itemId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "课程安排ID格式错误", "data": nil})
+		return
+	}
+
+	status, err := scoring.GetEvaluationStatus(personID.(int64), itemID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "未找到自评记录", "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "获取成功", "data": status})
+}
@@ -2,8 +2,10 @@ package employee
 
 import (
 	"net/http"
+	"sort"
 	"time"
 	"backend/database"
+	"backend/scheduling"
 
 	"github.com/gin-gonic/gin"
 
@@ -11,22 +13,25 @@ import (
 
 // TodayCourseResponse 今日课程响应结构
 type TodayCourseResponse struct {
-	ItemID          int       `json:"itemId"`
-	CourseID        int       `json:"courseId"`
-	CourseName      string    `json:"courseName"`
-	CourseDesc      string    `json:"courseDesc"`
-	CourseRequire   string    `json:"courseRequire"`
-	CourseClass     string    `json:"courseClass"`
-	ClassDate       string    `json:"classDate"`
-	ClassBeginTime  string    `json:"classBeginTime"`
-	ClassEndTime    string    `json:"classEndTime"`
-	Location        string    `json:"location"`
-	PlanID          int       `json:"planId"`
-	PlanName        string    `json:"planName"`
-	TeacherID       int       `json:"teacherId"`
-	TeacherName     string    `json:"teacherName"`
-	HasEvaluated    bool      `json:"hasEvaluated"`
-	Status          string    `json:"status"`
+	ItemID         int    `json:"itemId"`
+	CourseID       int    `json:"courseId"`
+	CourseName     string `json:"courseName"`
+	CourseDesc     string `json:"courseDesc"`
+	CourseRequire  string `json:"courseRequire"`
+	CourseClass    string `json:"courseClass"`
+	ClassDate      string `json:"classDate"`
+	ClassBeginTime string `json:"classBeginTime"`
+	ClassEndTime   string `json:"classEndTime"`
+	ClassTimezone  string `json:"classTimezone"`
+	StartsAt       string `json:"startsAt"`
+	EndsAt         string `json:"endsAt"`
+	Location       string `json:"location"`
+	PlanID         int    `json:"planId"`
+	PlanName       string `json:"planName"`
+	TeacherID      int    `json:"teacherId"`
+	TeacherName    string `json:"teacherName"`
+	HasEvaluated   bool   `json:"hasEvaluated"`
+	Status         string `json:"status"`
 }
 
 // GetTodayCourses 获取员工今日课程列表
@@ -44,11 +49,18 @@ func GetTodayCourses(c *gin.Context) {
 	
 	userID := personID.(int64)
 
-	// 获取今天的日期
-	today := time.Now()
+	// 获取查看者时区的今天
+	viewer, ok := scheduling.ViewerTimezone(c.Query("timezone"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无法识别的时区",
+			"data":    nil,
+		})
+		return
+	}
+	today := time.Now().In(viewer)
 	todayStr := today.Format("2006-01-02")
-	startOfDay := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
-	endOfDay := time.Date(today.Year(), today.Month(), today.Day(), 23, 59, 59, 999999999, today.Location())
 
 	// 1. 查询员工参与的培训计划ID
 	var planEmployees []database.PlanEmployee
@@ -73,6 +85,7 @@ func GetTodayCourses(c *gin.Context) {
 			"message": "获取成功",
 			"data": gin.H{
 				"date":        todayStr,
+				"timezone":    viewer.String(),
 				"courseCount": 0,
 				"courses":     []interface{}{},
 			},
@@ -80,15 +93,16 @@ func GetTodayCourses(c *gin.Context) {
 		return
 	}
 
-	// 2. 查询今日课程安排（使用与schedule相同的查询方式）
-	var courseItems []database.PlanCourseItem
+	// 2. 查询今日课程安排（使用与schedule相同的查询方式），前后各多查一天，换算时区后可能是今天
+	var candidates []database.PlanCourseItem
 	if err := database.DB.
 		Preload("Course.Teacher").
 		Preload("Plan").
 		Where("plan_id IN ?", planIDs).
-		Where("class_date >= ? AND class_date <= ?", startOfDay, endOfDay).
+		Where(database.DateBetween("class_date",
+			today.AddDate(0, 0, -1).Format("2006-01-02"), today.AddDate(0, 0, 1).Format("2006-01-02"))).
 		Order("class_begin_time ASC").
-		Find(&courseItems).Error; err != nil {
+		Find(&candidates).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
 			"message": "查询课程失败",
@@ -96,6 +110,18 @@ func GetTodayCourses(c *gin.Context) {
 		})
 		return
 	}
+	courseItems := make([]database.PlanCourseItem, 0, len(candidates))
+	localSlots := make(map[int64]scheduling.Slot, len(candidates))
+	for _, item := range candidates {
+		local := scheduling.ViewerSlot(item.ClassDate.Format("2006-01-02"), item.ClassBeginTime, item.ClassEndTime, item.Timezone, viewer)
+		if local.Date == todayStr {
+			courseItems = append(courseItems, item)
+			localSlots[item.ItemID] = local
+		}
+	}
+	sort.SliceStable(courseItems, func(i, j int) bool {
+		return localSlots[courseItems[i].ItemID].Begin < localSlots[courseItems[j].ItemID].Begin
+	})
 
 	// 3. 查询员工的评价记录
 	itemIDs := make([]int64, 0, len(courseItems))
@@ -116,24 +142,13 @@ func GetTodayCourses(c *gin.Context) {
 	// 4. 构建响应数据
 	courses := make([]TodayCourseResponse, 0, len(courseItems))
 	for _, item := range courseItems {
-		// 判断课程状态
+		// 判断课程状态（按课程安排所在时区的结束时刻）
 		status := "待上课"
-		classEndTime, err := time.Parse("15:04:05", item.ClassEndTime)
-		if err == nil {
-			classDateTime := time.Date(
-				item.ClassDate.Year(),
-				item.ClassDate.Month(),
-				item.ClassDate.Day(),
-				classEndTime.Hour(),
-				classEndTime.Minute(),
-				classEndTime.Second(),
-				0,
-				today.Location(),
-			)
-			if today.After(classDateTime) {
-				status = "已完成"
-			}
+		startsAt, endsAt := scheduling.ItemInterval(item)
+		if !today.Before(endsAt) {
+			status = "已完成"
 		}
+		local := localSlots[item.ItemID]
 
 		courses = append(courses, TodayCourseResponse{
 			ItemID:         int(item.ItemID),
@@ -142,9 +157,12 @@ func GetTodayCourses(c *gin.Context) {
 			CourseDesc:     item.Course.CourseDesc,
 			CourseRequire:  item.Course.CourseRequire,
 			CourseClass:    item.Course.CourseClass,
-			ClassDate:      item.ClassDate.Format("2006-01-02"),
-			ClassBeginTime: item.ClassBeginTime,
-			ClassEndTime:   item.ClassEndTime,
+			ClassDate:      local.Date,
+			ClassBeginTime: local.Begin,
+			ClassEndTime:   local.End,
+			ClassTimezone:  scheduling.TimezoneName(item.Timezone),
+			StartsAt:       startsAt.Format(time.RFC3339),
+			EndsAt:         endsAt.Format(time.RFC3339),
 			Location:       item.Location,
 			PlanID:         int(item.PlanID),
 			PlanName:       item.Plan.PlanName,
@@ -160,6 +178,7 @@ func GetTodayCourses(c *gin.Context) {
 		"message": "获取成功",
 		"data": gin.H{
 			"date":        todayStr,
+			"timezone":    viewer.String(),
 			"courseCount": len(courses),
 			"courses":     courses,
 		},
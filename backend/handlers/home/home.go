@@ -82,15 +82,15 @@ func getGlobalStatistics() gin.H {
 	database.DB.Model(&database.PlanCourseItem{}).Count(&totalClassCount)
 
 	// 进行中的计划数
-	database.DB.Model(&database.TrainingPlan{}).Where("plan_status = ?", "进行中").Count(&ongoingPlanCount)
+	database.DB.Model(&database.TrainingPlan{}).Where("plan_status = ?", database.PlanStatusInProgress).Count(&ongoingPlanCount)
 
 	// 已完成的计划数
-	database.DB.Model(&database.TrainingPlan{}).Where("plan_status = ?", "已完成").Count(&completedPlanCount)
+	database.DB.Model(&database.TrainingPlan{}).Where("plan_status = ?", database.PlanStatusCompleted).Count(&completedPlanCount)
 
 	// 平均满意度（加权得分的平均值，转换为百分比）
 	var avgResult *float64
 	database.DB.Model(&database.AttendanceEvaluation{}).
-		Select("AVG(COALESCE(self_score, 0) * (1 - COALESCE(score_ratio, 0.5)) + COALESCE(teacher_score, 0) * COALESCE(score_ratio, 0.5))").
+		Select("COALESCE(AVG(COALESCE(self_score, 0) * (1 - COALESCE(score_ratio, 0.5)) + COALESCE(teacher_score, 0) * COALESCE(score_ratio, 0.5)), 0)").
 		Scan(&avgResult)
 	
 	if avgResult != nil {
@@ -138,21 +138,22 @@ func getEmployeePersonalStats(personID int64) gin.H {
 
 	// 该员工的平均得分
 	database.DB.Model(&database.AttendanceEvaluation{}).
-		Select("AVG(COALESCE(self_score, 0) * (1 - COALESCE(score_ratio, 0.5)) + COALESCE(teacher_score, 0) * COALESCE(score_ratio, 0.5))").
+		Select("COALESCE(AVG(COALESCE(self_score, 0) * (1 - COALESCE(score_ratio, 0.5)) + COALESCE(teacher_score, 0) * COALESCE(score_ratio, 0.5)), 0)").
 		Where("person_id = ?", personID).
 		Scan(&myAverageScore)
 
 	// 该员工今日课程数
 	database.DB.Table("plan_employee").
 		Joins("JOIN plan_course_item ON plan_employee.plan_id = plan_course_item.plan_id").
-		Where("plan_employee.person_id = ? AND plan_course_item.class_date = ?", personID, today).
+		Where("plan_employee.person_id = ?", personID).
+		Where(database.OnDate("plan_course_item.class_date", today)).
 		Count(&myTodayCourseCount)
 
 	// 该员工本周课程数
 	database.DB.Table("plan_employee").
 		Joins("JOIN plan_course_item ON plan_employee.plan_id = plan_course_item.plan_id").
-		Where("plan_employee.person_id = ? AND plan_course_item.class_date >= ? AND plan_course_item.class_date <= ?",
-			personID, weekStart, weekEnd).
+		Where("plan_employee.person_id = ?", personID).
+		Where(database.DateBetween("plan_course_item.class_date", weekStart, weekEnd)).
 		Count(&myWeekCourseCount)
 
 	return gin.H{
@@ -197,7 +198,7 @@ func getTeacherPersonalStats(personID int64) gin.H {
 
 	// 该讲师的平均教学评分
 	database.DB.Table("attendance_evaluation").
-		Select("AVG(COALESCE(attendance_evaluation.teacher_score, 0))").
+		Select("COALESCE(AVG(COALESCE(attendance_evaluation.teacher_score, 0)), 0)").
 		Joins("JOIN plan_course_item ON attendance_evaluation.item_id = plan_course_item.item_id").
 		Joins("JOIN course ON plan_course_item.course_id = course.course_id").
 		Where("course.teacher_id = ? AND attendance_evaluation.teacher_score IS NOT NULL", personID).
@@ -206,14 +207,15 @@ func getTeacherPersonalStats(personID int64) gin.H {
 	// 该讲师今日授课数
 	database.DB.Table("plan_course_item").
 		Joins("JOIN course ON plan_course_item.course_id = course.course_id").
-		Where("course.teacher_id = ? AND plan_course_item.class_date = ?", personID, today).
+		Where("course.teacher_id = ?", personID).
+		Where(database.OnDate("plan_course_item.class_date", today)).
 		Count(&myTodayClassCount)
 
 	// 该讲师本周授课数
 	database.DB.Table("plan_course_item").
 		Joins("JOIN course ON plan_course_item.course_id = course.course_id").
-		Where("course.teacher_id = ? AND plan_course_item.class_date >= ? AND plan_course_item.class_date <= ?",
-			personID, weekStart, weekEnd).
+		Where("course.teacher_id = ?", personID).
+		Where(database.DateBetween("plan_course_item.class_date", weekStart, weekEnd)).
 		Count(&myWeekClassCount)
 
 	return gin.H{
@@ -0,0 +1,78 @@
+package planner
+
+import (
+	"backend/accounts"
+	"backend/database"
+	"backend/enrollment"
+	"backend/password"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateAccount 创建账号（接口5.66）：由课程大纲制定者直接创建任意角色的账号，不受注册方式限制
+func CreateAccount(c *gin.Context) {
+	var req struct {
+		Username string `json:"username" binding:"required,min=3,max=20"`
+		Password string `json:"password" binding:"required"` // 初始密码，按密码规则检查
+		Name     string `json:"name" binding:"required,min=2,max=20"`
+		Role     string `json:"role" binding:"required,oneof=employee teacher planner"`
+		Email    string `json:"email" binding:"omitempty,email,max=100"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	if msg := password.CurrentPolicy().Check(req.Password); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": msg,
+			"data":    nil,
+		})
+		return
+	}
+
+	// 人员和账号记录在同一事务中创建
+	var account *database.Account
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		account, err = accounts.Create(tx, accounts.NewAccount{
+			Username: req.Username,
+			Password: req.Password,
+			Name:     req.Name,
+			Role:     req.Role,
+			Email:    req.Email,
+		})
+		return err
+	})
+	if err == accounts.ErrUsernameTaken {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "创建账号失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 新员工可能匹配计划的自动报名规则
+	enrollment.Notify()
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "账号已创建",
+		"data":    accountData(account),
+	})
+}
@@ -0,0 +1,103 @@
+package planner
+
+import (
+	"backend/accounts"
+	"backend/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAccounts 获取账号列表（接口5.65），可按姓名或用户名、角色、状态筛选
+func GetAccounts(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if err != nil || pageSize < 1 {
+		pageSize = 20
+	}
+
+	query := database.DB.Model(&database.Account{}).Joins("Person")
+	if keyword := c.Query("keyword"); keyword != "" {
+		query = query.Where("account.login_name LIKE ? OR Person.name LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+	if role := c.Query("role"); role != "" {
+		query = query.Where("Person.role = ?", accounts.RoleDisplay(role))
+	}
+	switch c.Query("status") {
+	case "":
+	case "active":
+		query = query.Where("account.deactivated_at IS NULL")
+	case "deactivated":
+		query = query.Where("account.deactivated_at IS NOT NULL")
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的状态，可选 active/deactivated",
+			"data":    nil,
+		})
+		return
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	var list []database.Account
+	if err := query.Order("account.account_id").Offset((page - 1) * pageSize).Limit(pageSize).Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	items := make([]gin.H, 0, len(list))
+	for _, a := range list {
+		items = append(items, accountData(&a))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"total":    total,
+			"page":     page,
+			"pageSize": pageSize,
+			"list":     items,
+		},
+	})
+}
+
+// accountData 账号管理接口返回的账号信息，account 需已加载人员信息
+func accountData(account *database.Account) gin.H {
+	status := "active"
+	var deactivatedAt *string
+	if account.DeactivatedAt != nil {
+		status = "deactivated"
+		s := account.DeactivatedAt.Format("2006-01-02 15:04:05")
+		deactivatedAt = &s
+	}
+	return gin.H{
+		"personId":      account.PersonID,
+		"accountId":     account.AccountID,
+		"username":      account.LoginName,
+		"name":          account.Person.Name,
+		"role":          accounts.RoleCode(account.Person.Role),
+		"roleDisplay":   account.Person.Role,
+		"email":         account.Email,
+		"invitationId":  account.InvitationID,
+		"status":        status,
+		"deactivatedAt": deactivatedAt,
+	}
+}
@@ -4,12 +4,28 @@ import (
 	"net/http"
 	"strconv"
 	"backend/database"
+	"backend/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 // GetAnalytics 获取平台数据分析（接口5.16）
 func GetAnalytics(c *gin.Context) {
+	unitIDs, ok := orgUnitScope(c)
+	if !ok {
+		return
+	}
+
+	// 按组织节点筛选时，评价类统计只计入该节点及下级节点员工的评价，计划状态只统计有这些员工参与的计划
+	scope := ""
+	planScope := ""
+	var scopeArgs []interface{}
+	if unitIDs != nil {
+		scope = " AND ae.person_id IN (SELECT person_id FROM person WHERE org_unit_id IN ?)"
+		planScope = " WHERE plan_id IN (SELECT pe.plan_id FROM plan_employee pe JOIN person sp ON pe.person_id = sp.person_id WHERE sp.org_unit_id IN ?)"
+		scopeArgs = []interface{}{unitIDs}
+	}
+
 	// 获取查询参数
 	topNStr := c.DefaultQuery("topN", "10")
 	topN, err := strconv.Atoi(topNStr)
@@ -34,12 +50,12 @@ func GetAnalytics(c *gin.Context) {
 		FROM course c
 		LEFT JOIN plan_course_item pci ON c.course_id = pci.course_id
 		LEFT JOIN attendance_evaluation ae ON pci.item_id = ae.item_id
-		WHERE ae.teacher_score != 0 OR ae.teacher_comment != ''
+		WHERE (ae.teacher_score != 0 OR ae.teacher_comment != '')`+scope+`
 		GROUP BY c.course_id, c.course_name
 		HAVING COUNT(DISTINCT ae.person_id) > 0
 		ORDER BY course_avg_score DESC
 		LIMIT ?
-	`, topN).Scan(&courseRankings)
+	`, append(scopeArgs, topN)...).Scan(&courseRankings)
 
 	// 2. 培训计划排名（按平均分）
 	type PlanRanking struct {
@@ -56,12 +72,12 @@ func GetAnalytics(c *gin.Context) {
 		FROM training_plan tp
 		LEFT JOIN plan_course_item pci ON tp.plan_id = pci.plan_id
 		LEFT JOIN attendance_evaluation ae ON pci.item_id = ae.item_id
-		WHERE ae.teacher_score != 0 OR ae.teacher_comment != ''
+		WHERE (ae.teacher_score != 0 OR ae.teacher_comment != '')`+scope+`
 		GROUP BY tp.plan_id, tp.plan_name
 		HAVING COUNT(DISTINCT ae.person_id) > 0
 		ORDER BY plan_avg_score DESC
 		LIMIT ?
-	`, topN).Scan(&planRankings)
+	`, append(scopeArgs, topN)...).Scan(&planRankings)
 
 	// 3. 课程类型分布
 	type CourseClassDist struct {
@@ -77,10 +93,10 @@ func GetAnalytics(c *gin.Context) {
 			COALESCE(AVG(ae.self_score * (1 - ae.score_ratio) + ae.teacher_score * ae.score_ratio), 0) AS avg_score
 		FROM course c
 		LEFT JOIN plan_course_item pci ON c.course_id = pci.course_id
-		LEFT JOIN attendance_evaluation ae ON pci.item_id = ae.item_id AND (ae.teacher_score != 0 OR ae.teacher_comment != '')
+		LEFT JOIN attendance_evaluation ae ON pci.item_id = ae.item_id AND (ae.teacher_score != 0 OR ae.teacher_comment != '')`+scope+`
 		GROUP BY c.course_class
 		ORDER BY course_count DESC
-	`).Scan(&courseClassDistribution)
+	`, scopeArgs...).Scan(&courseClassDistribution)
 
 	// 4. 计划状态统计
 	type PlanStatusStat struct {
@@ -90,9 +106,9 @@ func GetAnalytics(c *gin.Context) {
 	var planStatusStatistics []PlanStatusStat
 	database.DB.Raw(`
 		SELECT plan_status AS plan_status, COUNT(*) AS count
-		FROM training_plan
+		FROM training_plan`+planScope+`
 		GROUP BY plan_status
-	`).Scan(&planStatusStatistics)
+	`, scopeArgs...).Scan(&planStatusStatistics)
 
 	// 5. 员工排名（按平均分）
 	type EmployeeRanking struct {
@@ -110,11 +126,11 @@ func GetAnalytics(c *gin.Context) {
 			COUNT(DISTINCT ae.item_id) AS course_count
 		FROM person p
 		INNER JOIN attendance_evaluation ae ON p.person_id = ae.person_id
-		WHERE p.role = '员工' AND (ae.teacher_score != 0 OR ae.teacher_comment != '')
+		WHERE p.role = '员工' AND (ae.teacher_score != 0 OR ae.teacher_comment != '')`+scope+`
 		GROUP BY p.person_id, p.name
 		ORDER BY avg_score DESC
 		LIMIT ?
-	`, topN).Scan(&employeeRankings)
+	`, append(scopeArgs, topN)...).Scan(&employeeRankings)
 
 	// 6. 讲师统计
 	type TeacherStat struct {
@@ -135,11 +151,83 @@ func GetAnalytics(c *gin.Context) {
 		FROM person p
 		INNER JOIN course c ON p.person_id = c.teacher_id
 		LEFT JOIN plan_course_item pci ON c.course_id = pci.course_id
-		LEFT JOIN attendance_evaluation ae ON pci.item_id = ae.item_id AND (ae.teacher_score != 0 OR ae.teacher_comment != '')
+		LEFT JOIN attendance_evaluation ae ON pci.item_id = ae.item_id AND (ae.teacher_score != 0 OR ae.teacher_comment != '')`+scope+`
 		WHERE p.role = '讲师'
 		GROUP BY p.person_id, p.name
 		ORDER BY course_count DESC
-	`).Scan(&teacherStatistics)
+	`, scopeArgs...).Scan(&teacherStatistics)
+
+	// 7. 课后反馈评分（理解程度/难度感受/满意度）：按课程、按讲师汇总
+	type RatingRow struct {
+		CourseID      int64
+		CourseName    string
+		TeacherID     int64
+		TeacherName   string
+		Understanding int
+		Difficulty    int
+		Satisfaction  int
+	}
+	var ratingRows []RatingRow
+	database.DB.Raw(`
+		SELECT 
+			c.course_id AS course_id,
+			c.course_name AS course_name,
+			c.teacher_id AS teacher_id,
+			p.name AS teacher_name,
+			ae.understanding AS understanding,
+			ae.difficulty AS difficulty,
+			ae.satisfaction AS satisfaction
+		FROM attendance_evaluation ae
+		INNER JOIN plan_course_item pci ON ae.item_id = pci.item_id
+		INNER JOIN course c ON pci.course_id = c.course_id
+		INNER JOIN person p ON c.teacher_id = p.person_id
+		WHERE (ae.understanding > 0 OR ae.difficulty > 0 OR ae.satisfaction > 0)`+scope+`
+		ORDER BY c.course_id
+	`, scopeArgs...).Scan(&ratingRows)
+
+	type CourseRating struct {
+		CourseID    int64                `json:"courseId"`
+		CourseName  string               `json:"courseName"`
+		TeacherID   int64                `json:"teacherId"`
+		TeacherName string               `json:"teacherName"`
+		Ratings     *utils.RatingSummary `json:"ratings"`
+	}
+	type TeacherRating struct {
+		TeacherID   int64                `json:"teacherId"`
+		TeacherName string               `json:"teacherName"`
+		Ratings     *utils.RatingSummary `json:"ratings"`
+	}
+	courseRatings := []*CourseRating{}
+	teacherRatings := []*TeacherRating{}
+	courseRatingMap := make(map[int64]*CourseRating)
+	teacherRatingMap := make(map[int64]*TeacherRating)
+	for _, row := range ratingRows {
+		cr, ok := courseRatingMap[row.CourseID]
+		if !ok {
+			cr = &CourseRating{
+				CourseID:    row.CourseID,
+				CourseName:  row.CourseName,
+				TeacherID:   row.TeacherID,
+				TeacherName: row.TeacherName,
+				Ratings:     utils.NewRatingSummary(),
+			}
+			courseRatingMap[row.CourseID] = cr
+			courseRatings = append(courseRatings, cr)
+		}
+		cr.Ratings.Add(row.Understanding, row.Difficulty, row.Satisfaction)
+
+		tr, ok := teacherRatingMap[row.TeacherID]
+		if !ok {
+			tr = &TeacherRating{
+				TeacherID:   row.TeacherID,
+				TeacherName: row.TeacherName,
+				Ratings:     utils.NewRatingSummary(),
+			}
+			teacherRatingMap[row.TeacherID] = tr
+			teacherRatings = append(teacherRatings, tr)
+		}
+		tr.Ratings.Add(row.Understanding, row.Difficulty, row.Satisfaction)
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
@@ -151,6 +239,8 @@ func GetAnalytics(c *gin.Context) {
 			"planStatusStatistics":    planStatusStatistics,
 			"employeeRankings":        employeeRankings,
 			"teacherStatistics":       teacherStatistics,
+			"courseRatings":           courseRatings,
+			"teacherRatings":          teacherRatings,
 		},
 	})
 }
@@ -0,0 +1,170 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"backend/utils"
+	"strconv"
+	"testing"
+)
+
+func TestGetAnalytics(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", dbtest.Date(-3), dbtest.Date(2), dbtest.EmployeeID, dbtest.Employee2ID)
+	finished := dbtest.CreatePlan(t, "往期培训", dbtest.Date(-30), dbtest.Date(-20), dbtest.EmployeeID)
+	if err := database.DB.Model(&finished).Update("plan_status", "已完成").Error; err != nil {
+		t.Fatalf("更新计划状态失败: %v", err)
+	}
+	fire := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	aid := dbtest.CreateCourse(t, "急救", "安全", dbtest.Teacher2ID)
+	english := dbtest.CreateCourse(t, "航海英语", "语言", dbtest.TeacherID)
+
+	fireItem := dbtest.CreateItem(t, plan, fire, dbtest.Date(-2), "09:00:00", "10:00:00", "Asia/Shanghai")
+	aidItem := dbtest.CreateItem(t, plan, aid, dbtest.Date(-1), "09:00:00", "10:00:00", "Asia/Shanghai")
+	dbtest.CreateItem(t, plan, english, dbtest.Date(1), "09:00:00", "10:00:00", "Asia/Shanghai")
+
+	// 80*0.4+90*0.6=86，70*0.5+60*0.5=65，90；讲师未评分的记录不计入排名
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: fireItem.ItemID, SelfScore: 80, TeacherScore: 90, ScoreRatio: 0.6,
+		Understanding: 5, Difficulty: 3, Satisfaction: 4,
+	})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.Employee2ID, ItemID: fireItem.ItemID, SelfScore: 70, TeacherScore: 60, ScoreRatio: 0.5,
+		Understanding: 3, Difficulty: 4, Satisfaction: 2,
+	})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: aidItem.ItemID, SelfScore: 90, TeacherScore: 90, TeacherComment: "熟练", ScoreRatio: 0.5,
+	})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.Employee2ID, ItemID: aidItem.ItemID, SelfScore: 60, ScoreRatio: 0.5,
+	})
+
+	var data struct {
+		CourseRankings []struct {
+			CourseID       int64   `json:"courseId"`
+			CourseName     string  `json:"courseName"`
+			CourseAvgScore float64 `json:"courseAvgScore"`
+			StudentCount   int64   `json:"studentCount"`
+		} `json:"courseRankings"`
+		PlanRankings []struct {
+			PlanID       int64   `json:"planId"`
+			PlanAvgScore float64 `json:"planAvgScore"`
+		} `json:"planRankings"`
+		CourseClassDistribution []struct {
+			CourseClass string  `json:"courseClass"`
+			CourseCount int64   `json:"courseCount"`
+			AvgScore    float64 `json:"avgScore"`
+		} `json:"courseClassDistribution"`
+		PlanStatusStatistics []struct {
+			PlanStatus string `json:"planStatus"`
+			Count      int64  `json:"count"`
+		} `json:"planStatusStatistics"`
+		EmployeeRankings []struct {
+			PersonID    int64   `json:"personId"`
+			PersonName  string  `json:"personName"`
+			AvgScore    float64 `json:"avgScore"`
+			CourseCount int64   `json:"courseCount"`
+		} `json:"employeeRankings"`
+		TeacherStatistics []struct {
+			TeacherID    int64   `json:"teacherId"`
+			TeacherName  string  `json:"teacherName"`
+			CourseCount  int64   `json:"courseCount"`
+			AvgScore     float64 `json:"avgScore"`
+			StudentCount int64   `json:"studentCount"`
+		} `json:"teacherStatistics"`
+		CourseRatings []struct {
+			CourseID int64               `json:"courseId"`
+			Ratings  utils.RatingSummary `json:"ratings"`
+		} `json:"courseRatings"`
+		TeacherRatings []struct {
+			TeacherID int64               `json:"teacherId"`
+			Ratings   utils.RatingSummary `json:"ratings"`
+		} `json:"teacherRatings"`
+	}
+	dbtest.Get(t, GetAnalytics, dbtest.PlannerID, "课程大纲制定者", "/planner/analytics").Decode(t, &data)
+
+	courses := data.CourseRankings
+	if len(courses) != 2 {
+		t.Fatalf("课程排名应有 2 门已评分课程，实际 %+v", courses)
+	}
+	if courses[0].CourseID != aid.CourseID || !dbtest.Near(courses[0].CourseAvgScore, 90) || courses[0].StudentCount != 1 {
+		t.Errorf("第 1 名应为急救（平均 90，1 名学员），实际 %+v", courses[0])
+	}
+	if courses[1].CourseID != fire.CourseID || !dbtest.Near(courses[1].CourseAvgScore, 75.5) || courses[1].StudentCount != 2 {
+		t.Errorf("第 2 名应为消防（平均 75.5，2 名学员），实际 %+v", courses[1])
+	}
+
+	if len(data.PlanRankings) != 1 || data.PlanRankings[0].PlanID != plan.PlanID || !dbtest.Near(data.PlanRankings[0].PlanAvgScore, 241.0/3) {
+		t.Errorf("计划排名应只有安全培训（平均 80.33），实际 %+v", data.PlanRankings)
+	}
+
+	classes := data.CourseClassDistribution
+	if len(classes) != 2 || classes[0].CourseClass != "安全" || classes[0].CourseCount != 2 || !dbtest.Near(classes[0].AvgScore, 241.0/3) {
+		t.Fatalf("课程类型分布第 1 项应为安全（2 门，平均 80.33），实际 %+v", classes)
+	}
+	if classes[1].CourseClass != "语言" || classes[1].CourseCount != 1 || classes[1].AvgScore != 0 {
+		t.Errorf("课程类型分布第 2 项应为语言（1 门，暂无成绩），实际 %+v", classes[1])
+	}
+
+	statuses := make(map[string]int64)
+	for _, s := range data.PlanStatusStatistics {
+		statuses[s.PlanStatus] = s.Count
+	}
+	if len(statuses) != 2 || statuses["进行中"] != 1 || statuses["已完成"] != 1 {
+		t.Errorf("计划状态统计应为进行中 1、已完成 1，实际 %+v", data.PlanStatusStatistics)
+	}
+
+	employees := data.EmployeeRankings
+	if len(employees) != 2 {
+		t.Fatalf("员工排名应有 2 名员工，实际 %+v", employees)
+	}
+	if employees[0].PersonID != dbtest.EmployeeID || employees[0].PersonName != "赵员工" || !dbtest.Near(employees[0].AvgScore, 88) || employees[0].CourseCount != 2 {
+		t.Errorf("第 1 名应为赵员工（平均 88，2 门课程），实际 %+v", employees[0])
+	}
+	if employees[1].PersonID != dbtest.Employee2ID || !dbtest.Near(employees[1].AvgScore, 65) || employees[1].CourseCount != 1 {
+		t.Errorf("第 2 名应为钱员工（平均 65，1 门课程），实际 %+v", employees[1])
+	}
+
+	teachers := data.TeacherStatistics
+	if len(teachers) != 2 {
+		t.Fatalf("讲师统计应有 2 名讲师，实际 %+v", teachers)
+	}
+	if teachers[0].TeacherID != dbtest.TeacherID || teachers[0].TeacherName != "李老师" || teachers[0].CourseCount != 2 ||
+		!dbtest.Near(teachers[0].AvgScore, 75.5) || teachers[0].StudentCount != 2 {
+		t.Errorf("李老师应有 2 次课、平均 75.5、2 名学员，实际 %+v", teachers[0])
+	}
+	if teachers[1].TeacherID != dbtest.Teacher2ID || teachers[1].CourseCount != 1 || !dbtest.Near(teachers[1].AvgScore, 90) || teachers[1].StudentCount != 1 {
+		t.Errorf("王老师应有 1 次课、平均 90、1 名学员，实际 %+v", teachers[1])
+	}
+
+	if len(data.CourseRatings) != 1 || data.CourseRatings[0].CourseID != fire.CourseID || data.CourseRatings[0].Ratings.ResponseCount != 2 {
+		t.Errorf("课后反馈应只汇总消防课程的 2 条反馈，实际 %+v", data.CourseRatings)
+	}
+	if len(data.TeacherRatings) != 1 || data.TeacherRatings[0].TeacherID != dbtest.TeacherID || data.TeacherRatings[0].Ratings.ResponseCount != 2 {
+		t.Errorf("讲师反馈应只汇总李老师的 2 条反馈，实际 %+v", data.TeacherRatings)
+	}
+
+	// 按组织节点筛选：只计入安全管理部（赵员工）的评价
+	var unit database.OrgUnit
+	if err := database.DB.Where("unit_name = ?", "安全管理部").First(&unit).Error; err != nil {
+		t.Fatalf("查询组织节点失败: %v", err)
+	}
+	var scoped struct {
+		EmployeeRankings []struct {
+			PersonID int64 `json:"personId"`
+		} `json:"employeeRankings"`
+		PlanStatusStatistics []struct {
+			PlanStatus string `json:"planStatus"`
+			Count      int64  `json:"count"`
+		} `json:"planStatusStatistics"`
+	}
+	target := "/planner/analytics?orgUnitId=" + strconv.FormatInt(unit.UnitID, 10)
+	dbtest.Get(t, GetAnalytics, dbtest.PlannerID, "课程大纲制定者", target).Decode(t, &scoped)
+	if len(scoped.EmployeeRankings) != 1 || scoped.EmployeeRankings[0].PersonID != dbtest.EmployeeID {
+		t.Errorf("筛选后员工排名应只有赵员工，实际 %+v", scoped.EmployeeRankings)
+	}
+	if len(scoped.PlanStatusStatistics) != 2 {
+		t.Errorf("赵员工参与了 2 个不同状态的计划，实际 %+v", scoped.PlanStatusStatistics)
+	}
+}
@@ -0,0 +1,134 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/scheduling"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// calendarEntryRequest 创建或修改工作日历条目的请求
+type calendarEntryRequest struct {
+	Kind      string `json:"kind"` // holiday / shutdown / workday
+	Name      string `json:"name"` // 名称，如"国庆节"
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"` // 为空时与开始日期相同
+	Site      string `json:"site"`    // 适用的楼宇或船舶，为空表示全部地点
+	Policy    string `json:"policy"`  // warn / block，为空时节假日为 warn、停工为 block
+}
+
+// formatCalendarEntry 格式化工作日历条目
+func formatCalendarEntry(e database.CalendarEntry) gin.H {
+	return gin.H{
+		"entryId":   e.EntryID,
+		"kind":      e.Kind,
+		"kindName":  scheduling.CalendarKindName(e.Kind),
+		"name":      e.Name,
+		"startDate": e.StartDate.Format("2006-01-02"),
+		"endDate":   e.EndDate.Format("2006-01-02"),
+		"site":      e.Site,
+		"policy":    e.Policy,
+		"source":    e.Source,
+	}
+}
+
+// defaultCalendarPolicy 条目类型的默认排课策略
+func defaultCalendarPolicy(kind string) string {
+	switch kind {
+	case scheduling.CalendarHoliday:
+		return scheduling.CalendarWarn
+	case scheduling.CalendarShutdown:
+		return scheduling.CalendarBlock
+	}
+	return ""
+}
+
+// applyCalendarEntry 校验请求并写入条目，返回错误提示
+func applyCalendarEntry(req calendarEntryRequest, entry *database.CalendarEntry) string {
+	req.Kind = strings.TrimSpace(req.Kind)
+	if req.Kind != scheduling.CalendarHoliday && req.Kind != scheduling.CalendarShutdown && req.Kind != scheduling.CalendarWorkday {
+		return "条目类型必须为 holiday、shutdown 或 workday"
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return "请填写名称"
+	}
+	if len([]rune(req.Name)) > 100 {
+		return "名称长度不能超过100字符"
+	}
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return "开始日期格式错误，请使用 YYYY-MM-DD 格式"
+	}
+	end := start
+	if req.EndDate != "" {
+		end, err = time.Parse("2006-01-02", req.EndDate)
+		if err != nil || end.Before(start) {
+			return "结束日期格式错误或早于开始日期"
+		}
+	}
+	if end.Sub(start) > 366*24*time.Hour {
+		return "日期范围不能超过一年"
+	}
+	req.Site = strings.TrimSpace(req.Site)
+	if len([]rune(req.Site)) > 50 {
+		return "楼宇或船舶名称长度不能超过50字符"
+	}
+
+	if req.Kind == scheduling.CalendarWorkday {
+		req.Policy = ""
+	} else if req.Policy == "" {
+		req.Policy = defaultCalendarPolicy(req.Kind)
+	} else if req.Policy != scheduling.CalendarWarn && req.Policy != scheduling.CalendarBlock {
+		return "排课策略必须为 warn 或 block"
+	}
+
+	entry.Kind = req.Kind
+	entry.Name = req.Name
+	entry.StartDate = start
+	entry.EndDate = end
+	entry.Site = req.Site
+	entry.Policy = req.Policy
+	return ""
+}
+
+// CreateCalendarEntry 登记工作日历条目（接口5.45）
+func CreateCalendarEntry(c *gin.Context) {
+	var req calendarEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	entry := database.CalendarEntry{Source: "manual"}
+	if msg := applyCalendarEntry(req, &entry); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": msg,
+			"data":    nil,
+		})
+		return
+	}
+
+	if err := database.DB.Create(&entry).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "登记工作日历失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "登记成功",
+		"data":    formatCalendarEntry(entry),
+	})
+}
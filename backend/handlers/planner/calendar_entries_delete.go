@@ -0,0 +1,46 @@
+package planner
+
+import (
+	"backend/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeleteCalendarEntry 删除工作日历条目（接口5.47）
+func DeleteCalendarEntry(c *gin.Context) {
+	entryId, err := strconv.ParseInt(c.Param("entryId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的工作日历条目ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	result := database.DB.Where("entry_id = ?", entryId).Delete(&database.CalendarEntry{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "删除工作日历失败",
+			"data":    nil,
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "工作日历条目不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "删除成功",
+		"data":    nil,
+	})
+}
@@ -0,0 +1,134 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/scheduling"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxImportEvents 单次导入的最大事件数
+const maxImportEvents = 1000
+
+// ImportCalendarEntries 从 iCal 文件导入节假日（接口5.48）
+// 每个事件导入为一个条目；同一地点范围内 UID 相同的事件再次导入时更新原条目，不会重复登记
+func ImportCalendarEntries(c *gin.Context) {
+	var req struct {
+		Content string `json:"content" binding:"required"` // iCal 文件内容
+		Kind    string `json:"kind"`                       // 导入的条目类型，默认 holiday
+		Site    string `json:"site"`                       // 适用的楼宇或船舶，为空表示全部地点
+		Policy  string `json:"policy"`                     // 排课策略，默认按类型
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	if req.Kind == "" {
+		req.Kind = scheduling.CalendarHoliday
+	}
+
+	events, err := scheduling.ParseICalEvents(req.Content)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "解析 iCal 文件失败：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	if len(events) > maxImportEvents {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "单次最多导入" + strconv.Itoa(maxImportEvents) + "个事件",
+			"data":    nil,
+		})
+		return
+	}
+
+	type skippedEvent struct {
+		UID     string `json:"uid"`
+		Summary string `json:"summary"`
+		Reason  string `json:"reason"`
+	}
+	skipped := []skippedEvent{}
+	var entries []database.CalendarEntry
+	for _, ev := range events {
+		if ev.Recurring {
+			skipped = append(skipped, skippedEvent{ev.UID, ev.Summary, "不支持重复事件，请导入按年份展开的节假日文件"})
+			continue
+		}
+		entry := database.CalendarEntry{Source: "ical", UID: ev.UID}
+		if entry.UID == "" {
+			entry.UID = ev.StartDate.Format("20060102") + "/" + ev.Summary
+		}
+		msg := applyCalendarEntry(calendarEntryRequest{
+			Kind:      req.Kind,
+			Name:      ev.Summary,
+			StartDate: ev.StartDate.Format("2006-01-02"),
+			EndDate:   ev.EndDate.Format("2006-01-02"),
+			Site:      req.Site,
+			Policy:    req.Policy,
+		}, &entry)
+		if msg != "" {
+			skipped = append(skipped, skippedEvent{ev.UID, ev.Summary, msg})
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	created, updated := 0, 0
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range entries {
+			entry := &entries[i]
+			var existing database.CalendarEntry
+			err := tx.Where("source = ? AND uid = ? AND site = ?", "ical", entry.UID, entry.Site).First(&existing).Error
+			if err == nil {
+				entry.EntryID = existing.EntryID
+				entry.CreatedAt = existing.CreatedAt
+				if err := tx.Save(entry).Error; err != nil {
+					return err
+				}
+				updated++
+				continue
+			}
+			if err != gorm.ErrRecordNotFound {
+				return err
+			}
+			if err := tx.Create(entry).Error; err != nil {
+				return err
+			}
+			created++
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "导入工作日历失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	list := make([]gin.H, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, formatCalendarEntry(e))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "导入成功",
+		"data": gin.H{
+			"createdCount": created,
+			"updatedCount": updated,
+			"skipped":      skipped,
+			"list":         list,
+		},
+	})
+}
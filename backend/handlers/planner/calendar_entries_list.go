@@ -0,0 +1,87 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/scheduling"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCalendarEntries 获取工作日历（接口5.44）
+// 返回与日期范围有交集的条目，并按地点解析出每天是否为工作日
+func GetCalendarEntries(c *gin.Context) {
+	year := time.Now().Year()
+	startDate := c.DefaultQuery("startDate", time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02"))
+	endDate := c.DefaultQuery("endDate", time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC).Format("2006-01-02"))
+	start, err1 := time.Parse("2006-01-02", startDate)
+	end, err2 := time.Parse("2006-01-02", endDate)
+	if err1 != nil || err2 != nil || end.Before(start) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "日期格式错误或结束日期早于开始日期，请使用 YYYY-MM-DD 格式",
+			"data":    nil,
+		})
+		return
+	}
+	if end.Sub(start) > 366*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "查询范围不能超过一年",
+			"data":    nil,
+		})
+		return
+	}
+	site := c.Query("site")
+
+	query := database.DB.
+		Where(database.DateUntil("start_date", endDate)).
+		Where(database.DateFrom("end_date", startDate))
+	if site != "" {
+		query = query.Where("(site = ? OR site = '')", site)
+	}
+	var entries []database.CalendarEntry
+	if err := query.Order("start_date, entry_id").Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询工作日历失败",
+			"data":    nil,
+		})
+		return
+	}
+	list := make([]gin.H, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, formatCalendarEntry(e))
+	}
+
+	// 按地点解析后的非工作日
+	cal, err := scheduling.LoadCalendar(startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询工作日历失败",
+			"data":    nil,
+		})
+		return
+	}
+	nonWorking := []scheduling.CalendarDay{}
+	for _, day := range cal.Days(start, end, site) {
+		if day.Mark != nil {
+			nonWorking = append(nonWorking, day)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"startDate":      startDate,
+			"endDate":        endDate,
+			"site":           site,
+			"total":          len(list),
+			"list":           list,
+			"nonWorkingDays": nonWorking,
+		},
+	})
+}
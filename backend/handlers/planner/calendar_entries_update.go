@@ -0,0 +1,67 @@
+package planner
+
+import (
+	"backend/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateCalendarEntry 修改工作日历条目（接口5.46）
+// 请求体与创建相同，整体替换条目内容
+func UpdateCalendarEntry(c *gin.Context) {
+	entryId, err := strconv.ParseInt(c.Param("entryId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的工作日历条目ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var req calendarEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	var entry database.CalendarEntry
+	if err := database.DB.Where("entry_id = ?", entryId).First(&entry).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "工作日历条目不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	if msg := applyCalendarEntry(req, &entry); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": msg,
+			"data":    nil,
+		})
+		return
+	}
+
+	if err := database.DB.Save(&entry).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "修改工作日历失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "修改成功",
+		"data":    formatCalendarEntry(entry),
+	})
+}
@@ -0,0 +1,172 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/scheduling"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// resolveLocation 根据 locationId 或地点名称确定上课地点，返回错误提示
+// 只传名称时按名称查找，未登记的名称自动登记为不限容量的地点（兼容按文本填写地点的旧客户端）
+func resolveLocation(locationID *int64, name string) (*database.Location, string) {
+	var loc database.Location
+	if locationID != nil {
+		if err := database.DB.Where("location_id = ?", *locationID).First(&loc).Error; err != nil {
+			return nil, "上课地点不存在"
+		}
+		return &loc, ""
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "请选择上课地点"
+	}
+	if len(name) > 100 {
+		return nil, "上课地点长度不能超过100字符"
+	}
+	if err := database.DB.Where("location_name = ?", name).FirstOrCreate(&loc, database.Location{LocationName: name}).Error; err != nil {
+		return nil, "登记上课地点失败"
+	}
+	return &loc, ""
+}
+
+// checkLocationAvailable 检查地点在该时间段是否已被占用、容量能否容纳计划的参训员工
+// 返回错误提示和冲突详情，可用时提示为空字符串
+func checkLocationAvailable(loc *database.Location, planID int64, slot scheduling.Slot, excludeItemID int64) (string, gin.H) {
+	bookings, err := scheduling.RoomBookings(loc.LocationID, slot, excludeItemID)
+	if err != nil {
+		return "检查地点占用失败", nil
+	}
+	if msg, detail := describeRoomBookings(loc, bookings); msg != "" {
+		return msg, detail
+	}
+
+	if loc.Capacity > 0 {
+		roster, err := scheduling.RosterSize(planID)
+		if err != nil {
+			return "查询参训人数失败", nil
+		}
+		return describeCapacity(loc, roster)
+	}
+	return "", nil
+}
+
+// describeRoomBookings 地点已被占用时的错误提示和冲突详情，没有占用时提示为空字符串
+func describeRoomBookings(loc *database.Location, bookings []scheduling.Booking) (string, gin.H) {
+	if len(bookings) == 0 {
+		return "", nil
+	}
+	return "地点冲突：" + loc.LocationName + "在该时间段已有其他课程安排", gin.H{
+		"locationId":   loc.LocationID,
+		"locationName": loc.LocationName,
+		"conflicts":    bookings,
+	}
+}
+
+// describeCapacity 地点容纳不下 roster 名参训员工时的错误提示和详情，容纳得下时提示为空字符串
+func describeCapacity(loc *database.Location, roster int64) (string, gin.H) {
+	if loc.Capacity == 0 || roster <= int64(loc.Capacity) {
+		return "", nil
+	}
+	return "容量不足：" + loc.LocationName + "最多容纳" + strconv.Itoa(loc.Capacity) + "人，该计划有" + strconv.FormatInt(roster, 10) + "名参训员工", gin.H{
+		"locationId":   loc.LocationID,
+		"locationName": loc.LocationName,
+		"capacity":     loc.Capacity,
+		"rosterSize":   roster,
+	}
+}
+
+// checkTeacherAvailable 检查讲师登记的可授课时间和不可授课时间段，返回错误提示和详情，可以授课时提示为空字符串
+func checkTeacherAvailable(teacherID int64, teacherName string, slot scheduling.Slot) (string, gin.H) {
+	unavailable, err := scheduling.TeacherUnavailability(teacherID, slot)
+	if err != nil {
+		return "检查讲师可授课时间失败", nil
+	}
+	return describeUnavailability(teacherID, teacherName, unavailable)
+}
+
+// describeUnavailability 讲师不能授课时的错误提示和详情，unavailable 为 nil 时提示为空字符串
+func describeUnavailability(teacherID int64, teacherName string, unavailable *scheduling.Unavailability) (string, gin.H) {
+	if unavailable == nil {
+		return "", nil
+	}
+	msg := "讲师不可授课：" + teacherName + "在该时间段不在登记的可授课时间内"
+	if unavailable.Type == scheduling.UnavailableBlackout {
+		b := unavailable.Blackout
+		msg = "讲师不可授课：" + teacherName + "在 " + b.StartDate + " " + b.BeginTime + " 至 " + b.EndDate + " " + b.EndTime + " 不可授课"
+		if b.Reason != "" {
+			msg += "（" + b.Reason + "）"
+		}
+	}
+	return msg, gin.H{
+		"teacherId":      teacherID,
+		"teacherName":    teacherName,
+		"unavailability": unavailable,
+	}
+}
+
+// calendarSite 工作日历中地点对应的楼宇或船舶，未指定地点时为空
+func calendarSite(loc *database.Location) string {
+	if loc == nil {
+		return ""
+	}
+	return loc.Building
+}
+
+// describeCalendarMark 非工作日的说明文字，如"2024-10-01 为国庆节（法定节假日）"
+func describeCalendarMark(date string, mark *scheduling.CalendarMark) string {
+	text := date + " 为" + mark.Name + "（" + scheduling.CalendarKindName(mark.Kind)
+	if mark.Site != "" {
+		text += "，" + mark.Site
+	}
+	return text + "）"
+}
+
+// checkWorkingDay 按工作日历检查上课日期在该地点是否为工作日
+// 策略为 block 时返回错误提示和详情；策略为 warn 时允许排课，返回提醒文字
+func checkWorkingDay(date string, loc *database.Location) (string, gin.H, string) {
+	mark, err := scheduling.CheckWorkingDay(date, calendarSite(loc))
+	if err != nil {
+		return "检查工作日历失败", nil, ""
+	}
+	return describeWorkingDay(date, mark)
+}
+
+// describeWorkingDay 按上课日期生效的非工作日条目（工作日为 nil）返回错误提示和详情，或提醒文字
+func describeWorkingDay(date string, mark *scheduling.CalendarMark) (string, gin.H, string) {
+	if mark == nil {
+		return "", nil, ""
+	}
+	if mark.Policy == scheduling.CalendarBlock {
+		return "非工作日：" + describeCalendarMark(date, mark) + "，不能排课", gin.H{
+			"date":     date,
+			"calendar": mark,
+		}, ""
+	}
+	return "", nil, describeCalendarMark(date, mark)
+}
+
+// checkEmployeeConflicts 检查计划的参训员工在该课程安排的时间段是否已有其他课程
+// 有冲突且未强制保存时返回错误提示；强制保存时冲突列表用于生成保存在课程安排上的警告
+func checkEmployeeConflicts(item scheduling.Booking, force bool) ([]scheduling.EmployeeConflict, string) {
+	conflicts, err := scheduling.EmployeeConflicts(item)
+	if err != nil {
+		return nil, "检查员工时间冲突失败"
+	}
+	if len(conflicts) > 0 && !force {
+		return conflicts, employeeConflictMessage(conflicts)
+	}
+	return conflicts, ""
+}
+
+// employeeConflictMessage 员工时间冲突的提示文字
+func employeeConflictMessage(conflicts []scheduling.EmployeeConflict) string {
+	persons := make(map[int64]bool)
+	for _, cf := range conflicts {
+		persons[cf.PersonID] = true
+	}
+	return "员工时间冲突：" + strconv.Itoa(len(persons)) + "名员工在该时间段已有其他课程安排，确认后可传 force=true 强制保存"
+}
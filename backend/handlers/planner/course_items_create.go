@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 	"backend/database"
+	"backend/scheduling"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,7 +18,9 @@ func CreateCourseItem(c *gin.Context) {
 		ClassDate      string `json:"classDate" binding:"required"`
 		ClassBeginTime string `json:"classBeginTime" binding:"required"`
 		ClassEndTime   string `json:"classEndTime" binding:"required"`
-		Location       string `json:"location" binding:"required"`
+		LocationID     *int64 `json:"locationId"`
+		Location       string `json:"location"` // 未传 locationId 时按名称匹配地点
+		Force          bool   `json:"force"`    // 员工时间冲突时仍然保存，冲突记录为警告
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -29,16 +32,6 @@ func CreateCourseItem(c *gin.Context) {
 		return
 	}
 
-	// 验证地点长度
-	if len(req.Location) > 100 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "上课地点长度不能超过100字符",
-			"data":    nil,
-		})
-		return
-	}
-
 	// 验证培训计划是否存在
 	var plan database.TrainingPlan
 	if err := database.DB.Where("plan_id = ?", req.PlanID).First(&plan).Error; err != nil {
@@ -61,29 +54,6 @@ func CreateCourseItem(c *gin.Context) {
 		return
 	}
 
-	// 检查讲师时间冲突
-	var conflictCount int64
-	database.DB.Model(&database.PlanCourseItem{}).
-		Joins("LEFT JOIN course ON plan_course_item.course_id = course.course_id").
-		Where("course.teacher_id = ?", course.TeacherID).
-		Where("plan_course_item.class_date = ?", req.ClassDate).
-		Where("(plan_course_item.class_begin_time < ? AND plan_course_item.class_end_time > ?) OR "+
-			"(plan_course_item.class_begin_time < ? AND plan_course_item.class_end_time > ?) OR "+
-			"(plan_course_item.class_begin_time >= ? AND plan_course_item.class_end_time <= ?)",
-			req.ClassEndTime, req.ClassBeginTime,
-			req.ClassEndTime, req.ClassBeginTime,
-			req.ClassBeginTime, req.ClassEndTime).
-		Count(&conflictCount)
-
-	if conflictCount > 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "时间冲突：讲师" + course.Teacher.Name + "在该时间段已有其他课程安排",
-			"data":    nil,
-		})
-		return
-	}
-
 	// 解析日期
 	classDate, err := time.Parse("2006-01-02", req.ClassDate)
 	if err != nil {
@@ -114,14 +84,120 @@ func CreateCourseItem(c *gin.Context) {
 		return
 	}
 
-	// 创建课程安排
-	item := database.PlanCourseItem{
-		PlanID:         req.PlanID,
-		CourseID:       req.CourseID,
-		ClassDate:      classDate,
+	if req.ClassBeginTime >= req.ClassEndTime {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "结束时间必须晚于开始时间",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 确定上课地点，上课时间按地点或计划的时区解释
+	loc, msg := resolveLocation(req.LocationID, req.Location)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": msg,
+			"data":    nil,
+		})
+		return
+	}
+	slot := scheduling.Slot{
+		Date:     req.ClassDate,
+		Begin:    req.ClassBeginTime,
+		End:      req.ClassEndTime,
+		Timezone: scheduling.ResolveTimezone(loc, plan),
+	}
+
+	// 检查讲师时间冲突（讲师在其他时区的课程按时刻比较）
+	teacherBookings, err := scheduling.TeacherBookings(course.TeacherID, slot, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "检查讲师时间冲突失败",
+			"data":    nil,
+		})
+		return
+	}
+	if len(teacherBookings) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "时间冲突：讲师" + course.Teacher.Name + "在该时间段已有其他课程安排",
+			"data": gin.H{
+				"conflicts": teacherBookings,
+			},
+		})
+		return
+	}
+
+	// 检查讲师登记的可授课时间和不可授课时间段
+	if msg, detail := checkTeacherAvailable(course.TeacherID, course.Teacher.Name, slot); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": msg,
+			"data":    detail,
+		})
+		return
+	}
+
+	// 按工作日历检查上课日期
+	msg, detail, calendarWarning := checkWorkingDay(req.ClassDate, loc)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": msg,
+			"data":    detail,
+		})
+		return
+	}
+
+	// 检查地点占用和容量
+	if msg, detail := checkLocationAvailable(loc, req.PlanID, slot, 0); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": msg,
+			"data":    detail,
+		})
+		return
+	}
+
+	// 检查参训员工时间冲突
+	conflicts, msg := checkEmployeeConflicts(scheduling.Booking{
+		PlanID:         plan.PlanID,
+		PlanName:       plan.PlanName,
+		CourseID:       course.CourseID,
+		CourseName:     course.CourseName,
+		TeacherName:    course.Teacher.Name,
+		ClassDate:      req.ClassDate,
 		ClassBeginTime: req.ClassBeginTime,
 		ClassEndTime:   req.ClassEndTime,
-		Location:       req.Location,
+		LocationID:     &loc.LocationID,
+		Location:       loc.LocationName,
+		Timezone:       slot.Timezone,
+	}, req.Force)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": msg,
+			"data": gin.H{
+				"conflicts": conflicts,
+			},
+		})
+		return
+	}
+
+	// 创建课程安排
+	item := database.PlanCourseItem{
+		PlanID:          req.PlanID,
+		CourseID:        req.CourseID,
+		ClassDate:       classDate,
+		ClassBeginTime:  req.ClassBeginTime,
+		ClassEndTime:    req.ClassEndTime,
+		Location:        loc.LocationName,
+		LocationID:      &loc.LocationID,
+		ConflictWarning: scheduling.FormatWarning(conflicts),
+		Timezone:        slot.Timezone,
 	}
 
 	if err := database.DB.Create(&item).Error; err != nil {
@@ -138,15 +214,20 @@ func CreateCourseItem(c *gin.Context) {
 		"code":    200,
 		"message": "创建成功",
 		"data": gin.H{
-			"itemId":         item.ItemID,
-			"planId":         item.PlanID,
-			"planName":       plan.PlanName,
-			"courseId":       item.CourseID,
-			"courseName":     course.CourseName,
-			"classDate":      item.ClassDate,
-			"classBeginTime": item.ClassBeginTime,
-			"classEndTime":   item.ClassEndTime,
-			"location":       item.Location,
+			"itemId":          item.ItemID,
+			"planId":          item.PlanID,
+			"planName":        plan.PlanName,
+			"courseId":        item.CourseID,
+			"courseName":      course.CourseName,
+			"classDate":       item.ClassDate,
+			"classBeginTime":  item.ClassBeginTime,
+			"classEndTime":    item.ClassEndTime,
+			"location":        item.Location,
+			"locationId":      item.LocationID,
+			"timezone":        scheduling.TimezoneName(item.Timezone),
+			"conflictWarning": item.ConflictWarning,
+			"conflicts":       conflicts,
+			"calendarWarning": calendarWarning,
 		},
 	})
 }
@@ -4,8 +4,10 @@ import (
 	"net/http"
 	"strconv"
 	"backend/database"
+	"backend/scheduling"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // DeleteCourseItem 删除课程安排（接口5.15）
@@ -26,6 +28,17 @@ func DeleteCourseItem(c *gin.Context) {
 	forceStr := c.Query("force")
 	force := forceStr == "true"
 
+	// 获取查询参数 scope（重复课程的删除范围）
+	scope := c.DefaultQuery("scope", seriesScopeThis)
+	if scope != seriesScopeThis && scope != seriesScopeFollowing && scope != seriesScopeAll {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的删除范围，可选值：this、following、all",
+			"data":    nil,
+		})
+		return
+	}
+
 	// 验证课程安排是否存在
 	var item database.PlanCourseItem
 	if err := database.DB.Where("item_id = ?", itemId).First(&item).Error; err != nil {
@@ -37,6 +50,12 @@ func DeleteCourseItem(c *gin.Context) {
 		return
 	}
 
+	// 删除重复课程中的多次上课
+	if scope != seriesScopeThis {
+		deleteSeriesItems(c, item, scope, force)
+		return
+	}
+
 	// 检查是否有评价记录
 	var evaluationCount int64
 	database.DB.Model(&database.AttendanceEvaluation{}).
@@ -60,8 +79,14 @@ func DeleteCourseItem(c *gin.Context) {
 		database.DB.Where("item_id = ?", itemId).Delete(&database.AttendanceEvaluation{})
 	}
 
-	// 删除课程安排
-	if err := database.DB.Delete(&item).Error; err != nil {
+	// 删除课程安排（保存快照，日历订阅据此取消事件）
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := scheduling.RecordCancellations(tx, []database.PlanCourseItem{item}); err != nil {
+			return err
+		}
+		return tx.Delete(&item).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
 			"message": "删除课程安排失败",
@@ -70,6 +95,11 @@ func DeleteCourseItem(c *gin.Context) {
 		return
 	}
 
+	// 重复课程中单独删除的日期记入系列的排除日期
+	if item.SeriesID != nil {
+		trimSeries(database.DB, *item.SeriesID, item.ClassDate, seriesScopeThis)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "删除成功",
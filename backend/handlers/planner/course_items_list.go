@@ -3,7 +3,9 @@ package planner
 import (
 	"net/http"
 	"strconv"
+	"time"
 	"backend/database"
+	"backend/scheduling"
 
 	"github.com/gin-gonic/gin"
 
@@ -32,6 +34,21 @@ func GetCourseItemsList(c *gin.Context) {
 		pageSize = 20
 	}
 
+	// 日期筛选条件按 YYYY-MM-DD 解析
+	for _, date := range []string{startDate, endDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "日期格式错误，应为 YYYY-MM-DD",
+				"data":    nil,
+			})
+			return
+		}
+	}
+
 	// 构建查询
 	query := database.DB.Model(&database.PlanCourseItem{}).
 		Joins("LEFT JOIN training_plan ON plan_course_item.plan_id = training_plan.plan_id").
@@ -54,12 +71,28 @@ func GetCourseItemsList(c *gin.Context) {
 		}
 	}
 
+	// 筛选条件：上课地点
+	if locationIdStr := c.Query("locationId"); locationIdStr != "" {
+		locationId, err := strconv.ParseInt(locationIdStr, 10, 64)
+		if err == nil {
+			query = query.Where("plan_course_item.location_id = ?", locationId)
+		}
+	}
+
+	// 筛选条件：重复课程
+	if seriesIdStr := c.Query("seriesId"); seriesIdStr != "" {
+		seriesId, err := strconv.ParseInt(seriesIdStr, 10, 64)
+		if err == nil {
+			query = query.Where("plan_course_item.series_id = ?", seriesId)
+		}
+	}
+
 	// 筛选条件：日期范围
 	if startDate != "" {
-		query = query.Where("plan_course_item.class_date >= ?", startDate)
+		query = query.Where(database.DateFrom("plan_course_item.class_date", startDate))
 	}
 	if endDate != "" {
-		query = query.Where("plan_course_item.class_date <= ?", endDate)
+		query = query.Where(database.DateUntil("plan_course_item.class_date", endDate))
 	}
 
 	// 查询总数
@@ -94,35 +127,48 @@ func GetCourseItemsList(c *gin.Context) {
 
 	// 构建响应数据
 	type ItemResponse struct {
-		ItemID         int64  `json:"itemId"`
-		PlanID         int64  `json:"planId"`
-		PlanName       string `json:"planName"`
-		CourseID       int64  `json:"courseId"`
-		CourseName     string `json:"courseName"`
-		CourseClass    string `json:"courseClass"`
-		TeacherID      int64  `json:"teacherId"`
-		TeacherName    string `json:"teacherName"`
-		ClassDate      string `json:"classDate"`
-		ClassBeginTime string `json:"classBeginTime"`
-		ClassEndTime   string `json:"classEndTime"`
-		Location       string `json:"location"`
+		ItemID          int64  `json:"itemId"`
+		PlanID          int64  `json:"planId"`
+		PlanName        string `json:"planName"`
+		CourseID        int64  `json:"courseId"`
+		CourseName      string `json:"courseName"`
+		CourseClass     string `json:"courseClass"`
+		TeacherID       int64  `json:"teacherId"`
+		TeacherName     string `json:"teacherName"`
+		ClassDate       string `json:"classDate"`
+		ClassBeginTime  string `json:"classBeginTime"`
+		ClassEndTime    string `json:"classEndTime"`
+		Timezone        string `json:"timezone"` // 上课日期和时间所在的时区
+		StartsAt        string `json:"startsAt"` // 开始时刻（RFC 3339，带时差）
+		EndsAt          string `json:"endsAt"`
+		Location        string `json:"location"`
+		LocationID      *int64 `json:"locationId"`
+		ConflictWarning string `json:"conflictWarning"`
+		SeriesID        *int64 `json:"seriesId"`
 	}
 
 	list := make([]ItemResponse, 0, len(items))
 	for _, item := range items {
+		startsAt, endsAt := scheduling.ItemInterval(item)
 		list = append(list, ItemResponse{
-			ItemID:         item.ItemID,
-			PlanID:         item.PlanID,
-			PlanName:       item.Plan.PlanName,
-			CourseID:       item.CourseID,
-			CourseName:     item.Course.CourseName,
-			CourseClass:    item.Course.CourseClass,
-			TeacherID:      item.Course.TeacherID,
-			TeacherName:    item.Course.Teacher.Name,
-			ClassDate:      item.ClassDate.Format("2006-01-02"),
-			ClassBeginTime: item.ClassBeginTime,
-			ClassEndTime:   item.ClassEndTime,
-			Location:       item.Location,
+			ItemID:          item.ItemID,
+			PlanID:          item.PlanID,
+			PlanName:        item.Plan.PlanName,
+			CourseID:        item.CourseID,
+			CourseName:      item.Course.CourseName,
+			CourseClass:     item.Course.CourseClass,
+			TeacherID:       item.Course.TeacherID,
+			TeacherName:     item.Course.Teacher.Name,
+			ClassDate:       item.ClassDate.Format("2006-01-02"),
+			ClassBeginTime:  item.ClassBeginTime,
+			ClassEndTime:    item.ClassEndTime,
+			Timezone:        scheduling.TimezoneName(item.Timezone),
+			StartsAt:        startsAt.Format(time.RFC3339),
+			EndsAt:          endsAt.Format(time.RFC3339),
+			Location:        item.Location,
+			LocationID:      item.LocationID,
+			ConflictWarning: item.ConflictWarning,
+			SeriesID:        item.SeriesID,
 		})
 	}
 
@@ -0,0 +1,394 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/scheduling"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 修改或删除重复课程中的某次上课时的影响范围
+const (
+	seriesScopeThis      = "this"      // 仅本次
+	seriesScopeFollowing = "following" // 本次及以后
+	seriesScopeAll       = "all"       // 整个系列
+)
+
+// occurrenceConflict 重复课程中某一次上课的冲突
+type occurrenceConflict struct {
+	ClassDate string `json:"classDate"`
+	Message   string `json:"message"`
+	Detail    gin.H  `json:"detail"`
+}
+
+// checkOccurrence 按 occ 中已读取的数据检查一次上课的讲师时间冲突和可授课时间、工作日历（策略为 block 的非工作日）、
+// 地点和参训员工时间冲突，occ 应包含该讲师、地点和上课日期
+// 返回冲突（无冲突时为 nil）和强制保存时需要记录的员工冲突警告
+func checkOccurrence(occ *scheduling.Occupancy, teacherID int64, loc *database.Location, booking scheduling.Booking, force bool) (*occurrenceConflict, string) {
+	slot := scheduling.Slot{Date: booking.ClassDate, Begin: booking.ClassBeginTime, End: booking.ClassEndTime, Timezone: booking.Timezone}
+	conflict := func(message string, detail gin.H) *occurrenceConflict {
+		return &occurrenceConflict{ClassDate: booking.ClassDate, Message: message, Detail: detail}
+	}
+
+	if teacherBookings := occ.TeacherBookings(teacherID, slot, booking.ItemID); len(teacherBookings) > 0 {
+		return conflict("时间冲突：讲师"+booking.TeacherName+"在该时间段已有其他课程安排", gin.H{
+			"conflicts": teacherBookings,
+		}), ""
+	}
+	unavailable, err := occ.TeacherUnavailability(teacherID, slot)
+	if err != nil {
+		return conflict("检查讲师可授课时间失败", nil), ""
+	}
+	if msg, detail := describeUnavailability(teacherID, booking.TeacherName, unavailable); msg != "" {
+		return conflict(msg, detail), ""
+	}
+	if msg, detail, _ := describeWorkingDay(booking.ClassDate, occ.WorkingDay(booking.ClassDate, calendarSite(loc))); msg != "" {
+		return conflict(msg, detail), ""
+	}
+
+	if loc != nil {
+		if msg, detail := describeRoomBookings(loc, occ.RoomBookings(loc.LocationID, slot, booking.ItemID)); msg != "" {
+			return conflict(msg, detail), ""
+		}
+		if msg, detail := describeCapacity(loc, occ.RosterSize()); msg != "" {
+			return conflict(msg, detail), ""
+		}
+	}
+
+	conflicts := occ.EmployeeConflicts(booking)
+	if len(conflicts) > 0 && !force {
+		return conflict(employeeConflictMessage(conflicts), gin.H{"conflicts": conflicts}), ""
+	}
+	return nil, scheduling.FormatWarning(conflicts)
+}
+
+// splitDates 将逗号分隔的日期转换为数组
+func splitDates(dates string) []string {
+	if dates == "" {
+		return []string{}
+	}
+	return strings.Split(dates, ",")
+}
+
+// joinDates 将日期排序后以逗号连接
+func joinDates(dates []string) string {
+	sort.Strings(dates)
+	return strings.Join(dates, ",")
+}
+
+// formatSeries 格式化重复课程系列及其课程安排
+func formatSeries(series database.CourseItemSeries, items []database.PlanCourseItem) gin.H {
+	list := make([]gin.H, 0, len(items))
+	for _, item := range items {
+		list = append(list, gin.H{
+			"itemId":          item.ItemID,
+			"classDate":       item.ClassDate.Format("2006-01-02"),
+			"classBeginTime":  item.ClassBeginTime,
+			"classEndTime":    item.ClassEndTime,
+			"location":        item.Location,
+			"locationId":      item.LocationID,
+			"timezone":        scheduling.TimezoneName(item.Timezone),
+			"conflictWarning": item.ConflictWarning,
+		})
+	}
+	return gin.H{
+		"seriesId":       series.SeriesID,
+		"planId":         series.PlanID,
+		"courseId":       series.CourseID,
+		"rrule":          series.RRule,
+		"startDate":      series.StartDate.Format("2006-01-02"),
+		"classBeginTime": series.ClassBeginTime,
+		"classEndTime":   series.ClassEndTime,
+		"location":       series.Location,
+		"locationId":     series.LocationID,
+		"excludeDates":   splitDates(series.ExcludeDates),
+		"itemCount":      len(items),
+		"items":          list,
+	}
+}
+
+// seriesItems 查询系列中的课程安排（按日期排序）
+func seriesItems(seriesID int64) ([]database.PlanCourseItem, error) {
+	var items []database.PlanCourseItem
+	err := database.DB.Where("series_id = ?", seriesID).
+		Order("class_date, class_begin_time").
+		Find(&items).Error
+	return items, err
+}
+
+// CreateCourseItemSeries 创建重复课程（接口5.38）
+// 按 RRULE 展开为多条课程安排，任意一次上课有冲突时整个系列都不创建
+func CreateCourseItemSeries(c *gin.Context) {
+	// 解析请求体
+	var req struct {
+		PlanID         int64    `json:"planId" binding:"required"`
+		CourseID       int64    `json:"courseId" binding:"required"`
+		StartDate      string   `json:"startDate" binding:"required"`
+		RRule          string   `json:"rrule" binding:"required"`
+		ClassBeginTime string   `json:"classBeginTime" binding:"required"`
+		ClassEndTime   string   `json:"classEndTime" binding:"required"`
+		LocationID     *int64   `json:"locationId"`
+		Location       string   `json:"location"`
+		ExcludeDates   []string `json:"excludeDates"` // 不上课的日期，如节假日
+		Force          bool     `json:"force"`        // 员工时间冲突时仍然保存，冲突记录为警告
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证培训计划是否存在
+	var plan database.TrainingPlan
+	if err := database.DB.Where("plan_id = ?", req.PlanID).First(&plan).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "培训计划不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证课程是否存在并获取讲师信息
+	var course database.Course
+	if err := database.DB.Preload("Teacher").Where("course_id = ?", req.CourseID).First(&course).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "课程不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证日期和时间格式
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "开始日期格式错误，请使用 YYYY-MM-DD 格式",
+			"data":    nil,
+		})
+		return
+	}
+	_, beginErr := time.Parse("15:04:05", req.ClassBeginTime)
+	_, endErr := time.Parse("15:04:05", req.ClassEndTime)
+	if beginErr != nil || endErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "时间格式错误，请使用 HH:mm:ss 格式",
+			"data":    nil,
+		})
+		return
+	}
+	if req.ClassBeginTime >= req.ClassEndTime {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "结束时间必须晚于开始时间",
+			"data":    nil,
+		})
+		return
+	}
+
+	if len(req.ExcludeDates) > 300 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "排除日期不能超过300个",
+			"data":    nil,
+		})
+		return
+	}
+	exclude := make(map[string]bool)
+	for _, d := range req.ExcludeDates {
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "排除日期格式错误：" + d,
+				"data":    nil,
+			})
+			return
+		}
+		exclude[d] = true
+	}
+
+	// 解析重复规则并展开上课日期
+	rule, err := scheduling.ParseRRule(req.RRule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	dates, err := rule.Expand(startDate, exclude)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	if len(dates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "重复规则没有展开出任何上课日期",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 确定上课地点
+	loc, msg := resolveLocation(req.LocationID, req.Location)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": msg,
+			"data":    nil,
+		})
+		return
+	}
+
+	// 一次读取整个系列日期范围内的占用情况和工作日历，之后逐次在内存中检查
+	occ, err := scheduling.LoadOccupancy(plan.PlanID, []int64{course.TeacherID}, []int64{loc.LocationID},
+		dates[0].Format("2006-01-02"), dates[len(dates)-1].Format("2006-01-02"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "检查冲突失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 按工作日历跳过禁止排课的非工作日（记入排除日期），提醒的非工作日照常上课
+	calendarSkipped := []string{}
+	calendarWarnings := []string{}
+	workingDates := make([]time.Time, 0, len(dates))
+	for _, date := range dates {
+		day := date.Format("2006-01-02")
+		mark := occ.WorkingDay(day, loc.Building)
+		if mark != nil && mark.Policy == scheduling.CalendarBlock {
+			exclude[day] = true
+			calendarSkipped = append(calendarSkipped, describeCalendarMark(day, mark))
+			continue
+		}
+		if mark != nil {
+			calendarWarnings = append(calendarWarnings, describeCalendarMark(day, mark))
+		}
+		workingDates = append(workingDates, date)
+	}
+	if len(workingDates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "重复规则展开的上课日期都是禁止排课的非工作日",
+			"data": gin.H{
+				"calendarSkipped": calendarSkipped,
+			},
+		})
+		return
+	}
+	dates = workingDates
+
+	// 逐次检查冲突，上课时间按地点或计划的时区解释
+	timezone := scheduling.ResolveTimezone(loc, plan)
+	conflicts := make([]occurrenceConflict, 0)
+	items := make([]database.PlanCourseItem, 0, len(dates))
+	for _, date := range dates {
+		booking := scheduling.Booking{
+			PlanID:         plan.PlanID,
+			PlanName:       plan.PlanName,
+			CourseID:       course.CourseID,
+			CourseName:     course.CourseName,
+			TeacherName:    course.Teacher.Name,
+			ClassDate:      date.Format("2006-01-02"),
+			ClassBeginTime: req.ClassBeginTime,
+			ClassEndTime:   req.ClassEndTime,
+			LocationID:     &loc.LocationID,
+			Location:       loc.LocationName,
+			Timezone:       timezone,
+		}
+		conflict, warning := checkOccurrence(occ, course.TeacherID, loc, booking, req.Force)
+		if conflict != nil {
+			conflicts = append(conflicts, *conflict)
+			continue
+		}
+		items = append(items, database.PlanCourseItem{
+			PlanID:          req.PlanID,
+			CourseID:        req.CourseID,
+			ClassDate:       date,
+			ClassBeginTime:  req.ClassBeginTime,
+			ClassEndTime:    req.ClassEndTime,
+			Location:        loc.LocationName,
+			LocationID:      &loc.LocationID,
+			ConflictWarning: warning,
+			Timezone:        timezone,
+		})
+	}
+	if len(conflicts) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "共" + strconv.Itoa(len(dates)) + "次上课，其中" + strconv.Itoa(len(conflicts)) + "次存在冲突，重复课程未创建",
+			"data": gin.H{
+				"occurrenceCount": len(dates),
+				"conflicts":       conflicts,
+			},
+		})
+		return
+	}
+
+	// 创建系列和全部课程安排
+	excludeDates := make([]string, 0, len(exclude))
+	for d := range exclude {
+		excludeDates = append(excludeDates, d)
+	}
+	series := database.CourseItemSeries{
+		PlanID:         req.PlanID,
+		CourseID:       req.CourseID,
+		RRule:          rule.String(),
+		StartDate:      startDate,
+		ClassBeginTime: req.ClassBeginTime,
+		ClassEndTime:   req.ClassEndTime,
+		LocationID:     &loc.LocationID,
+		Location:       loc.LocationName,
+		ExcludeDates:   joinDates(excludeDates),
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&series).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].SeriesID = &series.SeriesID
+		}
+		return tx.Create(&items).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "创建重复课程失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	data := formatSeries(series, items)
+	data["planName"] = plan.PlanName
+	data["courseName"] = course.CourseName
+	data["calendarSkipped"] = calendarSkipped
+	data["calendarWarnings"] = calendarWarnings
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "创建成功",
+		"data":    data,
+	})
+}
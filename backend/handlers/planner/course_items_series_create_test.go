@@ -0,0 +1,55 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"backend/scheduling"
+	"testing"
+	"time"
+)
+
+func TestCreateCourseItemSeriesChecksEachOccurrence(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", "2030-03-04", "2030-03-29", dbtest.EmployeeID)
+	other := dbtest.CreatePlan(t, "消防演练", "2030-03-04", "2030-03-29", dbtest.EmployeeID)
+	fire := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	aid := dbtest.CreateCourse(t, "急救", "安全", dbtest.Teacher2ID)
+
+	// 第二个周一停工，第三个周一员工参加另一个计划
+	shutdown, _ := time.Parse("2006-01-02", "2030-03-11")
+	if err := database.DB.Create(&database.CalendarEntry{Kind: scheduling.CalendarShutdown, Name: "年度检修", StartDate: shutdown, EndDate: shutdown, Policy: scheduling.CalendarBlock}).Error; err != nil {
+		t.Fatal(err)
+	}
+	dbtest.CreateItem(t, other, aid, "2030-03-18", "09:30:00", "10:30:00", "")
+
+	body := map[string]interface{}{
+		"planId": plan.PlanID, "courseId": fire.CourseID, "startDate": "2030-03-04", "rrule": "FREQ=WEEKLY;BYDAY=MO;COUNT=4",
+		"classBeginTime": "09:00:00", "classEndTime": "10:00:00", "location": "培训楼A101", "force": true,
+	}
+	var data struct {
+		ExcludeDates    []string `json:"excludeDates"`
+		CalendarSkipped []string `json:"calendarSkipped"`
+		Items           []struct {
+			ClassDate       string `json:"classDate"`
+			ConflictWarning string `json:"conflictWarning"`
+		} `json:"items"`
+	}
+	dbtest.Post(t, CreateCourseItemSeries, dbtest.PlannerID, "课程大纲制定者", "/planner/course-item-series", body).Decode(t, &data)
+
+	if len(data.CalendarSkipped) != 1 || len(data.ExcludeDates) != 1 || data.ExcludeDates[0] != "2030-03-11" {
+		t.Fatalf("停工日应跳过并记入排除日期，实际 %+v", data)
+	}
+	want := []string{"2030-03-04", "2030-03-18", "2030-03-25"}
+	if len(data.Items) != len(want) {
+		t.Fatalf("应创建 %d 次上课，实际 %+v", len(want), data.Items)
+	}
+	for i, item := range data.Items {
+		if item.ClassDate != want[i] {
+			t.Errorf("第 %d 次上课应为 %s，实际 %s", i, want[i], item.ClassDate)
+		}
+		if hasWarning := item.ConflictWarning != ""; hasWarning != (item.ClassDate == "2030-03-18") {
+			t.Errorf("%s 的员工冲突警告为 %q", item.ClassDate, item.ConflictWarning)
+		}
+	}
+}
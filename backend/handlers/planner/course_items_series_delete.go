@@ -0,0 +1,121 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/scheduling"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// trimSeries 系列中的课程安排被删除后调整系列：没有剩余课程时删除系列
+// 否则把被删除的日期记入排除日期，或在删除本次及以后时把重复规则截止到剩余的最后一次上课
+func trimSeries(tx *gorm.DB, seriesID int64, deletedDate time.Time, scope string) error {
+	var series database.CourseItemSeries
+	if err := tx.Where("series_id = ?", seriesID).First(&series).Error; err != nil {
+		return nil
+	}
+
+	var remaining int64
+	if err := tx.Model(&database.PlanCourseItem{}).Where("series_id = ?", seriesID).Count(&remaining).Error; err != nil {
+		return err
+	}
+	if remaining == 0 {
+		return tx.Delete(&series).Error
+	}
+
+	if scope == seriesScopeFollowing {
+		last, ok := lastSeriesDateBefore(tx, seriesID, deletedDate)
+		if !ok {
+			return nil
+		}
+		rule, err := scheduling.ParseRRule(series.RRule)
+		if err != nil {
+			return err
+		}
+		series.RRule = rule.EndingOn(last).String()
+	} else {
+		date := deletedDate.Format("2006-01-02")
+		dates := splitDates(series.ExcludeDates)
+		for _, d := range dates {
+			if d == date {
+				return nil
+			}
+		}
+		series.ExcludeDates = joinDates(append(dates, date))
+	}
+	return tx.Save(&series).Error
+}
+
+// deleteSeriesItems 删除重复课程中本次及以后（following）或全部（all）的上课
+func deleteSeriesItems(c *gin.Context, item database.PlanCourseItem, scope string, force bool) {
+	if item.SeriesID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "该课程安排不属于重复课程，只能删除本次",
+			"data":    nil,
+		})
+		return
+	}
+
+	targets, err := seriesScopeItems(item, scope)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询课程安排失败",
+			"data":    nil,
+		})
+		return
+	}
+	itemIDs := make([]int64, 0, len(targets))
+	for _, t := range targets {
+		itemIDs = append(itemIDs, t.ItemID)
+	}
+
+	// 检查是否有评价记录
+	var evaluationCount int64
+	database.DB.Model(&database.AttendanceEvaluation{}).
+		Where("item_id IN ?", itemIDs).
+		Count(&evaluationCount)
+	if evaluationCount > 0 && !force {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "要删除的课程安排已有员工评价记录，建议不要删除。如需强制删除，请添加force=true参数",
+			"data": gin.H{
+				"evaluationCount": evaluationCount,
+			},
+		})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("item_id IN ?", itemIDs).Delete(&database.AttendanceEvaluation{}).Error; err != nil {
+			return err
+		}
+		if err := scheduling.RecordCancellations(tx, targets); err != nil {
+			return err
+		}
+		if err := tx.Where("item_id IN ?", itemIDs).Delete(&database.PlanCourseItem{}).Error; err != nil {
+			return err
+		}
+		return trimSeries(tx, *item.SeriesID, item.ClassDate, scope)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "删除课程安排失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "删除成功",
+		"data": gin.H{
+			"deletedCount": len(targets),
+		},
+	})
+}
@@ -0,0 +1,58 @@
+package planner
+
+import (
+	"backend/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCourseItemSeries 获取重复课程详情（接口5.39）
+func GetCourseItemSeries(c *gin.Context) {
+	// 获取路径参数 seriesId
+	seriesIdStr := c.Param("seriesId")
+	seriesId, err := strconv.ParseInt(seriesIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的重复课程ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var series database.CourseItemSeries
+	if err := database.DB.Where("series_id = ?", seriesId).First(&series).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "重复课程不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	items, err := seriesItems(seriesId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询课程安排失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	var plan database.TrainingPlan
+	database.DB.Where("plan_id = ?", series.PlanID).First(&plan)
+	var course database.Course
+	database.DB.Where("course_id = ?", series.CourseID).First(&course)
+
+	data := formatSeries(series, items)
+	data["planName"] = plan.PlanName
+	data["courseName"] = course.CourseName
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    data,
+	})
+}
@@ -0,0 +1,296 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/scheduling"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// seriesItemsChange 同时修改重复课程中多次上课时可修改的字段
+type seriesItemsChange struct {
+	ClassBeginTime *string
+	ClassEndTime   *string
+	LocationID     *int64
+	Location       *string
+	Force          bool
+}
+
+// seriesScopeItems 按范围查询要修改或删除的课程安排：本次及以后，或整个系列
+func seriesScopeItems(item database.PlanCourseItem, scope string) ([]database.PlanCourseItem, error) {
+	query := database.DB.Where("series_id = ?", *item.SeriesID)
+	if scope == seriesScopeFollowing {
+		query = query.Where(database.DateFrom("class_date", item.ClassDate.Format("2006-01-02")))
+	}
+	var items []database.PlanCourseItem
+	err := query.Order("class_date, class_begin_time").Find(&items).Error
+	return items, err
+}
+
+// lastSeriesDateBefore 系列中指定日期之前最后一次上课的日期，没有时返回 false
+func lastSeriesDateBefore(tx *gorm.DB, seriesID int64, date time.Time) (time.Time, bool) {
+	var prev database.PlanCourseItem
+	err := tx.Where("series_id = ?", seriesID).
+		Where(database.DateBefore("class_date", date.Format("2006-01-02"))).
+		Order("class_date DESC").
+		First(&prev).Error
+	if err != nil {
+		return time.Time{}, false
+	}
+	return prev.ClassDate, true
+}
+
+// updateSeriesItems 修改重复课程中本次及以后（following）或全部（all）的上课时间和地点
+// 修改本次及以后时，原系列截止到前一次上课，后面的上课拆分为新的系列
+func updateSeriesItems(c *gin.Context, item database.PlanCourseItem, scope string, change seriesItemsChange) {
+	if item.SeriesID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "该课程安排不属于重复课程，只能修改本次",
+			"data":    nil,
+		})
+		return
+	}
+	var series database.CourseItemSeries
+	if err := database.DB.Where("series_id = ?", *item.SeriesID).First(&series).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "重复课程不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证时间格式
+	for _, t := range []*string{change.ClassBeginTime, change.ClassEndTime} {
+		if t == nil {
+			continue
+		}
+		if _, err := time.Parse("15:04:05", *t); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "时间格式错误，请使用 HH:mm:ss 格式",
+				"data":    nil,
+			})
+			return
+		}
+	}
+
+	// 新的上课地点（locationId 优先，其次按名称匹配）
+	var newLoc *database.Location
+	if change.LocationID != nil || change.Location != nil {
+		name := ""
+		if change.Location != nil {
+			name = *change.Location
+		}
+		var msg string
+		newLoc, msg = resolveLocation(change.LocationID, name)
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": msg,
+				"data":    nil,
+			})
+			return
+		}
+	}
+
+	if change.ClassBeginTime == nil && change.ClassEndTime == nil && newLoc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "没有提供更新内容，修改多次上课时只能修改上课时间和地点",
+			"data":    nil,
+		})
+		return
+	}
+
+	targets, err := seriesScopeItems(item, scope)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询课程安排失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 确定每次上课修改后的时间和地点
+	locations := make(map[int64]*database.Location)
+	targetLocs := make([]*database.Location, len(targets))
+	var locationIDs []int64
+	for i := range targets {
+		t := &targets[i]
+		if change.ClassBeginTime != nil {
+			t.ClassBeginTime = *change.ClassBeginTime
+		}
+		if change.ClassEndTime != nil {
+			t.ClassEndTime = *change.ClassEndTime
+		}
+		if t.ClassBeginTime >= t.ClassEndTime {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "结束时间必须晚于开始时间",
+				"data":    nil,
+			})
+			return
+		}
+
+		loc := newLoc
+		if loc == nil && t.LocationID != nil {
+			if cached, ok := locations[*t.LocationID]; ok {
+				loc = cached
+			} else {
+				loc = &database.Location{}
+				if err := database.DB.Where("location_id = ?", *t.LocationID).First(loc).Error; err != nil {
+					loc = nil
+				} else {
+					locationIDs = append(locationIDs, loc.LocationID)
+				}
+				locations[*t.LocationID] = loc
+			}
+		}
+		if newLoc != nil {
+			t.LocationID = &newLoc.LocationID
+			t.Location = newLoc.LocationName
+			t.Timezone = scheduling.ResolveTimezone(newLoc, item.Plan)
+		}
+		targetLocs[i] = loc
+	}
+	if newLoc != nil {
+		locationIDs = []int64{newLoc.LocationID}
+	}
+
+	// 一次读取修改范围内的占用情况，之后逐次在内存中检查冲突
+	var occ *scheduling.Occupancy
+	if len(targets) > 0 {
+		occ, err = scheduling.LoadOccupancy(item.PlanID, []int64{item.Course.TeacherID}, locationIDs,
+			targets[0].ClassDate.Format("2006-01-02"), targets[len(targets)-1].ClassDate.Format("2006-01-02"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    500,
+				"message": "检查冲突失败",
+				"data":    nil,
+			})
+			return
+		}
+	}
+	conflicts := make([]occurrenceConflict, 0)
+	for i := range targets {
+		t := &targets[i]
+		booking := scheduling.Booking{
+			ItemID:         t.ItemID,
+			PlanID:         t.PlanID,
+			PlanName:       item.Plan.PlanName,
+			CourseID:       t.CourseID,
+			CourseName:     item.Course.CourseName,
+			TeacherName:    item.Course.Teacher.Name,
+			ClassDate:      t.ClassDate.Format("2006-01-02"),
+			ClassBeginTime: t.ClassBeginTime,
+			ClassEndTime:   t.ClassEndTime,
+			LocationID:     t.LocationID,
+			Location:       t.Location,
+			Timezone:       t.Timezone,
+		}
+		conflict, warning := checkOccurrence(occ, item.Course.TeacherID, targetLocs[i], booking, change.Force)
+		if conflict != nil {
+			conflicts = append(conflicts, *conflict)
+			continue
+		}
+		t.ConflictWarning = warning
+	}
+	if len(conflicts) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "共" + strconv.Itoa(len(targets)) + "次上课，其中" + strconv.Itoa(len(conflicts)) + "次存在冲突，未做修改",
+			"data": gin.H{
+				"occurrenceCount": len(targets),
+				"conflicts":       conflicts,
+			},
+		})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		applyChange := func(s *database.CourseItemSeries) {
+			if change.ClassBeginTime != nil {
+				s.ClassBeginTime = *change.ClassBeginTime
+			}
+			if change.ClassEndTime != nil {
+				s.ClassEndTime = *change.ClassEndTime
+			}
+			if newLoc != nil {
+				s.LocationID = &newLoc.LocationID
+				s.Location = newLoc.LocationName
+			}
+		}
+
+		prevDate, hasPrev := lastSeriesDateBefore(tx, series.SeriesID, item.ClassDate)
+		if scope == seriesScopeFollowing && hasPrev {
+			// 拆分系列：原系列截止到前一次上课，本次及以后归入新系列
+			rule, err := scheduling.ParseRRule(series.RRule)
+			if err != nil {
+				return err
+			}
+			next := series
+			next.SeriesID = 0
+			next.StartDate = item.ClassDate
+			next.RRule = rule.EndingOn(targets[len(targets)-1].ClassDate).String()
+			next.CreatedAt = time.Time{}
+			applyChange(&next)
+
+			series.RRule = rule.EndingOn(prevDate).String()
+			if err := tx.Save(&series).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&next).Error; err != nil {
+				return err
+			}
+			series = next
+		} else {
+			applyChange(&series)
+			if err := tx.Save(&series).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, t := range targets {
+			err := tx.Model(&database.PlanCourseItem{}).Where("item_id = ?", t.ItemID).Updates(map[string]interface{}{
+				"class_begin_time": t.ClassBeginTime,
+				"class_end_time":   t.ClassEndTime,
+				"location":         t.Location,
+				"location_id":      t.LocationID,
+				"timezone":         t.Timezone,
+				"conflict_warning": t.ConflictWarning,
+				"series_id":        series.SeriesID,
+				"sequence":         gorm.Expr("sequence + 1"),
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "更新课程安排失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	items, _ := seriesItems(series.SeriesID)
+	data := formatSeries(series, items)
+	data["planName"] = item.Plan.PlanName
+	data["courseName"] = item.Course.CourseName
+	data["updatedCount"] = len(targets)
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "修改成功",
+		"data":    data,
+	})
+}
@@ -5,8 +5,10 @@ import (
 	"strconv"
 	"time"
 	"backend/database"
+	"backend/scheduling"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // UpdateCourseItem 修改课程安排（接口5.14）
@@ -25,7 +27,7 @@ func UpdateCourseItem(c *gin.Context) {
 
 	// 验证课程安排是否存在
 	var item database.PlanCourseItem
-	if err := database.DB.Where("item_id = ?", itemId).First(&item).Error; err != nil {
+	if err := database.DB.Preload("Plan").Preload("Course.Teacher").Where("item_id = ?", itemId).First(&item).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"code":    404,
 			"message": "课程安排不存在",
@@ -39,7 +41,10 @@ func UpdateCourseItem(c *gin.Context) {
 		ClassDate      *string `json:"classDate"`
 		ClassBeginTime *string `json:"classBeginTime"`
 		ClassEndTime   *string `json:"classEndTime"`
+		LocationID     *int64  `json:"locationId"`
 		Location       *string `json:"location"`
+		Force          bool    `json:"force"` // 员工时间冲突时仍然保存，冲突记录为警告
+		Scope          string  `json:"scope"` // 重复课程的修改范围：this（默认）/ following / all
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -51,6 +56,35 @@ func UpdateCourseItem(c *gin.Context) {
 		return
 	}
 
+	// 修改重复课程中的多次上课
+	switch req.Scope {
+	case "", seriesScopeThis:
+	case seriesScopeFollowing, seriesScopeAll:
+		if req.ClassDate != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "修改多次上课时不能修改日期，请逐次修改",
+				"data":    nil,
+			})
+			return
+		}
+		updateSeriesItems(c, item, req.Scope, seriesItemsChange{
+			ClassBeginTime: req.ClassBeginTime,
+			ClassEndTime:   req.ClassEndTime,
+			LocationID:     req.LocationID,
+			Location:       req.Location,
+			Force:          req.Force,
+		})
+		return
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的修改范围，可选值：this、following、all",
+			"data":    nil,
+		})
+		return
+	}
+
 	// 构建更新映射
 	updates := make(map[string]interface{})
 
@@ -93,16 +127,30 @@ func UpdateCourseItem(c *gin.Context) {
 		updates["class_end_time"] = *req.ClassEndTime
 	}
 
-	if req.Location != nil {
-		if len(*req.Location) > 100 {
+	// 更新上课地点（locationId 优先，其次按名称匹配）
+	var loc *database.Location
+	if req.LocationID != nil || req.Location != nil {
+		name := ""
+		if req.Location != nil {
+			name = *req.Location
+		}
+		var msg string
+		loc, msg = resolveLocation(req.LocationID, name)
+		if msg != "" {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"code":    400,
-				"message": "上课地点长度不能超过100字符",
+				"message": msg,
 				"data":    nil,
 			})
 			return
 		}
-		updates["location"] = *req.Location
+		updates["location"] = loc.LocationName
+		updates["location_id"] = loc.LocationID
+	} else if item.LocationID != nil {
+		loc = &database.Location{}
+		if err := database.DB.Where("location_id = ?", *item.LocationID).First(loc).Error; err != nil {
+			loc = nil
+		}
 	}
 
 	// 如果没有更新内容，直接返回
@@ -115,6 +163,101 @@ func UpdateCourseItem(c *gin.Context) {
 		return
 	}
 
+	// 修改后的时间段，更换地点时按新地点或计划的时区解释
+	slot := scheduling.Slot{
+		Date:     item.ClassDate.Format("2006-01-02"),
+		Begin:    item.ClassBeginTime,
+		End:      item.ClassEndTime,
+		Timezone: item.Timezone,
+	}
+	if updates["location_id"] != nil {
+		slot.Timezone = scheduling.ResolveTimezone(loc, item.Plan)
+		updates["timezone"] = slot.Timezone
+	}
+	if req.ClassDate != nil {
+		slot.Date = *req.ClassDate
+	}
+	if req.ClassBeginTime != nil {
+		slot.Begin = *req.ClassBeginTime
+	}
+	if req.ClassEndTime != nil {
+		slot.End = *req.ClassEndTime
+	}
+	if slot.Begin >= slot.End {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "结束时间必须晚于开始时间",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 检查讲师登记的可授课时间和不可授课时间段
+	if msg, detail := checkTeacherAvailable(item.Course.TeacherID, item.Course.Teacher.Name, slot); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": msg,
+			"data":    detail,
+		})
+		return
+	}
+
+	// 按工作日历检查上课日期
+	msg, detail, calendarWarning := checkWorkingDay(slot.Date, loc)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": msg,
+			"data":    detail,
+		})
+		return
+	}
+
+	// 检查地点占用和容量
+	if loc != nil {
+		if msg, detail := checkLocationAvailable(loc, item.PlanID, slot, item.ItemID); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": msg,
+				"data":    detail,
+			})
+			return
+		}
+	}
+
+	// 检查参训员工时间冲突，重新计算课程安排上的冲突警告
+	booking := scheduling.Booking{
+		ItemID:         item.ItemID,
+		PlanID:         item.PlanID,
+		PlanName:       item.Plan.PlanName,
+		CourseID:       item.CourseID,
+		CourseName:     item.Course.CourseName,
+		TeacherName:    item.Course.Teacher.Name,
+		ClassDate:      slot.Date,
+		ClassBeginTime: slot.Begin,
+		ClassEndTime:   slot.End,
+		LocationID:     item.LocationID,
+		Location:       item.Location,
+		Timezone:       slot.Timezone,
+	}
+	if loc != nil {
+		booking.LocationID = &loc.LocationID
+		booking.Location = loc.LocationName
+	}
+	conflicts, msg := checkEmployeeConflicts(booking, req.Force)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": msg,
+			"data": gin.H{
+				"conflicts": conflicts,
+			},
+		})
+		return
+	}
+	updates["conflict_warning"] = scheduling.FormatWarning(conflicts)
+	updates["sequence"] = gorm.Expr("sequence + 1") // 日历订阅据此更新事件
+
 	// 执行更新
 	if err := database.DB.Model(&item).Updates(updates).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -132,15 +275,21 @@ func UpdateCourseItem(c *gin.Context) {
 		"code":    200,
 		"message": "修改成功",
 		"data": gin.H{
-			"itemId":         item.ItemID,
-			"planId":         item.PlanID,
-			"planName":       item.Plan.PlanName,
-			"courseId":       item.CourseID,
-			"courseName":     item.Course.CourseName,
-			"classDate":      item.ClassDate.Format("2006-01-02"),
-			"classBeginTime": item.ClassBeginTime,
-			"classEndTime":   item.ClassEndTime,
-			"location":       item.Location,
+			"itemId":          item.ItemID,
+			"planId":          item.PlanID,
+			"planName":        item.Plan.PlanName,
+			"courseId":        item.CourseID,
+			"courseName":      item.Course.CourseName,
+			"classDate":       item.ClassDate.Format("2006-01-02"),
+			"classBeginTime":  item.ClassBeginTime,
+			"classEndTime":    item.ClassEndTime,
+			"location":        item.Location,
+			"locationId":      item.LocationID,
+			"timezone":        scheduling.TimezoneName(item.Timezone),
+			"conflictWarning": item.ConflictWarning,
+			"seriesId":        item.SeriesID,
+			"conflicts":       conflicts,
+			"calendarWarning": calendarWarning,
 		},
 	})
 }
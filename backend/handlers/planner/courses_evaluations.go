@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 	"backend/database"
+	"backend/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -43,10 +44,10 @@ func GetCourseEvaluations(c *gin.Context) {
 	var stats CourseStats
 	database.DB.Raw(`
 		SELECT 
-			COALESCE(AVG(ae.weighted_score), 0) AS course_avg_score,
+			COALESCE(AVG(ae.self_score * (1 - ae.score_ratio) + ae.teacher_score * ae.score_ratio), 0) AS course_avg_score,
 			COUNT(DISTINCT ae.person_id) AS student_count,
-			COALESCE(MAX(ae.weighted_score), 0) AS max_score,
-			COALESCE(MIN(ae.weighted_score), 0) AS min_score
+			COALESCE(MAX(ae.self_score * (1 - ae.score_ratio) + ae.teacher_score * ae.score_ratio), 0) AS max_score,
+			COALESCE(MIN(ae.self_score * (1 - ae.score_ratio) + ae.teacher_score * ae.score_ratio), 0) AS min_score
 		FROM plan_course_item pci
 		LEFT JOIN attendance_evaluation ae ON pci.item_id = ae.item_id
 		WHERE pci.course_id = ?
@@ -63,6 +64,9 @@ func GetCourseEvaluations(c *gin.Context) {
 		TeacherScore   float64 `json:"teacherScore"`
 		TeacherComment string  `json:"teacherComment"`
 		WeightedScore  float64 `json:"weightedScore"`
+		Understanding  int     `json:"understanding"`
+		Difficulty     int     `json:"difficulty"`
+		Satisfaction   int     `json:"satisfaction"`
 	}
 	var evaluations []Evaluation
 	database.DB.Raw(`
@@ -70,19 +74,28 @@ func GetCourseEvaluations(c *gin.Context) {
 			p.person_id AS person_id,
 			p.name AS person_name,
 			ae.item_id AS item_id,
-			pci.class_date AS class_date,
+			` + database.FormatDate("pci.class_date") + ` AS class_date,
 			COALESCE(ae.self_score, 0) AS self_score,
 			COALESCE(ae.self_comment, '') AS self_comment,
 			COALESCE(ae.teacher_score, 0) AS teacher_score,
 			COALESCE(ae.teacher_comment, '') AS teacher_comment,
-			COALESCE(ae.weighted_score, 0) AS weighted_score
+			COALESCE(ae.self_score * (1 - ae.score_ratio) + ae.teacher_score * ae.score_ratio, 0) AS weighted_score,
+			COALESCE(ae.understanding, 0) AS understanding,
+			COALESCE(ae.difficulty, 0) AS difficulty,
+			COALESCE(ae.satisfaction, 0) AS satisfaction
 		FROM plan_course_item pci
 		INNER JOIN attendance_evaluation ae ON pci.item_id = ae.item_id
 		INNER JOIN person p ON ae.person_id = p.person_id
 		WHERE pci.course_id = ?
-		ORDER BY pci.class_date DESC, ae.weighted_score DESC
+		ORDER BY pci.class_date DESC, weighted_score DESC
 	`, courseId).Scan(&evaluations)
 
+	// 3. 课后反馈评分汇总（理解程度/难度感受/满意度），与综合得分分开统计
+	ratings := utils.NewRatingSummary()
+	for _, e := range evaluations {
+		ratings.Add(e.Understanding, e.Difficulty, e.Satisfaction)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "获取成功",
@@ -93,6 +106,7 @@ func GetCourseEvaluations(c *gin.Context) {
 			"studentCount":   stats.StudentCount,
 			"maxScore":       stats.MaxScore,
 			"minScore":       stats.MinScore,
+			"ratings":        ratings,
 			"evaluations":    evaluations,
 		},
 	})
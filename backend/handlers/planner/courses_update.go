@@ -6,6 +6,7 @@ import (
 	"backend/database"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // UpdateCourse 修改课程（接口5.10）
@@ -141,6 +142,12 @@ func UpdateCourse(c *gin.Context) {
 		return
 	}
 
+	// 课程名称或讲师变化时，日历订阅中的上课事件需要更新
+	if updates["course_name"] != nil || updates["teacher_id"] != nil {
+		database.DB.Model(&database.PlanCourseItem{}).Where("course_id = ?", courseId).
+			Update("sequence", gorm.Expr("sequence + 1"))
+	}
+
 	// 重新查询更新后的课程（带讲师信息）
 	database.DB.Preload("Teacher").Where("course_id = ?", courseId).First(&course)
 
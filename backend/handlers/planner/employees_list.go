@@ -7,11 +7,20 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// GetEmployeesList 获取所有员工列表（用于选择）
+// GetEmployeesList 获取所有员工列表（用于选择，接口5.19），可按组织节点（含下级节点）筛选
 func GetEmployeesList(c *gin.Context) {
+	unitIDs, ok := orgUnitScope(c)
+	if !ok {
+		return
+	}
+
 	// 查询所有角色为"员工"的人员
+	query := database.DB.Where("role = ?", "员工")
+	if unitIDs != nil {
+		query = query.Where("org_unit_id IN ?", unitIDs)
+	}
 	var employees []database.Person
-	if err := database.DB.Where("role = ?", "员工").Find(&employees).Error; err != nil {
+	if err := query.Find(&employees).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
 			"message": "查询失败",
@@ -20,18 +29,39 @@ func GetEmployeesList(c *gin.Context) {
 		return
 	}
 
+	// 组织节点名称
+	var units []database.OrgUnit
+	database.DB.Find(&units)
+	unitNames := make(map[int64]string, len(units))
+	for _, u := range units {
+		unitNames[u.UnitID] = u.UnitName
+	}
+
 	// 构建响应数据
 	type EmployeeResponse struct {
-		PersonID   int64  `json:"personId"`
-		PersonName string `json:"personName"`
+		PersonID    int64  `json:"personId"`
+		PersonName  string `json:"personName"`
+		OrgUnitID   *int64 `json:"orgUnitId"`
+		OrgUnitName string `json:"orgUnitName"`
+		JobRank     string `json:"jobRank"`
+		HireDate    string `json:"hireDate"`
 	}
 
 	list := make([]EmployeeResponse, 0, len(employees))
 	for _, emp := range employees {
-		list = append(list, EmployeeResponse{
+		item := EmployeeResponse{
 			PersonID:   emp.PersonID,
 			PersonName: emp.Name,
-		})
+			OrgUnitID:  emp.OrgUnitID,
+			JobRank:    emp.JobRank,
+		}
+		if emp.OrgUnitID != nil {
+			item.OrgUnitName = unitNames[*emp.OrgUnitID]
+		}
+		if emp.HireDate != nil {
+			item.HireDate = emp.HireDate.Format("2006-01-02")
+		}
+		list = append(list, item)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
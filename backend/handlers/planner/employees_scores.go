@@ -41,7 +41,7 @@ func GetEmployeeScores(c *gin.Context) {
 	var overallStats OverallStats
 	database.DB.Raw(`
 		SELECT 
-			COALESCE(AVG(weighted_score), 0) AS overall_avg_score,
+			COALESCE(AVG(self_score * (1 - score_ratio) + teacher_score * score_ratio), 0) AS overall_avg_score,
 			COUNT(DISTINCT item_id) AS course_count
 		FROM attendance_evaluation
 		WHERE person_id = ?
@@ -56,7 +56,7 @@ func GetEmployeeScores(c *gin.Context) {
 	database.DB.Raw(`
 		SELECT 
 			c.course_class AS course_class,
-			COALESCE(AVG(ae.weighted_score), 0) AS avg_weighted_score
+			COALESCE(AVG(ae.self_score * (1 - ae.score_ratio) + ae.teacher_score * ae.score_ratio), 0) AS avg_weighted_score
 		FROM attendance_evaluation ae
 		INNER JOIN plan_course_item pci ON ae.item_id = pci.item_id
 		INNER JOIN course c ON pci.course_id = c.course_id
@@ -83,12 +83,12 @@ func GetEmployeeScores(c *gin.Context) {
 			ae.item_id AS item_id,
 			c.course_name AS course_name,
 			c.course_class AS course_class,
-			pci.class_date AS class_date,
+			` + database.FormatDate("pci.class_date") + ` AS class_date,
 			pci.class_begin_time AS class_begin_time,
 			pci.class_end_time AS class_end_time,
 			COALESCE(ae.self_score, 0) AS self_score,
 			COALESCE(ae.teacher_score, 0) AS teacher_score,
-			COALESCE(ae.weighted_score, 0) AS weighted_score
+			COALESCE(ae.self_score * (1 - ae.score_ratio) + ae.teacher_score * ae.score_ratio, 0) AS weighted_score
 		FROM attendance_evaluation ae
 		INNER JOIN plan_course_item pci ON ae.item_id = pci.item_id
 		INNER JOIN course c ON pci.course_id = c.course_id
@@ -0,0 +1,72 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"strconv"
+	"testing"
+)
+
+func TestGetEmployeeScores(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", dbtest.Date(-3), dbtest.Date(2), dbtest.EmployeeID, dbtest.Employee2ID)
+	fire := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	english := dbtest.CreateCourse(t, "航海英语", "语言", dbtest.Teacher2ID)
+	early := dbtest.CreateItem(t, plan, fire, dbtest.Date(-2), "09:00:00", "10:00:00", "Asia/Shanghai")
+	late := dbtest.CreateItem(t, plan, english, dbtest.Date(-1), "14:00:00", "15:30:00", "Asia/Shanghai")
+
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: early.ItemID, SelfScore: 80, TeacherScore: 90, TeacherComment: "认真", ScoreRatio: 0.6,
+	})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: late.ItemID, SelfScore: 70, TeacherScore: 60, ScoreRatio: 0.5,
+	})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.Employee2ID, ItemID: early.ItemID, SelfScore: 50, TeacherScore: 50, ScoreRatio: 0.5,
+	})
+
+	var data struct {
+		PersonName        string  `json:"personName"`
+		OverallAvgScore   float64 `json:"overallAvgScore"`
+		CourseCount       int64   `json:"courseCount"`
+		CourseClassScores []struct {
+			CourseClass      string  `json:"courseClass"`
+			AvgWeightedScore float64 `json:"avgWeightedScore"`
+		} `json:"courseClassScores"`
+		ItemScores []struct {
+			ItemID         int64   `json:"itemId"`
+			CourseName     string  `json:"courseName"`
+			ClassDate      string  `json:"classDate"`
+			ClassBeginTime string  `json:"classBeginTime"`
+			ClassEndTime   string  `json:"classEndTime"`
+			WeightedScore  float64 `json:"weightedScore"`
+		} `json:"itemScores"`
+	}
+	target := "/planner/employees/" + strconv.FormatInt(dbtest.EmployeeID, 10) + "/scores"
+	dbtest.GetRoute(t, "/planner/employees/:employeeId/scores", GetEmployeeScores, dbtest.PlannerID, "课程大纲制定者", target).Decode(t, &data)
+
+	// 80*0.4+90*0.6=86，70*0.5+60*0.5=65
+	if data.PersonName != "赵员工" || data.CourseCount != 2 || !dbtest.Near(data.OverallAvgScore, 75.5) {
+		t.Errorf("赵员工应有 2 门课程、平均 75.5，实际 %s、%d、%v", data.PersonName, data.CourseCount, data.OverallAvgScore)
+	}
+
+	classes := data.CourseClassScores
+	if len(classes) != 2 || classes[0].CourseClass != "安全" || !dbtest.Near(classes[0].AvgWeightedScore, 86) ||
+		classes[1].CourseClass != "语言" || !dbtest.Near(classes[1].AvgWeightedScore, 65) {
+		t.Errorf("课程类型成绩应为安全 86、语言 65，实际 %+v", classes)
+	}
+
+	// 按上课时间倒序
+	items := data.ItemScores
+	if len(items) != 2 {
+		t.Fatalf("应返回 2 节课的成绩，实际 %+v", items)
+	}
+	if items[0].ItemID != late.ItemID || items[0].ClassDate != dbtest.Date(-1) || items[0].ClassBeginTime != "14:00:00" ||
+		items[0].ClassEndTime != "15:30:00" || !dbtest.Near(items[0].WeightedScore, 65) {
+		t.Errorf("第 1 条应为 %s 14:00:00-15:30:00 的航海英语（65 分），实际 %+v", dbtest.Date(-1), items[0])
+	}
+	if items[1].ItemID != early.ItemID || items[1].ClassDate != dbtest.Date(-2) || items[1].CourseName != "消防" || !dbtest.Near(items[1].WeightedScore, 86) {
+		t.Errorf("第 2 条应为 %s 的消防（86 分），实际 %+v", dbtest.Date(-2), items[1])
+	}
+}
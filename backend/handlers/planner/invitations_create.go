@@ -0,0 +1,98 @@
+package planner
+
+import (
+	"backend/accounts"
+	"backend/notify"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateInvitation 生成注册邀请码（接口5.62）：按邀请码注册的账号获得指定角色；填写邮箱时把邀请链接发到该邮箱
+func CreateInvitation(c *gin.Context) {
+	var req struct {
+		Role      string `json:"role" binding:"required,oneof=employee teacher planner"`
+		Email     string `json:"email" binding:"omitempty,email,max=100"`
+		MaxUses   int    `json:"maxUses" binding:"omitempty,min=1,max=1000"`
+		ExpiresAt string `json:"expiresAt"` // 过期时间，格式 2006-01-02 15:04:05，不填时按 INVITATION_EXPIRE_HOURS 计算
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	if req.MaxUses == 0 {
+		req.MaxUses = 1
+	}
+
+	now := time.Now()
+	expiresAt := accounts.DefaultExpiry(now)
+	if req.ExpiresAt != "" {
+		t, err := time.ParseInLocation("2006-01-02 15:04:05", req.ExpiresAt, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "过期时间格式错误，应为 YYYY-MM-DD HH:MM:SS",
+				"data":    nil,
+			})
+			return
+		}
+		if !t.After(now) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "过期时间必须晚于当前时间",
+				"data":    nil,
+			})
+			return
+		}
+		expiresAt = t
+	}
+
+	code, inv, err := accounts.CreateInvitation(req.Role, req.Email, req.MaxUses, expiresAt, c.GetInt64("personId"), now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "生成邀请码失败",
+			"data":    nil,
+		})
+		return
+	}
+	link := accounts.InvitationLink(code)
+
+	if req.Email != "" {
+		msg := notify.Message{
+			To:      req.Email,
+			Subject: "注册邀请",
+			Body: "您好：\n\n" +
+				"您被邀请以" + inv.Role + "身份注册培训系统账号，请在 " + expiresAt.Format("2006-01-02 15:04") +
+				" 前打开以下链接完成注册：\n\n" + link + "\n\n" +
+				"邀请码：" + code + "\n",
+		}
+		go func() {
+			if err := notify.Send(msg); err != nil {
+				log.Printf("发送注册邀请邮件失败（邀请码 %d）: %v", inv.InvitationID, err)
+			}
+		}()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "邀请码已生成",
+		"data": gin.H{
+			"invitationId": inv.InvitationID,
+			"code":         code, // 邀请码只在生成时返回，之后无法再查询
+			"link":         link,
+			"role":         req.Role,
+			"roleDisplay":  inv.Role,
+			"email":        inv.Email,
+			"maxUses":      inv.MaxUses,
+			"expiresAt":    inv.ExpiresAt.Format("2006-01-02 15:04:05"),
+			"emailSent":    req.Email != "",
+		},
+	})
+}
@@ -0,0 +1,116 @@
+package planner
+
+import (
+	"backend/accounts"
+	"backend/database"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetInvitations 获取注册邀请码列表（接口5.63），按生成时间倒序；邀请码本身不保存，只返回前几位
+func GetInvitations(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if err != nil || pageSize < 1 {
+		pageSize = 20
+	}
+
+	now := time.Now()
+	query := database.DB.Model(&database.Invitation{})
+	if role := c.Query("role"); role != "" {
+		query = query.Where("role = ?", accounts.RoleDisplay(role))
+	}
+	switch c.Query("status") {
+	case "":
+	case accounts.InvitationActive:
+		query = query.Where("revoked_at IS NULL AND used_count < max_uses AND expires_at > ?", now)
+	case accounts.InvitationUsed:
+		query = query.Where("revoked_at IS NULL AND used_count >= max_uses")
+	case accounts.InvitationExpired:
+		query = query.Where("revoked_at IS NULL AND used_count < max_uses AND expires_at <= ?", now)
+	case accounts.InvitationRevoked:
+		query = query.Where("revoked_at IS NOT NULL")
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的状态，可选 active/used/expired/revoked",
+			"data":    nil,
+		})
+		return
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	var invitations []database.Invitation
+	if err := query.Order("created_at DESC, invitation_id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&invitations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	creatorIDs := make([]int64, 0, len(invitations))
+	for _, inv := range invitations {
+		creatorIDs = append(creatorIDs, inv.CreatorID)
+	}
+	names := make(map[int64]string)
+	if len(creatorIDs) > 0 {
+		var persons []database.Person
+		database.DB.Where("person_id IN ?", creatorIDs).Find(&persons)
+		for _, p := range persons {
+			names[p.PersonID] = p.Name
+		}
+	}
+
+	list := make([]gin.H, 0, len(invitations))
+	for i := range invitations {
+		inv := &invitations[i]
+		var revokedAt *string
+		if inv.RevokedAt != nil {
+			s := inv.RevokedAt.Format("2006-01-02 15:04:05")
+			revokedAt = &s
+		}
+		list = append(list, gin.H{
+			"invitationId": inv.InvitationID,
+			"codeHint":     inv.CodeHint,
+			"role":         accounts.RoleCode(inv.Role),
+			"roleDisplay":  inv.Role,
+			"email":        inv.Email,
+			"maxUses":      inv.MaxUses,
+			"usedCount":    inv.UsedCount,
+			"status":       accounts.InvitationStatus(inv, now),
+			"expiresAt":    inv.ExpiresAt.Format("2006-01-02 15:04:05"),
+			"revokedAt":    revokedAt,
+			"creatorId":    inv.CreatorID,
+			"creatorName":  names[inv.CreatorID],
+			"createdAt":    inv.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"total":    total,
+			"page":     page,
+			"pageSize": pageSize,
+			"list":     list,
+		},
+	})
+}
@@ -0,0 +1,52 @@
+package planner
+
+import (
+	"backend/accounts"
+	"backend/database"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RevokeInvitation 撤销注册邀请码（接口5.64）：撤销后不能再用于注册，已注册的账号不受影响
+func RevokeInvitation(c *gin.Context) {
+	invitationId, err := strconv.ParseInt(c.Param("invitationId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的邀请码ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var inv database.Invitation
+	if err := database.DB.Where("invitation_id = ?", invitationId).First(&inv).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "邀请码不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	if _, err := accounts.RevokeInvitation(inv.InvitationID, time.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "撤销邀请码失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "邀请码已撤销",
+		"data": gin.H{
+			"invitationId": inv.InvitationID,
+			"usedCount":    inv.UsedCount,
+		},
+	})
+}
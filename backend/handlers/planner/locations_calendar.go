@@ -0,0 +1,158 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/scheduling"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLocationsCalendar 获取培训地点占用日历（接口5.37）
+// 默认查询从今天起7天，可按地点或所在楼宇/船舶筛选
+func GetLocationsCalendar(c *gin.Context) {
+	startDate := c.DefaultQuery("startDate", time.Now().Format("2006-01-02"))
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "开始日期格式错误，请使用 YYYY-MM-DD 格式",
+			"data":    nil,
+		})
+		return
+	}
+	endDate := c.DefaultQuery("endDate", start.AddDate(0, 0, 6).Format("2006-01-02"))
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil || end.Before(start) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "结束日期格式错误或早于开始日期",
+			"data":    nil,
+		})
+		return
+	}
+	if end.Sub(start) > 92*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "查询范围不能超过92天",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 查询地点
+	query := database.DB.Model(&database.Location{})
+	if locationIdStr := c.Query("locationId"); locationIdStr != "" {
+		locationId, err := strconv.ParseInt(locationIdStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "无效的地点ID",
+				"data":    nil,
+			})
+			return
+		}
+		query = query.Where("location_id = ?", locationId)
+	}
+	if building := c.Query("building"); building != "" {
+		query = query.Where("building = ?", building)
+	}
+	var locations []database.Location
+	if err := query.Order("building, location_name").Find(&locations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询地点失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	locationIDs := make([]int64, 0, len(locations))
+	for _, loc := range locations {
+		locationIDs = append(locationIDs, loc.LocationID)
+	}
+	var bookings []scheduling.Booking
+	if len(locationIDs) > 0 {
+		bookings, err = scheduling.LocationBookings(locationIDs, startDate, endDate)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    500,
+				"message": "查询课程安排失败",
+				"data":    nil,
+			})
+			return
+		}
+	}
+
+	// 工作日历，按地点所在的楼宇或船舶标记非工作日
+	cal, err := scheduling.LoadCalendar(startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询工作日历失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 按地点、日期分组，附带参训人数
+	type BookingInfo struct {
+		scheduling.Booking
+		RosterSize int64 `json:"rosterSize"`
+	}
+	type DayInfo struct {
+		Date     string        `json:"date"`
+		Bookings []BookingInfo `json:"bookings"`
+	}
+	rosters := make(map[int64]int64)
+	days := make(map[int64][]*DayInfo)
+	for _, b := range bookings {
+		roster, ok := rosters[b.PlanID]
+		if !ok {
+			roster, _ = scheduling.RosterSize(b.PlanID)
+			rosters[b.PlanID] = roster
+		}
+		locDays := days[*b.LocationID]
+		if len(locDays) == 0 || locDays[len(locDays)-1].Date != b.ClassDate {
+			locDays = append(locDays, &DayInfo{Date: b.ClassDate, Bookings: []BookingInfo{}})
+			days[*b.LocationID] = locDays
+		}
+		day := locDays[len(locDays)-1]
+		day.Bookings = append(day.Bookings, BookingInfo{Booking: b, RosterSize: roster})
+	}
+
+	list := make([]gin.H, 0, len(locations))
+	for _, loc := range locations {
+		item := formatLocation(loc)
+		item["bookingCount"] = 0
+		item["days"] = []*DayInfo{}
+		nonWorking := []scheduling.CalendarDay{}
+		for _, day := range cal.Days(start, end, loc.Building) {
+			if day.Mark != nil {
+				nonWorking = append(nonWorking, day)
+			}
+		}
+		item["nonWorkingDays"] = nonWorking
+		if locDays, ok := days[loc.LocationID]; ok {
+			count := 0
+			for _, d := range locDays {
+				count += len(d.Bookings)
+			}
+			item["bookingCount"] = count
+			item["days"] = locDays
+		}
+		list = append(list, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"startDate": startDate,
+			"endDate":   endDate,
+			"locations": list,
+		},
+	})
+}
@@ -0,0 +1,122 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/scheduling"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// joinEquipment 将设备列表保存为逗号分隔的文本（去除空项和首尾空格）
+func joinEquipment(equipment []string) string {
+	items := make([]string, 0, len(equipment))
+	for _, e := range equipment {
+		if e = strings.TrimSpace(e); e != "" {
+			items = append(items, e)
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// formatLocation 将地点转换为响应格式（设备为数组）
+func formatLocation(loc database.Location) gin.H {
+	equipment := []string{}
+	if loc.Equipment != "" {
+		equipment = strings.Split(loc.Equipment, ",")
+	}
+	return gin.H{
+		"locationId":   loc.LocationID,
+		"locationName": loc.LocationName,
+		"building":     loc.Building,
+		"capacity":     loc.Capacity,
+		"equipment":    equipment,
+		"timezone":     loc.Timezone,
+	}
+}
+
+// CreateLocation 创建培训地点（接口5.34）
+func CreateLocation(c *gin.Context) {
+	// 解析请求体
+	var req struct {
+		LocationName string   `json:"locationName" binding:"required"`
+		Building     string   `json:"building"`
+		Capacity     int      `json:"capacity"`
+		Equipment    []string `json:"equipment"`
+		Timezone     string   `json:"timezone"` // IANA 时区，如 Asia/Singapore，为空时使用计划或系统默认时区
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证字段
+	loc := database.Location{
+		LocationName: strings.TrimSpace(req.LocationName),
+		Building:     strings.TrimSpace(req.Building),
+		Capacity:     req.Capacity,
+		Timezone:     strings.TrimSpace(req.Timezone),
+		Equipment:    joinEquipment(req.Equipment),
+	}
+	if msg := validateLocation(loc); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": msg,
+			"data":    nil,
+		})
+		return
+	}
+
+	// 地点名称不能重复
+	var count int64
+	database.DB.Model(&database.Location{}).Where("location_name = ?", loc.LocationName).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "地点名称已存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	if err := database.DB.Create(&loc).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "创建地点失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "创建成功",
+		"data":    formatLocation(loc),
+	})
+}
+
+// validateLocation 校验地点字段，返回错误提示，合法时返回空字符串
+func validateLocation(loc database.Location) string {
+	if len(loc.LocationName) == 0 || len(loc.LocationName) > 100 {
+		return "地点名称长度必须在1-100字符之间"
+	}
+	if len(loc.Building) > 50 {
+		return "所在楼宇或船舶长度不能超过50字符"
+	}
+	if loc.Capacity < 0 {
+		return "容纳人数不能为负数"
+	}
+	if len(loc.Equipment) > 500 {
+		return "设备列表过长，总长度不能超过500字符"
+	}
+	if len(loc.Timezone) > 64 || !scheduling.ValidTimezone(loc.Timezone) {
+		return "无法识别的时区：" + loc.Timezone + "，请使用 IANA 时区名称，如 Asia/Shanghai"
+	}
+	return ""
+}
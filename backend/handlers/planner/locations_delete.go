@@ -0,0 +1,64 @@
+package planner
+
+import (
+	"backend/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeleteLocation 删除培训地点（接口5.36），已被课程安排引用的地点不能删除
+func DeleteLocation(c *gin.Context) {
+	// 获取路径参数 locationId
+	locationIdStr := c.Param("locationId")
+	locationId, err := strconv.ParseInt(locationIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的地点ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证地点是否存在
+	var loc database.Location
+	if err := database.DB.Where("location_id = ?", locationId).First(&loc).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "地点不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 检查是否有课程安排引用
+	var itemCount int64
+	database.DB.Model(&database.PlanCourseItem{}).Where("location_id = ?", locationId).Count(&itemCount)
+	if itemCount > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无法删除，该地点已有课程安排",
+			"data": gin.H{
+				"itemCount": itemCount,
+			},
+		})
+		return
+	}
+
+	if err := database.DB.Delete(&loc).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "删除地点失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "删除成功",
+		"data":    nil,
+	})
+}
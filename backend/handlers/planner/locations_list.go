@@ -0,0 +1,46 @@
+package planner
+
+import (
+	"backend/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLocationsList 获取培训地点列表（接口5.33）
+func GetLocationsList(c *gin.Context) {
+	query := database.DB.Model(&database.Location{})
+
+	// 筛选条件：名称关键词、所在楼宇或船舶、最少容纳人数
+	if keyword := c.Query("keyword"); keyword != "" {
+		query = query.Where("location_name LIKE ?", "%"+keyword+"%")
+	}
+	if building := c.Query("building"); building != "" {
+		query = query.Where("building = ?", building)
+	}
+	if minCapacity, err := strconv.Atoi(c.Query("minCapacity")); err == nil && minCapacity > 0 {
+		query = query.Where("capacity = 0 OR capacity >= ?", minCapacity)
+	}
+
+	var locations []database.Location
+	if err := query.Order("building, location_name").Find(&locations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询地点失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	list := make([]gin.H, 0, len(locations))
+	for _, loc := range locations {
+		list = append(list, formatLocation(loc))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    list,
+	})
+}
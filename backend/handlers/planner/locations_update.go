@@ -0,0 +1,171 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/scheduling"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// UpdateLocation 修改培训地点（接口5.35）
+func UpdateLocation(c *gin.Context) {
+	// 获取路径参数 locationId
+	locationIdStr := c.Param("locationId")
+	locationId, err := strconv.ParseInt(locationIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的地点ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证地点是否存在
+	var loc database.Location
+	if err := database.DB.Where("location_id = ?", locationId).First(&loc).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "地点不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 解析请求体（均为可选）
+	var req struct {
+		LocationName *string   `json:"locationName"`
+		Building     *string   `json:"building"`
+		Capacity     *int      `json:"capacity"`
+		Equipment    *[]string `json:"equipment"`
+		Timezone     *string   `json:"timezone"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	oldName, oldTimezone := loc.LocationName, loc.Timezone
+	if req.LocationName != nil {
+		loc.LocationName = strings.TrimSpace(*req.LocationName)
+	}
+	if req.Building != nil {
+		loc.Building = strings.TrimSpace(*req.Building)
+	}
+	if req.Capacity != nil {
+		loc.Capacity = *req.Capacity
+	}
+	if req.Equipment != nil {
+		loc.Equipment = joinEquipment(*req.Equipment)
+	}
+	if req.Timezone != nil {
+		loc.Timezone = strings.TrimSpace(*req.Timezone)
+	}
+	if msg := validateLocation(loc); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": msg,
+			"data":    nil,
+		})
+		return
+	}
+
+	// 地点名称不能与其他地点重复
+	if loc.LocationName != oldName {
+		var count int64
+		database.DB.Model(&database.Location{}).
+			Where("location_name = ? AND location_id <> ?", loc.LocationName, locationId).
+			Count(&count)
+		if count > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "地点名称已存在",
+				"data":    nil,
+			})
+			return
+		}
+	}
+
+	// 缩小容量时，不能小于今后在此上课的计划的参训人数
+	if req.Capacity != nil && loc.Capacity > 0 {
+		var planIDs []int64
+		database.DB.Model(&database.PlanCourseItem{}).
+			Where("location_id = ?", locationId).
+			Where(database.DateFrom("class_date", time.Now().Format("2006-01-02"))).
+			Distinct().
+			Pluck("plan_id", &planIDs)
+		for _, planID := range planIDs {
+			roster, err := scheduling.RosterSize(planID)
+			if err == nil && roster > int64(loc.Capacity) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"code":    400,
+					"message": "容纳人数小于已安排在此上课的培训计划的参训人数",
+					"data": gin.H{
+						"planId":     planID,
+						"rosterSize": roster,
+						"capacity":   loc.Capacity,
+					},
+				})
+				return
+			}
+		}
+	}
+
+	// 执行更新，改名时同步课程安排上保存的地点名称
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{
+			"location_name": loc.LocationName,
+			"building":      loc.Building,
+			"capacity":      loc.Capacity,
+			"equipment":     loc.Equipment,
+			"timezone":      loc.Timezone,
+		}
+		if err := tx.Model(&database.Location{}).Where("location_id = ?", locationId).Updates(updates).Error; err != nil {
+			return err
+		}
+		if loc.LocationName != oldName {
+			if err := tx.Model(&database.PlanCourseItem{}).
+				Where("location_id = ?", locationId).
+				Update("location", loc.LocationName).Error; err != nil {
+				return err
+			}
+		}
+		// 修改时区时，今后在此上课的课程安排改按新时区解释（地点不再设置时区时改用计划的时区，计划也未设置时用系统默认时区）
+		if loc.Timezone != oldTimezone {
+			var timezone interface{} = loc.Timezone
+			if loc.Timezone == "" {
+				timezone = gorm.Expr("COALESCE((SELECT NULLIF(tp.timezone, '') FROM training_plan tp WHERE tp.plan_id = plan_course_item.plan_id), ?)",
+					scheduling.DefaultTimezoneName())
+			}
+			return tx.Model(&database.PlanCourseItem{}).
+				Where("location_id = ?", locationId).
+				Where(database.DateFrom("class_date", time.Now().Format("2006-01-02"))).
+				Updates(map[string]interface{}{"timezone": timezone, "sequence": gorm.Expr("sequence + 1")}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "修改地点失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "修改成功",
+		"data":    formatLocation(loc),
+	})
+}
@@ -0,0 +1,96 @@
+package planner
+
+import (
+	"backend/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLoginFailures 获取失败登录记录（接口5.61），按时间倒序
+func GetLoginFailures(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if err != nil || pageSize < 1 {
+		pageSize = 20
+	}
+
+	query := database.DB.Model(&database.LoginFailure{})
+	if username := c.Query("username"); username != "" {
+		query = query.Where("login_name = ?", username)
+	}
+	if ip := c.Query("ipAddress"); ip != "" {
+		query = query.Where("ip_address = ?", ip)
+	}
+	if reason := c.Query("reason"); reason != "" {
+		query = query.Where("reason = ?", reason)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	var records []database.LoginFailure
+	if err := query.Order("created_at DESC, failure_id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&records).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	personIDs := make([]int64, 0, len(records))
+	for _, r := range records {
+		if r.PersonID != nil {
+			personIDs = append(personIDs, *r.PersonID)
+		}
+	}
+	names := make(map[int64]string)
+	if len(personIDs) > 0 {
+		var persons []database.Person
+		database.DB.Where("person_id IN ?", personIDs).Find(&persons)
+		for _, p := range persons {
+			names[p.PersonID] = p.Name
+		}
+	}
+
+	list := make([]gin.H, 0, len(records))
+	for _, r := range records {
+		personName := ""
+		if r.PersonID != nil {
+			personName = names[*r.PersonID]
+		}
+		list = append(list, gin.H{
+			"failureId":  r.FailureID,
+			"username":   r.LoginName,
+			"personId":   r.PersonID,
+			"personName": personName,
+			"ipAddress":  r.IPAddress,
+			"userAgent":  r.UserAgent,
+			"reason":     r.Reason,
+			"createdAt":  r.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"total":    total,
+			"page":     page,
+			"pageSize": pageSize,
+			"list":     list,
+		},
+	})
+}
@@ -0,0 +1,119 @@
+package planner
+
+import (
+	"backend/database"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// orgUnitAllowedParents 各类型组织节点允许挂在哪些类型的节点之下（公司只能作为根节点）
+var orgUnitAllowedParents = map[string][]string{
+	database.OrgUnitTypeCompany:    {},
+	database.OrgUnitTypeDepartment: {database.OrgUnitTypeCompany, database.OrgUnitTypeDepartment},
+	database.OrgUnitTypeTeam:       {database.OrgUnitTypeDepartment, database.OrgUnitTypeVessel},
+	database.OrgUnitTypeVessel:     {database.OrgUnitTypeCompany, database.OrgUnitTypeDepartment},
+}
+
+// canBeChildOf 判断 childType 类型的节点能否挂在 parentType 类型的节点下
+func canBeChildOf(childType, parentType string) bool {
+	for _, t := range orgUnitAllowedParents[childType] {
+		if t == parentType {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOrgUnitParent 校验节点类型与上级节点的组合，返回错误提示，合法时返回空字符串
+func checkOrgUnitParent(unitType string, parentID *int64) string {
+	if parentID == nil {
+		if unitType != database.OrgUnitTypeCompany {
+			return unitType + "必须指定上级节点"
+		}
+		return ""
+	}
+	if unitType == database.OrgUnitTypeCompany {
+		return "公司只能作为根节点"
+	}
+
+	var parent database.OrgUnit
+	if err := database.DB.Where("unit_id = ?", *parentID).First(&parent).Error; err != nil {
+		return "上级节点不存在"
+	}
+	if !canBeChildOf(unitType, parent.UnitType) {
+		return unitType + "不能挂在" + parent.UnitType + "之下"
+	}
+	return ""
+}
+
+// CreateOrgUnit 创建组织节点（接口5.21）
+func CreateOrgUnit(c *gin.Context) {
+	// 解析请求体
+	var req struct {
+		UnitName string `json:"unitName" binding:"required"`
+		UnitType string `json:"unitType" binding:"required"`
+		ParentID *int64 `json:"parentId"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证字段
+	req.UnitName = strings.TrimSpace(req.UnitName)
+	if len(req.UnitName) == 0 || len(req.UnitName) > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "节点名称长度必须在1-50字符之间",
+			"data":    nil,
+		})
+		return
+	}
+
+	if !database.IsValidOrgUnitType(req.UnitType) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "节点类型必须是：公司/部门/班组/船舶",
+			"data":    nil,
+		})
+		return
+	}
+
+	if msg := checkOrgUnitParent(req.UnitType, req.ParentID); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": msg,
+			"data":    nil,
+		})
+		return
+	}
+
+	// 创建节点
+	unit := database.OrgUnit{
+		UnitName: req.UnitName,
+		UnitType: req.UnitType,
+		ParentID: req.ParentID,
+	}
+
+	if err := database.DB.Create(&unit).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "创建组织节点失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "创建成功",
+		"data":    unit,
+	})
+}
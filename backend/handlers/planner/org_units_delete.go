@@ -0,0 +1,68 @@
+package planner
+
+import (
+	"backend/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeleteOrgUnit 删除组织节点（接口5.23），存在下级节点或所属人员时不允许删除
+func DeleteOrgUnit(c *gin.Context) {
+	// 获取路径参数 unitId
+	unitIdStr := c.Param("unitId")
+	unitId, err := strconv.ParseInt(unitIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的组织节点ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证节点是否存在
+	var unit database.OrgUnit
+	if err := database.DB.Where("unit_id = ?", unitId).First(&unit).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "组织节点不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 检查下级节点和所属人员
+	var childCount, personCount int64
+	database.DB.Model(&database.OrgUnit{}).Where("parent_id = ?", unitId).Count(&childCount)
+	database.DB.Model(&database.Person{}).Where("org_unit_id = ?", unitId).Count(&personCount)
+
+	if childCount > 0 || personCount > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无法删除，请先移除该节点下的下级节点和人员",
+			"data": gin.H{
+				"childCount":  childCount,
+				"personCount": personCount,
+			},
+		})
+		return
+	}
+
+	// 删除节点
+	if err := database.DB.Delete(&unit).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "删除组织节点失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "删除成功",
+		"data":    nil,
+	})
+}
@@ -0,0 +1,119 @@
+package planner
+
+import (
+	"backend/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetOrgUnitsTree 获取组织架构树（接口5.20）
+func GetOrgUnitsTree(c *gin.Context) {
+	var units []database.OrgUnit
+	if err := database.DB.Order("unit_id").Find(&units).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询组织架构失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 统计各节点直属员工数
+	type countRow struct {
+		OrgUnitID int64
+		Count     int64
+	}
+	var counts []countRow
+	database.DB.Model(&database.Person{}).
+		Select("org_unit_id, COUNT(*) AS count").
+		Where("role = ? AND org_unit_id IS NOT NULL", "员工").
+		Group("org_unit_id").
+		Scan(&counts)
+	countMap := make(map[int64]int64, len(counts))
+	for _, row := range counts {
+		countMap[row.OrgUnitID] = row.Count
+	}
+
+	// 构建树形结构
+	type OrgUnitNode struct {
+		UnitID        int64          `json:"unitId"`
+		UnitName      string         `json:"unitName"`
+		UnitType      string         `json:"unitType"`
+		ParentID      *int64         `json:"parentId"`
+		EmployeeCount int64          `json:"employeeCount"`
+		Children      []*OrgUnitNode `json:"children"`
+	}
+
+	nodes := make(map[int64]*OrgUnitNode, len(units))
+	for _, u := range units {
+		nodes[u.UnitID] = &OrgUnitNode{
+			UnitID:        u.UnitID,
+			UnitName:      u.UnitName,
+			UnitType:      u.UnitType,
+			ParentID:      u.ParentID,
+			EmployeeCount: countMap[u.UnitID],
+			Children:      []*OrgUnitNode{},
+		}
+	}
+
+	roots := []*OrgUnitNode{}
+	for _, u := range units {
+		node := nodes[u.UnitID]
+		if u.ParentID != nil {
+			if parent, ok := nodes[*u.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    roots,
+	})
+}
+
+// orgUnitScope 解析查询参数 orgUnitId，返回该节点及其下级节点的ID列表
+// 未传 orgUnitId 时返回 nil 表示不限范围；参数错误时已写入响应，ok 为 false
+func orgUnitScope(c *gin.Context) (unitIDs []int64, ok bool) {
+	unitIdStr := c.Query("orgUnitId")
+	if unitIdStr == "" {
+		return nil, true
+	}
+
+	unitId, err := strconv.ParseInt(unitIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的组织节点ID",
+			"data":    nil,
+		})
+		return nil, false
+	}
+
+	var count int64
+	database.DB.Model(&database.OrgUnit{}).Where("unit_id = ?", unitId).Count(&count)
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "组织节点不存在",
+			"data":    nil,
+		})
+		return nil, false
+	}
+
+	unitIDs, err = database.OrgUnitSubtreeIDs(unitId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询组织架构失败",
+			"data":    nil,
+		})
+		return nil, false
+	}
+	return unitIDs, true
+}
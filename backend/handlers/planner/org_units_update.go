@@ -0,0 +1,162 @@
+package planner
+
+import (
+	"backend/database"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateOrgUnit 修改组织节点（接口5.22），可修改名称、类型或移动到其他上级节点
+func UpdateOrgUnit(c *gin.Context) {
+	// 获取路径参数 unitId
+	unitIdStr := c.Param("unitId")
+	unitId, err := strconv.ParseInt(unitIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的组织节点ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证节点是否存在
+	var unit database.OrgUnit
+	if err := database.DB.Where("unit_id = ?", unitId).First(&unit).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "组织节点不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 解析请求体（parentId 传 0 表示移动为根节点）
+	var req struct {
+		UnitName *string `json:"unitName"`
+		UnitType *string `json:"unitType"`
+		ParentID *int64  `json:"parentId"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	if req.UnitName == nil && req.UnitType == nil && req.ParentID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "没有提供更新内容",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 更新节点名称
+	if req.UnitName != nil {
+		name := strings.TrimSpace(*req.UnitName)
+		if len(name) == 0 || len(name) > 50 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "节点名称长度必须在1-50字符之间",
+				"data":    nil,
+			})
+			return
+		}
+		unit.UnitName = name
+	}
+
+	// 更新节点类型
+	if req.UnitType != nil {
+		if !database.IsValidOrgUnitType(*req.UnitType) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "节点类型必须是：公司/部门/班组/船舶",
+				"data":    nil,
+			})
+			return
+		}
+		unit.UnitType = *req.UnitType
+	}
+
+	// 更新上级节点，不能移动到自身或自己的下级节点之下
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			unit.ParentID = nil
+		} else {
+			subtree, err := database.OrgUnitSubtreeIDs(unitId)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"code":    500,
+					"message": "查询组织架构失败",
+					"data":    nil,
+				})
+				return
+			}
+			for _, id := range subtree {
+				if id == *req.ParentID {
+					c.JSON(http.StatusBadRequest, gin.H{
+						"code":    400,
+						"message": "不能将节点移动到自身或其下级节点之下",
+						"data":    nil,
+					})
+					return
+				}
+			}
+			unit.ParentID = req.ParentID
+		}
+	}
+
+	if msg := checkOrgUnitParent(unit.UnitType, unit.ParentID); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": msg,
+			"data":    nil,
+		})
+		return
+	}
+
+	// 类型变更后，现有下级节点仍需能挂在本节点之下
+	if req.UnitType != nil {
+		var children []database.OrgUnit
+		database.DB.Where("parent_id = ?", unitId).Find(&children)
+		for _, child := range children {
+			if !canBeChildOf(child.UnitType, unit.UnitType) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"code":    400,
+					"message": "下级节点「" + child.UnitName + "」（" + child.UnitType + "）不能挂在" + unit.UnitType + "之下",
+					"data":    nil,
+				})
+				return
+			}
+		}
+	}
+
+	// 执行更新（parent_id 可能被置空，使用 map 确保 NULL 也会写入）
+	updates := map[string]interface{}{
+		"unit_name": unit.UnitName,
+		"unit_type": unit.UnitType,
+		"parent_id": unit.ParentID,
+	}
+	if err := database.DB.Model(&database.OrgUnit{}).Where("unit_id = ?", unitId).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "修改组织节点失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "修改成功",
+		"data":    unit,
+	})
+}
@@ -0,0 +1,108 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/enrollment"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AddPersonCertificate 为人员登记证书（接口5.31），同名证书已存在时更新有效期
+func AddPersonCertificate(c *gin.Context) {
+	// 获取路径参数 personId
+	personIdStr := c.Param("personId")
+	personId, err := strconv.ParseInt(personIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的人员ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证人员是否存在
+	var person database.Person
+	if err := database.DB.Where("person_id = ?", personId).First(&person).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "人员不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 解析请求体
+	var req struct {
+		CertName  string `json:"certName" binding:"required"`
+		ExpiresOn string `json:"expiresOn"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	certName := strings.TrimSpace(req.CertName)
+	if len(certName) == 0 || len(certName) > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "证书名称长度必须在1-50字符之间",
+			"data":    nil,
+		})
+		return
+	}
+
+	var expiresOn *time.Time
+	if req.ExpiresOn != "" {
+		t, err := time.Parse("2006-01-02", req.ExpiresOn)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "有效期格式错误，应为YYYY-MM-DD",
+				"data":    nil,
+			})
+			return
+		}
+		expiresOn = &t
+	}
+
+	// 同名证书已存在时更新有效期
+	var cert database.PersonCertificate
+	err = database.DB.Where("person_id = ? AND cert_name = ?", personId, certName).First(&cert).Error
+	if err == nil {
+		cert.ExpiresOn = expiresOn
+		err = database.DB.Model(&cert).Update("expires_on", expiresOn).Error
+	} else {
+		cert = database.PersonCertificate{
+			PersonID:  personId,
+			CertName:  certName,
+			ExpiresOn: expiresOn,
+		}
+		err = database.DB.Create(&cert).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "登记证书失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 证书变动可能影响自动报名
+	enrollment.Notify()
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "登记成功",
+		"data":    formatCertificate(cert),
+	})
+}
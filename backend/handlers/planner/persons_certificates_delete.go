@@ -0,0 +1,60 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/enrollment"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeletePersonCertificate 删除人员的证书记录（接口5.32）
+func DeletePersonCertificate(c *gin.Context) {
+	// 获取路径参数
+	personId, err := strconv.ParseInt(c.Param("personId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的人员ID",
+			"data":    nil,
+		})
+		return
+	}
+	certId, err := strconv.ParseInt(c.Param("certId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的证书ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	result := database.DB.Where("cert_id = ? AND person_id = ?", certId, personId).Delete(&database.PersonCertificate{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "删除证书失败",
+			"data":    nil,
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "证书不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 证书变动可能影响自动报名
+	enrollment.Notify()
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "删除成功",
+		"data":    nil,
+	})
+}
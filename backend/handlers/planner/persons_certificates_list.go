@@ -0,0 +1,63 @@
+package planner
+
+import (
+	"backend/database"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPersonCertificates 获取人员持有的证书列表（接口5.30）
+func GetPersonCertificates(c *gin.Context) {
+	// 获取路径参数 personId
+	personIdStr := c.Param("personId")
+	personId, err := strconv.ParseInt(personIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的人员ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var certs []database.PersonCertificate
+	if err := database.DB.Where("person_id = ?", personId).Order("cert_name").Find(&certs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询证书失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	list := make([]gin.H, 0, len(certs))
+	for _, cert := range certs {
+		list = append(list, formatCertificate(cert))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    list,
+	})
+}
+
+// formatCertificate 将证书转换为响应格式，expired 表示已过有效期
+func formatCertificate(cert database.PersonCertificate) gin.H {
+	expiresOn := ""
+	expired := false
+	if cert.ExpiresOn != nil {
+		expiresOn = cert.ExpiresOn.Format("2006-01-02")
+		expired = expiresOn < time.Now().Format("2006-01-02")
+	}
+	return gin.H{
+		"certId":    cert.CertID,
+		"personId":  cert.PersonID,
+		"certName":  cert.CertName,
+		"expiresOn": expiresOn,
+		"expired":   expired,
+	}
+}
@@ -0,0 +1,53 @@
+package planner
+
+import (
+	"backend/authtoken"
+	"backend/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ForceLogout 强制用户下线（接口5.59）：注销该用户的全部会话，需要重新登录
+func ForceLogout(c *gin.Context) {
+	personId, err := strconv.ParseInt(c.Param("personId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的人员ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var person database.Person
+	if err := database.DB.Where("person_id = ?", personId).First(&person).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "人员不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	revoked, err := authtoken.RevokePersonSessions(person.PersonID, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "注销会话失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已强制下线",
+		"data": gin.H{
+			"personId":     person.PersonID,
+			"name":         person.Name,
+			"revokedCount": revoked,
+		},
+	})
+}
@@ -0,0 +1,89 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/enrollment"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetPersonOrgUnit 设置人员所属组织节点（接口5.24），orgUnitId 传 null 表示取消分配
+func SetPersonOrgUnit(c *gin.Context) {
+	// 获取路径参数 personId
+	personIdStr := c.Param("personId")
+	personId, err := strconv.ParseInt(personIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的人员ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证人员是否存在
+	var person database.Person
+	if err := database.DB.Where("person_id = ?", personId).First(&person).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "人员不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 解析请求体
+	var req struct {
+		OrgUnitID *int64 `json:"orgUnitId"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证组织节点是否存在
+	var unit database.OrgUnit
+	if req.OrgUnitID != nil {
+		if err := database.DB.Where("unit_id = ?", *req.OrgUnitID).First(&unit).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "组织节点不存在",
+				"data":    nil,
+			})
+			return
+		}
+	}
+
+	// 执行更新
+	if err := database.DB.Model(&database.Person{}).
+		Where("person_id = ?", personId).
+		Update("org_unit_id", req.OrgUnitID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "设置所属组织失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 组织节点变动可能影响自动报名
+	enrollment.Notify()
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "设置成功",
+		"data": gin.H{
+			"personId":    person.PersonID,
+			"personName":  person.Name,
+			"orgUnitId":   req.OrgUnitID,
+			"orgUnitName": unit.UnitName,
+		},
+	})
+}
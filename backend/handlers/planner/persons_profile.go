@@ -0,0 +1,124 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/enrollment"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdatePersonProfile 修改人员的职级和入职日期（接口5.29），用于自动报名规则匹配
+func UpdatePersonProfile(c *gin.Context) {
+	// 获取路径参数 personId
+	personIdStr := c.Param("personId")
+	personId, err := strconv.ParseInt(personIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的人员ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证人员是否存在
+	var person database.Person
+	if err := database.DB.Where("person_id = ?", personId).First(&person).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "人员不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 解析请求体（hireDate 传空字符串表示清除）
+	var req struct {
+		JobRank  *string `json:"jobRank"`
+		HireDate *string `json:"hireDate"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	// 构建更新映射
+	updates := make(map[string]interface{})
+
+	if req.JobRank != nil {
+		jobRank := strings.TrimSpace(*req.JobRank)
+		if len(jobRank) > 20 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "职级长度不能超过20字符",
+				"data":    nil,
+			})
+			return
+		}
+		updates["job_rank"] = jobRank
+		person.JobRank = jobRank
+	}
+
+	if req.HireDate != nil {
+		if *req.HireDate == "" {
+			updates["hire_date"] = nil
+			person.HireDate = nil
+		} else {
+			hireDate, err := time.Parse("2006-01-02", *req.HireDate)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"code":    400,
+					"message": "入职日期格式错误，应为YYYY-MM-DD",
+					"data":    nil,
+				})
+				return
+			}
+			updates["hire_date"] = hireDate
+			person.HireDate = &hireDate
+		}
+	}
+
+	if len(updates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "没有提供更新内容",
+			"data":    nil,
+		})
+		return
+	}
+
+	if err := database.DB.Model(&database.Person{}).Where("person_id = ?", personId).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "修改人员信息失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 人员信息变动可能影响自动报名
+	enrollment.Notify()
+
+	hireDate := ""
+	if person.HireDate != nil {
+		hireDate = person.HireDate.Format("2006-01-02")
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "修改成功",
+		"data": gin.H{
+			"personId":   person.PersonID,
+			"personName": person.Name,
+			"jobRank":    person.JobRank,
+			"hireDate":   hireDate,
+		},
+	})
+}
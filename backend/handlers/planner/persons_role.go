@@ -0,0 +1,113 @@
+package planner
+
+import (
+	"backend/accounts"
+	"backend/authtoken"
+	"backend/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChangePersonRole 修改人员角色（接口5.67）：修改后注销该用户的全部会话，重新登录后使用新角色
+func ChangePersonRole(c *gin.Context) {
+	personId, err := strconv.ParseInt(c.Param("personId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的人员ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var req struct {
+		Role string `json:"role" binding:"required,oneof=employee teacher planner"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	if personId == c.GetInt64("personId") {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "不能修改自己的角色",
+			"data":    nil,
+		})
+		return
+	}
+
+	var person database.Person
+	if err := database.DB.Where("person_id = ?", personId).First(&person).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "人员不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	role := accounts.RoleDisplay(req.Role)
+	if person.Role == role {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "角色未变化",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 讲师仍主讲课程时不能改为其他角色，需先更换课程的讲师
+	if person.Role == accounts.RoleDisplay(accounts.RoleTeacher) {
+		var courseCount int64
+		database.DB.Model(&database.Course{}).Where("teacher_id = ?", person.PersonID).Count(&courseCount)
+		if courseCount > 0 {
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    409,
+				"message": "该讲师仍主讲" + strconv.FormatInt(courseCount, 10) + "门课程，请先更换课程的讲师",
+				"data":    gin.H{"courseCount": courseCount},
+			})
+			return
+		}
+	}
+
+	previous := person.Role
+	if err := database.DB.Model(&person).Update("role", role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "修改角色失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 访问令牌中带有角色，注销会话使旧角色的令牌立即失效
+	revoked, err := authtoken.RevokePersonSessions(person.PersonID, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "角色已修改，但注销会话失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "角色已修改",
+		"data": gin.H{
+			"personId":        person.PersonID,
+			"name":            person.Name,
+			"previousRole":    accounts.RoleCode(previous),
+			"role":            req.Role,
+			"roleDisplay":     role,
+			"revokedSessions": revoked,
+		},
+	})
+}
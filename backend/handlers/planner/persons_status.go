@@ -0,0 +1,106 @@
+package planner
+
+import (
+	"backend/authtoken"
+	"backend/database"
+	"backend/enrollment"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetAccountStatus 停用或启用账号（接口5.68）：停用后不能登录，已登录的会话全部注销，并移出由报名规则加入的计划；人员及其培训记录保留
+func SetAccountStatus(c *gin.Context) {
+	personId, err := strconv.ParseInt(c.Param("personId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的人员ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var req struct {
+		Active *bool `json:"active" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	if personId == c.GetInt64("personId") {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "不能停用或启用自己的账号",
+			"data":    nil,
+		})
+		return
+	}
+
+	var account database.Account
+	if err := database.DB.Preload("Person").Where("person_id = ?", personId).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "账号不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	var deactivatedAt *time.Time
+	if !*req.Active {
+		if account.DeactivatedAt != nil {
+			deactivatedAt = account.DeactivatedAt
+		} else {
+			now := time.Now()
+			deactivatedAt = &now
+		}
+	}
+	if err := database.DB.Model(&database.Account{}).Where("account_id = ?", account.AccountID).Update("deactivated_at", deactivatedAt).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "修改账号状态失败",
+			"data":    nil,
+		})
+		return
+	}
+	account.DeactivatedAt = deactivatedAt
+
+	revoked := 0
+	message := "账号已启用"
+	if !*req.Active {
+		message = "账号已停用"
+		revoked, err = authtoken.RevokePersonSessions(account.PersonID, "")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    500,
+				"message": "账号已停用，但注销会话失败",
+				"data":    nil,
+			})
+			return
+		}
+		// 移出由报名规则加入的计划（已有评价记录的保留）；失败时由定时同步继续处理
+		if err := enrollment.SyncPerson(account.PersonID); err != nil {
+			log.Printf("账号停用后同步报名规则失败（人员 %d）: %v", account.PersonID, err)
+		}
+	} else {
+		// 重新启用后按报名规则重新加入匹配的计划
+		enrollment.Notify()
+	}
+
+	data := accountData(&account)
+	data["revokedSessions"] = revoked
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message,
+		"data":    data,
+	})
+}
@@ -0,0 +1,59 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/loginguard"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UnlockAccount 解除账号的登录锁定（接口5.60）：清除该账号的连续失败次数和锁定
+func UnlockAccount(c *gin.Context) {
+	personId, err := strconv.ParseInt(c.Param("personId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的人员ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var account database.Account
+	if err := database.DB.Preload("Person").Where("person_id = ?", personId).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "账号不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	status, err := loginguard.Status(account.LoginName)
+	if err == nil {
+		err = loginguard.Unlock(account.LoginName)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "解除锁定失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已解除锁定",
+		"data": gin.H{
+			"personId":  account.PersonID,
+			"name":      account.Person.Name,
+			"username":  account.LoginName,
+			"wasLocked": status.Locked(time.Now()),
+			"failures":  status.Failures,
+		},
+	})
+}
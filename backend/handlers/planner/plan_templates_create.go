@@ -0,0 +1,219 @@
+package planner
+
+import (
+	"backend/database"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// templateItem 模板中的一条课程安排，上课日期记为相对计划开始日期的天数
+type templateItem struct {
+	CourseID       int64  `json:"courseId"`
+	DayOffset      int    `json:"dayOffset"` // 0 表示计划开始当天
+	ClassBeginTime string `json:"classBeginTime"`
+	ClassEndTime   string `json:"classEndTime"`
+	LocationID     *int64 `json:"locationId"`
+	Location       string `json:"location"` // 地点已删除时按名称重新登记
+}
+
+// planBlueprint 创建培训计划所需的内容，来自模板或已有的培训计划
+type planBlueprint struct {
+	DurationDays  int
+	PlanStartTime string
+	PlanEndTime   string
+	Timezone      string
+	Items         []templateItem
+	Rules         []enrollmentRuleInput
+}
+
+// daysBetween 两个日期相差的天数（只比较日期部分）
+func daysBetween(from, to time.Time) int {
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// blueprintFromPlan 读取培训计划的课程安排和报名规则，日期换算为相对计划开始日期的天数
+// 重复课程展开后的每次上课都作为单独的课程安排保存
+func blueprintFromPlan(plan database.TrainingPlan) (*planBlueprint, error) {
+	var items []database.PlanCourseItem
+	err := database.DB.Where("plan_id = ?", plan.PlanID).
+		Order("class_date, class_begin_time, item_id").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	var rules []database.PlanEnrollmentRule
+	if err := database.DB.Where("plan_id = ?", plan.PlanID).Order("rule_id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	bp := &planBlueprint{
+		DurationDays:  daysBetween(plan.PlanStartDatetime, plan.PlanEndDatetime),
+		PlanStartTime: plan.PlanStartDatetime.Format("15:04:05"),
+		PlanEndTime:   plan.PlanEndDatetime.Format("15:04:05"),
+		Timezone:      plan.Timezone,
+		Items:         make([]templateItem, 0, len(items)),
+		Rules:         make([]enrollmentRuleInput, 0, len(rules)),
+	}
+	for _, item := range items {
+		bp.Items = append(bp.Items, templateItem{
+			CourseID:       item.CourseID,
+			DayOffset:      daysBetween(plan.PlanStartDatetime, item.ClassDate),
+			ClassBeginTime: item.ClassBeginTime,
+			ClassEndTime:   item.ClassEndTime,
+			LocationID:     item.LocationID,
+			Location:       item.Location,
+		})
+	}
+	for _, r := range rules {
+		in := enrollmentRuleInput{
+			OrgUnitID:          r.OrgUnitID,
+			JobRank:            r.JobRank,
+			MissingCertificate: r.MissingCertificate,
+		}
+		if r.HiredAfter != nil {
+			in.HiredAfter = r.HiredAfter.Format("2006-01-02")
+		}
+		bp.Rules = append(bp.Rules, in)
+	}
+	return bp, nil
+}
+
+// formatPlanTemplate 格式化培训计划模板（不含课程安排和报名规则明细）
+func formatPlanTemplate(template database.PlanTemplate, bp *planBlueprint, creatorName string) gin.H {
+	return gin.H{
+		"templateId":    template.TemplateID,
+		"templateName":  template.TemplateName,
+		"description":   template.Description,
+		"sourcePlanId":  template.SourcePlanID,
+		"durationDays":  template.DurationDays,
+		"planStartTime": template.PlanStartTime,
+		"planEndTime":   template.PlanEndTime,
+		"timezone":      template.Timezone,
+		"itemCount":     len(bp.Items),
+		"ruleCount":     len(bp.Rules),
+		"creatorId":     template.CreatorID,
+		"creatorName":   creatorName,
+		"createdAt":     template.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// templateBlueprint 读取模板中保存的课程安排和报名规则
+func templateBlueprint(template database.PlanTemplate) *planBlueprint {
+	bp := &planBlueprint{
+		DurationDays:  template.DurationDays,
+		PlanStartTime: template.PlanStartTime,
+		PlanEndTime:   template.PlanEndTime,
+		Timezone:      template.Timezone,
+		Items:         []templateItem{},
+		Rules:         []enrollmentRuleInput{},
+	}
+	json.Unmarshal([]byte(template.Items), &bp.Items)
+	json.Unmarshal([]byte(template.EnrollmentRules), &bp.Rules)
+	return bp
+}
+
+// CreatePlanTemplate 将培训计划保存为模板（接口5.52）
+// 保存课程安排的顺序、相对计划开始日期的天数、上课时间、地点和报名规则，不保存参训员工
+func CreatePlanTemplate(c *gin.Context) {
+	var req struct {
+		PlanID       int64  `json:"planId" binding:"required"`
+		TemplateName string `json:"templateName" binding:"required"`
+		Description  string `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	req.TemplateName = strings.TrimSpace(req.TemplateName)
+	req.Description = strings.TrimSpace(req.Description)
+	if req.TemplateName == "" || utf8.RuneCountInString(req.TemplateName) > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "模板名称长度1-50字符",
+			"data":    nil,
+		})
+		return
+	}
+	if utf8.RuneCountInString(req.Description) > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "模板说明长度不能超过200字符",
+			"data":    nil,
+		})
+		return
+	}
+
+	var plan database.TrainingPlan
+	if err := database.DB.Where("plan_id = ?", req.PlanID).First(&plan).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "培训计划不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	bp, err := blueprintFromPlan(plan)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询课程安排失败",
+			"data":    nil,
+		})
+		return
+	}
+	if len(bp.Items) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "培训计划中没有课程安排，不能保存为模板",
+			"data":    nil,
+		})
+		return
+	}
+
+	items, _ := json.Marshal(bp.Items)
+	rules, _ := json.Marshal(bp.Rules)
+	personId, _ := c.Get("personId")
+	template := database.PlanTemplate{
+		TemplateName:    req.TemplateName,
+		Description:     req.Description,
+		SourcePlanID:    &plan.PlanID,
+		DurationDays:    bp.DurationDays,
+		PlanStartTime:   bp.PlanStartTime,
+		PlanEndTime:     bp.PlanEndTime,
+		Timezone:        bp.Timezone,
+		Items:           string(items),
+		EnrollmentRules: string(rules),
+		CreatorID:       personId.(int64),
+		CreatedAt:       time.Now(),
+	}
+	if err := database.DB.Create(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "保存模板失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	var creator database.Person
+	database.DB.Where("person_id = ?", template.CreatorID).First(&creator)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "保存成功",
+		"data":    formatPlanTemplate(template, bp, creator.Name),
+	})
+}
@@ -0,0 +1,46 @@
+package planner
+
+import (
+	"backend/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeletePlanTemplate 删除培训计划模板（接口5.55），已按模板创建的培训计划不受影响
+func DeletePlanTemplate(c *gin.Context) {
+	templateId, err := strconv.ParseInt(c.Param("templateId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的模板ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	result := database.DB.Where("template_id = ?", templateId).Delete(&database.PlanTemplate{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "删除模板失败",
+			"data":    nil,
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "模板不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "删除成功",
+		"data":    nil,
+	})
+}
@@ -0,0 +1,94 @@
+package planner
+
+import (
+	"backend/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPlanTemplateDetail 获取培训计划模板详情（接口5.54），包括课程安排和报名规则
+func GetPlanTemplateDetail(c *gin.Context) {
+	templateId, err := strconv.ParseInt(c.Param("templateId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的模板ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var template database.PlanTemplate
+	if err := database.DB.Where("template_id = ?", templateId).First(&template).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "模板不存在",
+			"data":    nil,
+		})
+		return
+	}
+	bp := templateBlueprint(template)
+
+	var creator database.Person
+	database.DB.Where("person_id = ?", template.CreatorID).First(&creator)
+
+	// 课程名称和讲师按当前数据显示，课程已删除时名称为空
+	courseIDs := make([]int64, 0, len(bp.Items))
+	for _, item := range bp.Items {
+		courseIDs = append(courseIDs, item.CourseID)
+	}
+	courses := make(map[int64]database.Course)
+	if len(courseIDs) > 0 {
+		var found []database.Course
+		database.DB.Preload("Teacher").Where("course_id IN ?", courseIDs).Find(&found)
+		for _, course := range found {
+			courses[course.CourseID] = course
+		}
+	}
+	items := make([]gin.H, 0, len(bp.Items))
+	for _, item := range bp.Items {
+		course := courses[item.CourseID]
+		items = append(items, gin.H{
+			"courseId":       item.CourseID,
+			"courseName":     course.CourseName,
+			"teacherName":    course.Teacher.Name,
+			"dayOffset":      item.DayOffset,
+			"classBeginTime": item.ClassBeginTime,
+			"classEndTime":   item.ClassEndTime,
+			"locationId":     item.LocationID,
+			"location":       item.Location,
+		})
+	}
+
+	var units []database.OrgUnit
+	database.DB.Find(&units)
+	unitNames := make(map[int64]string, len(units))
+	for _, u := range units {
+		unitNames[u.UnitID] = u.UnitName
+	}
+	rules := make([]gin.H, 0, len(bp.Rules))
+	for _, r := range bp.Rules {
+		orgUnitName := ""
+		if r.OrgUnitID != nil {
+			orgUnitName = unitNames[*r.OrgUnitID]
+		}
+		rules = append(rules, gin.H{
+			"orgUnitId":          r.OrgUnitID,
+			"orgUnitName":        orgUnitName,
+			"jobRank":            r.JobRank,
+			"hiredAfter":         r.HiredAfter,
+			"missingCertificate": r.MissingCertificate,
+		})
+	}
+
+	data := formatPlanTemplate(template, bp, creator.Name)
+	data["items"] = items
+	data["enrollmentRules"] = rules
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    data,
+	})
+}
@@ -0,0 +1,282 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/enrollment"
+	"backend/planstatus"
+	"backend/scheduling"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// instantiateRequest 按模板或已有培训计划创建新计划的请求
+type instantiateRequest struct {
+	PlanName  string  `json:"planName" binding:"required"`
+	StartDate string  `json:"startDate" binding:"required"` // 新计划的开始日期，课程安排按相对天数排在此日期之后
+	Timezone  *string `json:"timezone"`                     // 不传时沿用模板或原计划的时区
+	Force     bool    `json:"force"`                        // 员工时间冲突时仍然创建，冲突记录为警告
+}
+
+// createPlanFromBlueprint 按模板内容创建培训计划、课程安排和报名规则
+// 每次上课都按新日期检查冲突，任意一次有冲突时都不创建；返回 HTTP 状态码、提示和响应数据
+// origin 说明计划的来源，记录在状态变更记录中
+func createPlanFromBlueprint(bp *planBlueprint, req instantiateRequest, creatorID int64, origin string) (int, string, gin.H) {
+	req.PlanName = strings.TrimSpace(req.PlanName)
+	if req.PlanName == "" || utf8.RuneCountInString(req.PlanName) > 50 {
+		return http.StatusBadRequest, "计划名称长度1-50字符", nil
+	}
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return http.StatusBadRequest, "开始日期格式错误，请使用 YYYY-MM-DD 格式", nil
+	}
+	timezone := bp.Timezone
+	if req.Timezone != nil {
+		timezone = strings.TrimSpace(*req.Timezone)
+		if len(timezone) > 64 || !scheduling.ValidTimezone(timezone) {
+			return http.StatusBadRequest, "无法识别的时区：" + timezone + "，请使用 IANA 时区名称，如 Asia/Shanghai", nil
+		}
+	}
+
+	startTime, err := time.Parse("2006-01-02 15:04:05", req.StartDate+" "+bp.PlanStartTime)
+	if err != nil {
+		return http.StatusInternalServerError, "模板中的计划开始时间有误", nil
+	}
+	endTime, err := time.Parse("2006-01-02 15:04:05", startDate.AddDate(0, 0, bp.DurationDays).Format("2006-01-02")+" "+bp.PlanEndTime)
+	if err != nil {
+		return http.StatusInternalServerError, "模板中的计划结束时间有误", nil
+	}
+	plan := database.TrainingPlan{
+		PlanName:          req.PlanName,
+		PlanStatus:        database.PlanStatusPlanning,
+		PlanStartDatetime: startTime,
+		PlanEndDatetime:   endTime,
+		CreatorID:         creatorID,
+		Timezone:          timezone,
+	}
+
+	// 报名规则中的组织节点可能已被删除，按当前数据重新校验
+	rules, msg := parseEnrollmentRules(bp.Rules)
+	if msg != "" {
+		return http.StatusBadRequest, "报名规则已失效：" + msg, nil
+	}
+	// 按报名规则将加入新计划的员工，用于检查地点容量和员工时间冲突
+	preview, err := enrollment.Preview(0, rules)
+	if err != nil {
+		return http.StatusInternalServerError, "计算报名名单失败", nil
+	}
+	roster := make([]int64, 0, len(preview.ToAdd))
+	for _, m := range preview.ToAdd {
+		roster = append(roster, m.PersonID)
+	}
+
+	// 先确定各次上课的课程、地点和日期，再一次读取涉及的讲师、地点在日期范围内的占用情况
+	type occurrence struct {
+		course  *database.Course
+		loc     *database.Location
+		booking scheduling.Booking
+	}
+	courses := make(map[int64]*database.Course)
+	conflicts := make([]occurrenceConflict, 0)
+	occurrences := make([]occurrence, 0, len(bp.Items))
+	var teacherIDs, locationIDs []int64
+	var firstDate, lastDate string
+	for _, ti := range bp.Items {
+		classDate := startDate.AddDate(0, 0, ti.DayOffset).Format("2006-01-02")
+		course, ok := courses[ti.CourseID]
+		if !ok {
+			course = &database.Course{}
+			if err := database.DB.Preload("Teacher").Where("course_id = ?", ti.CourseID).First(course).Error; err != nil {
+				course = nil
+			} else {
+				teacherIDs = append(teacherIDs, course.TeacherID)
+			}
+			courses[ti.CourseID] = course
+		}
+		if course == nil {
+			conflicts = append(conflicts, occurrenceConflict{ClassDate: classDate, Message: "课程已被删除"})
+			continue
+		}
+		// 地点已删除时按名称重新登记
+		var loc *database.Location
+		if ti.LocationID != nil {
+			loc, _ = resolveLocation(ti.LocationID, "")
+		}
+		if loc == nil {
+			var msg string
+			if loc, msg = resolveLocation(nil, ti.Location); msg != "" {
+				conflicts = append(conflicts, occurrenceConflict{ClassDate: classDate, Message: course.CourseName + "：" + msg})
+				continue
+			}
+		}
+		locationIDs = append(locationIDs, loc.LocationID)
+		if firstDate == "" || classDate < firstDate {
+			firstDate = classDate
+		}
+		if classDate > lastDate {
+			lastDate = classDate
+		}
+
+		occurrences = append(occurrences, occurrence{course: course, loc: loc, booking: scheduling.Booking{
+			PlanName:       plan.PlanName,
+			CourseID:       course.CourseID,
+			CourseName:     course.CourseName,
+			TeacherName:    course.Teacher.Name,
+			ClassDate:      classDate,
+			ClassBeginTime: ti.ClassBeginTime,
+			ClassEndTime:   ti.ClassEndTime,
+			LocationID:     &loc.LocationID,
+			Location:       loc.LocationName,
+			Timezone:       scheduling.ResolveTimezone(loc, plan),
+		}})
+	}
+
+	var occ *scheduling.Occupancy
+	if len(occurrences) > 0 {
+		// 新计划尚未保存，没有参训员工，员工时间冲突按报名规则计算的名单另行检查
+		if occ, err = scheduling.LoadOccupancy(0, teacherIDs, locationIDs, firstDate, lastDate); err != nil {
+			return http.StatusInternalServerError, "检查冲突失败", nil
+		}
+	}
+	employeeConflicts := make([]scheduling.EmployeeConflict, 0)
+	items := make([]database.PlanCourseItem, 0, len(occurrences))
+	for _, o := range occurrences {
+		course, loc, booking, classDate := o.course, o.loc, o.booking, o.booking.ClassDate
+		if conflict, _ := checkOccurrence(occ, course.TeacherID, loc, booking, req.Force); conflict != nil {
+			conflict.Message = course.CourseName + "：" + conflict.Message
+			conflicts = append(conflicts, *conflict)
+			continue
+		}
+		if loc.Capacity > 0 && len(roster) > loc.Capacity {
+			conflicts = append(conflicts, occurrenceConflict{
+				ClassDate: classDate,
+				Message:   course.CourseName + "：容量不足：" + loc.LocationName + "最多容纳" + strconv.Itoa(loc.Capacity) + "人，按报名规则将有" + strconv.Itoa(len(roster)) + "名参训员工",
+				Detail: gin.H{
+					"locationId":   loc.LocationID,
+					"locationName": loc.LocationName,
+					"capacity":     loc.Capacity,
+					"rosterSize":   len(roster),
+				},
+			})
+			continue
+		}
+		found, err := scheduling.RosterConflicts(booking, roster)
+		if err != nil {
+			return http.StatusInternalServerError, "检查员工时间冲突失败", nil
+		}
+		if len(found) > 0 && !req.Force {
+			conflicts = append(conflicts, occurrenceConflict{
+				ClassDate: classDate,
+				Message:   course.CourseName + "：" + employeeConflictMessage(found),
+				Detail:    gin.H{"conflicts": found},
+			})
+			continue
+		}
+		employeeConflicts = append(employeeConflicts, found...)
+
+		date, _ := time.Parse("2006-01-02", classDate)
+		items = append(items, database.PlanCourseItem{
+			CourseID:        course.CourseID,
+			ClassDate:       date,
+			ClassBeginTime:  booking.ClassBeginTime,
+			ClassEndTime:    booking.ClassEndTime,
+			Location:        loc.LocationName,
+			LocationID:      &loc.LocationID,
+			ConflictWarning: scheduling.FormatWarning(found),
+			Timezone:        booking.Timezone,
+		})
+	}
+	if len(conflicts) > 0 {
+		return http.StatusBadRequest, "共" + strconv.Itoa(len(bp.Items)) + "次上课，其中" + strconv.Itoa(len(conflicts)) + "次存在冲突，未创建培训计划", gin.H{
+			"occurrenceCount": len(bp.Items),
+			"conflicts":       conflicts,
+		}
+	}
+
+	// 计划、课程安排和报名规则在同一事务中创建
+	var result *enrollment.Result
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&plan).Error; err != nil {
+			return err
+		}
+		if err := planstatus.RecordCreated(tx, plan, creatorID, origin); err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].PlanID = plan.PlanID
+		}
+		if len(items) > 0 {
+			if err := tx.Create(&items).Error; err != nil {
+				return err
+			}
+		}
+		var err error
+		result, err = enrollment.ReplaceRulesIn(tx, plan.PlanID, rules)
+		return err
+	})
+	if err != nil {
+		return http.StatusInternalServerError, "创建培训计划失败", nil
+	}
+
+	return http.StatusOK, "创建成功", gin.H{
+		"planId":            plan.PlanID,
+		"planName":          plan.PlanName,
+		"planStatus":        plan.PlanStatus,
+		"planStartDatetime": plan.PlanStartDatetime.Format("2006-01-02 15:04:05"),
+		"planEndDatetime":   plan.PlanEndDatetime.Format("2006-01-02 15:04:05"),
+		"timezone":          plan.Timezone,
+		"creatorId":         plan.CreatorID,
+		"itemCount":         len(items),
+		"enrollment":        result,
+		"employeeConflicts": employeeConflicts,
+	}
+}
+
+// CreatePlanFromTemplate 按模板创建培训计划（接口5.56）
+func CreatePlanFromTemplate(c *gin.Context) {
+	templateId, err := strconv.ParseInt(c.Param("templateId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的模板ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var req instantiateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	var template database.PlanTemplate
+	if err := database.DB.Where("template_id = ?", templateId).First(&template).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "模板不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	personId, _ := c.Get("personId")
+	status, msg, data := createPlanFromBlueprint(templateBlueprint(template), req, personId.(int64), "按模板「"+template.TemplateName+"」创建")
+	if status == http.StatusOK {
+		data["templateId"] = template.TemplateID
+	}
+	c.JSON(status, gin.H{
+		"code":    status,
+		"message": msg,
+		"data":    data,
+	})
+}
@@ -0,0 +1,80 @@
+package planner
+
+import (
+	"backend/database"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPlanTemplatesList 获取培训计划模板列表（接口5.53），可按模板名称搜索
+func GetPlanTemplatesList(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if err != nil || pageSize < 1 {
+		pageSize = 20
+	}
+
+	query := database.DB.Model(&database.PlanTemplate{})
+	if keyword := strings.TrimSpace(c.Query("keyword")); keyword != "" {
+		query = query.Where("template_name LIKE ?", "%"+keyword+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询模板失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	var templates []database.PlanTemplate
+	err = query.Order("created_at DESC, template_id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&templates).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询模板失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	creatorIDs := make([]int64, 0, len(templates))
+	for _, t := range templates {
+		creatorIDs = append(creatorIDs, t.CreatorID)
+	}
+	creatorNames := make(map[int64]string)
+	if len(creatorIDs) > 0 {
+		var persons []database.Person
+		database.DB.Where("person_id IN ?", creatorIDs).Find(&persons)
+		for _, p := range persons {
+			creatorNames[p.PersonID] = p.Name
+		}
+	}
+
+	list := make([]gin.H, 0, len(templates))
+	for _, t := range templates {
+		list = append(list, formatPlanTemplate(t, templateBlueprint(t), creatorNames[t.CreatorID]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"total":    total,
+			"page":     page,
+			"pageSize": pageSize,
+			"list":     list,
+		},
+	})
+}
@@ -3,7 +3,9 @@ package planner
 import (
 	"net/http"
 	"strconv"
+	"time"
 	"backend/database"
+	"backend/scheduling"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,6 +27,7 @@ func AddEmployeesToPlan(c *gin.Context) {
 	// 解析请求体
 	var req struct {
 		EmployeeIds []int64 `json:"employeeIds" binding:"required"`
+		Force       bool    `json:"force"` // 员工时间冲突时仍然添加，冲突记录为课程安排上的警告
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -87,6 +90,61 @@ func AddEmployeesToPlan(c *gin.Context) {
 		existingMap[relation.PersonID] = true
 	}
 
+	// 加入后的参训人数不能超过该计划今后上课地点的容量
+	var newIds []int64
+	for _, employeeId := range req.EmployeeIds {
+		if !existingMap[employeeId] {
+			newIds = append(newIds, employeeId)
+		}
+	}
+	newCount := len(newIds)
+	if newCount > 0 {
+		roster, _ := scheduling.RosterSize(planId)
+		var smallest database.Location
+		err := database.DB.Model(&database.Location{}).
+			Joins("JOIN plan_course_item ON plan_course_item.location_id = location.location_id").
+			Where("plan_course_item.plan_id = ? AND location.capacity > 0", planId).
+			Where(database.DateFrom("plan_course_item.class_date", time.Now().Format("2006-01-02"))).
+			Order("location.capacity").
+			Limit(1).
+			Find(&smallest).Error
+		if err == nil && smallest.LocationID != 0 && roster+int64(newCount) > int64(smallest.Capacity) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "容量不足：" + smallest.LocationName + "最多容纳" + strconv.Itoa(smallest.Capacity) + "人",
+				"data": gin.H{
+					"locationId":   smallest.LocationID,
+					"locationName": smallest.LocationName,
+					"capacity":     smallest.Capacity,
+					"rosterSize":   roster,
+					"addingCount":  newCount,
+				},
+			})
+			return
+		}
+	}
+
+	// 新加入的员工在该计划今后的上课时间不能已有其他课程
+	conflicts, err := scheduling.EnrollmentConflicts(planId, newIds, time.Now().Format("2006-01-02"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "检查员工时间冲突失败",
+			"data":    nil,
+		})
+		return
+	}
+	if len(conflicts) > 0 && !req.Force {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": employeeConflictMessage(conflicts),
+			"data": gin.H{
+				"conflicts": conflicts,
+			},
+		})
+		return
+	}
+
 	// 批量插入新的关联关系
 	addedCount := 0
 	skippedCount := 0
@@ -100,6 +158,7 @@ func AddEmployeesToPlan(c *gin.Context) {
 		planEmployee := database.PlanEmployee{
 			PlanID:   planId,
 			PersonID: employeeId,
+			Source:   database.PlanEmployeeSourceManual,
 		}
 		if err := database.DB.Create(&planEmployee).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -112,6 +171,26 @@ func AddEmployeesToPlan(c *gin.Context) {
 		addedCount++
 	}
 
+	// 手动加回的员工不再被自动报名排除
+	database.DB.Where("plan_id = ? AND person_id IN ?", planId, req.EmployeeIds).
+		Delete(&database.PlanEnrollmentExclusion{})
+
+	// 强制添加时，在冲突的课程安排上记录警告
+	refreshed := make(map[int64]bool)
+	for _, cf := range conflicts {
+		if refreshed[cf.Item.ItemID] {
+			continue
+		}
+		refreshed[cf.Item.ItemID] = true
+		itemConflicts, err := scheduling.EmployeeConflicts(cf.Item)
+		if err != nil {
+			continue
+		}
+		database.DB.Model(&database.PlanCourseItem{}).
+			Where("item_id = ?", cf.Item.ItemID).
+			Update("conflict_warning", scheduling.FormatWarning(itemConflicts))
+	}
+
 	// 返回结果
 	message := "添加成功"
 	if skippedCount > 0 {
@@ -124,6 +203,7 @@ func AddEmployeesToPlan(c *gin.Context) {
 		"data": gin.H{
 			"addedCount":   addedCount,
 			"skippedCount": skippedCount,
+			"conflicts":    conflicts,
 		},
 	})
 }
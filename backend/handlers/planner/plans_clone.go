@@ -0,0 +1,64 @@
+package planner
+
+import (
+	"backend/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClonePlan 复制培训计划（接口5.57）
+// 按新的开始日期复制课程安排和报名规则，不复制参训员工和评价，冲突检查同 5.56
+func ClonePlan(c *gin.Context) {
+	planId, err := strconv.ParseInt(c.Param("planId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的培训计划ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var req instantiateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	var plan database.TrainingPlan
+	if err := database.DB.Where("plan_id = ?", planId).First(&plan).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "培训计划不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	bp, err := blueprintFromPlan(plan)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询课程安排失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	personId, _ := c.Get("personId")
+	status, msg, data := createPlanFromBlueprint(bp, req, personId.(int64), "复制计划「"+plan.PlanName+"」")
+	if status == http.StatusOK {
+		data["sourcePlanId"] = plan.PlanID
+	}
+	c.JSON(status, gin.H{
+		"code":    status,
+		"message": msg,
+		"data":    data,
+	})
+}
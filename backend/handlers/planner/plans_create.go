@@ -2,10 +2,14 @@ package planner
 
 import (
 	"net/http"
+	"strings"
 	"time"
 	"backend/database"
+	"backend/planstatus"
+	"backend/scheduling"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // CreatePlanRequest 创建培训计划请求
@@ -14,6 +18,7 @@ type CreatePlanRequest struct {
 	PlanStatus        string `json:"planStatus" binding:"required"`
 	PlanStartDatetime string `json:"planStartDatetime" binding:"required"`
 	PlanEndDatetime   string `json:"planEndDatetime" binding:"required"`
+	Timezone          string `json:"timezone"` // IANA 时区，课程安排的地点未设置时区时按此解释上课时间
 }
 
 // CreatePlan 创建培训计划（5.2接口）
@@ -40,12 +45,22 @@ func CreatePlan(c *gin.Context) {
 		return
 	}
 
-	// 验证计划状态
-	validStatuses := map[string]bool{"规划中": true, "进行中": true, "已完成": true}
-	if !validStatuses[req.PlanStatus] {
+	// 验证计划状态，新建的计划还没有课程安排，不能直接完成
+	if req.PlanStatus != database.PlanStatusPlanning && req.PlanStatus != database.PlanStatusInProgress {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
-			"message": "计划状态必须为：规划中/进行中/已完成",
+			"message": "新建计划的状态必须为：规划中/进行中",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证时区
+	req.Timezone = strings.TrimSpace(req.Timezone)
+	if len(req.Timezone) > 64 || !scheduling.ValidTimezone(req.Timezone) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无法识别的时区：" + req.Timezone + "，请使用 IANA 时区名称，如 Asia/Shanghai",
 			"data":    nil,
 		})
 		return
@@ -89,9 +104,16 @@ func CreatePlan(c *gin.Context) {
 		PlanStartDatetime: startTime,
 		PlanEndDatetime:   endTime,
 		CreatorID:         personID.(int64),
+		Timezone:          req.Timezone,
 	}
 
-	if err := database.DB.Create(&plan).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&plan).Error; err != nil {
+			return err
+		}
+		return planstatus.RecordCreated(tx, plan, plan.CreatorID, "")
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
 			"message": "创建失败",
@@ -114,6 +136,7 @@ func CreatePlan(c *gin.Context) {
 			"planStatus":        plan.PlanStatus,
 			"planStartDatetime": plan.PlanStartDatetime.Format("2006-01-02 15:04:05"),
 			"planEndDatetime":   plan.PlanEndDatetime.Format("2006-01-02 15:04:05"),
+			"timezone":          plan.Timezone,
 			"creatorId":         plan.CreatorID,
 			"creatorName":       creator.Name,
 		},
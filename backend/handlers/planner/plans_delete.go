@@ -6,6 +6,7 @@ import (
 	"backend/database"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // DeletePlan 删除培训计划（5.4接口）
@@ -57,8 +58,29 @@ func DeletePlan(c *gin.Context) {
 		return
 	}
 
-	// 删除培训计划
-	if err := database.DB.Delete(&plan).Error; err != nil {
+	// 删除培训计划（连同报名规则、排除名单、重复课程、排课方案、改期记录和状态变更记录）
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("plan_id = ?", planID).Delete(&database.PlanEnrollmentRule{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("plan_id = ?", planID).Delete(&database.PlanEnrollmentExclusion{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("plan_id = ?", planID).Delete(&database.CourseItemSeries{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("plan_id = ?", planID).Delete(&database.TimetableProposal{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("plan_id = ?", planID).Delete(&database.CourseItemReschedule{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("plan_id = ?", planID).Delete(&database.PlanStatusTransition{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&plan).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
 			"message": "删除失败",
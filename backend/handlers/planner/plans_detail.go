@@ -4,28 +4,33 @@ import (
 	"net/http"
 	"strconv"
 	"backend/database"
+	"backend/scheduling"
 
 	"github.com/gin-gonic/gin"
 )
 
 // CourseItemDetail 课程安排详情
 type CourseItemDetail struct {
-	ItemID         int64  `json:"itemId"`
-	CourseID       int64  `json:"courseId"`
-	CourseName     string `json:"courseName"`
-	CourseClass    string `json:"courseClass"`
-	TeacherID      int64  `json:"teacherId"`
-	TeacherName    string `json:"teacherName"`
-	ClassDate      string `json:"classDate"`
-	ClassBeginTime string `json:"classBeginTime"`
-	ClassEndTime   string `json:"classEndTime"`
-	Location       string `json:"location"`
+	ItemID          int64  `json:"itemId"`
+	CourseID        int64  `json:"courseId"`
+	CourseName      string `json:"courseName"`
+	CourseClass     string `json:"courseClass"`
+	TeacherID       int64  `json:"teacherId"`
+	TeacherName     string `json:"teacherName"`
+	ClassDate       string `json:"classDate"`
+	ClassBeginTime  string `json:"classBeginTime"`
+	ClassEndTime    string `json:"classEndTime"`
+	Timezone        string `json:"timezone"` // 上课日期和时间所在的时区
+	Location        string `json:"location"`
+	ConflictWarning string `json:"conflictWarning"` // 强制保存时记录的员工时间冲突
+	SeriesID        *int64 `json:"seriesId"`        // 所属重复课程，单次安排为 null
 }
 
 // EmployeeDetail 员工详情
 type EmployeeDetail struct {
 	PersonID int64  `json:"personId"`
 	Name     string `json:"name"`
+	Source   string `json:"source"` // manual：手动添加，rule：按报名规则自动加入
 }
 
 // PlanDetailResponse 培训计划详情响应
@@ -35,6 +40,7 @@ type PlanDetailResponse struct {
 	PlanStatus        string             `json:"planStatus"`
 	PlanStartDatetime string             `json:"planStartDatetime"`
 	PlanEndDatetime   string             `json:"planEndDatetime"`
+	Timezone          string             `json:"timezone"` // 计划的时区，为空表示系统默认时区
 	CreatorID         int64              `json:"creatorId"`
 	CreatorName       string             `json:"creatorName"`
 	CourseItems       []CourseItemDetail `json:"courseItems"`
@@ -76,10 +82,13 @@ func GetPlanDetail(c *gin.Context) {
 			c.course_class,
 			c.teacher_id,
 			p.name as teacher_name,
-			DATE_FORMAT(pci.class_date, '%Y-%m-%d') as class_date,
-			TIME_FORMAT(pci.class_begin_time, '%H:%i:%s') as class_begin_time,
-			TIME_FORMAT(pci.class_end_time, '%H:%i:%s') as class_end_time,
-			pci.location
+			` + database.FormatDate("pci.class_date") + ` as class_date,
+			pci.class_begin_time,
+			pci.class_end_time,
+			pci.timezone,
+			pci.location,
+			pci.conflict_warning,
+			pci.series_id
 		`).
 		Joins("JOIN course c ON pci.course_id = c.course_id").
 		Joins("JOIN person p ON c.teacher_id = p.person_id").
@@ -96,10 +105,14 @@ func GetPlanDetail(c *gin.Context) {
 		return
 	}
 
+	for i := range courseItems {
+		courseItems[i].Timezone = scheduling.TimezoneName(courseItems[i].Timezone)
+	}
+
 	// 查询关联员工
 	var employees []EmployeeDetail
 	err = database.DB.Table("plan_employee pe").
-		Select("pe.person_id, p.name").
+		Select("pe.person_id, p.name, pe.source").
 		Joins("JOIN person p ON pe.person_id = p.person_id").
 		Where("pe.plan_id = ?", planID).
 		Order("p.name ASC").
@@ -121,6 +134,7 @@ func GetPlanDetail(c *gin.Context) {
 		PlanStatus:        plan.PlanStatus,
 		PlanStartDatetime: plan.PlanStartDatetime.Format("2006-01-02 15:04:05"),
 		PlanEndDatetime:   plan.PlanEndDatetime.Format("2006-01-02 15:04:05"),
+		Timezone:          plan.Timezone,
 		CreatorID:         plan.CreatorID,
 		CreatorName:       plan.Creator.Name,
 		CourseItems:       courseItems,
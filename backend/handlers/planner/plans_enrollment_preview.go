@@ -0,0 +1,82 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/enrollment"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreviewEnrollment 预览按报名规则同步后的员工名单变化，不写入数据库（接口5.27）
+// 请求体传 rules 时预览这组规则（用于保存前试算），不传时预览计划已保存的规则
+func PreviewEnrollment(c *gin.Context) {
+	// 获取路径参数 planId
+	planIdStr := c.Param("planId")
+	planId, err := strconv.ParseInt(planIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的培训计划ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证培训计划是否存在
+	var plan database.TrainingPlan
+	if err := database.DB.Where("plan_id = ?", planId).First(&plan).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "培训计划不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 请求体可选
+	var req struct {
+		Rules *[]enrollmentRuleInput `json:"rules"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "请求参数错误：" + err.Error(),
+				"data":    nil,
+			})
+			return
+		}
+	}
+
+	var rules []database.PlanEnrollmentRule
+	if req.Rules != nil {
+		var msg string
+		rules, msg = parseEnrollmentRules(*req.Rules)
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": msg,
+				"data":    nil,
+			})
+			return
+		}
+	}
+
+	result, err := enrollment.Preview(planId, rules)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "预览报名规则失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    result,
+	})
+}
@@ -0,0 +1,93 @@
+package planner
+
+import (
+	"backend/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetEnrollmentRules 获取培训计划的自动报名规则（接口5.25）
+func GetEnrollmentRules(c *gin.Context) {
+	// 获取路径参数 planId
+	planIdStr := c.Param("planId")
+	planId, err := strconv.ParseInt(planIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的培训计划ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证培训计划是否存在
+	var plan database.TrainingPlan
+	if err := database.DB.Where("plan_id = ?", planId).First(&plan).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "培训计划不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	var rules []database.PlanEnrollmentRule
+	if err := database.DB.Where("plan_id = ?", planId).Order("rule_id").Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询报名规则失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 统计由规则加入的员工数
+	var ruleEnrolledCount int64
+	database.DB.Model(&database.PlanEmployee{}).
+		Where("plan_id = ? AND source = ?", planId, database.PlanEmployeeSourceRule).
+		Count(&ruleEnrolledCount)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"planId":            plan.PlanID,
+			"planName":          plan.PlanName,
+			"rules":             formatEnrollmentRules(rules),
+			"ruleEnrolledCount": ruleEnrolledCount,
+		},
+	})
+}
+
+// formatEnrollmentRules 将报名规则转换为响应格式（日期为 YYYY-MM-DD，附带组织节点名称）
+func formatEnrollmentRules(rules []database.PlanEnrollmentRule) []gin.H {
+	var units []database.OrgUnit
+	database.DB.Find(&units)
+	unitNames := make(map[int64]string, len(units))
+	for _, u := range units {
+		unitNames[u.UnitID] = u.UnitName
+	}
+
+	list := make([]gin.H, 0, len(rules))
+	for _, r := range rules {
+		hiredAfter := ""
+		if r.HiredAfter != nil {
+			hiredAfter = r.HiredAfter.Format("2006-01-02")
+		}
+		orgUnitName := ""
+		if r.OrgUnitID != nil {
+			orgUnitName = unitNames[*r.OrgUnitID]
+		}
+		list = append(list, gin.H{
+			"ruleId":             r.RuleID,
+			"orgUnitId":          r.OrgUnitID,
+			"orgUnitName":        orgUnitName,
+			"jobRank":            r.JobRank,
+			"hiredAfter":         hiredAfter,
+			"missingCertificate": r.MissingCertificate,
+		})
+	}
+	return list
+}
@@ -0,0 +1,136 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/enrollment"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// enrollmentRuleInput 报名规则请求参数，未填写的条件不参与匹配
+type enrollmentRuleInput struct {
+	OrgUnitID          *int64 `json:"orgUnitId"`
+	JobRank            string `json:"jobRank"`
+	HiredAfter         string `json:"hiredAfter"`
+	MissingCertificate string `json:"missingCertificate"`
+}
+
+// parseEnrollmentRules 校验并转换报名规则，返回错误提示，合法时提示为空字符串
+func parseEnrollmentRules(inputs []enrollmentRuleInput) ([]database.PlanEnrollmentRule, string) {
+	rules := make([]database.PlanEnrollmentRule, 0, len(inputs))
+	for i, in := range inputs {
+		prefix := "第" + strconv.Itoa(i+1) + "条规则："
+		rule := database.PlanEnrollmentRule{
+			OrgUnitID:          in.OrgUnitID,
+			JobRank:            strings.TrimSpace(in.JobRank),
+			MissingCertificate: strings.TrimSpace(in.MissingCertificate),
+		}
+
+		if rule.OrgUnitID == nil && rule.JobRank == "" && in.HiredAfter == "" && rule.MissingCertificate == "" {
+			return nil, prefix + "至少需要设置一个条件"
+		}
+		if rule.OrgUnitID != nil {
+			var count int64
+			database.DB.Model(&database.OrgUnit{}).Where("unit_id = ?", *rule.OrgUnitID).Count(&count)
+			if count == 0 {
+				return nil, prefix + "组织节点不存在"
+			}
+		}
+		if len(rule.JobRank) > 20 {
+			return nil, prefix + "职级长度不能超过20字符"
+		}
+		if len(rule.MissingCertificate) > 50 {
+			return nil, prefix + "证书名称长度不能超过50字符"
+		}
+		if in.HiredAfter != "" {
+			hiredAfter, err := time.Parse("2006-01-02", in.HiredAfter)
+			if err != nil {
+				return nil, prefix + "入职日期格式错误，应为YYYY-MM-DD"
+			}
+			rule.HiredAfter = &hiredAfter
+		}
+		rules = append(rules, rule)
+	}
+	return rules, ""
+}
+
+// UpdateEnrollmentRules 替换培训计划的自动报名规则并立即同步员工名单（接口5.26）
+func UpdateEnrollmentRules(c *gin.Context) {
+	// 获取路径参数 planId
+	planIdStr := c.Param("planId")
+	planId, err := strconv.ParseInt(planIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的培训计划ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证培训计划是否存在
+	var plan database.TrainingPlan
+	if err := database.DB.Where("plan_id = ?", planId).First(&plan).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "培训计划不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	if plan.PlanStatus == database.PlanStatusCompleted {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "已完成的培训计划不能修改报名规则",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 解析请求体（rules 为空数组表示清除全部规则，由规则加入的员工随之移除）
+	var req struct {
+		Rules []enrollmentRuleInput `json:"rules"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	rules, msg := parseEnrollmentRules(req.Rules)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": msg,
+			"data":    nil,
+		})
+		return
+	}
+
+	result, err := enrollment.ReplaceRules(planId, rules)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "保存报名规则失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "保存成功",
+		"data": gin.H{
+			"rules":  formatEnrollmentRules(rules),
+			"result": result,
+		},
+	})
+}
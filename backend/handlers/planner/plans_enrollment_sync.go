@@ -0,0 +1,61 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/enrollment"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SyncEnrollment 立即按已保存的报名规则同步员工名单（接口5.28）
+func SyncEnrollment(c *gin.Context) {
+	// 获取路径参数 planId
+	planIdStr := c.Param("planId")
+	planId, err := strconv.ParseInt(planIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的培训计划ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证培训计划是否存在
+	var plan database.TrainingPlan
+	if err := database.DB.Where("plan_id = ?", planId).First(&plan).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "培训计划不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	if plan.PlanStatus == database.PlanStatusCompleted {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "已完成的培训计划不再同步员工名单",
+			"data":    nil,
+		})
+		return
+	}
+
+	result, err := enrollment.Sync(planId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "同步员工名单失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "同步成功",
+		"data":    result,
+	})
+}
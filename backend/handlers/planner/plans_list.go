@@ -3,6 +3,7 @@ package planner
 import (
 	"net/http"
 	"strconv"
+	"time"
 	"backend/database"
 
 	"github.com/gin-gonic/gin"
@@ -43,14 +44,29 @@ func GetPlansList(c *gin.Context) {
 		pageSize = 10
 	}
 
+	// 日期筛选条件按 YYYY-MM-DD 解析
+	for _, date := range []string{startDate, endDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "日期格式错误，应为 YYYY-MM-DD",
+				"data":    nil,
+			})
+			return
+		}
+	}
+
 	// 构建查询
 	query := database.DB.Table("training_plan tp").
 		Select(`
 			tp.plan_id,
 			tp.plan_name,
 			tp.plan_status,
-			DATE_FORMAT(tp.plan_start_datetime, '%Y-%m-%d %H:%i:%s') as plan_start_datetime,
-			DATE_FORMAT(tp.plan_end_datetime, '%Y-%m-%d %H:%i:%s') as plan_end_datetime,
+			` + database.FormatDateTime("tp.plan_start_datetime") + ` as plan_start_datetime,
+			` + database.FormatDateTime("tp.plan_end_datetime") + ` as plan_end_datetime,
 			tp.creator_id,
 			p.name as creator_name,
 			COALESCE(employee_counts.count, 0) as employee_count,
@@ -73,10 +89,10 @@ func GetPlansList(c *gin.Context) {
 		query = query.Where("tp.plan_status = ?", status)
 	}
 	if startDate != "" {
-		query = query.Where("DATE(tp.plan_start_datetime) >= ?", startDate)
+		query = query.Where(database.DateFrom("tp.plan_start_datetime", startDate))
 	}
 	if endDate != "" {
-		query = query.Where("DATE(tp.plan_end_datetime) <= ?", endDate)
+		query = query.Where(database.DateUntil("tp.plan_end_datetime", endDate))
 	}
 	if keyword != "" {
 		query = query.Where("tp.plan_name LIKE ?", "%"+keyword+"%")
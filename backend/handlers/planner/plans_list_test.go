@@ -0,0 +1,39 @@
+package planner
+
+import (
+	"backend/database/dbtest"
+	"testing"
+)
+
+func TestGetPlansList(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", dbtest.Date(-3), dbtest.Date(2), dbtest.EmployeeID, dbtest.Employee2ID)
+	course := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	dbtest.CreateItem(t, plan, course, dbtest.Date(-1), "09:00:00", "10:00:00", "Asia/Shanghai")
+	dbtest.CreatePlan(t, "往期培训", dbtest.Date(-30), dbtest.Date(-20))
+
+	var data struct {
+		Total int64          `json:"total"`
+		List  []PlanListItem `json:"list"`
+	}
+	target := "/planner/plans?startDate=" + dbtest.Date(-3) + "&endDate=" + dbtest.Date(2)
+	dbtest.Get(t, GetPlansList, dbtest.PlannerID, "课程大纲制定者", target).Decode(t, &data)
+
+	if data.Total != 1 || len(data.List) != 1 {
+		t.Fatalf("日期范围内应有 1 个计划，实际 %d 个：%+v", data.Total, data.List)
+	}
+	got := data.List[0]
+	if got.PlanID != plan.PlanID || got.PlanName != "安全培训" || got.CreatorName != "张主管" {
+		t.Errorf("计划信息不正确：%+v", got)
+	}
+	if want := dbtest.Date(-3) + " 08:00:00"; got.PlanStartDatetime != want {
+		t.Errorf("planStartDatetime 应为 %q（YYYY-MM-DD HH:mm:ss），实际为 %q", want, got.PlanStartDatetime)
+	}
+	if want := dbtest.Date(2) + " 18:00:00"; got.PlanEndDatetime != want {
+		t.Errorf("planEndDatetime 应为 %q（YYYY-MM-DD HH:mm:ss），实际为 %q", want, got.PlanEndDatetime)
+	}
+	if got.EmployeeCount != 2 || got.CourseCount != 1 {
+		t.Errorf("应有 2 名员工、1 条课程安排，实际 %d、%d", got.EmployeeCount, got.CourseCount)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"backend/database"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
 )
 
 // RemoveEmployeeFromPlan 从培训计划移除员工（接口5.7）
@@ -94,6 +95,14 @@ func RemoveEmployeeFromPlan(c *gin.Context) {
 		return
 	}
 
+	// 计划设置了报名规则时记录排除，避免下次同步又被自动加入
+	var ruleCount int64
+	database.DB.Model(&database.PlanEnrollmentRule{}).Where("plan_id = ?", planId).Count(&ruleCount)
+	if ruleCount > 0 {
+		database.DB.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&database.PlanEnrollmentExclusion{PlanID: planId, PersonID: employeeId})
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "移除成功",
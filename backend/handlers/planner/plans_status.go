@@ -0,0 +1,107 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/planstatus"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPlanStatus 获取培训计划的状态、可变更到的状态和状态变更记录（接口5.58）
+func GetPlanStatus(c *gin.Context) {
+	planId, err := strconv.ParseInt(c.Param("planId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的培训计划ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var plan database.TrainingPlan
+	if err := database.DB.Where("plan_id = ?", planId).First(&plan).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "培训计划不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 可变更到的状态及当前不满足的条件
+	now := time.Now()
+	next := make([]gin.H, 0)
+	for _, to := range planstatus.Next(plan.PlanStatus) {
+		blockers, err := planstatus.Check(plan, to, now)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    500,
+				"message": "检查计划状态失败",
+				"data":    nil,
+			})
+			return
+		}
+		next = append(next, gin.H{
+			"status":   to,
+			"allowed":  len(blockers) == 0,
+			"blockers": blockers,
+		})
+	}
+
+	var records []database.PlanStatusTransition
+	if err := database.DB.Where("plan_id = ?", planId).Order("created_at DESC, transition_id DESC").Find(&records).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询状态变更记录失败",
+			"data":    nil,
+		})
+		return
+	}
+	actorIDs := make([]int64, 0, len(records))
+	for _, r := range records {
+		if r.ActorID != nil {
+			actorIDs = append(actorIDs, *r.ActorID)
+		}
+	}
+	actorNames := make(map[int64]string)
+	if len(actorIDs) > 0 {
+		var persons []database.Person
+		database.DB.Where("person_id IN ?", actorIDs).Find(&persons)
+		for _, p := range persons {
+			actorNames[p.PersonID] = p.Name
+		}
+	}
+	history := make([]gin.H, 0, len(records))
+	for _, r := range records {
+		actorName := ""
+		if r.ActorID != nil {
+			actorName = actorNames[*r.ActorID]
+		}
+		history = append(history, gin.H{
+			"transitionId": r.TransitionID,
+			"fromStatus":   r.FromStatus,
+			"toStatus":     r.ToStatus,
+			"actorId":      r.ActorID,
+			"actorName":    actorName,
+			"source":       r.Source,
+			"reason":       r.Reason,
+			"createdAt":    r.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"planId":     plan.PlanID,
+			"planName":   plan.PlanName,
+			"planStatus": plan.PlanStatus,
+			"next":       next,
+			"history":    history,
+		},
+	})
+}
@@ -3,18 +3,25 @@ package planner
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 	"backend/database"
+	"backend/planstatus"
+	"backend/scheduling"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // UpdatePlanRequest 更新培训计划请求
 type UpdatePlanRequest struct {
-	PlanName          string `json:"planName"`
-	PlanStatus        string `json:"planStatus"`
-	PlanStartDatetime string `json:"planStartDatetime"`
-	PlanEndDatetime   string `json:"planEndDatetime"`
+	PlanName          string  `json:"planName"`
+	PlanStatus        string  `json:"planStatus"`
+	PlanStartDatetime string  `json:"planStartDatetime"`
+	PlanEndDatetime   string  `json:"planEndDatetime"`
+	Timezone          *string `json:"timezone"`     // IANA 时区，传空字符串表示改用系统默认时区
+	StatusReason      string  `json:"statusReason"` // 修改状态的原因，记录在状态变更记录中
 }
 
 // UpdatePlan 修改培训计划（5.3接口）
@@ -60,9 +67,10 @@ func UpdatePlan(c *gin.Context) {
 		updates["plan_name"] = req.PlanName
 	}
 
+	// 状态按 planstatus 的变更规则检查，与其他字段一起保存
+	newStatus := ""
 	if req.PlanStatus != "" {
-		validStatuses := map[string]bool{"规划中": true, "进行中": true, "已完成": true}
-		if !validStatuses[req.PlanStatus] {
+		if !planstatus.Valid(req.PlanStatus) {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"code":    400,
 				"message": "计划状态必须为：规划中/进行中/已完成",
@@ -70,7 +78,64 @@ func UpdatePlan(c *gin.Context) {
 			})
 			return
 		}
-		updates["plan_status"] = req.PlanStatus
+		if req.PlanStatus != plan.PlanStatus {
+			if !planstatus.Allowed(plan.PlanStatus, req.PlanStatus) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"code":    400,
+					"message": "计划状态不能从" + plan.PlanStatus + "变更为" + req.PlanStatus,
+					"data": gin.H{
+						"planStatus": plan.PlanStatus,
+						"next":       planstatus.Next(plan.PlanStatus),
+					},
+				})
+				return
+			}
+			blockers, err := planstatus.Check(plan, req.PlanStatus, time.Now())
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"code":    500,
+					"message": "检查计划状态失败",
+					"data":    nil,
+				})
+				return
+			}
+			if len(blockers) > 0 {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"code":    400,
+					"message": planstatus.Message(req.PlanStatus, blockers),
+					"data": gin.H{
+						"blockers": blockers,
+					},
+				})
+				return
+			}
+			newStatus = req.PlanStatus
+		}
+	}
+	req.StatusReason = strings.TrimSpace(req.StatusReason)
+	if utf8.RuneCountInString(req.StatusReason) > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "状态变更原因长度不能超过200字符",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 处理时区
+	if req.Timezone != nil {
+		timezone := strings.TrimSpace(*req.Timezone)
+		if len(timezone) > 64 || !scheduling.ValidTimezone(timezone) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "无法识别的时区：" + timezone + "，请使用 IANA 时区名称，如 Asia/Shanghai",
+				"data":    nil,
+			})
+			return
+		}
+		if timezone != plan.Timezone {
+			updates["timezone"] = timezone
+		}
 	}
 
 	// 处理开始时间
@@ -117,15 +182,51 @@ func UpdatePlan(c *gin.Context) {
 		return
 	}
 
-	// 更新数据库
+	// 更新数据库，状态变更与其他字段在同一事务中保存并记录
+	personId, _ := c.Get("personId")
+	actorID := personId.(int64)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if newStatus != "" {
+			if err := planstatus.Apply(tx, &plan, newStatus, &actorID, database.PlanStatusSourceManual, req.StatusReason); err != nil {
+				return err
+			}
+		}
+		if len(updates) > 0 {
+			return tx.Model(&database.TrainingPlan{}).Where("plan_id = ?", planID).Updates(updates).Error
+		}
+		return nil
+	})
+	if err == planstatus.ErrStatusChanged {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "修改失败",
+			"data":    nil,
+		})
+		return
+	}
 	if len(updates) > 0 {
-		if err := database.DB.Model(&plan).Updates(updates).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code":    500,
-				"message": "修改失败",
-				"data":    nil,
-			})
-			return
+		// 计划名称变化时，日历订阅中的上课事件需要更新
+		if updates["plan_name"] != nil {
+			database.DB.Model(&database.PlanCourseItem{}).Where("plan_id = ?", planID).
+				Update("sequence", gorm.Expr("sequence + 1"))
+		}
+		// 修改时区时，今后在未设置时区的地点上课的课程安排改按新时区解释（计划不再设置时区时用系统默认时区）
+		if planTimezone, ok := updates["timezone"].(string); ok {
+			timezone := scheduling.ResolveTimezone(nil, database.TrainingPlan{Timezone: planTimezone})
+			database.DB.Model(&database.PlanCourseItem{}).
+				Where("plan_id = ?", planID).
+				Where(database.DateFrom("class_date", time.Now().Format("2006-01-02"))).
+				Where("(location_id IS NULL OR location_id IN (?))",
+					database.DB.Model(&database.Location{}).Select("location_id").Where("timezone = ''")).
+				Updates(map[string]interface{}{"timezone": timezone, "sequence": gorm.Expr("sequence + 1")})
 		}
 	}
 
@@ -146,6 +247,7 @@ func UpdatePlan(c *gin.Context) {
 			"planStatus":        plan.PlanStatus,
 			"planStartDatetime": plan.PlanStartDatetime.Format("2006-01-02 15:04:05"),
 			"planEndDatetime":   plan.PlanEndDatetime.Format("2006-01-02 15:04:05"),
+			"timezone":          plan.Timezone,
 			"creatorId":         plan.CreatorID,
 			"creatorName":       creator.Name,
 		},
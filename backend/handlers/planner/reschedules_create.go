@@ -0,0 +1,180 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/scheduling"
+	"backend/scoring"
+	"backend/utils"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// rescheduleConfirmMessage 改期需要确认时的提示文字
+func rescheduleConfirmMessage(impact scheduling.RescheduleImpact) string {
+	parts := []string{}
+	if len(impact.EmployeeConflicts) > 0 {
+		persons := make(map[int64]bool)
+		for _, cf := range impact.EmployeeConflicts {
+			persons[cf.PersonID] = true
+		}
+		parts = append(parts, strconv.Itoa(len(persons))+"名员工在改期后的时间段已有其他课程安排")
+	}
+	if len(impact.InvalidEvaluations) > 0 {
+		parts = append(parts, strconv.Itoa(len(impact.InvalidEvaluations))+"份已提交的自评将早于上课时间")
+	}
+	return "改期影响：" + strings.Join(parts, "，") + "，确认后可传 force=true 继续改期"
+}
+
+// CreateReschedule 课程安排改期（接口5.50）
+// 先按 5.49 分析影响，讲师或地点冲突、讲师不可授课、禁止排课的非工作日时不改期；
+// 员工时间冲突或自评失效时需传 force=true。全部课程安排在同一事务中修改，失效的自评一并清除，并记录改期原因
+func CreateReschedule(c *gin.Context) {
+	var req rescheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" || utf8.RuneCountInString(req.Reason) > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请填写改期原因，长度1-500字符",
+			"data":    nil,
+		})
+		return
+	}
+
+	target, status, msg := buildReschedule(req)
+	if msg != "" {
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": msg,
+			"data":    nil,
+		})
+		return
+	}
+
+	impact, err := scheduling.AnalyzeReschedule(target.Moves, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "分析改期影响失败",
+			"data":    nil,
+		})
+		return
+	}
+	if impact.Blocked() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "改期后存在讲师、地点或工作日历冲突，未做调整",
+			"data":    formatRescheduleImpact(target, req, impact),
+		})
+		return
+	}
+	if impact.NeedsConfirmation() && !req.Force {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": rescheduleConfirmMessage(impact),
+			"data":    formatRescheduleImpact(target, req, impact),
+		})
+		return
+	}
+
+	// 强制改期时，员工冲突记录为各课程安排上的警告
+	itemConflicts := make(map[int64][]scheduling.EmployeeConflict)
+	for _, cf := range impact.EmployeeConflicts {
+		itemConflicts[cf.Item.ItemID] = append(itemConflicts[cf.Item.ItemID], cf)
+	}
+
+	moves, _ := json.Marshal(target.Moves)
+	personId, _ := c.Get("personId")
+	record := database.CourseItemReschedule{
+		PlanID:     target.Plan.PlanID,
+		ItemID:     target.ItemID,
+		ShiftDays:  req.ShiftDays,
+		Reason:     req.Reason,
+		Moves:      string(moves),
+		OperatorID: personId.(int64),
+		CreatedAt:  time.Now(),
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, m := range target.Moves {
+			classDate, _ := time.Parse("2006-01-02", m.ClassDate)
+			err := tx.Model(&database.PlanCourseItem{}).Where("item_id = ?", m.Item.ItemID).Updates(map[string]interface{}{
+				"class_date":       classDate,
+				"class_begin_time": m.ClassBeginTime,
+				"class_end_time":   m.ClassEndTime,
+				"conflict_warning": scheduling.FormatWarning(itemConflicts[m.Item.ItemID]),
+				"sequence":         gorm.Expr("sequence + 1"), // 日历订阅据此更新事件
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// 强制改期时，早于改期后上课时间的自评作废（讲师评分保留），员工在课程结束后重新提交
+		for _, e := range impact.InvalidEvaluations {
+			err := tx.Model(&database.AttendanceEvaluation{}).
+				Where("item_id = ? AND person_id = ?", e.ItemID, e.PersonID).
+				Updates(map[string]interface{}{
+					"self_comment":         "",
+					"self_score":           0,
+					"self_score_rationale": "",
+					"self_scoring_status":  "",
+					"understanding":        0,
+					"difficulty":           0,
+					"satisfaction":         0,
+				}).Error
+			if err != nil {
+				return err
+			}
+			if err := scoring.CancelPending(tx, e.PersonID, e.ItemID, utils.ScoreTypeSelf); err != nil {
+				return err
+			}
+		}
+
+		// 整个计划顺延时，计划的起止时间一起顺延
+		if target.ItemID == nil {
+			start, end := target.planShifted(req.ShiftDays)
+			record.PlanStartDatetime = &target.Plan.PlanStartDatetime
+			record.PlanEndDatetime = &target.Plan.PlanEndDatetime
+			err := tx.Model(&database.TrainingPlan{}).Where("plan_id = ?", target.Plan.PlanID).Updates(map[string]interface{}{
+				"plan_start_datetime": start,
+				"plan_end_datetime":   end,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Create(&record).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "改期失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	data := formatRescheduleImpact(target, req, impact)
+	data["rescheduleId"] = record.RescheduleID
+	data["reason"] = record.Reason
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "改期成功",
+		"data":    data,
+	})
+}
@@ -0,0 +1,46 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"testing"
+)
+
+func TestCreateRescheduleClearsInvalidEvaluations(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", dbtest.Date(-3), dbtest.Date(9), dbtest.EmployeeID, dbtest.Employee2ID)
+	course := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	item := dbtest.CreateItem(t, plan, course, dbtest.Date(-1), "09:00:00", "10:00:00", "Asia/Shanghai")
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: item.ItemID, SelfComment: "学会了灭火器的使用", SelfScore: 80,
+		Understanding: 4, Difficulty: 3, Satisfaction: 5, TeacherScore: 90, TeacherComment: "认真", ScoreRatio: 0.5,
+	})
+
+	body := map[string]interface{}{
+		"itemId": item.ItemID, "classDate": dbtest.Date(3), "reason": "讲师出差，补课", "force": true,
+	}
+	var data struct {
+		Impact struct {
+			InvalidEvaluations []struct {
+				PersonID int64 `json:"personId"`
+			} `json:"invalidEvaluations"`
+		} `json:"impact"`
+	}
+	dbtest.Post(t, CreateReschedule, dbtest.PlannerID, "课程大纲制定者", "/planner/reschedules", body).Decode(t, &data)
+	if len(data.Impact.InvalidEvaluations) != 1 || data.Impact.InvalidEvaluations[0].PersonID != dbtest.EmployeeID {
+		t.Fatalf("应报告赵员工的自评失效，实际 %+v", data.Impact.InvalidEvaluations)
+	}
+
+	// 自评清除，讲师评分保留
+	var evaluation database.AttendanceEvaluation
+	if err := database.DB.Where("item_id = ? AND person_id = ?", item.ItemID, dbtest.EmployeeID).First(&evaluation).Error; err != nil {
+		t.Fatal(err)
+	}
+	if evaluation.SelfComment != "" || evaluation.SelfScore != 0 || evaluation.Understanding != 0 {
+		t.Errorf("强制改期后失效的自评应清除，实际 %+v", evaluation)
+	}
+	if evaluation.TeacherScore != 90 || evaluation.TeacherComment != "认真" {
+		t.Errorf("强制改期后讲师评分应保留，实际 %+v", evaluation)
+	}
+}
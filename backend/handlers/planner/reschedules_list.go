@@ -0,0 +1,117 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/scheduling"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// formatReschedule 格式化改期记录
+func formatReschedule(record database.CourseItemReschedule, operatorName string) gin.H {
+	moves := []scheduling.Move{}
+	json.Unmarshal([]byte(record.Moves), &moves)
+
+	data := gin.H{
+		"rescheduleId": record.RescheduleID,
+		"planId":       record.PlanID,
+		"itemId":       record.ItemID,
+		"shiftDays":    record.ShiftDays,
+		"reason":       record.Reason,
+		"itemCount":    len(moves),
+		"moves":        moves,
+		"operatorId":   record.OperatorID,
+		"operatorName": operatorName,
+		"createdAt":    record.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	// 整个计划顺延时记录了顺延前的计划起止时间
+	if record.PlanStartDatetime != nil && record.PlanEndDatetime != nil {
+		data["planStartDatetimeBefore"] = record.PlanStartDatetime.Format("2006-01-02 15:04:05")
+		data["planEndDatetimeBefore"] = record.PlanEndDatetime.Format("2006-01-02 15:04:05")
+	}
+	return data
+}
+
+// GetReschedulesList 获取改期记录列表（接口5.51）
+func GetReschedulesList(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if err != nil || pageSize < 1 {
+		pageSize = 20
+	}
+
+	query := database.DB.Model(&database.CourseItemReschedule{})
+	if planIdStr := c.Query("planId"); planIdStr != "" {
+		planId, err := strconv.ParseInt(planIdStr, 10, 64)
+		if err == nil {
+			query = query.Where("plan_id = ?", planId)
+		}
+	}
+	// 按课程安排筛选时，包括整个计划顺延中涉及该安排的记录
+	if itemIdStr := c.Query("itemId"); itemIdStr != "" {
+		itemId, err := strconv.ParseInt(itemIdStr, 10, 64)
+		if err == nil {
+			query = query.Where("(item_id = ? OR (item_id IS NULL AND plan_id IN (?)))", itemId,
+				database.DB.Model(&database.PlanCourseItem{}).Select("plan_id").Where("item_id = ?", itemId))
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询改期记录失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	var records []database.CourseItemReschedule
+	err = query.Order("created_at DESC, reschedule_id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&records).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询改期记录失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	operatorIDs := make([]int64, 0, len(records))
+	for _, r := range records {
+		operatorIDs = append(operatorIDs, r.OperatorID)
+	}
+	operatorNames := make(map[int64]string)
+	if len(operatorIDs) > 0 {
+		var persons []database.Person
+		database.DB.Where("person_id IN ?", operatorIDs).Find(&persons)
+		for _, p := range persons {
+			operatorNames[p.PersonID] = p.Name
+		}
+	}
+
+	list := make([]gin.H, 0, len(records))
+	for _, r := range records {
+		list = append(list, formatReschedule(r, operatorNames[r.OperatorID]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"total":    total,
+			"page":     page,
+			"pageSize": pageSize,
+			"list":     list,
+		},
+	})
+}
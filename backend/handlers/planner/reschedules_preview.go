@@ -0,0 +1,216 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/scheduling"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxShiftDays 顺延天数的上限（前后各一年）
+const maxShiftDays = 366
+
+// rescheduleRequest 改期请求：单条课程安排（itemId）或整个培训计划（planId）
+// 按天数顺延（shiftDays），或把单条课程安排改到新的日期和时间
+type rescheduleRequest struct {
+	ItemID         *int64  `json:"itemId"`
+	PlanID         *int64  `json:"planId"`
+	ShiftDays      int     `json:"shiftDays"` // 正数往后顺延，负数提前
+	ClassDate      *string `json:"classDate"`
+	ClassBeginTime *string `json:"classBeginTime"`
+	ClassEndTime   *string `json:"classEndTime"`
+	Reason         string  `json:"reason"`
+	Force          bool    `json:"force"` // 员工时间冲突或自评失效时仍然改期
+}
+
+// rescheduleTarget 要改期的课程安排及改期后的时间
+type rescheduleTarget struct {
+	Plan   database.TrainingPlan
+	ItemID *int64 // 单条课程安排改期时为该安排，整个计划顺延时为 nil
+	Moves  []scheduling.Move
+}
+
+// planShifted 整个计划顺延时计划的新起止时间
+func (t rescheduleTarget) planShifted(days int) (time.Time, time.Time) {
+	return t.Plan.PlanStartDatetime.AddDate(0, 0, days), t.Plan.PlanEndDatetime.AddDate(0, 0, days)
+}
+
+// buildReschedule 校验改期请求并计算每条课程安排改期后的时间
+// 请求有误时返回 HTTP 状态码和错误提示
+func buildReschedule(req rescheduleRequest) (*rescheduleTarget, int, string) {
+	if (req.ItemID == nil) == (req.PlanID == nil) {
+		return nil, http.StatusBadRequest, "请指定要改期的课程安排（itemId）或培训计划（planId），二者只能选一"
+	}
+	newSlot := req.ClassDate != nil || req.ClassBeginTime != nil || req.ClassEndTime != nil
+	if req.ShiftDays != 0 && newSlot {
+		return nil, http.StatusBadRequest, "顺延天数和新的上课时间不能同时指定"
+	}
+	if req.ShiftDays == 0 && !newSlot {
+		return nil, http.StatusBadRequest, "请指定顺延天数（shiftDays）或新的上课时间"
+	}
+	if req.ShiftDays > maxShiftDays || req.ShiftDays < -maxShiftDays {
+		return nil, http.StatusBadRequest, "顺延天数不能超过" + strconv.Itoa(maxShiftDays) + "天"
+	}
+	if req.PlanID != nil && newSlot {
+		return nil, http.StatusBadRequest, "整个计划改期时只能按天数顺延"
+	}
+	if req.ClassDate != nil {
+		if _, err := time.Parse("2006-01-02", *req.ClassDate); err != nil {
+			return nil, http.StatusBadRequest, "日期格式错误，请使用 YYYY-MM-DD 格式"
+		}
+	}
+	for _, t := range []*string{req.ClassBeginTime, req.ClassEndTime} {
+		if t == nil {
+			continue
+		}
+		if _, err := time.Parse("15:04:05", *t); err != nil {
+			return nil, http.StatusBadRequest, "时间格式错误，请使用 HH:mm:ss 格式"
+		}
+	}
+
+	target := &rescheduleTarget{}
+	var items []database.PlanCourseItem
+	if req.ItemID != nil {
+		var item database.PlanCourseItem
+		if err := database.DB.Preload("Plan").Preload("Course.Teacher").Where("item_id = ?", *req.ItemID).First(&item).Error; err != nil {
+			return nil, http.StatusNotFound, "课程安排不存在"
+		}
+		target.Plan = item.Plan
+		target.ItemID = &item.ItemID
+		items = []database.PlanCourseItem{item}
+	} else {
+		if err := database.DB.Where("plan_id = ?", *req.PlanID).First(&target.Plan).Error; err != nil {
+			return nil, http.StatusNotFound, "培训计划不存在"
+		}
+		err := database.DB.Preload("Course.Teacher").
+			Where("plan_id = ?", target.Plan.PlanID).
+			Order("class_date, class_begin_time").
+			Find(&items).Error
+		if err != nil {
+			return nil, http.StatusInternalServerError, "查询课程安排失败"
+		}
+	}
+	if target.Plan.PlanStatus == database.PlanStatusCompleted {
+		return nil, http.StatusBadRequest, "已完成的培训计划不能改期"
+	}
+
+	// 上课地点所在楼宇或船舶（检查工作日历用）
+	locationIDs := []int64{}
+	for _, item := range items {
+		if item.LocationID != nil {
+			locationIDs = append(locationIDs, *item.LocationID)
+		}
+	}
+	sites := make(map[int64]string)
+	if len(locationIDs) > 0 {
+		var locations []database.Location
+		database.DB.Where("location_id IN ?", locationIDs).Find(&locations)
+		for i := range locations {
+			sites[locations[i].LocationID] = calendarSite(&locations[i])
+		}
+	}
+
+	target.Moves = make([]scheduling.Move, 0, len(items))
+	for _, item := range items {
+		move := scheduling.Move{
+			Item: scheduling.Booking{
+				ItemID:         item.ItemID,
+				PlanID:         item.PlanID,
+				PlanName:       target.Plan.PlanName,
+				CourseID:       item.CourseID,
+				CourseName:     item.Course.CourseName,
+				TeacherName:    item.Course.Teacher.Name,
+				ClassDate:      item.ClassDate.Format("2006-01-02"),
+				ClassBeginTime: item.ClassBeginTime,
+				ClassEndTime:   item.ClassEndTime,
+				LocationID:     item.LocationID,
+				Location:       item.Location,
+				Timezone:       item.Timezone,
+			},
+			TeacherID:      item.Course.TeacherID,
+			ClassDate:      item.ClassDate.AddDate(0, 0, req.ShiftDays).Format("2006-01-02"),
+			ClassBeginTime: item.ClassBeginTime,
+			ClassEndTime:   item.ClassEndTime,
+		}
+		if item.LocationID != nil {
+			move.Site = sites[*item.LocationID]
+		}
+		if req.ClassDate != nil {
+			move.ClassDate = *req.ClassDate
+		}
+		if req.ClassBeginTime != nil {
+			move.ClassBeginTime = *req.ClassBeginTime
+		}
+		if req.ClassEndTime != nil {
+			move.ClassEndTime = *req.ClassEndTime
+		}
+		if move.ClassBeginTime >= move.ClassEndTime {
+			return nil, http.StatusBadRequest, "结束时间必须晚于开始时间"
+		}
+		target.Moves = append(target.Moves, move)
+	}
+	return target, 0, ""
+}
+
+// formatRescheduleImpact 格式化改期影响报告
+func formatRescheduleImpact(target *rescheduleTarget, req rescheduleRequest, impact scheduling.RescheduleImpact) gin.H {
+	data := gin.H{
+		"planId":            target.Plan.PlanID,
+		"planName":          target.Plan.PlanName,
+		"itemId":            target.ItemID,
+		"shiftDays":         req.ShiftDays,
+		"itemCount":         len(target.Moves),
+		"blocked":           impact.Blocked(),
+		"needsConfirmation": impact.NeedsConfirmation(),
+		"impact":            impact,
+	}
+	if target.ItemID == nil {
+		start, end := target.planShifted(req.ShiftDays)
+		data["planStartDatetime"] = start.Format("2006-01-02 15:04:05")
+		data["planEndDatetime"] = end.Format("2006-01-02 15:04:05")
+	}
+	return data
+}
+
+// PreviewReschedule 课程安排改期影响分析（接口5.49），不修改数据
+// 列出受影响的员工和讲师、讲师和地点冲突、员工时间冲突以及改期后将失效的自评
+func PreviewReschedule(c *gin.Context) {
+	var req rescheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	target, status, msg := buildReschedule(req)
+	if msg != "" {
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": msg,
+			"data":    nil,
+		})
+		return
+	}
+
+	impact, err := scheduling.AnalyzeReschedule(target.Moves, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "分析改期影响失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    formatRescheduleImpact(target, req, impact),
+	})
+}
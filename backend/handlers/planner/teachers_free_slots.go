@@ -0,0 +1,108 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/scheduling"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTeachersFreeSlots 查询讲师在日期范围内的空闲时间段（接口5.43）
+// 扣除已有课程安排、讲师登记的可授课时间以外的时间和不可授课时间段
+func GetTeachersFreeSlots(c *gin.Context) {
+	badRequest := func(message string) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": message,
+			"data":    nil,
+		})
+	}
+
+	// 日期范围
+	start, err1 := time.Parse("2006-01-02", c.Query("startDate"))
+	end, err2 := time.Parse("2006-01-02", c.Query("endDate"))
+	if err1 != nil || err2 != nil || end.Before(start) {
+		badRequest("日期格式错误或结束日期早于开始日期，请使用 YYYY-MM-DD 格式")
+		return
+	}
+	if end.Sub(start) > 31*24*time.Hour {
+		badRequest("查询日期范围不能超过31天")
+		return
+	}
+
+	// 每天的查询时间段
+	dayStart, err1 := scheduling.ParseClock(c.DefaultQuery("dayStart", "08:00:00"))
+	dayEnd, err2 := scheduling.ParseClock(c.DefaultQuery("dayEnd", "18:00:00"))
+	if err1 != nil || err2 != nil || dayStart >= dayEnd {
+		badRequest("每天的时间段错误，请使用 HH:mm:ss 格式且开始早于结束")
+		return
+	}
+	minMinutes, err := strconv.Atoi(c.DefaultQuery("minMinutes", "30"))
+	if err != nil || minMinutes < 1 {
+		badRequest("最短空闲时长必须为正整数（分钟）")
+		return
+	}
+
+	// 讲师范围：指定讲师或全部讲师
+	query := database.DB.Where("role = ?", "讲师")
+	if teacherIdStr := c.Query("teacherId"); teacherIdStr != "" {
+		teacherId, err := strconv.ParseInt(teacherIdStr, 10, 64)
+		if err != nil {
+			badRequest("无效的讲师ID")
+			return
+		}
+		query = query.Where("person_id = ?", teacherId)
+	}
+	var teachers []database.Person
+	if err := query.Order("person_id").Find(&teachers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询讲师列表失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	list := make([]gin.H, 0, len(teachers))
+	if len(teachers) > 0 {
+		teacherIDs := make([]int64, 0, len(teachers))
+		for _, t := range teachers {
+			teacherIDs = append(teacherIDs, t.PersonID)
+		}
+		free, err := scheduling.TeacherFreeSlots(teacherIDs, start, end, dayStart, dayEnd, minMinutes)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    500,
+				"message": "查询讲师空闲时间失败",
+				"data":    nil,
+			})
+			return
+		}
+		for _, t := range teachers {
+			minutes := 0
+			for _, s := range free[t.PersonID] {
+				minutes += s.Minutes
+			}
+			list = append(list, gin.H{
+				"teacherId":   t.PersonID,
+				"teacherName": t.Name,
+				"freeMinutes": minutes,
+				"freeSlots":   free[t.PersonID],
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"startDate": start.Format("2006-01-02"),
+			"endDate":   end.Format("2006-01-02"),
+			"total":     len(list),
+			"list":      list,
+		},
+	})
+}
@@ -0,0 +1,271 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/scheduling"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// errProposalChanged 采纳期间方案已被其他请求采纳（方案中记录的课程安排ID已变化）
+var errProposalChanged = errors.New("排课方案已被修改，请刷新后重试")
+
+// AcceptTimetableProposal 采纳自动排课方案（接口5.42）
+// 可采纳全部或指定序号的上课；采纳前按当前数据重新检查冲突，任意一次有冲突时都不采纳
+func AcceptTimetableProposal(c *gin.Context) {
+	// 获取路径参数 proposalId
+	proposalIdStr := c.Param("proposalId")
+	proposalId, err := strconv.ParseInt(proposalIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的排课方案ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 解析请求体
+	var req struct {
+		Sessions []int `json:"sessions"` // 要采纳的上课序号，不传表示采纳全部未采纳的
+		Force    bool  `json:"force"`    // 员工时间冲突时仍然保存，冲突记录为警告
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	var proposal database.TimetableProposal
+	if err := database.DB.Where("proposal_id = ?", proposalId).First(&proposal).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "排课方案不存在",
+			"data":    nil,
+		})
+		return
+	}
+	var plan database.TrainingPlan
+	if err := database.DB.Where("plan_id = ?", proposal.PlanID).First(&plan).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "培训计划不存在",
+			"data":    nil,
+		})
+		return
+	}
+	_, sessions := formatTimetableProposal(proposal)
+
+	// 确定要采纳的上课
+	var chosen []int
+	if len(req.Sessions) == 0 {
+		for i, s := range sessions {
+			if s.ItemID == nil {
+				chosen = append(chosen, i)
+			}
+		}
+	} else {
+		seen := make(map[int]bool)
+		for _, idx := range req.Sessions {
+			if idx < 0 || idx >= len(sessions) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"code":    400,
+					"message": "上课序号不存在：" + strconv.Itoa(idx),
+					"data":    nil,
+				})
+				return
+			}
+			if sessions[idx].ItemID != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"code":    400,
+					"message": "第" + strconv.Itoa(idx) + "次上课已经采纳过",
+					"data":    nil,
+				})
+				return
+			}
+			if !seen[idx] {
+				seen[idx] = true
+				chosen = append(chosen, idx)
+			}
+		}
+	}
+	if len(chosen) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "方案中没有待采纳的上课",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 按当前数据重新检查冲突：先确定各次上课的课程、地点和时间，再一次读取涉及的讲师、地点在日期范围内的占用情况
+	type occurrence struct {
+		course  *database.Course
+		loc     *database.Location
+		booking scheduling.Booking
+	}
+	courses := make(map[int64]*database.Course)
+	locations := make(map[int64]*database.Location)
+	conflicts := make([]occurrenceConflict, 0)
+	occurrences := make([]occurrence, 0, len(chosen))
+	var teacherIDs, locationIDs []int64
+	var startDate, endDate string
+	for _, idx := range chosen {
+		s := sessions[idx]
+		course, ok := courses[s.CourseID]
+		if !ok {
+			course = &database.Course{}
+			if err := database.DB.Preload("Teacher").Where("course_id = ?", s.CourseID).First(course).Error; err != nil {
+				course = nil
+			} else {
+				teacherIDs = append(teacherIDs, course.TeacherID)
+			}
+			courses[s.CourseID] = course
+		}
+		loc, ok := locations[s.LocationID]
+		if !ok {
+			loc = &database.Location{}
+			if err := database.DB.Where("location_id = ?", s.LocationID).First(loc).Error; err != nil {
+				loc = nil
+			} else {
+				locationIDs = append(locationIDs, loc.LocationID)
+			}
+			locations[s.LocationID] = loc
+		}
+		if course == nil || loc == nil {
+			conflicts = append(conflicts, occurrenceConflict{ClassDate: s.ClassDate, Message: "课程或上课地点已被删除"})
+			continue
+		}
+
+		// 方案中的时间按计划的时区排出，换算到地点的时区保存
+		slot := scheduling.Slot{Date: s.ClassDate, Begin: s.ClassBeginTime, End: s.ClassEndTime, Timezone: plan.Timezone}.
+			In(scheduling.ResolveTimezone(loc, plan))
+		if startDate == "" || slot.Date < startDate {
+			startDate = slot.Date
+		}
+		if slot.Date > endDate {
+			endDate = slot.Date
+		}
+		occurrences = append(occurrences, occurrence{course: course, loc: loc, booking: scheduling.Booking{
+			PlanID:         plan.PlanID,
+			PlanName:       plan.PlanName,
+			CourseID:       course.CourseID,
+			CourseName:     course.CourseName,
+			TeacherName:    course.Teacher.Name,
+			ClassDate:      slot.Date,
+			ClassBeginTime: slot.Begin,
+			ClassEndTime:   slot.End,
+			LocationID:     &loc.LocationID,
+			Location:       loc.LocationName,
+			Timezone:       slot.Timezone,
+		}})
+	}
+
+	items := make([]database.PlanCourseItem, 0, len(chosen))
+	if len(occurrences) > 0 {
+		occ, err := scheduling.LoadOccupancy(plan.PlanID, teacherIDs, locationIDs, startDate, endDate)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    500,
+				"message": "检查冲突失败",
+				"data":    nil,
+			})
+			return
+		}
+		for _, o := range occurrences {
+			conflict, warning := checkOccurrence(occ, o.course.TeacherID, o.loc, o.booking, req.Force)
+			if conflict != nil {
+				conflicts = append(conflicts, *conflict)
+				continue
+			}
+			classDate, _ := time.Parse("2006-01-02", o.booking.ClassDate)
+			items = append(items, database.PlanCourseItem{
+				PlanID:          plan.PlanID,
+				CourseID:        o.course.CourseID,
+				ClassDate:       classDate,
+				ClassBeginTime:  o.booking.ClassBeginTime,
+				ClassEndTime:    o.booking.ClassEndTime,
+				Location:        o.loc.LocationName,
+				LocationID:      &o.loc.LocationID,
+				ConflictWarning: warning,
+				Timezone:        o.booking.Timezone,
+			})
+		}
+	}
+	if len(conflicts) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "共" + strconv.Itoa(len(chosen)) + "次上课，其中" + strconv.Itoa(len(conflicts)) + "次存在冲突，未采纳，请重新生成方案",
+			"data": gin.H{
+				"occurrenceCount": len(chosen),
+				"conflicts":       conflicts,
+			},
+		})
+		return
+	}
+
+	// 创建课程安排并记录到方案中
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&items).Error; err != nil {
+			return err
+		}
+		for i, idx := range chosen {
+			itemID := items[i].ItemID
+			sessions[idx].ItemID = &itemID
+		}
+		sessionsJSON, err := json.Marshal(sessions)
+		if err != nil {
+			return err
+		}
+		// 只有方案仍是读取时的内容才记录，同时采纳的请求中只有一个成功，其余回滚已创建的课程安排
+		result := tx.Model(&database.TimetableProposal{}).
+			Where("proposal_id = ? AND sessions = ?", proposal.ProposalID, proposal.Sessions).
+			Update("sessions", string(sessionsJSON))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errProposalChanged
+		}
+		return nil
+	})
+	if err == errProposalChanged {
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    409,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "采纳排课方案失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	accepted := make([]scheduling.ProposedSession, 0, len(chosen))
+	for _, idx := range chosen {
+		accepted = append(accepted, sessions[idx])
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "采纳成功",
+		"data": gin.H{
+			"proposalId":    proposal.ProposalID,
+			"acceptedCount": len(accepted),
+			"sessions":      accepted,
+		},
+	})
+}
@@ -0,0 +1,68 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"backend/scheduling"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestAcceptTimetableProposalPartially(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", dbtest.Date(1), dbtest.Date(9), dbtest.EmployeeID)
+	course := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	var room database.Location
+	if err := database.DB.Where("location_name = ?", "培训楼A101").First(&room).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := make([]scheduling.ProposedSession, 0, 3)
+	for i, days := range []int{2, 3, 4} {
+		sessions = append(sessions, scheduling.ProposedSession{
+			Index: i, CourseID: course.CourseID, CourseName: course.CourseName, TeacherID: dbtest.TeacherID,
+			ClassDate: dbtest.Date(days), ClassBeginTime: "09:00:00", ClassEndTime: "11:00:00",
+			LocationID: room.LocationID, Location: room.LocationName,
+		})
+	}
+	sessionsJSON, _ := json.Marshal(sessions)
+	proposal := database.TimetableProposal{PlanID: plan.PlanID, Sessions: string(sessionsJSON), Unplaced: "[]", CreatorID: dbtest.PlannerID, CreatedAt: time.Now()}
+	if err := database.DB.Create(&proposal).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	route := "/timetable-proposals/:proposalId/accept"
+	target := "/timetable-proposals/" + strconv.FormatInt(proposal.ProposalID, 10) + "/accept"
+	var data struct {
+		AcceptedCount int                          `json:"acceptedCount"`
+		Sessions      []scheduling.ProposedSession `json:"sessions"`
+	}
+
+	// 先采纳第 1 次，再不指定序号采纳其余的
+	dbtest.PostRoute(t, route, AcceptTimetableProposal, dbtest.PlannerID, "课程大纲制定者", target, map[string]interface{}{"sessions": []int{1}}).Decode(t, &data)
+	if data.AcceptedCount != 1 || data.Sessions[0].Index != 1 || data.Sessions[0].ItemID == nil {
+		t.Fatalf("应采纳第 1 次上课，实际 %+v", data)
+	}
+	dbtest.PostRoute(t, route, AcceptTimetableProposal, dbtest.PlannerID, "课程大纲制定者", target, map[string]interface{}{}).Decode(t, &data)
+	if data.AcceptedCount != 2 || data.Sessions[0].Index != 0 || data.Sessions[1].Index != 2 {
+		t.Fatalf("应采纳其余的第 0、2 次上课，实际 %+v", data)
+	}
+
+	var items int64
+	database.DB.Model(&database.PlanCourseItem{}).Where("plan_id = ?", plan.PlanID).Count(&items)
+	if items != 3 {
+		t.Fatalf("应生成 3 条课程安排，实际 %d 条", items)
+	}
+	database.DB.First(&proposal, proposal.ProposalID)
+	if err := json.Unmarshal([]byte(proposal.Sessions), &sessions); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range sessions {
+		if s.ItemID == nil {
+			t.Errorf("第 %d 次上课应记录课程安排ID", s.Index)
+		}
+	}
+}
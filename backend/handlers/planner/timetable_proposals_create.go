@@ -0,0 +1,247 @@
+package planner
+
+import (
+	"backend/database"
+	"backend/scheduling"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// formatTimetableProposal 格式化自动排课方案
+func formatTimetableProposal(proposal database.TimetableProposal) (gin.H, []scheduling.ProposedSession) {
+	sessions := []scheduling.ProposedSession{}
+	unplaced := []scheduling.UnplacedDemand{}
+	json.Unmarshal([]byte(proposal.Sessions), &sessions)
+	json.Unmarshal([]byte(proposal.Unplaced), &unplaced)
+
+	accepted := 0
+	for _, s := range sessions {
+		if s.ItemID != nil {
+			accepted++
+		}
+	}
+	return gin.H{
+		"proposalId":    proposal.ProposalID,
+		"planId":        proposal.PlanID,
+		"createdAt":     proposal.CreatedAt.Format("2006-01-02 15:04:05"),
+		"sessionCount":  len(sessions),
+		"acceptedCount": accepted,
+		"sessions":      sessions,
+		"unplaced":      unplaced,
+	}, sessions
+}
+
+// CreateTimetableProposal 为培训计划自动生成排课方案（接口5.40）
+// 方案只保存不生效，通过 5.42 整体或部分采纳后才生成课程安排
+func CreateTimetableProposal(c *gin.Context) {
+	// 获取路径参数 planId
+	planIdStr := c.Param("planId")
+	planId, err := strconv.ParseInt(planIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的培训计划ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 解析请求体
+	var req struct {
+		StartDate    string   `json:"startDate" binding:"required"`
+		EndDate      string   `json:"endDate" binding:"required"`
+		DayStart     string   `json:"dayStart"`    // 每天最早上课时间，默认 08:00:00
+		DayEnd       string   `json:"dayEnd"`      // 每天最晚下课时间，默认 18:00:00
+		SlotMinutes  int      `json:"slotMinutes"` // 开始时间粒度（分钟），默认30
+		Weekdays     []int    `json:"weekdays"`    // 上课的星期（1-7 表示周一到周日），默认周一到周五
+		ExcludeDates []string `json:"excludeDates"`
+		Courses      []struct {
+			CourseID        int64  `json:"courseId" binding:"required"`
+			Sessions        int    `json:"sessions" binding:"required"`
+			DurationMinutes int    `json:"durationMinutes" binding:"required"`
+			LocationID      *int64 `json:"locationId"`
+		} `json:"courses" binding:"required,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	// 验证培训计划是否存在
+	var plan database.TrainingPlan
+	if err := database.DB.Where("plan_id = ?", planId).First(&plan).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "培训计划不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	badRequest := func(message string) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": message,
+			"data":    nil,
+		})
+	}
+
+	// 日期范围
+	start, err1 := time.Parse("2006-01-02", req.StartDate)
+	end, err2 := time.Parse("2006-01-02", req.EndDate)
+	if err1 != nil || err2 != nil || end.Before(start) {
+		badRequest("日期格式错误或结束日期早于开始日期，请使用 YYYY-MM-DD 格式")
+		return
+	}
+	if end.Sub(start) > 92*24*time.Hour {
+		badRequest("排课日期范围不能超过92天")
+		return
+	}
+
+	// 每天的上课时间
+	if req.DayStart == "" {
+		req.DayStart = "08:00:00"
+	}
+	if req.DayEnd == "" {
+		req.DayEnd = "18:00:00"
+	}
+	dayStart, err1 := scheduling.ParseClock(req.DayStart)
+	dayEnd, err2 := scheduling.ParseClock(req.DayEnd)
+	if err1 != nil || err2 != nil || dayStart >= dayEnd {
+		badRequest("每天的上课时间段错误，请使用 HH:mm:ss 格式且开始早于结束")
+		return
+	}
+	if req.SlotMinutes == 0 {
+		req.SlotMinutes = 30
+	}
+	if req.SlotMinutes < 5 || req.SlotMinutes > 240 {
+		badRequest("开始时间粒度必须在5-240分钟之间")
+		return
+	}
+
+	weekdays := make(map[time.Weekday]bool)
+	if len(req.Weekdays) == 0 {
+		req.Weekdays = []int{1, 2, 3, 4, 5}
+	}
+	for _, w := range req.Weekdays {
+		if w < 1 || w > 7 {
+			badRequest("上课星期必须为1-7")
+			return
+		}
+		weekdays[time.Weekday(w%7)] = true
+	}
+
+	exclude := make(map[string]bool)
+	for _, d := range req.ExcludeDates {
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			badRequest("排除日期格式错误：" + d)
+			return
+		}
+		exclude[d] = true
+	}
+
+	// 排课需求
+	if len(req.Courses) == 0 {
+		badRequest("请至少提供一门课程")
+		return
+	}
+	demands := make([]scheduling.CourseDemand, 0, len(req.Courses))
+	for _, rc := range req.Courses {
+		var course database.Course
+		if err := database.DB.Preload("Teacher").Where("course_id = ?", rc.CourseID).First(&course).Error; err != nil {
+			badRequest("课程不存在：" + strconv.FormatInt(rc.CourseID, 10))
+			return
+		}
+		if rc.Sessions < 1 || rc.Sessions > 100 {
+			badRequest("课程" + course.CourseName + "的上课次数必须在1-100之间")
+			return
+		}
+		if rc.DurationMinutes < 15 || rc.DurationMinutes > dayEnd-dayStart {
+			badRequest("课程" + course.CourseName + "的每次时长必须至少15分钟且不超过每天的上课时间段")
+			return
+		}
+		if rc.LocationID != nil {
+			var count int64
+			database.DB.Model(&database.Location{}).Where("location_id = ?", *rc.LocationID).Count(&count)
+			if count == 0 {
+				badRequest("上课地点不存在：" + strconv.FormatInt(*rc.LocationID, 10))
+				return
+			}
+		}
+		demands = append(demands, scheduling.CourseDemand{
+			CourseID:        course.CourseID,
+			CourseName:      course.CourseName,
+			TeacherID:       course.TeacherID,
+			TeacherName:     course.Teacher.Name,
+			Sessions:        rc.Sessions,
+			DurationMinutes: rc.DurationMinutes,
+			LocationID:      rc.LocationID,
+		})
+	}
+
+	var rooms []database.Location
+	database.DB.Find(&rooms)
+	roster, _ := scheduling.RosterSize(planId)
+
+	result, err := scheduling.GenerateTimetable(scheduling.TimetableInput{
+		PlanID:       planId,
+		StartDate:    start,
+		EndDate:      end,
+		DayStart:     dayStart,
+		DayEnd:       dayEnd,
+		SlotMinutes:  req.SlotMinutes,
+		Weekdays:     weekdays,
+		ExcludeDates: exclude,
+		Courses:      demands,
+		Rooms:        rooms,
+		RosterSize:   roster,
+		Timezone:     plan.Timezone,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "生成排课方案失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 保存方案
+	personId, _ := c.Get("personId")
+	sessionsJSON, _ := json.Marshal(result.Sessions)
+	unplacedJSON, _ := json.Marshal(result.Unplaced)
+	proposal := database.TimetableProposal{
+		PlanID:    planId,
+		Sessions:  string(sessionsJSON),
+		Unplaced:  string(unplacedJSON),
+		CreatorID: personId.(int64),
+	}
+	if err := database.DB.Create(&proposal).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "保存排课方案失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	message := "排课方案已生成"
+	if len(result.Unplaced) > 0 {
+		message = "排课方案已生成，部分课程未能排入"
+	}
+	data, _ := formatTimetableProposal(proposal)
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message,
+		"data":    data,
+	})
+}
@@ -0,0 +1,41 @@
+package planner
+
+import (
+	"backend/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTimetableProposal 获取自动排课方案（接口5.41）
+func GetTimetableProposal(c *gin.Context) {
+	// 获取路径参数 proposalId
+	proposalIdStr := c.Param("proposalId")
+	proposalId, err := strconv.ParseInt(proposalIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的排课方案ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var proposal database.TimetableProposal
+	if err := database.DB.Where("proposal_id = ?", proposalId).First(&proposal).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "排课方案不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	data, _ := formatTimetableProposal(proposal)
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    data,
+	})
+}
@@ -0,0 +1,93 @@
+package teacher
+
+import (
+	"net/http"
+	"time"
+
+	"backend/database"
+	"backend/scheduling"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loadWindows 查询讲师登记的每周可授课时间段
+func loadWindows(teacherID int64) ([]scheduling.Window, error) {
+	var rows []database.TeacherAvailability
+	if err := database.DB.Where("teacher_id = ?", teacherID).Order("weekday, begin_time").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	windows := make([]scheduling.Window, 0, len(rows))
+	for _, row := range rows {
+		windows = append(windows, scheduling.Window{
+			Weekday:   row.Weekday,
+			BeginTime: row.BeginTime,
+			EndTime:   row.EndTime,
+		})
+	}
+	return windows, nil
+}
+
+// GetAvailability 获取讲师的每周可授课时间段和不可授课时间段
+func GetAvailability(c *gin.Context) {
+	// 获取当前用户信息
+	personID, exists := c.Get("personId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "未登录",
+			"data":    nil,
+		})
+		return
+	}
+
+	teacherID := personID.(int64)
+
+	// 默认只返回今天及以后的不可授课时间段
+	from := c.DefaultQuery("from", time.Now().Format("2006-01-02"))
+	if _, err := time.Parse("2006-01-02", from); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "日期格式错误，应为 YYYY-MM-DD",
+			"data":    nil,
+		})
+		return
+	}
+
+	windows, err := loadWindows(teacherID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询可授课时间失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	var blackouts []database.TeacherBlackout
+	if err := database.DB.
+		Where("teacher_id = ?", teacherID).
+		Where(database.DateFrom("end_date", from)).
+		Order("start_date, begin_time").
+		Find(&blackouts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询不可授课时间失败",
+			"data":    nil,
+		})
+		return
+	}
+	periods := make([]scheduling.BlackoutPeriod, 0, len(blackouts))
+	for _, b := range blackouts {
+		periods = append(periods, scheduling.FormatBlackout(b))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"restricted": len(windows) > 0,
+			"windows":    windows,
+			"blackouts":  periods,
+		},
+	})
+}
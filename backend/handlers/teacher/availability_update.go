@@ -0,0 +1,140 @@
+package teacher
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"backend/database"
+	"backend/scheduling"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxWindows 每位讲师最多登记的每周可授课时间段数
+const maxWindows = 50
+
+// UpdateAvailabilityWindows 整体替换讲师的每周可授课时间段
+// 传空数组表示不再限制可授课时间；已有课程安排不受影响，不在新时间段内的安排在返回中列出
+func UpdateAvailabilityWindows(c *gin.Context) {
+	// 获取当前用户信息
+	personID, exists := c.Get("personId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "未登录",
+			"data":    nil,
+		})
+		return
+	}
+
+	teacherID := personID.(int64)
+
+	var req struct {
+		Windows []scheduling.Window `json:"windows" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	badRequest := func(message string) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": message,
+			"data":    nil,
+		})
+	}
+
+	if len(req.Windows) > maxWindows {
+		badRequest("可授课时间段不能超过" + strconv.Itoa(maxWindows) + "个")
+		return
+	}
+	for _, w := range req.Windows {
+		if w.Weekday < 1 || w.Weekday > 7 {
+			badRequest("星期必须为1-7")
+			return
+		}
+		if _, err := time.Parse("15:04:05", w.BeginTime); err != nil {
+			badRequest("时间格式错误，请使用 HH:mm:ss 格式")
+			return
+		}
+		if _, err := time.Parse("15:04:05", w.EndTime); err != nil {
+			badRequest("时间格式错误，请使用 HH:mm:ss 格式")
+			return
+		}
+		if w.BeginTime >= w.EndTime {
+			badRequest("结束时间必须晚于开始时间")
+			return
+		}
+	}
+
+	// 同一天的时间段不能重叠
+	windows := append([]scheduling.Window(nil), req.Windows...)
+	sort.Slice(windows, func(i, j int) bool {
+		if windows[i].Weekday != windows[j].Weekday {
+			return windows[i].Weekday < windows[j].Weekday
+		}
+		return windows[i].BeginTime < windows[j].BeginTime
+	})
+	for i := 1; i < len(windows); i++ {
+		prev, cur := windows[i-1], windows[i]
+		if prev.Weekday == cur.Weekday && cur.BeginTime < prev.EndTime {
+			badRequest("同一天的可授课时间段不能重叠：星期" + strconv.Itoa(cur.Weekday) + " " + prev.BeginTime + "-" + prev.EndTime + " 与 " + cur.BeginTime + "-" + cur.EndTime)
+			return
+		}
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("teacher_id = ?", teacherID).Delete(&database.TeacherAvailability{}).Error; err != nil {
+			return err
+		}
+		if len(windows) == 0 {
+			return nil
+		}
+		rows := make([]database.TeacherAvailability, 0, len(windows))
+		for _, w := range windows {
+			rows = append(rows, database.TeacherAvailability{
+				TeacherID: teacherID,
+				Weekday:   w.Weekday,
+				BeginTime: w.BeginTime,
+				EndTime:   w.EndTime,
+			})
+		}
+		return tx.Create(&rows).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "保存可授课时间失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 今天及以后不在新时间段内的已有课程安排
+	affected, err := scheduling.BookingsOutsideWindows(teacherID, windows, time.Now().Format("2006-01-02"))
+	if err != nil {
+		affected = []scheduling.Booking{}
+	}
+	message := "保存成功"
+	if len(affected) > 0 {
+		message = "保存成功，有" + strconv.Itoa(len(affected)) + "次已安排的课程不在可授课时间内，请联系培训规划者调整"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message,
+		"data": gin.H{
+			"restricted":    len(windows) > 0,
+			"windows":       windows,
+			"affectedItems": affected,
+		},
+	})
+}
@@ -0,0 +1,124 @@
+package teacher
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/database"
+	"backend/scheduling"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateBlackout 登记讲师不可授课的时间段（请假、随船出海等）
+// 登记后排课时不能再安排该时间段；已有课程安排不受影响，与该时间段冲突的安排在返回中列出
+func CreateBlackout(c *gin.Context) {
+	// 获取当前用户信息
+	personID, exists := c.Get("personId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "未登录",
+			"data":    nil,
+		})
+		return
+	}
+
+	teacherID := personID.(int64)
+
+	var req struct {
+		StartDate string `json:"startDate" binding:"required"`
+		BeginTime string `json:"beginTime"` // 开始日期当天的开始时间，默认 00:00:00
+		EndDate   string `json:"endDate" binding:"required"`
+		EndTime   string `json:"endTime"` // 结束日期当天的结束时间，默认 23:59:59（到当天结束）
+		Reason    string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误：" + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	badRequest := func(message string) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": message,
+			"data":    nil,
+		})
+	}
+
+	startDate, err1 := time.Parse("2006-01-02", req.StartDate)
+	endDate, err2 := time.Parse("2006-01-02", req.EndDate)
+	if err1 != nil || err2 != nil {
+		badRequest("日期格式错误，应为 YYYY-MM-DD")
+		return
+	}
+	if req.BeginTime == "" {
+		req.BeginTime = "00:00:00"
+	}
+	if req.EndTime == "" {
+		req.EndTime = "23:59:59"
+	}
+	if _, err := time.Parse("15:04:05", req.BeginTime); err != nil {
+		badRequest("时间格式错误，请使用 HH:mm:ss 格式")
+		return
+	}
+	if _, err := time.Parse("15:04:05", req.EndTime); err != nil {
+		badRequest("时间格式错误，请使用 HH:mm:ss 格式")
+		return
+	}
+	if endDate.Before(startDate) || (req.StartDate == req.EndDate && req.BeginTime >= req.EndTime) {
+		badRequest("结束时间必须晚于开始时间")
+		return
+	}
+	if endDate.Sub(startDate) > 366*24*time.Hour {
+		badRequest("不可授课时间段不能超过一年")
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if len([]rune(req.Reason)) > 200 {
+		badRequest("原因长度不能超过200字符")
+		return
+	}
+
+	blackout := database.TeacherBlackout{
+		TeacherID: teacherID,
+		StartDate: startDate,
+		BeginTime: req.BeginTime,
+		EndDate:   endDate,
+		EndTime:   req.EndTime,
+		Reason:    req.Reason,
+	}
+	if err := database.DB.Create(&blackout).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "登记不可授课时间失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 与该时间段冲突的已有课程安排
+	affected, err := scheduling.BookingsInBlackout(blackout)
+	if err != nil {
+		affected = []scheduling.Booking{}
+	}
+	message := "登记成功"
+	if len(affected) > 0 {
+		message = "登记成功，有" + strconv.Itoa(len(affected)) + "次已安排的课程与该时间段冲突，请联系培训规划者调整"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message,
+		"data": gin.H{
+			"blackout":      scheduling.FormatBlackout(blackout),
+			"affectedItems": affected,
+		},
+	})
+}
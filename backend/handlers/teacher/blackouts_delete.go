@@ -0,0 +1,69 @@
+package teacher
+
+import (
+	"net/http"
+	"strconv"
+
+	"backend/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeleteBlackout 删除讲师自己登记的不可授课时间段
+func DeleteBlackout(c *gin.Context) {
+	// 获取当前用户信息
+	personID, exists := c.Get("personId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "未登录",
+			"data":    nil,
+		})
+		return
+	}
+
+	teacherID := personID.(int64)
+
+	blackoutID, err := strconv.ParseInt(c.Param("blackoutId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的不可授课时间ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var blackout database.TeacherBlackout
+	if err := database.DB.Where("blackout_id = ?", blackoutID).First(&blackout).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "不可授课时间不存在",
+			"data":    nil,
+		})
+		return
+	}
+	if blackout.TeacherID != teacherID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "无权限：只能删除自己登记的不可授课时间",
+			"data":    nil,
+		})
+		return
+	}
+
+	if err := database.DB.Delete(&blackout).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "删除不可授课时间失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "删除成功",
+		"data":    nil,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 	"backend/database"
+	"backend/utils"
 
 	"github.com/gin-gonic/gin"
 
@@ -95,6 +96,9 @@ func GetCourseStatistics(c *gin.Context) {
 		"90-100":  0,
 	}
 
+	// 课后反馈评分汇总（理解程度/难度感受/满意度）
+	ratings := utils.NewRatingSummary()
+
 	// 用于去重学员
 	studentMap := make(map[int64]bool)
 
@@ -136,6 +140,8 @@ func GetCourseStatistics(c *gin.Context) {
 			scoreDistribution["90-100"]++
 		}
 
+		ratings.Add(eval.Understanding, eval.Difficulty, eval.Satisfaction)
+
 		// 学员统计
 		studentMap[eval.PersonID] = true
 		studentScoresMap[eval.PersonID] = append(studentScoresMap[eval.PersonID], weightedScore)
@@ -257,6 +263,7 @@ func GetCourseStatistics(c *gin.Context) {
 				"excellentRate":     excellentRate,
 				"scoreDistribution": scoreDistribution,
 			},
+			"ratings":       ratings,
 			"classStat":     classStat,
 			"studentScores": studentScores,
 		},
@@ -6,21 +6,26 @@ import (
 	"time"
 
 	"backend/database"
+	"backend/scheduling"
 
 	"github.com/gin-gonic/gin"
 )
 
 // StudentEvaluation 学员评价信息
 type StudentEvaluation struct {
-	PersonID       int64   `json:"personId"`
-	PersonName     string  `json:"personName"`
-	SelfScore      float64 `json:"selfScore"`
-	SelfComment    string  `json:"selfComment"`
-	TeacherScore   *float64 `json:"teacherScore"`
-	TeacherComment string  `json:"teacherComment"`
-	ScoreRatio     float64 `json:"scoreRatio"`
-	EvaluatedAt    *string `json:"evaluatedAt"`
-	Status         string  `json:"status"`
+	PersonID              int64    `json:"personId"`
+	PersonName            string   `json:"personName"`
+	SelfScore             float64  `json:"selfScore"`
+	SelfComment           string   `json:"selfComment"`
+	SelfScoreRationale    string   `json:"selfScoreRationale"`
+	TeacherScore          *float64 `json:"teacherScore"`
+	TeacherComment        string   `json:"teacherComment"`
+	TeacherScoreRationale string   `json:"teacherScoreRationale"`
+	SelfScoringStatus     string   `json:"selfScoringStatus"`
+	TeacherScoringStatus  string   `json:"teacherScoringStatus"`
+	ScoreRatio            float64  `json:"scoreRatio"`
+	EvaluatedAt           *string  `json:"evaluatedAt"`
+	Status                string   `json:"status"`
 }
 
 // CourseItemWithStudents 课程安排及学员信息
@@ -32,6 +37,7 @@ type CourseItemWithStudents struct {
 	ClassDate      string              `json:"classDate"`
 	ClassBeginTime string              `json:"classBeginTime"`
 	ClassEndTime   string              `json:"classEndTime"`
+	Timezone       string              `json:"timezone"`
 	Location       string              `json:"location"`
 	PlanName       string              `json:"planName"`
 	Students       []StudentEvaluation `json:"students"`
@@ -59,10 +65,8 @@ func GetPendingEvaluations(c *gin.Context) {
 		status = "pending" // 默认只显示待评分
 	}
 
-	// 查询讲师已完成的课程安排（课程已结束）
+	// 查询讲师已完成的课程安排（课程已结束）：先按日期粗筛，再按课程所在时区判断
 	now := time.Now()
-	today := now.Format("2006-01-02")
-	currentTime := now.Format("15:04:05")
 
 	query := database.DB.Table("plan_course_item pci").
 		Select(`
@@ -70,17 +74,17 @@ func GetPendingEvaluations(c *gin.Context) {
 			pci.course_id,
 			c.course_name,
 			c.course_class,
-			pci.class_date,
-			TIME_FORMAT(pci.class_begin_time, '%H:%i:%s') as class_begin_time,
-			TIME_FORMAT(pci.class_end_time, '%H:%i:%s') as class_end_time,
+			` + database.FormatDate("pci.class_date") + ` as class_date,
+			pci.class_begin_time,
+			pci.class_end_time,
+			pci.timezone,
 			pci.location,
 			tp.plan_name
 		`).
 		Joins("JOIN course c ON pci.course_id = c.course_id").
 		Joins("JOIN training_plan tp ON pci.plan_id = tp.plan_id").
 		Where("c.teacher_id = ?", teacherID).
-		Where("(pci.class_date < ? OR (pci.class_date = ? AND pci.class_end_time < ?))", 
-			today, today, currentTime)
+		Where(database.DateUntil("pci.class_date", scheduling.EndedBefore(now)))
 
 	// 如果指定了课程ID
 	if courseIDStr != "" {
@@ -98,6 +102,7 @@ func GetPendingEvaluations(c *gin.Context) {
 		ClassDate      string
 		ClassBeginTime string
 		ClassEndTime   string
+		Timezone       string
 		Location       string
 		PlanName       string
 	}
@@ -118,6 +123,10 @@ func GetPendingEvaluations(c *gin.Context) {
 	pendingCount := 0
 
 	for _, item := range courseItems {
+		if !scheduling.ClassEnded(item.ClassDate, item.ClassEndTime, item.Timezone, now) {
+			continue
+		}
+
 		// 查询该课程的所有学员评价
 		var evaluations []database.AttendanceEvaluation
 		evalQuery := database.DB.
@@ -159,15 +168,19 @@ func GetPendingEvaluations(c *gin.Context) {
 			}
 
 			students = append(students, StudentEvaluation{
-				PersonID:       eval.PersonID,
-				PersonName:     eval.Person.Name,
-				SelfScore:      eval.SelfScore,
-				SelfComment:    eval.SelfComment,
-				TeacherScore:   teacherScore,
-				TeacherComment: eval.TeacherComment,
-				ScoreRatio:     eval.ScoreRatio,
-				EvaluatedAt:    evaluatedAt,
-				Status:         evalStatus,
+				PersonID:              eval.PersonID,
+				PersonName:            eval.Person.Name,
+				SelfScore:             eval.SelfScore,
+				SelfComment:           eval.SelfComment,
+				SelfScoreRationale:    eval.SelfScoreRationale,
+				TeacherScore:          teacherScore,
+				TeacherComment:        eval.TeacherComment,
+				TeacherScoreRationale: eval.TeacherScoreRationale,
+				SelfScoringStatus:     eval.SelfScoringStatus,
+				TeacherScoringStatus:  eval.TeacherScoringStatus,
+				ScoreRatio:            eval.ScoreRatio,
+				EvaluatedAt:           evaluatedAt,
+				Status:                evalStatus,
 			})
 		}
 
@@ -179,6 +192,7 @@ func GetPendingEvaluations(c *gin.Context) {
 			ClassDate:      item.ClassDate,
 			ClassBeginTime: item.ClassBeginTime,
 			ClassEndTime:   item.ClassEndTime,
+			Timezone:       scheduling.TimezoneName(item.Timezone),
 			Location:       item.Location,
 			PlanName:       item.PlanName,
 			Students:       students,
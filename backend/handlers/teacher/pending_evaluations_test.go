@@ -0,0 +1,80 @@
+package teacher
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"testing"
+)
+
+func TestGetPendingEvaluations(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", dbtest.Date(-3), dbtest.Date(2), dbtest.EmployeeID, dbtest.Employee2ID)
+	course := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	otherCourse := dbtest.CreateCourse(t, "急救", "安全", dbtest.Teacher2ID)
+
+	ended := dbtest.CreateItem(t, plan, course, dbtest.Date(-1), "09:00:00", "10:30:00", "Asia/Shanghai")
+	upcoming := dbtest.CreateItem(t, plan, course, dbtest.Date(2), "09:00:00", "10:00:00", "Asia/Shanghai")
+	others := dbtest.CreateItem(t, plan, otherCourse, dbtest.Date(-1), "14:00:00", "15:00:00", "Asia/Shanghai")
+
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: ended.ItemID, SelfScore: 80, SelfComment: "学到了很多", ScoreRatio: 0.5,
+	})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.Employee2ID, ItemID: ended.ItemID, SelfScore: 70, SelfComment: "还需练习",
+		TeacherScore: 88, TeacherComment: "认真", ScoreRatio: 0.5,
+	})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{PersonID: dbtest.EmployeeID, ItemID: upcoming.ItemID})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{PersonID: dbtest.EmployeeID, ItemID: others.ItemID, SelfComment: "其他讲师"})
+
+	type response struct {
+		TotalCount   int                      `json:"totalCount"`
+		PendingCount int                      `json:"pendingCount"`
+		CourseItems  []CourseItemWithStudents `json:"courseItems"`
+	}
+
+	// 默认只返回待评分的学员
+	var pending response
+	dbtest.Get(t, GetPendingEvaluations, dbtest.TeacherID, "讲师", "/teacher/pending-evaluations").Decode(t, &pending)
+	if pending.TotalCount != 1 || pending.PendingCount != 1 || len(pending.CourseItems) != 1 {
+		t.Fatalf("应有 1 门课程的 1 名学员待评分，实际 %+v", pending)
+	}
+	item := pending.CourseItems[0]
+	if item.ItemID != ended.ItemID {
+		t.Fatalf("待评分课程应为课程安排 %d，实际为 %d", ended.ItemID, item.ItemID)
+	}
+	if item.ClassDate != dbtest.Date(-1) {
+		t.Errorf("classDate 应为 %q（YYYY-MM-DD），实际为 %q", dbtest.Date(-1), item.ClassDate)
+	}
+	if item.ClassBeginTime != "09:00:00" || item.ClassEndTime != "10:30:00" {
+		t.Errorf("上课时间应为 09:00:00-10:30:00，实际为 %s-%s", item.ClassBeginTime, item.ClassEndTime)
+	}
+	if item.Timezone != "Asia/Shanghai" || item.CourseName != "消防" || item.CourseClass != "安全" || item.PlanName != "安全培训" {
+		t.Errorf("课程安排信息不正确：%+v", item)
+	}
+	if len(item.Students) != 1 {
+		t.Fatalf("应有 1 名待评分学员，实际 %d 名", len(item.Students))
+	}
+	student := item.Students[0]
+	if student.PersonID != dbtest.EmployeeID || student.PersonName != "赵员工" || student.SelfComment != "学到了很多" {
+		t.Errorf("学员信息不正确：%+v", student)
+	}
+	if student.Status != "pending" || student.TeacherScore != nil || student.EvaluatedAt != nil {
+		t.Errorf("未评分学员的 status 应为 pending，teacherScore 和 evaluatedAt 为 null：%+v", student)
+	}
+
+	// status=all 同时返回已评分的学员
+	var all response
+	dbtest.Get(t, GetPendingEvaluations, dbtest.TeacherID, "讲师", "/teacher/pending-evaluations?status=all").Decode(t, &all)
+	if all.TotalCount != 2 || all.PendingCount != 1 || len(all.CourseItems) != 1 || len(all.CourseItems[0].Students) != 2 {
+		t.Fatalf("应有 1 门课程的 2 名学员，其中 1 名待评分，实际 %+v", all)
+	}
+	for _, s := range all.CourseItems[0].Students {
+		if s.PersonID != dbtest.Employee2ID {
+			continue
+		}
+		if s.Status != "evaluated" || s.TeacherScore == nil || *s.TeacherScore != 88 || s.TeacherComment != "认真" {
+			t.Errorf("已评分学员的 status 应为 evaluated，teacherScore 为 88：%+v", s)
+		}
+	}
+}
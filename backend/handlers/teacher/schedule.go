@@ -2,9 +2,11 @@ package teacher
 
 import (
 	"net/http"
+	"sort"
 	"time"
 
 	"backend/database"
+	"backend/scheduling"
 
 	"github.com/gin-gonic/gin"
 )
@@ -49,14 +51,37 @@ func GetSchedule(c *gin.Context) {
 		return
 	}
 
-	// 查询指定日期范围内的授课课程
+	// 查看者时区：课程按该时区的日期和时间展示
+	viewer, ok := scheduling.ViewerTimezone(c.Query("timezone"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无法识别的时区",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 查询工作日历，用于标记非工作日
+	cal, err := scheduling.LoadCalendar(startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "查询工作日历失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 查询指定日期范围内的授课课程，前后各多查一天，换算时区后可能落入范围
 	var courseItems []database.PlanCourseItem
-	err := database.DB.
+	err = database.DB.
 		Preload("Course").
 		Preload("Plan").
 		Joins("JOIN course ON plan_course_item.course_id = course.course_id").
-		Where("course.teacher_id = ? AND plan_course_item.class_date BETWEEN ? AND ?", 
-			teacherID, startDate, endDate).
+		Where("course.teacher_id = ?", teacherID).
+		Where(database.DateBetween("plan_course_item.class_date",
+			startTime.AddDate(0, 0, -1).Format("2006-01-02"), endTime.AddDate(0, 0, 1).Format("2006-01-02"))).
 		Order("plan_course_item.class_date ASC, plan_course_item.class_begin_time ASC").
 		Find(&courseItems).Error
 
@@ -100,13 +125,20 @@ func GetSchedule(c *gin.Context) {
 		countMap[result.ItemID] = result
 	}
 
-	// 按日期分组课程
+	// 按查看者时区的日期分组课程
 	scheduleMap := make(map[string][]map[string]interface{})
 	weekDays := []string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
-	
+	totalCourses := 0
+
 	for _, item := range courseItems {
-		dateStr := item.ClassDate.Format("2006-01-02")
-		
+		local := scheduling.ViewerSlot(item.ClassDate.Format("2006-01-02"), item.ClassBeginTime, item.ClassEndTime, item.Timezone, viewer)
+		if local.Date < startDate || local.Date > endDate {
+			continue
+		}
+		startsAt, endsAt := scheduling.ItemInterval(item)
+		dateStr := local.Date
+		totalCourses++
+
 		counts := countMap[item.ItemID]
 		
 		course := map[string]interface{}{
@@ -116,8 +148,11 @@ func GetSchedule(c *gin.Context) {
 			"courseDesc":     item.Course.CourseDesc,
 			"courseRequire":  item.Course.CourseRequire,
 			"courseClass":    item.Course.CourseClass,
-			"classBeginTime": item.ClassBeginTime,
-			"classEndTime":   item.ClassEndTime,
+			"classBeginTime": local.Begin,
+			"classEndTime":   local.End,
+			"classTimezone":  scheduling.TimezoneName(item.Timezone),
+			"startsAt":       startsAt.Format(time.RFC3339),
+			"endsAt":         endsAt.Format(time.RFC3339),
 			"location":       item.Location,
 			"planId":         item.PlanID,
 			"planName":       item.Plan.PlanName,
@@ -127,6 +162,11 @@ func GetSchedule(c *gin.Context) {
 		
 		scheduleMap[dateStr] = append(scheduleMap[dateStr], course)
 	}
+	for _, courses := range scheduleMap {
+		sort.SliceStable(courses, func(i, j int) bool {
+			return courses[i]["classBeginTime"].(string) < courses[j]["classBeginTime"].(string)
+		})
+	}
 
 	// 构建完整日程（包含没有课程的日期）
 	schedule := []map[string]interface{}{}
@@ -139,9 +179,11 @@ func GetSchedule(c *gin.Context) {
 		}
 
 		schedule = append(schedule, map[string]interface{}{
-			"date":      dateStr,
-			"dayOfWeek": dayOfWeek,
-			"courses":   courses,
+			"date":            dateStr,
+			"dayOfWeek":       dayOfWeek,
+			"courses":         courses,
+			"isWorkingDay":    cal.Resolve(dateStr, "") == nil,
+			"calendarEntries": cal.Marks(dateStr),
 		})
 	}
 
@@ -151,7 +193,8 @@ func GetSchedule(c *gin.Context) {
 		"data": gin.H{
 			"startDate":    startDate,
 			"endDate":      endDate,
-			"totalCourses": len(courseItems),
+			"timezone":     viewer.String(),
+			"totalCourses": totalCourses,
 			"schedule":     schedule,
 		},
 	})
@@ -0,0 +1,84 @@
+package teacher
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"testing"
+)
+
+func TestGetSchedule(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", dbtest.Date(-3), dbtest.Date(5), dbtest.EmployeeID, dbtest.Employee2ID)
+	course := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	otherCourse := dbtest.CreateCourse(t, "急救", "安全", dbtest.Teacher2ID)
+
+	local := dbtest.CreateItem(t, plan, course, dbtest.Date(1), "09:00:00", "10:00:00", "Asia/Shanghai")
+	// UTC 前一天 20:00 即上海当天 04:00，按查看者时区落入范围
+	shifted := dbtest.CreateItem(t, plan, course, dbtest.Date(-1), "20:00:00", "21:00:00", "UTC")
+	dbtest.CreateItem(t, plan, course, dbtest.Date(3), "09:00:00", "10:00:00", "Asia/Shanghai")
+	dbtest.CreateItem(t, plan, otherCourse, dbtest.Date(1), "14:00:00", "15:00:00", "Asia/Shanghai")
+
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{PersonID: dbtest.EmployeeID, ItemID: local.ItemID, SelfScore: 80})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.Employee2ID, ItemID: local.ItemID, SelfScore: 70, TeacherScore: 88, TeacherComment: "认真", ScoreRatio: 0.5,
+	})
+
+	type scheduled struct {
+		ItemID         int64  `json:"itemId"`
+		ClassBeginTime string `json:"classBeginTime"`
+		ClassEndTime   string `json:"classEndTime"`
+		ClassTimezone  string `json:"classTimezone"`
+		PlanName       string `json:"planName"`
+		StudentCount   int    `json:"studentCount"`
+		EvaluatedCount int    `json:"evaluatedCount"`
+	}
+	var data struct {
+		Timezone     string `json:"timezone"`
+		TotalCourses int    `json:"totalCourses"`
+		Schedule     []struct {
+			Date    string      `json:"date"`
+			Courses []scheduled `json:"courses"`
+		} `json:"schedule"`
+	}
+	target := "/teacher/schedule?startDate=" + dbtest.Date(0) + "&endDate=" + dbtest.Date(2) + "&timezone=Asia/Shanghai"
+	dbtest.Get(t, GetSchedule, dbtest.TeacherID, "讲师", target).Decode(t, &data)
+
+	if data.Timezone != "Asia/Shanghai" || data.TotalCourses != 2 || len(data.Schedule) != 3 {
+		t.Fatalf("应按 Asia/Shanghai 返回 3 天共 2 节课，实际 %+v", data)
+	}
+	want := []struct {
+		date      string
+		itemID    int64
+		begin     string
+		timezone  string
+		students  int
+		evaluated int
+	}{
+		{dbtest.Date(0), shifted.ItemID, "04:00:00", "UTC", 0, 0},
+		{dbtest.Date(1), local.ItemID, "09:00:00", "Asia/Shanghai", 2, 1},
+		{dbtest.Date(2), 0, "", "", 0, 0},
+	}
+	for i, w := range want {
+		day := data.Schedule[i]
+		if day.Date != w.date {
+			t.Errorf("第 %d 天应为 %s，实际为 %s", i+1, w.date, day.Date)
+		}
+		if w.itemID == 0 {
+			if len(day.Courses) != 0 {
+				t.Errorf("%s 应没有课程，实际 %+v", w.date, day.Courses)
+			}
+			continue
+		}
+		if len(day.Courses) != 1 {
+			t.Fatalf("%s 应有 1 节课，实际 %+v", w.date, day.Courses)
+		}
+		got := day.Courses[0]
+		if got.ItemID != w.itemID || got.ClassBeginTime != w.begin || got.ClassTimezone != w.timezone || got.PlanName != "安全培训" {
+			t.Errorf("%s 应为课程安排 %d（%s 开始，%s），实际 %+v", w.date, w.itemID, w.begin, w.timezone, got)
+		}
+		if got.StudentCount != w.students || got.EvaluatedCount != w.evaluated {
+			t.Errorf("课程安排 %d 应有 %d 名学员、%d 名已评分，实际 %d、%d", w.itemID, w.students, w.evaluated, got.StudentCount, got.EvaluatedCount)
+		}
+	}
+}
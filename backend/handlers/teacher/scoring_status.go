@@ -0,0 +1,45 @@
+package teacher
+
+import (
+	"backend/database"
+	"backend/scoring"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetScoringStatus 查询学员评价的AI评分状态（提交AI辅助评分后轮询）
+func GetScoringStatus(c *gin.Context) {
+	teacherID, exists := c.Get("personId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "未登录", "data": nil})
+		return
+	}
+
+	itemID, err1 := strconv.ParseInt(c.Query("itemId"), 10, 64)
+	personID, err2 := strconv.ParseInt(c.Query("personId"), 10, 64)
+	if err1 != nil || err2 != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数格式错误", "data": nil})
+		return
+	}
+
+	// 验证课程是否是该讲师的课程
+	var courseItem database.PlanCourseItem
+	if err := database.DB.Preload("Course").Where("item_id = ?", itemID).First(&courseItem).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "课程安排不存在", "data": nil})
+		return
+	}
+	if courseItem.Course.TeacherID != teacherID.(int64) {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": "无权查看该课程", "data": nil})
+		return
+	}
+
+	status, err := scoring.GetEvaluationStatus(personID, itemID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "未找到学员评价记录", "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "获取成功", "data": status})
+}
@@ -2,10 +2,12 @@ package teacher
 
 import (
 	"backend/database"
+	"backend/scoring"
 	"backend/utils"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // SubmitGradingRequest 提交评分请求
@@ -74,30 +76,47 @@ func SubmitGrading(c *gin.Context) {
 		return
 	}
 
-	// 如果讲师提供了评语但没有评分，使用AI生成评分
+	// 如果讲师提供了评语但没有评分，交由异步评分队列使用AI生成评分
 	teacherScore := *req.TeacherScore
-	if req.TeacherComment != "" && req.TeacherScore != nil && *req.TeacherScore == 0 {
-		// 获取课程名称
-		var course database.Course
-		database.DB.Where("course_id = ?", courseItem.CourseID).First(&course)
-		
-		// 调用AI生成讲师评分
-		aiScore, err := utils.GenerateTeacherScore(req.TeacherComment, course.CourseName)
-		if err == nil && aiScore > 0 {
-			teacherScore = aiScore
-		}
+	useAI := req.TeacherComment != "" && req.TeacherScore != nil && *req.TeacherScore == 0
+	teacherScoringStatus := ""
+	if useAI {
+		teacherScoringStatus = database.ScoringStatusPending
 	}
 
 	// 计算综合得分
 	selfScore := evaluation.SelfScore
 	finalScore := selfScore*(1-req.ScoreRatio) + teacherScore*req.ScoreRatio
 
-	// 更新评价记录
-	evaluation.TeacherScore = teacherScore
-	evaluation.TeacherComment = req.TeacherComment
-	evaluation.ScoreRatio = req.ScoreRatio
+	// 更新评价记录（只更新讲师相关字段，避免覆盖异步写入的自评分数）
+	var job *database.AIScoringJob
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&database.AttendanceEvaluation{}).
+			Where("item_id = ? AND person_id = ?", req.ItemID, req.PersonID).
+			Updates(map[string]interface{}{
+				"teacher_score":           teacherScore,
+				"teacher_comment":         req.TeacherComment,
+				"score_ratio":             req.ScoreRatio,
+				"teacher_score_rationale": "",
+				"teacher_scoring_status":  teacherScoringStatus,
+			}).Error; err != nil {
+			return err
+		}
+
+		if !useAI {
+			// 讲师直接打分，作废之前未完成的AI评分任务
+			return scoring.CancelPending(tx, req.PersonID, req.ItemID, utils.ScoreTypeTeacher)
+		}
+
+		// 获取课程名称
+		var course database.Course
+		tx.Where("course_id = ?", courseItem.CourseID).First(&course)
 
-	if err := database.DB.Save(&evaluation).Error; err != nil {
+		var err error
+		job, err = scoring.EnqueueTeacherScoring(tx, req.PersonID, req.ItemID, req.TeacherComment, course.CourseName)
+		return err
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
 			"message": "保存评分失败",
@@ -105,6 +124,17 @@ func SubmitGrading(c *gin.Context) {
 		})
 		return
 	}
+	scoring.Notify()
+
+	// AI评分完成前，讲师分数与综合得分暂不返回；员工自评仍在等待AI评分时，自评分数与综合得分同样暂不返回
+	var selfScoreResp, teacherScoreResp, weightedScoreResp interface{} = selfScore, teacherScore, finalScore
+	var scoringJobID interface{}
+	if job != nil {
+		teacherScoreResp, weightedScoreResp, scoringJobID = nil, nil, job.JobID
+	}
+	if evaluation.SelfScoringStatus == database.ScoringStatusPending {
+		selfScoreResp, weightedScoreResp = nil, nil
+	}
 
 	// 获取学员姓名
 	var person database.Person
@@ -114,14 +144,17 @@ func SubmitGrading(c *gin.Context) {
 		"code":    200,
 		"message": "评分提交成功",
 		"data": gin.H{
-			"itemId":         req.ItemID,
-			"personId":       req.PersonID,
-			"personName":     person.Name,
-			"selfScore":      selfScore,
-			"teacherScore":   teacherScore,
-			"scoreRatio":     req.ScoreRatio,
-			"weightedScore":  finalScore,
-			"teacherComment": req.TeacherComment,
+			"itemId":               req.ItemID,
+			"personId":             req.PersonID,
+			"personName":           person.Name,
+			"selfScore":            selfScoreResp,
+			"teacherScore":         teacherScoreResp,
+			"scoreRatio":           req.ScoreRatio,
+			"weightedScore":        weightedScoreResp,
+			"teacherComment":       req.TeacherComment,
+			"selfScoringStatus":    evaluation.SelfScoringStatus,
+			"teacherScoringStatus": teacherScoringStatus,
+			"scoringJobId":         scoringJobID,
 		},
 	})
 }
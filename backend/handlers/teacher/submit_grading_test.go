@@ -0,0 +1,65 @@
+package teacher
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"testing"
+)
+
+func TestSubmitGrading(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", dbtest.Date(-3), dbtest.Date(5), dbtest.EmployeeID, dbtest.Employee2ID)
+	course := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	item := dbtest.CreateItem(t, plan, course, dbtest.Date(-1), "09:00:00", "10:00:00", "Asia/Shanghai")
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{PersonID: dbtest.EmployeeID, ItemID: item.ItemID, SelfScore: 70})
+	// 自评仍在等待AI评分，自评分数尚未确定
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.Employee2ID, ItemID: item.ItemID, SelfScoringStatus: database.ScoringStatusPending,
+	})
+
+	tests := []struct {
+		personID int64
+		self     *float64
+		weighted *float64
+	}{
+		{dbtest.EmployeeID, floatPtr(70), floatPtr(76)},
+		{dbtest.Employee2ID, nil, nil},
+	}
+	for _, tt := range tests {
+		var data struct {
+			SelfScore         *float64 `json:"selfScore"`
+			TeacherScore      *float64 `json:"teacherScore"`
+			WeightedScore     *float64 `json:"weightedScore"`
+			SelfScoringStatus string   `json:"selfScoringStatus"`
+		}
+		body := map[string]interface{}{"itemId": item.ItemID, "personId": tt.personID, "teacherScore": 80, "scoreRatio": 0.6}
+		dbtest.Post(t, SubmitGrading, dbtest.TeacherID, "讲师", "/teacher/grading", body).Decode(t, &data)
+
+		if data.TeacherScore == nil || !dbtest.Near(*data.TeacherScore, 80) {
+			t.Errorf("学员 %d 的讲师评分应为 80，实际 %v", tt.personID, data.TeacherScore)
+		}
+		if !sameScore(data.SelfScore, tt.self) || !sameScore(data.WeightedScore, tt.weighted) {
+			t.Errorf("学员 %d 的自评分数和综合得分应为 %v、%v，实际 %v、%v（自评状态 %q）",
+				tt.personID, show(tt.self), show(tt.weighted), show(data.SelfScore), show(data.WeightedScore), data.SelfScoringStatus)
+		}
+	}
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func sameScore(got, want *float64) bool {
+	if got == nil || want == nil {
+		return got == want
+	}
+	return dbtest.Near(*got, *want)
+}
+
+func show(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
@@ -109,7 +109,8 @@ func GetTeachingStatistics(c *gin.Context) {
 	// 获取时间范围内的所有课程安排
 	var courseItems []database.PlanCourseItem
 	database.DB.Preload("Course").
-		Where("course_id IN ? AND class_date BETWEEN ? AND ?", courseIDs, startDate, endDate).
+		Where("course_id IN ?", courseIDs).
+		Where(database.DateBetween("class_date", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))).
 		Order("class_date DESC").
 		Find(&courseItems)
 
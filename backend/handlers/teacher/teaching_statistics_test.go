@@ -0,0 +1,93 @@
+package teacher
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"testing"
+)
+
+func TestGetTeachingStatistics(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", dbtest.Date(-5), dbtest.Date(5), dbtest.EmployeeID, dbtest.Employee2ID)
+	fire := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	english := dbtest.CreateCourse(t, "航海英语", "语言", dbtest.TeacherID)
+	otherCourse := dbtest.CreateCourse(t, "急救", "安全", dbtest.Teacher2ID)
+
+	fireItem := dbtest.CreateItem(t, plan, fire, dbtest.Date(-2), "09:00:00", "10:30:00", "Asia/Shanghai")
+	englishItem := dbtest.CreateItem(t, plan, english, dbtest.Date(-1), "14:00:00", "15:00:00", "Asia/Shanghai")
+	dbtest.CreateItem(t, plan, fire, dbtest.Date(1), "09:00:00", "10:00:00", "Asia/Shanghai")
+	otherItem := dbtest.CreateItem(t, plan, otherCourse, dbtest.Date(-1), "09:00:00", "10:00:00", "Asia/Shanghai")
+
+	// 80*0.4+90*0.6=86，70*0.5+60*0.5=65，90
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: fireItem.ItemID, SelfScore: 80, TeacherScore: 90, TeacherComment: "认真", ScoreRatio: 0.6,
+	})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.Employee2ID, ItemID: fireItem.ItemID, SelfScore: 70, TeacherScore: 60, ScoreRatio: 0.5,
+	})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.EmployeeID, ItemID: englishItem.ItemID, SelfScore: 90, TeacherScore: 90, TeacherComment: "流利", ScoreRatio: 0.5,
+	})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{
+		PersonID: dbtest.Employee2ID, ItemID: otherItem.ItemID, SelfScore: 10, TeacherScore: 10, TeacherComment: "其他讲师", ScoreRatio: 0.5,
+	})
+
+	var data struct {
+		Period struct {
+			StartDate string `json:"startDate"`
+			EndDate   string `json:"endDate"`
+		} `json:"period"`
+		Statistics struct {
+			CourseCount            int     `json:"courseCount"`
+			ClassCount             int     `json:"classCount"`
+			StudentCount           int     `json:"studentCount"`
+			TotalHours             float64 `json:"totalHours"`
+			AverageTeachingScore   float64 `json:"averageTeachingScore"`
+			EvaluationRate         float64 `json:"evaluationRate"`
+			CourseTypeDistribution []struct {
+				CourseClass  string  `json:"courseClass"`
+				CourseCount  int     `json:"courseCount"`
+				AverageScore float64 `json:"averageScore"`
+			} `json:"courseTypeDistribution"`
+		} `json:"statistics"`
+		RecentClasses []struct {
+			ItemID         int64   `json:"itemId"`
+			ClassDate      string  `json:"classDate"`
+			StudentCount   int     `json:"studentCount"`
+			EvaluatedCount int     `json:"evaluatedCount"`
+			AverageScore   float64 `json:"averageScore"`
+		} `json:"recentClasses"`
+	}
+	target := "/teacher/teaching-statistics?startDate=" + dbtest.Date(-5) + "&endDate=" + dbtest.Date(0)
+	dbtest.Get(t, GetTeachingStatistics, dbtest.TeacherID, "讲师", target).Decode(t, &data)
+
+	if data.Period.StartDate != dbtest.Date(-5) || data.Period.EndDate != dbtest.Date(0) {
+		t.Errorf("统计区间应为 %s 至 %s，实际 %+v", dbtest.Date(-5), dbtest.Date(0), data.Period)
+	}
+	stats := data.Statistics
+	if stats.CourseCount != 2 || stats.ClassCount != 2 || stats.StudentCount != 2 || !dbtest.Near(stats.TotalHours, 2.5) {
+		t.Errorf("应有 2 门课程、区间内 2 次课、2 名学员、2.5 课时，实际 %+v", stats)
+	}
+	if !dbtest.Near(stats.AverageTeachingScore, 241.0/3) || !dbtest.Near(stats.EvaluationRate, 200.0/3) {
+		t.Errorf("平均分应为 80.33、评价率 66.67%%，实际 %v、%v", stats.AverageTeachingScore, stats.EvaluationRate)
+	}
+	for _, d := range stats.CourseTypeDistribution {
+		if (d.CourseClass == "安全" && !dbtest.Near(d.AverageScore, 75.5)) || (d.CourseClass == "语言" && !dbtest.Near(d.AverageScore, 90)) {
+			t.Errorf("课程类型 %s 的平均分不正确：%v", d.CourseClass, d.AverageScore)
+		}
+	}
+
+	// 按上课日期倒序
+	recent := data.RecentClasses
+	if len(recent) != 2 {
+		t.Fatalf("最近授课应有区间内的 2 次课，实际 %+v", recent)
+	}
+	if recent[0].ItemID != englishItem.ItemID || recent[0].ClassDate != dbtest.Date(-1) || recent[0].StudentCount != 1 || !dbtest.Near(recent[0].AverageScore, 90) {
+		t.Errorf("第 1 次应为 %s 的航海英语（1 名学员，90 分），实际 %+v", dbtest.Date(-1), recent[0])
+	}
+	if recent[1].ItemID != fireItem.ItemID || recent[1].ClassDate != dbtest.Date(-2) || recent[1].StudentCount != 2 ||
+		recent[1].EvaluatedCount != 1 || !dbtest.Near(recent[1].AverageScore, 75.5) {
+		t.Errorf("第 2 次应为 %s 的消防（2 名学员、1 名已评分，75.5 分），实际 %+v", dbtest.Date(-2), recent[1])
+	}
+}
@@ -2,29 +2,34 @@ package teacher
 
 import (
 	"net/http"
+	"sort"
 	"time"
 
 	"backend/database"
+	"backend/scheduling"
 
 	"github.com/gin-gonic/gin"
 )
 
 // TodayCourseResponse 今日授课响应结构
 type TodayCourseResponse struct {
-	ItemID          int    `json:"itemId"`
-	CourseID        int    `json:"courseId"`
-	CourseName      string `json:"courseName"`
-	CourseDesc      string `json:"courseDesc"`
-	CourseRequire   string `json:"courseRequire"`
-	CourseClass     string `json:"courseClass"`
-	ClassDate       string `json:"classDate"`
-	ClassBeginTime  string `json:"classBeginTime"`
-	ClassEndTime    string `json:"classEndTime"`
-	Location        string `json:"location"`
-	PlanID          int    `json:"planId"`
-	PlanName        string `json:"planName"`
-	StudentCount    int    `json:"studentCount"`
-	EvaluatedCount  int    `json:"evaluatedCount"`
+	ItemID         int    `json:"itemId"`
+	CourseID       int    `json:"courseId"`
+	CourseName     string `json:"courseName"`
+	CourseDesc     string `json:"courseDesc"`
+	CourseRequire  string `json:"courseRequire"`
+	CourseClass    string `json:"courseClass"`
+	ClassDate      string `json:"classDate"`
+	ClassBeginTime string `json:"classBeginTime"`
+	ClassEndTime   string `json:"classEndTime"`
+	ClassTimezone  string `json:"classTimezone"`
+	StartsAt       string `json:"startsAt"`
+	EndsAt         string `json:"endsAt"`
+	Location       string `json:"location"`
+	PlanID         int    `json:"planId"`
+	PlanName       string `json:"planName"`
+	StudentCount   int    `json:"studentCount"`
+	EvaluatedCount int    `json:"evaluatedCount"`
 }
 
 // GetTodayCourses 获取讲师今日授课列表
@@ -42,11 +47,18 @@ func GetTodayCourses(c *gin.Context) {
 	
 	teacherID := personID.(int64)
 
-	// 获取今天的日期
-	today := time.Now()
+	// 获取查看者时区的今天
+	viewer, ok := scheduling.ViewerTimezone(c.Query("timezone"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无法识别的时区",
+			"data":    nil,
+		})
+		return
+	}
+	today := time.Now().In(viewer)
 	todayStr := today.Format("2006-01-02")
-	startOfDay := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
-	endOfDay := time.Date(today.Year(), today.Month(), today.Day(), 23, 59, 59, 999999999, today.Location())
 
 	// 获取讲师负责的所有课程ID
 	var courses []database.Course
@@ -65,6 +77,7 @@ func GetTodayCourses(c *gin.Context) {
 			"message": "获取成功",
 			"data": gin.H{
 				"date":        todayStr,
+				"timezone":    viewer.String(),
 				"courseCount": 0,
 				"courses":     []interface{}{},
 			},
@@ -77,15 +90,16 @@ func GetTodayCourses(c *gin.Context) {
 		courseIDs[i] = course.CourseID
 	}
 
-	// 查询今日课程安排（使用与schedule相同的查询方式）
-	var courseItems []database.PlanCourseItem
+	// 查询今日课程安排（使用与schedule相同的查询方式），前后各多查一天，换算时区后可能是今天
+	var candidates []database.PlanCourseItem
 	if err := database.DB.
 		Preload("Course").
 		Preload("Plan").
 		Where("course_id IN ?", courseIDs).
-		Where("class_date >= ? AND class_date <= ?", startOfDay, endOfDay).
+		Where(database.DateBetween("class_date",
+			today.AddDate(0, 0, -1).Format("2006-01-02"), today.AddDate(0, 0, 1).Format("2006-01-02"))).
 		Order("class_begin_time ASC").
-		Find(&courseItems).Error; err != nil {
+		Find(&candidates).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
 			"message": "查询课程失败",
@@ -93,6 +107,18 @@ func GetTodayCourses(c *gin.Context) {
 		})
 		return
 	}
+	courseItems := make([]database.PlanCourseItem, 0, len(candidates))
+	localSlots := make(map[int64]scheduling.Slot, len(candidates))
+	for _, item := range candidates {
+		local := scheduling.ViewerSlot(item.ClassDate.Format("2006-01-02"), item.ClassBeginTime, item.ClassEndTime, item.Timezone, viewer)
+		if local.Date == todayStr {
+			courseItems = append(courseItems, item)
+			localSlots[item.ItemID] = local
+		}
+	}
+	sort.SliceStable(courseItems, func(i, j int) bool {
+		return localSlots[courseItems[i].ItemID].Begin < localSlots[courseItems[j].ItemID].Begin
+	})
 
 	// 统计每个课程的学员数和已评分数
 	itemIDs := make([]int64, len(courseItems))
@@ -127,6 +153,8 @@ func GetTodayCourses(c *gin.Context) {
 	response := make([]TodayCourseResponse, 0, len(courseItems))
 	for _, item := range courseItems {
 		counts := countMap[item.ItemID]
+		local := localSlots[item.ItemID]
+		startsAt, endsAt := scheduling.ItemInterval(item)
 		response = append(response, TodayCourseResponse{
 			ItemID:         int(item.ItemID),
 			CourseID:       int(item.CourseID),
@@ -134,9 +162,12 @@ func GetTodayCourses(c *gin.Context) {
 			CourseDesc:     item.Course.CourseDesc,
 			CourseRequire:  item.Course.CourseRequire,
 			CourseClass:    item.Course.CourseClass,
-			ClassDate:      item.ClassDate.Format("2006-01-02"),
-			ClassBeginTime: item.ClassBeginTime,
-			ClassEndTime:   item.ClassEndTime,
+			ClassDate:      local.Date,
+			ClassBeginTime: local.Begin,
+			ClassEndTime:   local.End,
+			ClassTimezone:  scheduling.TimezoneName(item.Timezone),
+			StartsAt:       startsAt.Format(time.RFC3339),
+			EndsAt:         endsAt.Format(time.RFC3339),
 			Location:       item.Location,
 			PlanID:         int(item.PlanID),
 			PlanName:       item.Plan.PlanName,
@@ -150,6 +181,7 @@ func GetTodayCourses(c *gin.Context) {
 		"message": "获取成功",
 		"data": gin.H{
 			"date":        todayStr,
+			"timezone":    viewer.String(),
 			"courseCount": len(response),
 			"courses":     response,
 		},
@@ -0,0 +1,272 @@
+package loginguard
+
+import (
+	"backend/config"
+	"backend/database"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 失败次数的存储方式（对应配置项 LOGIN_GUARD_STORE）
+const (
+	StoreMemory   = "memory"   // 进程内存，单实例部署
+	StoreDatabase = "database" // login_attempt 表，多实例共享
+)
+
+// guard 登录失败的限制规则
+type guard struct {
+	store     Store
+	maxUser   int
+	maxIP     int
+	window    time.Duration
+	lockout   time.Duration
+	baseDelay time.Duration
+	maxDelay  time.Duration
+
+	resetMaxUser int           // 同一账号在统计窗口内最多发送重置链接的次数
+	resetMaxIP   int           // 同一 IP 在统计窗口内最多申请重置密码的次数
+	resetWindow  time.Duration // 申请重置密码次数的统计窗口
+}
+
+var g *guard
+
+// Init 按配置初始化登录失败限制（启动时调用）
+func Init(cfg *config.Config) error {
+	var store Store
+	switch strings.ToLower(cfg.LoginGuardStore) {
+	case "", StoreMemory:
+		store = NewMemoryStore()
+	case StoreDatabase:
+		store = DatabaseStore{}
+	default:
+		return fmt.Errorf("不支持的登录失败计数存储: %s", cfg.LoginGuardStore)
+	}
+	if cfg.LoginFailureWindowMins <= 0 || cfg.LoginLockoutMinutes <= 0 {
+		return fmt.Errorf("LOGIN_FAILURE_WINDOW_MINUTES 和 LOGIN_LOCKOUT_MINUTES 必须大于 0")
+	}
+	if cfg.PasswordResetWindowMins <= 0 {
+		return fmt.Errorf("PASSWORD_RESET_WINDOW_MINUTES 必须大于 0")
+	}
+
+	g = &guard{
+		store:     store,
+		maxUser:   cfg.LoginMaxFailures,
+		maxIP:     cfg.LoginMaxFailuresPerIP,
+		window:    time.Duration(cfg.LoginFailureWindowMins) * time.Minute,
+		lockout:   time.Duration(cfg.LoginLockoutMinutes) * time.Minute,
+		baseDelay: time.Duration(cfg.LoginDelaySeconds) * time.Second,
+		maxDelay:  time.Duration(cfg.LoginMaxDelaySeconds) * time.Second,
+
+		resetMaxUser: cfg.PasswordResetMaxPerAccount,
+		resetMaxIP:   cfg.PasswordResetMaxPerIP,
+		resetWindow:  time.Duration(cfg.PasswordResetWindowMins) * time.Minute,
+	}
+	return nil
+}
+
+// SetStore 替换失败次数的存储
+func SetStore(store Store) {
+	g.store = store
+}
+
+// userKey 用户名不区分大小写，超长时截断
+func userKey(username string) string {
+	name := strings.ToLower(strings.TrimSpace(username))
+	if utf8.RuneCountInString(name) > 100 {
+		name = string([]rune(name)[:100])
+	}
+	return "user:" + name
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// resetKey 申请重置密码的次数与登录失败次数分开计数
+func resetKey(key string) string {
+	return "reset:" + key
+}
+
+// Decision 是否允许本次登录尝试
+type Decision struct {
+	Allowed    bool
+	Reason     string        // 不允许时为 database.LoginFailureThrottled 或 database.LoginFailureLocked
+	Message    string        // 不允许时的提示
+	RetryAfter time.Duration // 需要等待的时间
+}
+
+// delayAfter 连续失败 failures 次后需等待的时间：第一次失败后等待 LOGIN_DELAY_SECONDS，之后每次翻倍
+func (g *guard) delayAfter(failures int) time.Duration {
+	if failures <= 0 || g.baseDelay <= 0 {
+		return 0
+	}
+	delay := g.baseDelay
+	for i := 1; i < failures && delay < g.maxDelay; i++ {
+		delay *= 2
+	}
+	if g.maxDelay > 0 && delay > g.maxDelay {
+		delay = g.maxDelay
+	}
+	return delay
+}
+
+// userLimit 用户名的限制：距上次失败需等待 delayAfter，达到 LOGIN_MAX_FAILURES 次后锁定
+func (g *guard) userLimit() Limit {
+	return Limit{Window: g.window, Max: g.maxUser, Delay: g.delayAfter}
+}
+
+// ipLimit IP 的限制：达到 LOGIN_MAX_FAILURES_PER_IP 次后锁定
+func (g *guard) ipLimit() Limit {
+	return Limit{Window: g.window, Max: g.maxIP}
+}
+
+// Reserve 验证密码前占用一次登录尝试，用户名或 IP 被锁定、或距上次失败未到等待时间时不允许
+// 占用时先计为一次失败，检查和计数是一个原子操作，并发的尝试不会同时通过检查；
+// 允许时验证密码后必须调用 Failure 或 Success 结束本次尝试
+func Reserve(username, ip string, now time.Time) (Decision, error) {
+	ipAttempts, ok, err := g.store.Reserve(ipKey(ip), now, g.ipLimit())
+	if err != nil {
+		return Decision{}, err
+	}
+	if !ok {
+		return denied("该IP登录失败次数过多", ipAttempts, g.ipLimit(), now), nil
+	}
+
+	userAttempts, ok, err := g.store.Reserve(userKey(username), now, g.userLimit())
+	if err != nil || !ok {
+		if releaseErr := g.store.Release(ipKey(ip)); err == nil {
+			err = releaseErr
+		}
+		if err != nil {
+			return Decision{}, err
+		}
+		return denied("该账号登录失败次数过多", userAttempts, g.userLimit(), now), nil
+	}
+	return Decision{Allowed: true}, nil
+}
+
+// denied 占用不到登录尝试时的提示
+func denied(prefix string, a Attempts, limit Limit, now time.Time) Decision {
+	if a.Locked(now) {
+		return locked(prefix, a.LockedUntil, now)
+	}
+	// 未锁定时是距上次失败未到等待时间，或者达到上限的那次尝试还在验证密码（失败后即锁定）
+	wait := time.Second
+	if limit.Delay != nil {
+		if w := a.LastFailureAt.Add(limit.Delay(a.Failures)).Sub(now); w > 0 {
+			wait = w
+		}
+	}
+	seconds := int((wait + time.Second - 1) / time.Second)
+	return Decision{
+		Reason:     database.LoginFailureThrottled,
+		Message:    fmt.Sprintf("登录失败次数过多，请%d秒后再试", seconds),
+		RetryAfter: wait,
+	}
+}
+
+func locked(prefix string, until, now time.Time) Decision {
+	minutes := int((until.Sub(now) + time.Minute - 1) / time.Minute)
+	return Decision{
+		Reason:     database.LoginFailureLocked,
+		Message:    fmt.Sprintf("%s，已被临时锁定，请%d分钟后再试", prefix, minutes),
+		RetryAfter: until.Sub(now),
+	}
+}
+
+// Failure 密码错误（或用户名不存在）时结束本次尝试，次数已在 Reserve 时计入；
+// 返回用户名因本次失败被锁定到的时间（未锁定时为零值）
+func Failure(username, ip string, now time.Time) (time.Time, error) {
+	if _, err := g.store.Lock(ipKey(ip), now, g.maxIP, g.lockout); err != nil {
+		return time.Time{}, err
+	}
+	user, err := g.store.Lock(userKey(username), now, g.maxUser, g.lockout)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if user.Locked(now) {
+		return user.LockedUntil, nil
+	}
+	return time.Time{}, nil
+}
+
+// Success 密码正确时结束本次尝试：清除用户名的失败次数，撤销 IP 本次占用的次数
+// （IP 之前的失败次数不清除，避免用自己的账号登录来重置）
+func Success(username, ip string) error {
+	if err := g.store.Reset(userKey(username)); err != nil {
+		return err
+	}
+	return g.store.Release(ipKey(ip))
+}
+
+// ReserveReset 占用 IP 的一次重置密码申请，达到上限后在统计窗口内不能再申请
+// 申请者尚未证明自己控制账号，不按用户名拒绝申请，避免他人冒用用户名耗尽账号主人的申请次数
+func ReserveReset(ip string, now time.Time) (Decision, error) {
+	limit := Limit{Window: g.resetWindow, Max: g.resetMaxIP}
+	attempts, ok, err := g.store.Reserve(resetKey(ipKey(ip)), now, limit)
+	if err != nil {
+		return Decision{}, err
+	}
+	if !ok {
+		return resetThrottled(limit.Wait(attempts, now)), nil
+	}
+	return Decision{Allowed: true}, nil
+}
+
+func resetThrottled(wait time.Duration) Decision {
+	minutes := int((wait + time.Minute - 1) / time.Minute)
+	return Decision{
+		Reason:     database.LoginFailureThrottled,
+		Message:    fmt.Sprintf("申请重置密码过于频繁，请%d分钟后再试", minutes),
+		RetryAfter: wait,
+	}
+}
+
+// ReserveResetLink 发送重置链接前占用账号的一次发送次数，统计窗口内发送的链接数达到上限时不再发送新链接
+// 不发送时不生成新令牌，已发到邮箱的最新链接仍然有效；占用后未能发送时调用 ReleaseResetLink
+func ReserveResetLink(username string, now time.Time) (Decision, error) {
+	limit := Limit{Window: g.resetWindow, Max: g.resetMaxUser}
+	attempts, ok, err := g.store.Reserve(resetKey(userKey(username)), now, limit)
+	if err != nil {
+		return Decision{}, err
+	}
+	if !ok {
+		return resetThrottled(limit.Wait(attempts, now)), nil
+	}
+	return Decision{Allowed: true}, nil
+}
+
+// ReleaseResetLink 撤销 ReserveResetLink 占用的发送次数（重置链接未能发送时）
+func ReleaseResetLink(username string) error {
+	return g.store.Release(resetKey(userKey(username)))
+}
+
+// Status 查询用户名的失败次数和锁定状态
+func Status(username string) (Attempts, error) {
+	return g.store.Get(userKey(username))
+}
+
+// Unlock 解除用户名的锁定并清除失败次数
+func Unlock(username string) error {
+	return g.store.Reset(userKey(username))
+}
+
+// Audit 记录一次失败或被拦截的登录
+func Audit(username string, personID *int64, ip, userAgent, reason string, now time.Time) error {
+	if name := []rune(username); len(name) > 50 {
+		username = string(name[:50])
+	}
+	if ua := []rune(userAgent); len(ua) > 255 {
+		userAgent = string(ua[:255])
+	}
+	return database.DB.Create(&database.LoginFailure{
+		LoginName: username,
+		PersonID:  personID,
+		IPAddress: ip,
+		UserAgent: userAgent,
+		Reason:    reason,
+		CreatedAt: now,
+	}).Error
+}
@@ -0,0 +1,166 @@
+package loginguard
+
+import (
+	"backend/database"
+	"sync"
+	"testing"
+	"time"
+)
+
+// useTestGuard 使用内存存储：同一账号失败 3 次锁定 30 分钟，同一 IP 失败 10 次锁定
+func useTestGuard() {
+	g = &guard{
+		store:     NewMemoryStore(),
+		maxUser:   3,
+		maxIP:     10,
+		window:    15 * time.Minute,
+		lockout:   30 * time.Minute,
+		baseDelay: time.Second,
+		maxDelay:  8 * time.Second,
+	}
+}
+
+func TestDelayAfter(t *testing.T) {
+	useTestGuard()
+	want := []time.Duration{0, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second, 8 * time.Second}
+	for failures, w := range want {
+		if got := g.delayAfter(failures); got != w {
+			t.Errorf("失败 %d 次后应等待 %v，实际 %v", failures, w, got)
+		}
+	}
+
+	g.baseDelay = 0
+	if got := g.delayAfter(3); got != 0 {
+		t.Errorf("LOGIN_DELAY_SECONDS 为 0 时不应等待，实际 %v", got)
+	}
+}
+
+func TestReserveDelay(t *testing.T) {
+	useTestGuard()
+	g.maxUser = 0
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	// 每次失败后等待时间翻倍；未到等待时间不能再尝试，换 IP 和大小写也一样
+	for failures := 1; failures <= 5; failures++ {
+		if d, err := Reserve("Employee", "10.0.0.1", now); err != nil || !d.Allowed {
+			t.Fatalf("第 %d 次尝试应允许，实际 %+v（%v）", failures, d, err)
+		}
+		if _, err := Failure("Employee", "10.0.0.1", now); err != nil {
+			t.Fatal(err)
+		}
+
+		wait := g.delayAfter(failures)
+		d, err := Reserve("employee", "10.0.0.2", now.Add(wait-time.Millisecond))
+		if err != nil || d.Allowed || d.Reason != database.LoginFailureThrottled || d.RetryAfter != time.Millisecond {
+			t.Fatalf("失败 %d 次后 %v 内应不允许（还需等待 1ms），实际 %+v（%v）", failures, wait, d, err)
+		}
+		now = now.Add(wait)
+	}
+
+	// 超过统计窗口后重新计数
+	now = now.Add(g.window + time.Second)
+	if d, _ := Reserve("employee", "10.0.0.1", now); !d.Allowed {
+		t.Fatalf("超过统计窗口后应允许，实际 %+v", d)
+	}
+	if a, _ := Status("employee"); a.Failures != 1 {
+		t.Errorf("超过统计窗口后应重新计数，实际 %d 次", a.Failures)
+	}
+}
+
+func TestLockoutExpiry(t *testing.T) {
+	useTestGuard()
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	var lockedUntil time.Time
+	for failures := 1; failures <= g.maxUser; failures++ {
+		if d, err := Reserve("employee", "10.0.0.1", now); err != nil || !d.Allowed {
+			t.Fatalf("第 %d 次尝试应允许，实际 %+v（%v）", failures, d, err)
+		}
+		var err error
+		if lockedUntil, err = Failure("employee", "10.0.0.1", now); err != nil {
+			t.Fatal(err)
+		}
+		if failures < g.maxUser && !lockedUntil.IsZero() {
+			t.Fatalf("失败 %d 次不应锁定", failures)
+		}
+		now = now.Add(g.maxDelay)
+	}
+	if want := now.Add(-g.maxDelay).Add(g.lockout); !lockedUntil.Equal(want) {
+		t.Fatalf("失败 %d 次后应锁定到 %v，实际 %v", g.maxUser, want, lockedUntil)
+	}
+
+	d, err := Reserve("employee", "10.0.0.2", lockedUntil.Add(-time.Second))
+	if err != nil || d.Allowed || d.Reason != database.LoginFailureLocked || d.RetryAfter != time.Second {
+		t.Fatalf("锁定期间应不允许，实际 %+v（%v）", d, err)
+	}
+	// 被账号锁定拦下的尝试不占用 IP 的次数
+	if a, _ := g.store.Get(ipKey("10.0.0.2")); a.Failures != 0 {
+		t.Errorf("被拦下的尝试不应计入 IP 的失败次数，实际 %d 次", a.Failures)
+	}
+
+	// 锁定到期后可以再尝试，失败次数重新计数
+	if d, _ := Reserve("employee", "10.0.0.2", lockedUntil); !d.Allowed {
+		t.Fatalf("锁定到期后应允许，实际 %+v", d)
+	}
+	if lockedUntil, _ := Failure("employee", "10.0.0.2", lockedUntil); !lockedUntil.IsZero() {
+		t.Errorf("锁定到期后第一次失败不应再次锁定")
+	}
+}
+
+func TestSuccessReleasesIP(t *testing.T) {
+	useTestGuard()
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	Reserve("employee", "10.0.0.1", now)
+	Failure("employee", "10.0.0.1", now)
+	now = now.Add(time.Second)
+	if d, _ := Reserve("employee", "10.0.0.1", now); !d.Allowed {
+		t.Fatalf("等待后应允许，实际 %+v", d)
+	}
+	if err := Success("employee", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if a, _ := Status("employee"); a.Failures != 0 {
+		t.Errorf("登录成功后应清除账号的失败次数，实际 %d 次", a.Failures)
+	}
+	// 之前的失败仍计入 IP，成功的这次不计入
+	if a, _ := g.store.Get(ipKey("10.0.0.1")); a.Failures != 1 {
+		t.Errorf("IP 应只计入之前的 1 次失败，实际 %d 次", a.Failures)
+	}
+}
+
+func TestReserveConcurrent(t *testing.T) {
+	useTestGuard()
+	g.baseDelay = 0
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	// 同时发起的尝试在验证密码前各占一次，最多 maxUser 次通过检查
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if d, err := Reserve("employee", "10.0.0.1", now); err == nil && d.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed != g.maxUser {
+		t.Errorf("并发尝试应只有 %d 次通过检查，实际 %d 次", g.maxUser, allowed)
+	}
+
+	// 达到上限的尝试还在验证密码时稍后再试，失败后锁定
+	d, _ := Reserve("employee", "10.0.0.1", now)
+	if d.Allowed || d.Reason != database.LoginFailureThrottled || d.RetryAfter != time.Second {
+		t.Errorf("次数已满但未锁定时应 1 秒后再试，实际 %+v", d)
+	}
+	if lockedUntil, _ := Failure("employee", "10.0.0.1", now); lockedUntil.IsZero() {
+		t.Errorf("达到上限的尝试失败后应锁定账号")
+	}
+}
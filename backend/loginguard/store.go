@@ -0,0 +1,238 @@
+package loginguard
+
+import (
+	"backend/database"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Attempts 一个用户名或 IP 的连续失败情况
+type Attempts struct {
+	Failures      int
+	LastFailureAt time.Time // 没有失败记录时为零值
+	LockedUntil   time.Time // 未锁定时为零值
+}
+
+// Locked 在 now 时是否处于锁定中
+func (a Attempts) Locked(now time.Time) bool {
+	return a.LockedUntil.After(now)
+}
+
+// Limit 一类尝试的限制
+type Limit struct {
+	Window time.Duration                    // 距上次尝试超过该时间后重新计数
+	Max    int                              // 统计窗口内最多占用的次数（大于 0 时生效）
+	Delay  func(failures int) time.Duration // 已有 failures 次时距上次尝试需等待的时间，为 nil 时不等待
+}
+
+// Wait 在 now 时还需等待多久才能再占用一次尝试，0 表示可以占用
+func (l Limit) Wait(a Attempts, now time.Time) time.Duration {
+	if a.Locked(now) {
+		return a.LockedUntil.Sub(now)
+	}
+	if !a.LastFailureAt.After(now.Add(-l.Window)) {
+		return 0
+	}
+	if l.Delay != nil {
+		if wait := a.LastFailureAt.Add(l.Delay(a.Failures)).Sub(now); wait > 0 {
+			return wait
+		}
+	}
+	if l.Max > 0 && a.Failures >= l.Max {
+		return a.LastFailureAt.Add(l.Window).Sub(now)
+	}
+	return 0
+}
+
+// Store 失败次数的存储
+// 单实例部署使用 MemoryStore；多实例部署使用共享的 DatabaseStore，各实例看到相同的计数和锁定状态
+type Store interface {
+	// Get 查询 key 的失败情况，没有记录时返回零值
+	Get(key string) (Attempts, error)
+	// Reserve 按 limit 检查并占用一次尝试，检查和计数是一个原子操作，并发的尝试不会同时通过检查：
+	// 允许时次数加一（距上次超过 limit.Window 时重新计数）并把 now 记为上次时间，返回占用后的情况和 true；
+	// 不允许时不做修改，返回当前情况和 false
+	Reserve(key string, now time.Time, limit Limit) (Attempts, bool, error)
+	// Release 撤销一次占用（尝试成功、不计为失败时）
+	Release(key string) error
+	// Lock 次数达到 max（大于 0）时锁定到 now+lockFor 并清零计数，返回处理后的情况
+	Lock(key string, now time.Time, max int, lockFor time.Duration) (Attempts, error)
+	// Reset 清除 key 的失败次数和锁定
+	Reset(key string) error
+}
+
+// MemoryStore 保存在进程内存中的失败次数，重启后清空
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*Attempts
+}
+
+// memoryPruneSize 记录数超过该值时清理已过统计窗口且未锁定的记录
+const memoryPruneSize = 10000
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]*Attempts)}
+}
+
+func (m *MemoryStore) Get(key string) (Attempts, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if a, ok := m.entries[key]; ok {
+		return *a, nil
+	}
+	return Attempts{}, nil
+}
+
+func (m *MemoryStore) Reserve(key string, now time.Time, limit Limit) (Attempts, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.entries) >= memoryPruneSize {
+		for k, a := range m.entries {
+			if !a.Locked(now) && a.LastFailureAt.Before(now.Add(-limit.Window)) {
+				delete(m.entries, k)
+			}
+		}
+	}
+
+	a, ok := m.entries[key]
+	if !ok {
+		a = &Attempts{}
+		m.entries[key] = a
+	}
+	if limit.Wait(*a, now) > 0 {
+		return *a, false, nil
+	}
+	if a.LastFailureAt.Before(now.Add(-limit.Window)) {
+		a.Failures = 0
+	}
+	a.Failures++
+	a.LastFailureAt = now
+	return *a, true, nil
+}
+
+func (m *MemoryStore) Release(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if a, ok := m.entries[key]; ok && a.Failures > 0 {
+		a.Failures--
+	}
+	return nil
+}
+
+func (m *MemoryStore) Lock(key string, now time.Time, max int, lockFor time.Duration) (Attempts, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.entries[key]
+	if !ok {
+		return Attempts{}, nil
+	}
+	if max > 0 && a.Failures >= max {
+		a.Failures = 0
+		a.LockedUntil = now.Add(lockFor)
+	}
+	return *a, nil
+}
+
+func (m *MemoryStore) Reset(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
+
+// DatabaseStore 保存在 login_attempt 表中的失败次数，多个实例共享
+type DatabaseStore struct{}
+
+func (DatabaseStore) Get(key string) (Attempts, error) {
+	var row database.LoginAttempt
+	err := database.DB.Where("attempt_key = ?", key).First(&row).Error
+	if err == gorm.ErrRecordNotFound {
+		return Attempts{}, nil
+	}
+	if err != nil {
+		return Attempts{}, err
+	}
+	return attemptsOf(row), nil
+}
+
+// reserveRetries 其他实例同时占用同一 key 时重新读取的次数
+const reserveRetries = 10
+
+func (DatabaseStore) Reserve(key string, now time.Time, limit Limit) (Attempts, bool, error) {
+	// 先确保记录存在，再按读到的次数做条件更新：条件中带上读到的次数和允许的上次时间，
+	// 其他实例在读取之后占用过时更新不到记录，重新读取后再判断
+	err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&database.LoginAttempt{AttemptKey: key}).Error
+	if err != nil {
+		return Attempts{}, false, err
+	}
+	for i := 0; i < reserveRetries; i++ {
+		var row database.LoginAttempt
+		if err := database.DB.Where("attempt_key = ?", key).First(&row).Error; err != nil {
+			return Attempts{}, false, err
+		}
+		a := attemptsOf(row)
+		if limit.Wait(a, now) > 0 {
+			return a, false, nil
+		}
+
+		query := database.DB.Model(&database.LoginAttempt{}).
+			Where("attempt_key = ? AND failures = ?", key, row.Failures).
+			Where("(locked_until IS NULL OR locked_until <= ?)", now)
+		failures := row.Failures + 1
+		if a.LastFailureAt.Before(now.Add(-limit.Window)) {
+			failures = 1
+			query = query.Where("(last_failure_at IS NULL OR last_failure_at < ?)", now.Add(-limit.Window))
+		} else if limit.Delay != nil {
+			query = query.Where("last_failure_at <= ?", now.Add(-limit.Delay(row.Failures)))
+		}
+		result := query.Updates(map[string]interface{}{"failures": failures, "last_failure_at": now})
+		if result.Error != nil {
+			return Attempts{}, false, result.Error
+		}
+		if result.RowsAffected == 1 {
+			a.Failures = failures
+			a.LastFailureAt = now
+			return a, true, nil
+		}
+	}
+	return Attempts{}, false, errors.New("并发尝试过多，未能占用登录尝试")
+}
+
+func (DatabaseStore) Release(key string) error {
+	return database.DB.Model(&database.LoginAttempt{}).
+		Where("attempt_key = ? AND failures > 0", key).
+		Update("failures", gorm.Expr("failures - 1")).Error
+}
+
+func (DatabaseStore) Lock(key string, now time.Time, max int, lockFor time.Duration) (Attempts, error) {
+	if max > 0 {
+		err := database.DB.Model(&database.LoginAttempt{}).
+			Where("attempt_key = ? AND failures >= ?", key, max).
+			Updates(map[string]interface{}{"failures": 0, "locked_until": now.Add(lockFor)}).Error
+		if err != nil {
+			return Attempts{}, err
+		}
+	}
+	return DatabaseStore{}.Get(key)
+}
+
+func (DatabaseStore) Reset(key string) error {
+	return database.DB.Where("attempt_key = ?", key).Delete(&database.LoginAttempt{}).Error
+}
+
+func attemptsOf(row database.LoginAttempt) Attempts {
+	a := Attempts{Failures: row.Failures}
+	if row.LastFailureAt != nil {
+		a.LastFailureAt = *row.LastFailureAt
+	}
+	if row.LockedUntil != nil {
+		a.LockedUntil = *row.LockedUntil
+	}
+	return a
+}
@@ -1,15 +1,32 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"backend/accounts"
+	"backend/authtoken"
 	"backend/config"
 	"backend/database"
+	"backend/enrollment"
 	"backend/handlers/auth"
+	"backend/handlers/calendar"
 	"backend/handlers/employee"
 	"backend/handlers/home"
 	"backend/handlers/planner"
 	"backend/handlers/teacher"
+	"backend/loginguard"
 	"backend/middleware"
+	"backend/notify"
+	"backend/planstatus"
+	"backend/scheduling"
+	"backend/scoring"
+	"backend/utils"
 
 	"github.com/gin-gonic/gin"
 
@@ -21,32 +38,114 @@ func main() {
 		log.Fatalf("配置加载失败: %v", err)
 	}
 
-	// 2. 初始化数据库
+	// 迁移命令：go run . migrate up | down [步数] | status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := database.Connect(); err != nil {
+			log.Fatalf("数据库连接失败: %v", err)
+		}
+		defer database.CloseDB()
+		if err := database.RunMigrateCommand(os.Args[2:]); err != nil {
+			log.Fatalf("迁移失败: %v", err)
+		}
+		return
+	}
+
+	// 2. 初始化AI评分提供方
+	if err := utils.InitAIProvider(config.AppConfig); err != nil {
+		log.Fatalf("AI提供方初始化失败: %v", err)
+	}
+	log.Printf("AI评分提供方: %s", utils.GetAIProvider().Name())
+
+	// 加载访问令牌签名密钥
+	if err := authtoken.Init(config.AppConfig); err != nil {
+		log.Fatalf("访问令牌配置错误: %v", err)
+	}
+
+	// 初始化登录失败次数限制
+	if err := loginguard.Init(config.AppConfig); err != nil {
+		log.Fatalf("登录失败限制配置错误: %v", err)
+	}
+
+	// 初始化注册方式和邀请码
+	if err := accounts.Init(config.AppConfig); err != nil {
+		log.Fatalf("注册方式配置错误: %v", err)
+	}
+	log.Printf("注册方式: %s", accounts.RegistrationMode())
+
+	// 初始化通知发送方（重置密码邮件等）
+	if err := notify.Init(config.AppConfig); err != nil {
+		log.Fatalf("通知发送方初始化失败: %v", err)
+	}
+	log.Printf("通知发送方: %s", notify.Current().Name())
+
+	// 3. 初始化数据库
 	if err := database.InitDB(); err != nil {
 		log.Fatalf("数据库初始化失败: %v", err)
 	}
 	defer database.CloseDB()
 
-	// 3. 插入测试账号（首次运行时自动插入，已存在则跳过）
+	// 地点和计划都未设置时区的课程安排补上系统默认时区
+	if err := scheduling.BackfillTimezones(); err != nil {
+		log.Printf("课程安排时区补齐失败: %v", err)
+	}
+	log.Printf("系统默认时区: %s", scheduling.DefaultTimezoneName())
+
+	// 启动异步AI评分队列
+	scoring.Start(config.AppConfig)
+	defer scoring.Stop()
+
+	// 启动报名规则定时同步
+	enrollment.Start(config.AppConfig)
+	defer enrollment.Stop()
+
+	// 启动计划状态自动推进
+	planstatus.Start(config.AppConfig)
+	defer planstatus.Stop()
+
+	// 启动过期会话定时清理
+	authtoken.Start(config.AppConfig)
+	defer authtoken.Stop()
+
+	// 退出前等待后台发送中的重置密码邮件
+	defer auth.WaitResetMails()
+
+	// 4. 插入测试账号（首次运行时自动插入，已存在则跳过）
 	if err := database.SeedTestAccounts(); err != nil {
 		log.Printf("测试账号插入失败: %v", err)
 	}
 
-	// 4. 创建 Gin 引擎
+	// 5. 创建 Gin 引擎
 	r := gin.Default()
 
-	// 5. 应用全局中间件
+	// 6. 应用全局中间件
 	r.Use(middleware.CORS()) // CORS 跨域
 
-	// 6. 注册路由
+	// 7. 注册路由
 	setupRoutes(r)
 
-	// 7. 启动服务器
+	// 8. 启动服务器
+	// 收到 SIGINT / SIGTERM 后停止接收新请求，等待进行中的请求完成，再依次停止后台任务
 	port := ":" + config.AppConfig.ServerPort
+	srv := &http.Server{Addr: port, Handler: r}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	idle := make(chan struct{})
+	go func() {
+		defer close(idle)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("服务器关闭超时: %v", err)
+		}
+	}()
+
 	log.Printf("服务器启动在端口 %s", port)
-	if err := r.Run(port); err != nil {
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("服务器启动失败: %v", err)
 	}
+	<-idle
+	log.Println("服务器已停止，正在结束后台任务")
 }
 
 // setupRoutes 设置所有路由
@@ -59,14 +158,41 @@ func setupRoutes(r *gin.Engine) {
 		// POST /api/auth/login - 用户登录
 		authGroup.POST("/login", auth.Login)
 
+		// POST /api/auth/refresh - 用刷新令牌换取新的访问令牌
+		authGroup.POST("/refresh", auth.Refresh)
+
+		// POST /api/auth/password/forgot - 申请重置密码（发送重置链接）
+		authGroup.POST("/password/forgot", auth.ForgotPassword)
+
+		// POST /api/auth/password/reset - 用重置令牌设置新密码
+		authGroup.POST("/password/reset", auth.ResetPassword)
+
 		// POST /api/auth/register - 用户注册
 		authGroup.POST("/register", auth.Register)
 
+		// GET /api/auth/invitation - 查询邀请码
+		authGroup.GET("/invitation", auth.GetInvitation)
+
 		// POST /api/auth/logout - 退出登录（需要鉴权）
 		authGroup.POST("/logout", middleware.AuthRequired(), auth.Logout)
 
 		// GET /api/auth/current-user - 获取当前用户信息（需要鉴权）
 		authGroup.GET("/current-user", middleware.AuthRequired(), auth.GetCurrentUser)
+
+		// GET /api/auth/sessions - 获取当前用户的登录会话（需要鉴权）
+		authGroup.GET("/sessions", middleware.AuthRequired(), auth.ListSessions)
+
+		// DELETE /api/auth/sessions - 注销当前用户的全部会话（需要鉴权）
+		authGroup.DELETE("/sessions", middleware.AuthRequired(), auth.RevokeAllSessions)
+
+		// DELETE /api/auth/sessions/:sessionId - 注销当前用户的某个会话（需要鉴权）
+		authGroup.DELETE("/sessions/:sessionId", middleware.AuthRequired(), auth.RevokeSession)
+
+		// PUT /api/auth/password - 修改密码（需要鉴权）
+		authGroup.PUT("/password", middleware.AuthRequired(), auth.ChangePassword)
+
+		// PUT /api/auth/email - 绑定找回密码用的邮箱（需要鉴权）
+		authGroup.PUT("/email", middleware.AuthRequired(), auth.UpdateEmail)
 	}
 
 	// ==================== 主页相关接口 ====================
@@ -90,11 +216,26 @@ func setupRoutes(r *gin.Engine) {
 		// POST /api/teacher/submit-grading - 提交学员评分
 		teacherGroup.POST("/submit-grading", teacher.SubmitGrading)
 
+		// GET /api/teacher/scoring-status - 查询学员评价的AI评分状态
+		teacherGroup.GET("/scoring-status", teacher.GetScoringStatus)
+
 		// GET /api/teacher/course-statistics - 获取课程成绩统计
 		teacherGroup.GET("/course-statistics", teacher.GetCourseStatistics)
 
 		// GET /api/teacher/teaching-statistics - 获取讲师授课统计
 		teacherGroup.GET("/teaching-statistics", teacher.GetTeachingStatistics)
+
+		// GET /api/teacher/availability - 获取可授课时间和不可授课时间
+		teacherGroup.GET("/availability", teacher.GetAvailability)
+
+		// PUT /api/teacher/availability/windows - 设置每周可授课时间段
+		teacherGroup.PUT("/availability/windows", teacher.UpdateAvailabilityWindows)
+
+		// POST /api/teacher/blackouts - 登记不可授课时间段（请假、出海等）
+		teacherGroup.POST("/blackouts", teacher.CreateBlackout)
+
+		// DELETE /api/teacher/blackouts/:blackoutId - 删除不可授课时间段
+		teacherGroup.DELETE("/blackouts/:blackoutId", teacher.DeleteBlackout)
 	}
 
 	// ==================== 员工端接口 ====================
@@ -110,6 +251,9 @@ func setupRoutes(r *gin.Engine) {
 		// POST /api/employee/submit-evaluation - 提交课程自评
 		employeeGroup.POST("/submit-evaluation", employee.SubmitEvaluation)
 
+		// GET /api/employee/scoring-status - 查询自评的AI评分状态
+		employeeGroup.GET("/scoring-status", employee.GetScoringStatus)
+
 		// GET /api/employee/scores - 获取员工成绩列表
 		employeeGroup.GET("/scores", employee.GetScores)
 
@@ -127,6 +271,9 @@ func setupRoutes(r *gin.Engine) {
 		// GET /api/planner/teachers - 获取讲师列表（用于选择）
 		plannerGroup.GET("/teachers", planner.GetTeachersList)
 
+		// GET /api/planner/teachers/free-slots - 查询讲师空闲时间段
+		plannerGroup.GET("/teachers/free-slots", planner.GetTeachersFreeSlots)
+
 		// GET /api/planner/employees - 获取员工列表（用于选择）
 		plannerGroup.GET("/employees", planner.GetEmployeesList)
 
@@ -175,6 +322,66 @@ func setupRoutes(r *gin.Engine) {
 		// DELETE /api/planner/course-items/:itemId - 删除课程安排
 		plannerGroup.DELETE("/course-items/:itemId", planner.DeleteCourseItem)
 
+		// POST /api/planner/course-item-series - 按重复规则创建重复课程
+		plannerGroup.POST("/course-item-series", planner.CreateCourseItemSeries)
+
+		// GET /api/planner/course-item-series/:seriesId - 获取重复课程详情
+		plannerGroup.GET("/course-item-series/:seriesId", planner.GetCourseItemSeries)
+
+		// POST /api/planner/plans/:planId/timetable-proposals - 自动生成排课方案
+		plannerGroup.POST("/plans/:planId/timetable-proposals", planner.CreateTimetableProposal)
+
+		// GET /api/planner/timetable-proposals/:proposalId - 获取排课方案
+		plannerGroup.GET("/timetable-proposals/:proposalId", planner.GetTimetableProposal)
+
+		// POST /api/planner/timetable-proposals/:proposalId/accept - 整体或部分采纳排课方案
+		plannerGroup.POST("/timetable-proposals/:proposalId/accept", planner.AcceptTimetableProposal)
+
+		// POST /api/planner/reschedules/preview - 分析课程安排改期的影响
+		plannerGroup.POST("/reschedules/preview", planner.PreviewReschedule)
+
+		// POST /api/planner/reschedules - 课程安排改期（单条或整个计划顺延）
+		plannerGroup.POST("/reschedules", planner.CreateReschedule)
+
+		// GET /api/planner/reschedules - 获取改期记录
+		plannerGroup.GET("/reschedules", planner.GetReschedulesList)
+
+		// POST /api/planner/plan-templates - 将培训计划保存为模板
+		plannerGroup.POST("/plan-templates", planner.CreatePlanTemplate)
+
+		// GET /api/planner/plan-templates - 获取培训计划模板列表
+		plannerGroup.GET("/plan-templates", planner.GetPlanTemplatesList)
+
+		// GET /api/planner/plan-templates/:templateId - 获取培训计划模板详情
+		plannerGroup.GET("/plan-templates/:templateId", planner.GetPlanTemplateDetail)
+
+		// DELETE /api/planner/plan-templates/:templateId - 删除培训计划模板
+		plannerGroup.DELETE("/plan-templates/:templateId", planner.DeletePlanTemplate)
+
+		// POST /api/planner/plan-templates/:templateId/plans - 按模板创建培训计划
+		plannerGroup.POST("/plan-templates/:templateId/plans", planner.CreatePlanFromTemplate)
+
+		// POST /api/planner/plans/:planId/clone - 复制培训计划到新的开始日期
+		plannerGroup.POST("/plans/:planId/clone", planner.ClonePlan)
+
+		// GET /api/planner/plans/:planId/status - 获取计划状态、可变更到的状态和变更记录
+		plannerGroup.GET("/plans/:planId/status", planner.GetPlanStatus)
+
+		// GET /api/planner/calendar-entries - 获取工作日历
+		plannerGroup.GET("/calendar-entries", planner.GetCalendarEntries)
+
+		// POST /api/planner/calendar-entries - 登记节假日、停工或工作日调整
+		plannerGroup.POST("/calendar-entries", planner.CreateCalendarEntry)
+
+		// POST /api/planner/calendar-entries/import - 从 iCal 文件导入节假日
+		plannerGroup.POST("/calendar-entries/import", planner.ImportCalendarEntries)
+
+		// PUT /api/planner/calendar-entries/:entryId - 修改工作日历条目
+		plannerGroup.PUT("/calendar-entries/:entryId", planner.UpdateCalendarEntry)
+
+		// DELETE /api/planner/calendar-entries/:entryId - 删除工作日历条目
+		plannerGroup.DELETE("/calendar-entries/:entryId", planner.DeleteCalendarEntry)
+
 		// GET /api/planner/analytics - 获取平台数据分析
 		plannerGroup.GET("/analytics", planner.GetAnalytics)
 
@@ -183,6 +390,103 @@ func setupRoutes(r *gin.Engine) {
 
 		// GET /api/planner/courses/:courseId/evaluations - 获取课程评价详情
 		plannerGroup.GET("/courses/:courseId/evaluations", planner.GetCourseEvaluations)
+
+		// GET /api/planner/org-units - 获取组织架构树
+		plannerGroup.GET("/org-units", planner.GetOrgUnitsTree)
+
+		// POST /api/planner/org-units - 创建组织节点
+		plannerGroup.POST("/org-units", planner.CreateOrgUnit)
+
+		// PUT /api/planner/org-units/:unitId - 修改组织节点
+		plannerGroup.PUT("/org-units/:unitId", planner.UpdateOrgUnit)
+
+		// DELETE /api/planner/org-units/:unitId - 删除组织节点
+		plannerGroup.DELETE("/org-units/:unitId", planner.DeleteOrgUnit)
+
+		// PUT /api/planner/persons/:personId/org-unit - 设置人员所属组织节点
+		plannerGroup.PUT("/persons/:personId/org-unit", planner.SetPersonOrgUnit)
+
+		// POST /api/planner/persons/:personId/logout - 强制用户下线
+		plannerGroup.POST("/persons/:personId/logout", planner.ForceLogout)
+
+		// POST /api/planner/persons/:personId/unlock - 解除账号的登录锁定
+		plannerGroup.POST("/persons/:personId/unlock", planner.UnlockAccount)
+
+		// GET /api/planner/login-failures - 获取失败登录记录
+		plannerGroup.GET("/login-failures", planner.GetLoginFailures)
+
+		// POST /api/planner/invitations - 生成注册邀请码
+		plannerGroup.POST("/invitations", planner.CreateInvitation)
+
+		// GET /api/planner/invitations - 获取注册邀请码列表
+		plannerGroup.GET("/invitations", planner.GetInvitations)
+
+		// DELETE /api/planner/invitations/:invitationId - 撤销注册邀请码
+		plannerGroup.DELETE("/invitations/:invitationId", planner.RevokeInvitation)
+
+		// GET /api/planner/accounts - 获取账号列表
+		plannerGroup.GET("/accounts", planner.GetAccounts)
+
+		// POST /api/planner/accounts - 创建账号
+		plannerGroup.POST("/accounts", planner.CreateAccount)
+
+		// PUT /api/planner/persons/:personId/role - 修改人员角色
+		plannerGroup.PUT("/persons/:personId/role", planner.ChangePersonRole)
+
+		// PUT /api/planner/persons/:personId/status - 停用或启用账号
+		plannerGroup.PUT("/persons/:personId/status", planner.SetAccountStatus)
+
+		// GET /api/planner/plans/:planId/enrollment-rules - 获取计划的自动报名规则
+		plannerGroup.GET("/plans/:planId/enrollment-rules", planner.GetEnrollmentRules)
+
+		// PUT /api/planner/plans/:planId/enrollment-rules - 替换自动报名规则并立即同步
+		plannerGroup.PUT("/plans/:planId/enrollment-rules", planner.UpdateEnrollmentRules)
+
+		// POST /api/planner/plans/:planId/enrollment-rules/preview - 预览规则同步结果（不写入）
+		plannerGroup.POST("/plans/:planId/enrollment-rules/preview", planner.PreviewEnrollment)
+
+		// POST /api/planner/plans/:planId/enrollment-rules/sync - 立即按规则同步员工名单
+		plannerGroup.POST("/plans/:planId/enrollment-rules/sync", planner.SyncEnrollment)
+
+		// PUT /api/planner/persons/:personId/profile - 修改人员职级和入职日期
+		plannerGroup.PUT("/persons/:personId/profile", planner.UpdatePersonProfile)
+
+		// GET /api/planner/persons/:personId/certificates - 获取人员证书
+		plannerGroup.GET("/persons/:personId/certificates", planner.GetPersonCertificates)
+
+		// POST /api/planner/persons/:personId/certificates - 登记人员证书
+		plannerGroup.POST("/persons/:personId/certificates", planner.AddPersonCertificate)
+
+		// DELETE /api/planner/persons/:personId/certificates/:certId - 删除人员证书
+		plannerGroup.DELETE("/persons/:personId/certificates/:certId", planner.DeletePersonCertificate)
+
+		// GET /api/planner/locations - 获取培训地点列表
+		plannerGroup.GET("/locations", planner.GetLocationsList)
+
+		// POST /api/planner/locations - 创建培训地点
+		plannerGroup.POST("/locations", planner.CreateLocation)
+
+		// PUT /api/planner/locations/:locationId - 修改培训地点
+		plannerGroup.PUT("/locations/:locationId", planner.UpdateLocation)
+
+		// DELETE /api/planner/locations/:locationId - 删除培训地点
+		plannerGroup.DELETE("/locations/:locationId", planner.DeleteLocation)
+
+		// GET /api/planner/locations/calendar - 获取培训地点占用日历
+		plannerGroup.GET("/locations/calendar", planner.GetLocationsCalendar)
+	}
+
+	// ==================== 日历订阅接口 ====================
+	calendarGroup := api.Group("/calendar")
+	{
+		// GET /api/calendar/subscription - 获取个人日历订阅地址（需要鉴权）
+		calendarGroup.GET("/subscription", middleware.AuthRequired(), calendar.GetSubscription)
+
+		// POST /api/calendar/subscription/rotate - 重置日历订阅地址（需要鉴权）
+		calendarGroup.POST("/subscription/rotate", middleware.AuthRequired(), calendar.RotateSubscription)
+
+		// GET /api/calendar/{token}.ics - 日历订阅（凭订阅令牌访问）
+		calendarGroup.GET("/:feed", calendar.GetFeed)
 	}
 
 	// 健康检查接口
@@ -1,19 +1,27 @@
 package middleware
 
 import (
+	"backend/authtoken"
 	"net/http"
+	"strings"
 	"time"
-	"backend/database"
 
 	"github.com/gin-gonic/gin"
-
 )
 
-// AuthRequired 简单鉴权中间件（验证 Session-ID）
+// accessToken 从请求头读取访问令牌：Authorization: Bearer <token>，兼容原来的 Session-ID 请求头
+func accessToken(c *gin.Context) string {
+	if auth := c.GetHeader("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	return c.GetHeader("Session-ID")
+}
+
+// AuthRequired 鉴权中间件：验证访问令牌的签名和有效期，用户信息取自令牌，不查询数据库
 func AuthRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		sessionID := c.GetHeader("Session-ID")
-		if sessionID == "" {
+		token := accessToken(c)
+		if token == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"code":    401,
 				"message": "未登录或登录已过期",
@@ -23,23 +31,30 @@ func AuthRequired() gin.HandlerFunc {
 			return
 		}
 
-		// 查询会话
-		var session database.Session
-		if err := database.DB.Where("session_id = ?", sessionID).First(&session).Error; err != nil {
+		claims, err := authtoken.Parse(token, time.Now())
+		if err == authtoken.ErrExpired {
+			// 前端收到此提示后用刷新令牌换取新的访问令牌
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"code":    401,
-				"message": "会话无效或已过期",
+				"message": "访问令牌已过期，请刷新令牌或重新登录",
 				"data":    nil,
 			})
 			c.Abort()
 			return
 		}
-
-		// 检查会话是否过期
-		if time.Now().After(session.ExpiresAt) {
+		if err == authtoken.ErrRevoked {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code":    401,
+				"message": "会话已被注销，请重新登录",
+				"data":    nil,
+			})
+			c.Abort()
+			return
+		}
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"code":    401,
-				"message": "会话已过期，请重新登录",
+				"message": "会话无效或已过期",
 				"data":    nil,
 			})
 			c.Abort()
@@ -47,8 +62,9 @@ func AuthRequired() gin.HandlerFunc {
 		}
 
 		// 将用户信息存入上下文
-		c.Set("personId", session.PersonID)
-		c.Set("role", session.Role)
+		c.Set("personId", claims.PersonID)
+		c.Set("role", claims.Role)
+		c.Set("sessionId", claims.SessionID)
 
 		c.Next()
 	}
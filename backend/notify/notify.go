@@ -0,0 +1,155 @@
+package notify
+
+import (
+	"backend/config"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 通知发送方式（对应配置项 NOTIFIER）
+const (
+	NotifierMailSink = "mailsink" // 邮件写入本地目录，开发和测试时查看
+	NotifierSMTP     = "smtp"     // 通过 SMTP 服务器发送邮件
+	NotifierLog      = "log"      // 只写入日志
+)
+
+// Message 发给用户的通知
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier 通知发送方
+type Notifier interface {
+	Name() string
+	Send(msg Message) error
+}
+
+// current 当前使用的发送方，在启动时由 Init 设置
+var current Notifier
+
+// Init 根据配置初始化通知发送方（启动时调用）
+func Init(cfg *config.Config) error {
+	notifier, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	current = notifier
+	return nil
+}
+
+// New 根据配置创建通知发送方
+func New(cfg *config.Config) (Notifier, error) {
+	switch strings.ToLower(cfg.Notifier) {
+	case "", NotifierMailSink:
+		return &MailSink{Dir: cfg.MailSinkDir, From: cfg.MailFrom}, nil
+	case NotifierSMTP:
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("NOTIFIER=smtp 时必须设置 SMTP_HOST")
+		}
+		return &SMTPNotifier{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			User:     cfg.SMTPUser,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		}, nil
+	case NotifierLog:
+		return &LogNotifier{}, nil
+	default:
+		return nil, fmt.Errorf("不支持的通知发送方式: %s", cfg.Notifier)
+	}
+}
+
+// Set 替换当前使用的发送方
+func Set(notifier Notifier) {
+	current = notifier
+}
+
+// Current 当前使用的发送方
+func Current() Notifier {
+	return current
+}
+
+// Send 用当前发送方发送通知
+func Send(msg Message) error {
+	return current.Send(msg)
+}
+
+// compose 按 RFC 5322 格式组装邮件，主题按 RFC 2047 编码
+func compose(from string, msg Message, now time.Time) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: =?UTF-8?B?" + base64.StdEncoding.EncodeToString([]byte(msg.Subject)) + "?=\r\n")
+	b.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// MailSink 把邮件保存为本地 .eml 文件，不真正发送
+type MailSink struct {
+	Dir  string
+	From string
+}
+
+func (m *MailSink) Name() string { return NotifierMailSink }
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9@._-]`)
+
+func (m *MailSink) Send(msg Message) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	now := time.Now()
+	name := now.Format("20060102-150405.000000") + "-" + unsafeFileChars.ReplaceAllString(msg.To, "_") + ".eml"
+	path := filepath.Join(m.Dir, name)
+	if err := os.WriteFile(path, compose(m.From, msg, now), 0o600); err != nil {
+		return err
+	}
+	log.Printf("邮件已写入 %s", path)
+	return nil
+}
+
+// SMTPNotifier 通过 SMTP 服务器发送邮件
+type SMTPNotifier struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	From     string
+}
+
+func (n *SMTPNotifier) Name() string { return NotifierSMTP }
+
+func (n *SMTPNotifier) Send(msg Message) error {
+	var auth smtp.Auth
+	if n.User != "" {
+		auth = smtp.PlainAuth("", n.User, n.Password, n.Host)
+	}
+	addr := net.JoinHostPort(n.Host, strconv.Itoa(n.Port))
+	return smtp.SendMail(addr, auth, n.From, []string{msg.To}, compose(n.From, msg, time.Now()))
+}
+
+// LogNotifier 只把通知写入日志
+type LogNotifier struct{}
+
+func (LogNotifier) Name() string { return NotifierLog }
+
+func (LogNotifier) Send(msg Message) error {
+	log.Printf("通知 → %s：%s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
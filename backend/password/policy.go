@@ -0,0 +1,89 @@
+package password
+
+import (
+	"backend/config"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// maxBytes bcrypt 只使用密码的前 72 个字节，更长的密码直接拒绝
+const maxBytes = 72
+
+// Policy 密码规则，由 PASSWORD_* 配置项设置
+type Policy struct {
+	MinLength     int
+	RequireLetter bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// CurrentPolicy 当前配置的密码规则
+func CurrentPolicy() Policy {
+	cfg := config.AppConfig
+	return Policy{
+		MinLength:     cfg.PasswordMinLength,
+		RequireLetter: cfg.PasswordRequireLetter,
+		RequireDigit:  cfg.PasswordRequireDigit,
+		RequireSymbol: cfg.PasswordRequireSymbol,
+	}
+}
+
+// Describe 密码规则的说明，如"至少8位，必须包含字母和数字"
+func (p Policy) Describe() string {
+	desc := "至少" + strconv.Itoa(p.MinLength) + "位"
+	kinds := make([]string, 0, 3)
+	if p.RequireLetter {
+		kinds = append(kinds, "字母")
+	}
+	if p.RequireDigit {
+		kinds = append(kinds, "数字")
+	}
+	if p.RequireSymbol {
+		kinds = append(kinds, "特殊字符")
+	}
+	if len(kinds) > 0 {
+		desc += "，必须包含" + strings.Join(kinds, "和")
+	}
+	return desc
+}
+
+// Check 检查密码是否符合规则，不符合时返回提示
+func (p Policy) Check(pw string) string {
+	if utf8.RuneCountInString(pw) < p.MinLength || len(pw) > maxBytes {
+		return "密码长度不符合要求：" + p.Describe() + "，且不超过" + strconv.Itoa(maxBytes) + "个字节"
+	}
+	var letter, digit, symbol bool
+	for _, r := range pw {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsSpace(r):
+		default:
+			symbol = true
+		}
+	}
+	if (p.RequireLetter && !letter) || (p.RequireDigit && !digit) || (p.RequireSymbol && !symbol) {
+		return "密码强度不足：" + p.Describe()
+	}
+	return ""
+}
+
+// Hash 计算密码的 bcrypt 哈希
+func Hash(pw string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(pw), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// Matches 密码是否与哈希匹配
+func Matches(hash, pw string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(pw)) == nil
+}
@@ -0,0 +1,87 @@
+package password
+
+import (
+	"backend/config"
+	"backend/database"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrResetInvalid 重置令牌不存在、已使用或已被新申请的令牌取代
+	ErrResetInvalid = errors.New("重置链接无效或已使用，请重新申请")
+	// ErrResetExpired 重置令牌已过期
+	ErrResetExpired = errors.New("重置链接已过期，请重新申请")
+)
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateResetToken 为账号生成密码重置令牌，返回令牌明文（只用于发送给用户）和过期时间
+// 同一账号之前申请的未使用令牌同时作废，只有最新的链接有效
+func CreateResetToken(accountID int64, requestedIP string, now time.Time) (string, time.Time, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", time.Time{}, err
+	}
+	token := hex.EncodeToString(bytes)
+	expiresAt := now.Add(time.Duration(config.AppConfig.PasswordResetMinutes) * time.Minute)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&database.PasswordResetToken{}).
+			Where("account_id = ? AND used_at IS NULL", accountID).
+			Update("used_at", now).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&database.PasswordResetToken{
+			AccountID:   accountID,
+			TokenHash:   hashToken(token),
+			RequestedIP: requestedIP,
+			CreatedAt:   now,
+			ExpiresAt:   expiresAt,
+		}).Error
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// ConsumeResetToken 在事务 tx 中把重置令牌标记为已使用，返回令牌记录
+// 并发使用同一令牌时只有一次成功
+func ConsumeResetToken(tx *gorm.DB, token string, now time.Time) (*database.PasswordResetToken, error) {
+	var record database.PasswordResetToken
+	err := tx.Where("token_hash = ?", hashToken(token)).First(&record).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrResetInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if record.UsedAt != nil {
+		return nil, ErrResetInvalid
+	}
+	if !record.ExpiresAt.After(now) {
+		return nil, ErrResetExpired
+	}
+
+	result := tx.Model(&database.PasswordResetToken{}).
+		Where("token_id = ? AND used_at IS NULL", record.TokenID).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrResetInvalid
+	}
+	record.UsedAt = &now
+	return &record, nil
+}
@@ -0,0 +1,154 @@
+package planstatus
+
+import (
+	"backend/config"
+	"backend/database"
+	"backend/scheduling"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// scheduler 定时按计划时间和课程进度推进计划状态：
+// 规划中的计划在开始时间到达或第一次课开始后变为进行中；
+// 进行中的计划在结束时间到达、全部课程结束且评分完成后变为已完成
+type scheduler struct {
+	interval time.Duration
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+var s *scheduler
+
+// Start 启动计划状态定时任务（启动时调用），间隔不大于 0 时不启动
+func Start(cfg *config.Config) {
+	if cfg.PlanStatusSyncMinutes <= 0 {
+		log.Println("计划状态自动推进已关闭")
+		return
+	}
+
+	s = &scheduler{
+		interval: time.Duration(cfg.PlanStatusSyncMinutes) * time.Minute,
+		stop:     make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.loop()
+
+	log.Printf("计划状态定时任务已启动，间隔: %v", s.interval)
+}
+
+// Stop 停止定时任务，等待进行中的检查完成
+func Stop() {
+	if s == nil {
+		return
+	}
+	close(s.stop)
+	s.wg.Wait()
+}
+
+func (s *scheduler) loop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		Advance(time.Now())
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// planTime 计划开始或结束时间按计划的时区换算为时刻
+func planTime(plan database.TrainingPlan, t time.Time) time.Time {
+	return scheduling.ClassTime(t.Format("2006-01-02"), t.Format("15:04:05"), plan.Timezone)
+}
+
+// firstClassStarted 计划中是否已有课程开始上课
+func firstClassStarted(planID int64, now time.Time) (bool, error) {
+	items, err := planItems(planID)
+	if err != nil {
+		return false, err
+	}
+	for _, item := range items {
+		if !scheduling.ClassTime(item.ClassDate, item.ClassBeginTime, item.Timezone).After(now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// autoTarget 按计划时间和课程进度，计划应自动变更到的状态和原因，不需要变更时返回空字符串
+func autoTarget(plan database.TrainingPlan, now time.Time) (string, string, error) {
+	switch plan.PlanStatus {
+	case database.PlanStatusPlanning:
+		if !planTime(plan, plan.PlanStartDatetime).After(now) {
+			return database.PlanStatusInProgress, "计划开始时间已到", nil
+		}
+		started, err := firstClassStarted(plan.PlanID, now)
+		if err != nil || !started {
+			return "", "", err
+		}
+		return database.PlanStatusInProgress, "第一次课已开始", nil
+	case database.PlanStatusInProgress:
+		if !planTime(plan, plan.PlanEndDatetime).After(now) {
+			return database.PlanStatusCompleted, "计划结束时间已到，课程均已结束且评分完成", nil
+		}
+	}
+	return "", "", nil
+}
+
+// Advance 检查全部未完成的计划，按时间和课程进度推进状态，返回状态变更的次数
+// 已经结束的规划中计划会在同一次检查中依次变为进行中、已完成
+func Advance(now time.Time) int {
+	var plans []database.TrainingPlan
+	err := database.DB.Where("plan_status IN ?", []string{database.PlanStatusPlanning, database.PlanStatusInProgress}).
+		Order("plan_id").
+		Find(&plans).Error
+	if err != nil {
+		log.Printf("查询需推进状态的计划失败: %v", err)
+		return 0
+	}
+
+	advanced := 0
+	for i := range plans {
+		plan := &plans[i]
+		for {
+			to, reason, err := autoTarget(*plan, now)
+			if err != nil {
+				log.Printf("计划 %d 查询课程进度失败: %v", plan.PlanID, err)
+				break
+			}
+			if to == "" {
+				break
+			}
+
+			// 不满足条件（如还有评价未评分）时保持当前状态，下次再检查
+			blockers, err := Check(*plan, to, now)
+			if err != nil {
+				log.Printf("计划 %d 检查状态变更失败: %v", plan.PlanID, err)
+				break
+			}
+			if len(blockers) > 0 {
+				break
+			}
+			from := plan.PlanStatus
+			err = database.DB.Transaction(func(tx *gorm.DB) error {
+				return Apply(tx, plan, to, nil, database.PlanStatusSourceAuto, reason)
+			})
+			if err != nil {
+				if err != ErrStatusChanged {
+					log.Printf("计划 %d 状态变更失败: %v", plan.PlanID, err)
+				}
+				break
+			}
+			log.Printf("计划 %d 状态自动变更：%s → %s（%s）", plan.PlanID, from, to, reason)
+			advanced++
+		}
+	}
+	return advanced
+}
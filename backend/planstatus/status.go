@@ -0,0 +1,186 @@
+package planstatus
+
+import (
+	"backend/database"
+	"backend/scheduling"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrStatusChanged 变更时计划状态已被其他操作（或定时任务）修改
+var ErrStatusChanged = errors.New("培训计划状态已被修改，请刷新后重试")
+
+// 阻止状态变更的原因（Blocker.Reason）
+const (
+	BlockerNoItems        = "no_items"        // 计划中没有课程安排
+	BlockerClassesStarted = "classes_started" // 已有课程开始上课
+	BlockerClassesPending = "classes_pending" // 还有课程没有结束
+	BlockerGradesPending  = "grades_pending"  // 还有评价没有评分
+)
+
+// Blocker 阻止状态变更的原因
+type Blocker struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	Count   int64  `json:"count"`
+}
+
+// transitions 允许的状态变更：规划中 ⇄ 进行中 ⇄ 已完成，不能跳过进行中
+var transitions = map[string][]string{
+	database.PlanStatusPlanning:   {database.PlanStatusInProgress},
+	database.PlanStatusInProgress: {database.PlanStatusPlanning, database.PlanStatusCompleted},
+	database.PlanStatusCompleted:  {database.PlanStatusInProgress},
+}
+
+// Valid 是否为合法的计划状态
+func Valid(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// Next 从 status 可以变更到的状态
+func Next(status string) []string {
+	return transitions[status]
+}
+
+// Allowed 是否允许从 from 变更到 to
+func Allowed(from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// planItem 检查课程进度用到的课程安排字段
+type planItem struct {
+	ClassDate      string
+	ClassBeginTime string
+	ClassEndTime   string
+	Timezone       string
+}
+
+// planItems 计划的全部课程安排
+func planItems(planID int64) ([]planItem, error) {
+	var items []planItem
+	err := database.DB.Model(&database.PlanCourseItem{}).
+		Select(database.FormatDate("class_date")+" AS class_date, class_begin_time, class_end_time, timezone").
+		Where("plan_id = ?", planID).
+		Scan(&items).Error
+	return items, err
+}
+
+// Check 检查计划能否变更到 to，返回阻止变更的原因（可以变更时为空）
+//   - 规划中 → 进行中：计划中至少有一条课程安排
+//   - 进行中 → 规划中：还没有课程开始上课
+//   - 进行中 → 已完成：全部课程已结束，且没有待评分的评价（讲师未评分或 AI 评分未完成）
+//   - 已完成 → 进行中：重新开放计划，不做检查
+func Check(plan database.TrainingPlan, to string, now time.Time) ([]Blocker, error) {
+	blockers := []Blocker{}
+	if plan.PlanStatus == database.PlanStatusCompleted {
+		return blockers, nil
+	}
+
+	items, err := planItems(plan.PlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch to {
+	case database.PlanStatusInProgress:
+		if len(items) == 0 {
+			blockers = append(blockers, Blocker{Reason: BlockerNoItems, Message: "计划中没有课程安排"})
+		}
+
+	case database.PlanStatusPlanning:
+		var started int64
+		for _, item := range items {
+			if !scheduling.ClassTime(item.ClassDate, item.ClassBeginTime, item.Timezone).After(now) {
+				started++
+			}
+		}
+		if started > 0 {
+			blockers = append(blockers, Blocker{Reason: BlockerClassesStarted, Message: strconv.FormatInt(started, 10) + "次课程已开始上课", Count: started})
+		}
+
+	case database.PlanStatusCompleted:
+		var pending int64
+		for _, item := range items {
+			if !scheduling.ClassEnded(item.ClassDate, item.ClassEndTime, item.Timezone, now) {
+				pending++
+			}
+		}
+		if pending > 0 {
+			blockers = append(blockers, Blocker{Reason: BlockerClassesPending, Message: strconv.FormatInt(pending, 10) + "次课程尚未结束", Count: pending})
+		}
+
+		var ungraded int64
+		err := database.DB.Model(&database.AttendanceEvaluation{}).
+			Where("item_id IN (?)", database.DB.Model(&database.PlanCourseItem{}).Select("item_id").Where("plan_id = ?", plan.PlanID)).
+			// 与成绩统计相同：有讲师评分或评语即为已评分（评语可不填）；AI 评分仍在进行中的也算未评分
+			Where("((COALESCE(teacher_score, 0) = 0 AND COALESCE(teacher_comment, '') = '') OR self_scoring_status = ? OR teacher_scoring_status = ?)",
+				database.ScoringStatusPending, database.ScoringStatusPending).
+			Count(&ungraded).Error
+		if err != nil {
+			return nil, err
+		}
+		if ungraded > 0 {
+			blockers = append(blockers, Blocker{Reason: BlockerGradesPending, Message: strconv.FormatInt(ungraded, 10) + "份评价尚未评分", Count: ungraded})
+		}
+	}
+	return blockers, nil
+}
+
+// Message 状态不能变更时的提示文字，如"不能变更为已完成：2次课程尚未结束；5份评价尚未评分"
+func Message(to string, blockers []Blocker) string {
+	parts := make([]string, 0, len(blockers))
+	for _, b := range blockers {
+		parts = append(parts, b.Message)
+	}
+	return "不能变更为" + to + "：" + strings.Join(parts, "；")
+}
+
+// Apply 在事务 tx 中把计划变更到 to 并记录变更，actorID 为空表示定时任务自动变更
+// 调用前应先用 Allowed 和 Check 检查；计划状态已被修改时返回 ErrStatusChanged
+func Apply(tx *gorm.DB, plan *database.TrainingPlan, to string, actorID *int64, source, reason string) error {
+	result := tx.Model(&database.TrainingPlan{}).
+		Where("plan_id = ? AND plan_status = ?", plan.PlanID, plan.PlanStatus).
+		Update("plan_status", to)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStatusChanged
+	}
+	record := database.PlanStatusTransition{
+		PlanID:     plan.PlanID,
+		FromStatus: plan.PlanStatus,
+		ToStatus:   to,
+		ActorID:    actorID,
+		Source:     source,
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return err
+	}
+	plan.PlanStatus = to
+	return nil
+}
+
+// RecordCreated 记录新建计划的初始状态
+func RecordCreated(tx *gorm.DB, plan database.TrainingPlan, actorID int64, reason string) error {
+	return tx.Create(&database.PlanStatusTransition{
+		PlanID:    plan.PlanID,
+		ToStatus:  plan.PlanStatus,
+		ActorID:   &actorID,
+		Source:    database.PlanStatusSourceCreate,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}).Error
+}
@@ -0,0 +1,167 @@
+package planstatus
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"testing"
+	"time"
+)
+
+func TestAllowed(t *testing.T) {
+	statuses := []string{database.PlanStatusPlanning, database.PlanStatusInProgress, database.PlanStatusCompleted}
+	allowed := map[[2]string]bool{
+		{database.PlanStatusPlanning, database.PlanStatusInProgress}:  true,
+		{database.PlanStatusInProgress, database.PlanStatusPlanning}:  true,
+		{database.PlanStatusInProgress, database.PlanStatusCompleted}: true,
+		{database.PlanStatusCompleted, database.PlanStatusInProgress}: true,
+	}
+	for _, from := range statuses {
+		for _, to := range statuses {
+			if got, want := Allowed(from, to), allowed[[2]string{from, to}]; got != want {
+				t.Errorf("Allowed(%s, %s) = %v，应为 %v", from, to, got, want)
+			}
+		}
+	}
+	if Allowed("未知", database.PlanStatusInProgress) || Valid("未知") {
+		t.Error("未知状态不应允许变更")
+	}
+}
+
+// reasons 阻止变更的原因及次数
+func reasons(blockers []Blocker) map[string]int64 {
+	got := map[string]int64{}
+	for _, b := range blockers {
+		got[b.Reason] = b.Count
+	}
+	return got
+}
+
+func setStatus(t *testing.T, plan *database.TrainingPlan, status string) {
+	t.Helper()
+	if err := database.DB.Model(&database.TrainingPlan{}).Where("plan_id = ?", plan.PlanID).Update("plan_status", status).Error; err != nil {
+		t.Fatalf("修改计划状态失败: %v", err)
+	}
+	plan.PlanStatus = status
+}
+
+func TestCheck(t *testing.T) {
+	dbtest.Setup(t)
+	now := time.Now()
+	course := dbtest.CreateCourse(t, "安全培训", "安全", dbtest.TeacherID)
+
+	empty := dbtest.CreatePlan(t, "空计划", dbtest.Date(1), dbtest.Date(5))
+	setStatus(t, &empty, database.PlanStatusPlanning)
+
+	// 一次课已结束（已评分、未评分各一份评价），一次课还没开始
+	running := dbtest.CreatePlan(t, "进行中计划", dbtest.Date(-3), dbtest.Date(3), dbtest.EmployeeID, dbtest.Employee2ID)
+	past := dbtest.CreateItem(t, running, course, dbtest.Date(-2), "09:00:00", "11:00:00", "")
+	dbtest.CreateItem(t, running, course, dbtest.Date(2), "09:00:00", "11:00:00", "")
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{PersonID: dbtest.EmployeeID, ItemID: past.ItemID, SelfScore: 80, TeacherScore: 90})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{PersonID: dbtest.Employee2ID, ItemID: past.ItemID, SelfScore: 70})
+
+	// 课程均已结束：一份只有讲师评语，一份讲师评分仍在等待 AI
+	ended := dbtest.CreatePlan(t, "已结束计划", dbtest.Date(-5), dbtest.Date(-1), dbtest.EmployeeID, dbtest.Employee2ID)
+	endedItem := dbtest.CreateItem(t, ended, course, dbtest.Date(-2), "09:00:00", "11:00:00", "")
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{PersonID: dbtest.EmployeeID, ItemID: endedItem.ItemID, SelfScore: 80, TeacherComment: "表现良好"})
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{PersonID: dbtest.Employee2ID, ItemID: endedItem.ItemID, SelfScore: 70,
+		TeacherComment: "需要加强", TeacherScoringStatus: database.ScoringStatusPending})
+
+	graded := dbtest.CreatePlan(t, "已评分计划", dbtest.Date(-5), dbtest.Date(-1), dbtest.EmployeeID)
+	gradedItem := dbtest.CreateItem(t, graded, course, dbtest.Date(-2), "09:00:00", "11:00:00", "")
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{PersonID: dbtest.EmployeeID, ItemID: gradedItem.ItemID, SelfScore: 80, TeacherScore: 85})
+
+	upcoming := dbtest.CreatePlan(t, "未开课计划", dbtest.Date(1), dbtest.Date(5))
+	dbtest.CreateItem(t, upcoming, course, dbtest.Date(2), "09:00:00", "11:00:00", "")
+
+	completed := dbtest.CreatePlan(t, "已完成计划", dbtest.Date(-5), dbtest.Date(3))
+	dbtest.CreateItem(t, completed, course, dbtest.Date(2), "09:00:00", "11:00:00", "")
+	setStatus(t, &completed, database.PlanStatusCompleted)
+
+	cases := []struct {
+		name string
+		plan database.TrainingPlan
+		to   string
+		want map[string]int64
+	}{
+		{"没有课程安排不能开始", empty, database.PlanStatusInProgress, map[string]int64{BlockerNoItems: 0}},
+		{"有课程安排可以开始", upcoming, database.PlanStatusInProgress, map[string]int64{}},
+		{"已开课不能退回规划中", running, database.PlanStatusPlanning, map[string]int64{BlockerClassesStarted: 1}},
+		{"未开课可以退回规划中", upcoming, database.PlanStatusPlanning, map[string]int64{}},
+		{"课程未结束且有未评分", running, database.PlanStatusCompleted, map[string]int64{BlockerClassesPending: 1, BlockerGradesPending: 1}},
+		{"AI 评分未完成算未评分", ended, database.PlanStatusCompleted, map[string]int64{BlockerGradesPending: 1}},
+		{"课程结束且已评分可以完成", graded, database.PlanStatusCompleted, map[string]int64{}},
+		{"已完成计划重新开放不做检查", completed, database.PlanStatusInProgress, map[string]int64{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			blockers, err := Check(tc.plan, tc.to, now)
+			if err != nil {
+				t.Fatalf("检查失败: %v", err)
+			}
+			got := reasons(blockers)
+			if len(got) != len(tc.want) {
+				t.Fatalf("阻止原因 %v，应为 %v", got, tc.want)
+			}
+			for reason, count := range tc.want {
+				if c, ok := got[reason]; !ok || c != count {
+					t.Fatalf("阻止原因 %v，应为 %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAdvance(t *testing.T) {
+	dbtest.Setup(t)
+	course := dbtest.CreateCourse(t, "安全培训", "安全", dbtest.TeacherID)
+
+	// 已结束且已评分的规划中计划在同一次检查中变为进行中、已完成
+	finished := dbtest.CreatePlan(t, "已结束计划", dbtest.Date(-5), dbtest.Date(-1), dbtest.EmployeeID)
+	item := dbtest.CreateItem(t, finished, course, dbtest.Date(-2), "09:00:00", "11:00:00", "")
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{PersonID: dbtest.EmployeeID, ItemID: item.ItemID, SelfScore: 80, TeacherScore: 90})
+	setStatus(t, &finished, database.PlanStatusPlanning)
+
+	// 已结束但还有未评分的评价，停在进行中
+	ungraded := dbtest.CreatePlan(t, "未评分计划", dbtest.Date(-5), dbtest.Date(-1), dbtest.EmployeeID)
+	item = dbtest.CreateItem(t, ungraded, course, dbtest.Date(-2), "09:00:00", "11:00:00", "")
+	dbtest.CreateEvaluation(t, database.AttendanceEvaluation{PersonID: dbtest.EmployeeID, ItemID: item.ItemID, SelfScore: 80})
+	setStatus(t, &ungraded, database.PlanStatusPlanning)
+
+	// 开始时间未到，但第一次课已开始
+	early := dbtest.CreatePlan(t, "提前开课计划", dbtest.Date(1), dbtest.Date(5))
+	dbtest.CreateItem(t, early, course, dbtest.Date(-1), "09:00:00", "11:00:00", "")
+	setStatus(t, &early, database.PlanStatusPlanning)
+
+	// 开始时间未到且没有开课，保持规划中
+	future := dbtest.CreatePlan(t, "未开始计划", dbtest.Date(1), dbtest.Date(5))
+	dbtest.CreateItem(t, future, course, dbtest.Date(2), "09:00:00", "11:00:00", "")
+	setStatus(t, &future, database.PlanStatusPlanning)
+
+	if got := Advance(time.Now()); got != 4 {
+		t.Fatalf("状态变更 %d 次，应为 4 次", got)
+	}
+	want := map[int64]string{
+		finished.PlanID: database.PlanStatusCompleted,
+		ungraded.PlanID: database.PlanStatusInProgress,
+		early.PlanID:    database.PlanStatusInProgress,
+		future.PlanID:   database.PlanStatusPlanning,
+	}
+	for planID, status := range want {
+		var plan database.TrainingPlan
+		database.DB.First(&plan, planID)
+		if plan.PlanStatus != status {
+			t.Errorf("计划 %s 状态为 %s，应为 %s", plan.PlanName, plan.PlanStatus, status)
+		}
+	}
+
+	var records int64
+	database.DB.Model(&database.PlanStatusTransition{}).Where("plan_id = ? AND source = ?", finished.PlanID, database.PlanStatusSourceAuto).Count(&records)
+	if records != 2 {
+		t.Fatalf("自动变更记录 %d 条，应为 2 条", records)
+	}
+
+	// 再次检查不再变更
+	if got := Advance(time.Now()); got != 0 {
+		t.Fatalf("重复检查变更了 %d 次", got)
+	}
+}
@@ -0,0 +1,338 @@
+package scheduling
+
+import (
+	"backend/database"
+	"sort"
+	"time"
+)
+
+// 讲师不可授课的原因
+const (
+	UnavailableOutsideWindow = "outside_window" // 不在讲师登记的每周可授课时间段内
+	UnavailableBlackout      = "blackout"       // 与讲师的不可授课时间段重叠
+)
+
+// Window 讲师每周可授课的一个时间段
+type Window struct {
+	Weekday   int    `json:"weekday"` // 1-7 表示周一到周日
+	BeginTime string `json:"beginTime"`
+	EndTime   string `json:"endTime"`
+}
+
+// BlackoutPeriod 讲师不可授课的时间段
+type BlackoutPeriod struct {
+	BlackoutID int64  `json:"blackoutId"`
+	StartDate  string `json:"startDate"`
+	BeginTime  string `json:"beginTime"`
+	EndDate    string `json:"endDate"`
+	EndTime    string `json:"endTime"`
+	Reason     string `json:"reason"`
+}
+
+// Unavailability 讲师在某个时间段不能授课的原因
+type Unavailability struct {
+	Type     string          `json:"type"`
+	Windows  []Window        `json:"windows,omitempty"`  // 当天登记的可授课时间段（不在可授课时间段内时）
+	Blackout *BlackoutPeriod `json:"blackout,omitempty"` // 重叠的不可授课时间段
+}
+
+// FreeSlot 讲师空闲的时间段
+type FreeSlot struct {
+	Date      string `json:"date"`
+	BeginTime string `json:"beginTime"`
+	EndTime   string `json:"endTime"`
+	Minutes   int    `json:"minutes"`
+}
+
+// dayMinutes 一天的分钟数，不可授课时间段的结束时间 23:59:59 视为到当天结束
+const dayMinutes = 24 * 60
+
+// IsoWeekday 将 time.Weekday 转换为 1-7 表示的周一到周日
+func IsoWeekday(d time.Weekday) int {
+	if d == time.Sunday {
+		return 7
+	}
+	return int(d)
+}
+
+// FormatBlackout 格式化不可授课时间段
+func FormatBlackout(b database.TeacherBlackout) BlackoutPeriod {
+	return BlackoutPeriod{
+		BlackoutID: b.BlackoutID,
+		StartDate:  b.StartDate.Format("2006-01-02"),
+		BeginTime:  b.BeginTime,
+		EndDate:    b.EndDate.Format("2006-01-02"),
+		EndTime:    b.EndTime,
+		Reason:     b.Reason,
+	}
+}
+
+// blackoutOn 不可授课时间段在某一天占用的时间（分钟），与该天无关时返回 false
+func blackoutOn(b database.TeacherBlackout, date string) (interval, bool) {
+	start, end := b.StartDate.Format("2006-01-02"), b.EndDate.Format("2006-01-02")
+	if date < start || date > end {
+		return interval{}, false
+	}
+	iv := interval{0, dayMinutes}
+	if date == start {
+		iv.begin, _ = ParseClock(b.BeginTime)
+	}
+	if date == end && b.EndTime != "23:59:59" {
+		iv.end, _ = ParseClock(b.EndTime)
+	}
+	return iv, iv.begin < iv.end
+}
+
+// blackoutsBetween 讲师在日期范围内的不可授课时间段
+func blackoutsBetween(teacherIDs []int64, startDate, endDate string) ([]database.TeacherBlackout, error) {
+	var blackouts []database.TeacherBlackout
+	err := database.DB.
+		Where("teacher_id IN ?", teacherIDs).
+		Where(database.DateUntil("start_date", endDate)).
+		Where(database.DateFrom("end_date", startDate)).
+		Order("start_date, begin_time").
+		Find(&blackouts).Error
+	return blackouts, err
+}
+
+// weeklyWindows 讲师登记的每周可授课时间段：讲师 -> 星期 -> 时间段，没有登记的讲师不在结果中
+func weeklyWindows(teacherIDs []int64) (map[int64]map[int][]interval, error) {
+	var rows []database.TeacherAvailability
+	if err := database.DB.Where("teacher_id IN ?", teacherIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	windows := make(map[int64]map[int][]interval)
+	for _, row := range rows {
+		begin, err1 := ParseClock(row.BeginTime)
+		end, err2 := ParseClock(row.EndTime)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if windows[row.TeacherID] == nil {
+			windows[row.TeacherID] = make(map[int][]interval)
+		}
+		windows[row.TeacherID][row.Weekday] = append(windows[row.TeacherID][row.Weekday], interval{begin, end})
+	}
+	return windows, nil
+}
+
+// TeacherUnavailability 检查讲师在该时间段能否授课，可以授课时返回 nil
+// 讲师登记了每周可授课时间段时，上课时间必须完整落在当天的某个时间段内；讲师没有登记时不限制
+func TeacherUnavailability(teacherID int64, slot Slot) (*Unavailability, error) {
+	var windows []database.TeacherAvailability
+	if err := database.DB.Where("teacher_id = ?", teacherID).Order("weekday, begin_time").Find(&windows).Error; err != nil {
+		return nil, err
+	}
+	blackouts, err := blackoutsBetween([]int64{teacherID}, slot.Date, slot.Date)
+	if err != nil {
+		return nil, err
+	}
+	return unavailability(windows, blackouts, slot)
+}
+
+// unavailability 按讲师的每周可授课时间段（按星期、开始时间排序）和不可授课时间段检查时间段能否授课
+func unavailability(windows []database.TeacherAvailability, blackouts []database.TeacherBlackout, slot Slot) (*Unavailability, error) {
+	date, err := time.Parse("2006-01-02", slot.Date)
+	if err != nil {
+		return nil, err
+	}
+	begin, err := ParseClock(slot.Begin)
+	if err != nil {
+		return nil, err
+	}
+	end, err := ParseClock(slot.End)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(windows) > 0 {
+		weekday := IsoWeekday(date.Weekday())
+		inside := false
+		today := []Window{}
+		for _, w := range windows {
+			if w.Weekday != weekday {
+				continue
+			}
+			today = append(today, Window{Weekday: w.Weekday, BeginTime: w.BeginTime, EndTime: w.EndTime})
+			wb, _ := ParseClock(w.BeginTime)
+			we, _ := ParseClock(w.EndTime)
+			if wb <= begin && end <= we {
+				inside = true
+			}
+		}
+		if !inside {
+			return &Unavailability{Type: UnavailableOutsideWindow, Windows: today}, nil
+		}
+	}
+
+	for _, b := range blackouts {
+		if iv, ok := blackoutOn(b, slot.Date); ok && iv.begin < end && iv.end > begin {
+			period := FormatBlackout(b)
+			return &Unavailability{Type: UnavailableBlackout, Blackout: &period}, nil
+		}
+	}
+	return nil, nil
+}
+
+// addTeacherUnavailability 将讲师每周可授课时间段以外的时间和不可授课时间段记为讲师被占用
+func addTeacherUnavailability(busy busyMap, teacherIDs []int64, days []time.Time) error {
+	if len(teacherIDs) == 0 || len(days) == 0 {
+		return nil
+	}
+	windows, err := weeklyWindows(teacherIDs)
+	if err != nil {
+		return err
+	}
+	for teacherID, byWeekday := range windows {
+		for _, d := range days {
+			date := d.Format("2006-01-02")
+			ivs := append([]interval(nil), byWeekday[IsoWeekday(d.Weekday())]...)
+			sort.Slice(ivs, func(i, j int) bool { return ivs[i].begin < ivs[j].begin })
+			cursor := 0
+			for _, iv := range ivs {
+				if iv.begin > cursor {
+					busy.add(busyKey("teacher", teacherID, date), cursor, iv.begin)
+				}
+				if iv.end > cursor {
+					cursor = iv.end
+				}
+			}
+			if cursor < dayMinutes {
+				busy.add(busyKey("teacher", teacherID, date), cursor, dayMinutes)
+			}
+		}
+	}
+
+	blackouts, err := blackoutsBetween(teacherIDs, days[0].Format("2006-01-02"), days[len(days)-1].Format("2006-01-02"))
+	if err != nil {
+		return err
+	}
+	for _, b := range blackouts {
+		for _, d := range days {
+			date := d.Format("2006-01-02")
+			if iv, ok := blackoutOn(b, date); ok {
+				busy.add(busyKey("teacher", b.TeacherID, date), iv.begin, iv.end)
+			}
+		}
+	}
+	return nil
+}
+
+// TeacherFreeSlots 计算讲师在日期范围内每天 dayStart-dayEnd（分钟）之间的空闲时间段
+// 扣除已有课程安排、每周可授课时间段以外的时间和不可授课时间段，只返回不短于 minMinutes 的时间段
+func TeacherFreeSlots(teacherIDs []int64, start, end time.Time, dayStart, dayEnd, minMinutes int) (map[int64][]FreeSlot, error) {
+	var days []time.Time
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+
+	busy := make(busyMap)
+	if err := addTeacherUnavailability(busy, teacherIDs, days); err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Booking
+		TeacherID int64
+	}
+	err := bookingsQuery().
+		Select(bookingsColumns()+", c.teacher_id").
+		Where("c.teacher_id IN ?", teacherIDs).
+		Where(database.DateBetween("pci.class_date", start.Format("2006-01-02"), end.Format("2006-01-02"))).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		begin, err1 := ParseClock(row.ClassBeginTime)
+		finish, err2 := ParseClock(row.ClassEndTime)
+		if err1 == nil && err2 == nil {
+			busy.add(busyKey("teacher", row.TeacherID, row.ClassDate), begin, finish)
+		}
+	}
+
+	free := make(map[int64][]FreeSlot, len(teacherIDs))
+	for _, teacherID := range teacherIDs {
+		slots := []FreeSlot{}
+		for _, d := range days {
+			date := d.Format("2006-01-02")
+			for _, gap := range busy.gaps(busyKey("teacher", teacherID, date), dayStart, dayEnd) {
+				if gap.end-gap.begin >= minMinutes {
+					slots = append(slots, FreeSlot{
+						Date:      date,
+						BeginTime: FormatClock(gap.begin),
+						EndTime:   FormatClock(gap.end),
+						Minutes:   gap.end - gap.begin,
+					})
+				}
+			}
+		}
+		free[teacherID] = slots
+	}
+	return free, nil
+}
+
+// teacherBookingsFrom 讲师从某天起（endDate 不为空时截止到该天）的课程安排
+func teacherBookingsFrom(teacherID int64, startDate, endDate string) ([]Booking, error) {
+	query := bookingsQuery().
+		Where("c.teacher_id = ?", teacherID).
+		Where(database.DateFrom("pci.class_date", startDate))
+	if endDate != "" {
+		query = query.Where(database.DateUntil("pci.class_date", endDate))
+	}
+	var bookings []Booking
+	err := query.Order("pci.class_date, pci.class_begin_time").Scan(&bookings).Error
+	return bookings, err
+}
+
+// BookingsInBlackout 与不可授课时间段重叠的讲师已有课程安排
+func BookingsInBlackout(b database.TeacherBlackout) ([]Booking, error) {
+	bookings, err := teacherBookingsFrom(b.TeacherID, b.StartDate.Format("2006-01-02"), b.EndDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	result := []Booking{}
+	for _, bk := range bookings {
+		begin, err1 := ParseClock(bk.ClassBeginTime)
+		end, err2 := ParseClock(bk.ClassEndTime)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if iv, ok := blackoutOn(b, bk.ClassDate); ok && iv.begin < end && iv.end > begin {
+			result = append(result, bk)
+		}
+	}
+	return result, nil
+}
+
+// BookingsOutsideWindows 讲师从某天起不在每周可授课时间段内的课程安排，windows 为空表示不限制
+func BookingsOutsideWindows(teacherID int64, windows []Window, fromDate string) ([]Booking, error) {
+	result := []Booking{}
+	if len(windows) == 0 {
+		return result, nil
+	}
+	bookings, err := teacherBookingsFrom(teacherID, fromDate, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, bk := range bookings {
+		date, err := time.Parse("2006-01-02", bk.ClassDate)
+		if err != nil {
+			continue
+		}
+		begin, _ := ParseClock(bk.ClassBeginTime)
+		end, _ := ParseClock(bk.ClassEndTime)
+		inside := false
+		for _, w := range windows {
+			wb, _ := ParseClock(w.BeginTime)
+			we, _ := ParseClock(w.EndTime)
+			if w.Weekday == IsoWeekday(date.Weekday()) && wb <= begin && end <= we {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			result = append(result, bk)
+		}
+	}
+	return result, nil
+}
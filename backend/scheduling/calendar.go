@@ -0,0 +1,145 @@
+package scheduling
+
+import (
+	"backend/database"
+	"time"
+)
+
+// 工作日历条目类型
+const (
+	CalendarHoliday  = "holiday"  // 法定节假日
+	CalendarShutdown = "shutdown" // 公司停工
+	CalendarWorkday  = "workday"  // 照常工作（覆盖同一天的节假日或停工）
+)
+
+// 非工作日排课策略
+const (
+	CalendarWarn  = "warn"  // 允许排课，返回提醒
+	CalendarBlock = "block" // 禁止排课
+)
+
+// CalendarMark 某一天命中的工作日历条目
+type CalendarMark struct {
+	EntryID int64  `json:"entryId"`
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Site    string `json:"site"`
+	Policy  string `json:"policy"`
+}
+
+// CalendarDay 按地点解析后的一天：是否工作日，以及生效的日历条目
+type CalendarDay struct {
+	Date string        `json:"date"`
+	Mark *CalendarMark `json:"mark"` // 非工作日时生效的条目，工作日为 nil
+}
+
+// WorkingCalendar 日期范围内的工作日历条目（日期 -> 条目）
+type WorkingCalendar map[string][]CalendarMark
+
+// LoadCalendar 读取与日期范围有交集的工作日历条目，按天展开
+func LoadCalendar(startDate, endDate string) (WorkingCalendar, error) {
+	var entries []database.CalendarEntry
+	err := database.DB.
+		Where(database.DateUntil("start_date", endDate)).
+		Where(database.DateFrom("end_date", startDate)).
+		Order("start_date, entry_id").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	start, err1 := time.Parse("2006-01-02", startDate)
+	end, err2 := time.Parse("2006-01-02", endDate)
+	if err1 != nil || err2 != nil {
+		return WorkingCalendar{}, nil
+	}
+
+	cal := make(WorkingCalendar)
+	for _, e := range entries {
+		mark := CalendarMark{EntryID: e.EntryID, Kind: e.Kind, Name: e.Name, Site: e.Site, Policy: e.Policy}
+		from, to := e.StartDate, e.EndDate
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			date := d.Format("2006-01-02")
+			cal[date] = append(cal[date], mark)
+		}
+	}
+	return cal, nil
+}
+
+// Resolve 返回某地点（楼宇或船舶，空字符串表示不区分地点）在某天生效的非工作日条目，工作日返回 nil
+// 该地点有专属条目时只看专属条目，否则看全部地点通用的条目；其中有 workday 时为工作日，
+// 否则取策略最严格的条目（block 优先于 warn）
+func (cal WorkingCalendar) Resolve(date, site string) *CalendarMark {
+	var marks []CalendarMark
+	if site != "" {
+		for _, m := range cal[date] {
+			if m.Site == site {
+				marks = append(marks, m)
+			}
+		}
+	}
+	if len(marks) == 0 {
+		for _, m := range cal[date] {
+			if m.Site == "" {
+				marks = append(marks, m)
+			}
+		}
+	}
+
+	var result *CalendarMark
+	for i := range marks {
+		m := &marks[i]
+		if m.Kind == CalendarWorkday {
+			return nil
+		}
+		if result == nil || (m.Policy == CalendarBlock && result.Policy != CalendarBlock) {
+			result = m
+		}
+	}
+	return result
+}
+
+// Marks 某一天命中的全部条目（包括各楼宇或船舶的专属条目）
+func (cal WorkingCalendar) Marks(date string) []CalendarMark {
+	if marks, ok := cal[date]; ok {
+		return marks
+	}
+	return []CalendarMark{}
+}
+
+// Days 按地点解析日期范围内的每一天
+func (cal WorkingCalendar) Days(start, end time.Time, site string) []CalendarDay {
+	days := []CalendarDay{}
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		days = append(days, CalendarDay{Date: date, Mark: cal.Resolve(date, site)})
+	}
+	return days
+}
+
+// CheckWorkingDay 检查某地点在某天是否为工作日，返回生效的非工作日条目，工作日返回 nil
+func CheckWorkingDay(date, site string) (*CalendarMark, error) {
+	cal, err := LoadCalendar(date, date)
+	if err != nil {
+		return nil, err
+	}
+	return cal.Resolve(date, site), nil
+}
+
+// CalendarKindName 工作日历条目类型的中文名称
+func CalendarKindName(kind string) string {
+	switch kind {
+	case CalendarHoliday:
+		return "法定节假日"
+	case CalendarShutdown:
+		return "公司停工"
+	case CalendarWorkday:
+		return "照常工作"
+	}
+	return kind
+}
@@ -0,0 +1,118 @@
+package scheduling
+
+import (
+	"backend/database"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Slot 一次课程安排占用的时间段
+type Slot struct {
+	Date  string // YYYY-MM-DD
+	Begin string // HH:mm:ss
+	End   string // HH:mm:ss
+
+	Timezone string // 上课日期和时间所在的 IANA 时区
+}
+
+// Booking 已有的课程安排（冲突检查和占用日历中使用）
+type Booking struct {
+	ItemID         int64  `json:"itemId"`
+	PlanID         int64  `json:"planId"`
+	PlanName       string `json:"planName"`
+	CourseID       int64  `json:"courseId"`
+	CourseName     string `json:"courseName"`
+	TeacherName    string `json:"teacherName"`
+	ClassDate      string `json:"classDate"`
+	ClassBeginTime string `json:"classBeginTime"`
+	ClassEndTime   string `json:"classEndTime"`
+	LocationID     *int64 `json:"locationId"`
+	Location       string `json:"location"`
+	Timezone       string `json:"timezone"`
+}
+
+// bookingsColumns 与 Booking 对应的查询字段
+func bookingsColumns() string {
+	return "pci.item_id, pci.plan_id, tp.plan_name, pci.course_id, c.course_name, p.name AS teacher_name, " +
+		database.FormatDate("pci.class_date") + " AS class_date, pci.class_begin_time, pci.class_end_time, pci.location_id, pci.location, pci.timezone"
+}
+
+// bookingsQuery 课程安排查询（带计划、课程、讲师信息），字段与 Booking 对应
+func bookingsQuery() *gorm.DB {
+	return database.DB.Table("plan_course_item pci").
+		Select(bookingsColumns()).
+		Joins("JOIN training_plan tp ON pci.plan_id = tp.plan_id").
+		Joins("JOIN course c ON pci.course_id = c.course_id").
+		Joins("LEFT JOIN person p ON c.teacher_id = p.person_id")
+}
+
+// overlapping 限定可能与时间段重叠的课程安排，excludeItemID 为修改中的安排自身
+// 不同时区的上课日期可能相差一天，这里只按前后一天筛选，查询结果再用 Slot.Overlaps 按时刻判断
+func overlapping(query *gorm.DB, slot Slot, excludeItemID int64) *gorm.DB {
+	date, err := time.Parse("2006-01-02", slot.Date)
+	if err != nil {
+		return query.Where("1 = 0")
+	}
+	return query.
+		Where(database.DateBetween("pci.class_date",
+			date.AddDate(0, 0, -1).Format("2006-01-02"), date.AddDate(0, 0, 1).Format("2006-01-02"))).
+		Where("pci.item_id <> ?", excludeItemID)
+}
+
+// Overlaps 判断课程安排与时间段是否重叠（按时刻比较，首尾相接不算重叠）
+func (s Slot) Overlaps(b Booking) bool {
+	begin, end := s.Interval()
+	other := Slot{Date: b.ClassDate, Begin: b.ClassBeginTime, End: b.ClassEndTime, Timezone: b.Timezone}
+	otherBegin, otherEnd := other.Interval()
+	return otherBegin.Before(end) && otherEnd.After(begin)
+}
+
+// overlappingBookings 从候选课程安排中筛选与时间段重叠的
+func overlappingBookings(slot Slot, candidates []Booking) []Booking {
+	bookings := []Booking{}
+	for _, b := range candidates {
+		if slot.Overlaps(b) {
+			bookings = append(bookings, b)
+		}
+	}
+	return bookings
+}
+
+// RoomBookings 返回同一地点与时间段重叠的课程安排
+func RoomBookings(locationID int64, slot Slot, excludeItemID int64) ([]Booking, error) {
+	var candidates []Booking
+	err := overlapping(bookingsQuery(), slot, excludeItemID).
+		Where("pci.location_id = ?", locationID).
+		Order("pci.class_date, pci.class_begin_time").
+		Scan(&candidates).Error
+	return overlappingBookings(slot, candidates), err
+}
+
+// TeacherBookings 返回同一讲师与时间段重叠的课程安排
+func TeacherBookings(teacherID int64, slot Slot, excludeItemID int64) ([]Booking, error) {
+	var candidates []Booking
+	err := overlapping(bookingsQuery(), slot, excludeItemID).
+		Where("c.teacher_id = ?", teacherID).
+		Order("pci.class_date, pci.class_begin_time").
+		Scan(&candidates).Error
+	return overlappingBookings(slot, candidates), err
+}
+
+// LocationBookings 返回地点在日期范围内的全部课程安排（按日期、开始时间排序）
+func LocationBookings(locationIDs []int64, startDate, endDate string) ([]Booking, error) {
+	var bookings []Booking
+	err := bookingsQuery().
+		Where("pci.location_id IN ?", locationIDs).
+		Where(database.DateBetween("pci.class_date", startDate, endDate)).
+		Order("pci.class_date, pci.class_begin_time").
+		Scan(&bookings).Error
+	return bookings, err
+}
+
+// RosterSize 返回培训计划的参训员工数
+func RosterSize(planID int64) (int64, error) {
+	var count int64
+	err := database.DB.Model(&database.PlanEmployee{}).Where("plan_id = ?", planID).Count(&count).Error
+	return count, err
+}
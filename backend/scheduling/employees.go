@@ -0,0 +1,150 @@
+package scheduling
+
+import (
+	"backend/database"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// Overlap 两个课程安排重叠的时间段
+type Overlap struct {
+	Date  string `json:"date"`
+	Begin string `json:"begin"`
+	End   string `json:"end"`
+}
+
+// EmployeeConflict 员工在同一时间段被安排了两门课
+type EmployeeConflict struct {
+	PersonID     int64   `json:"personId"`
+	PersonName   string  `json:"personName"`
+	Item         Booking `json:"item"`         // 正在保存的课程安排
+	ConflictWith Booking `json:"conflictWith"` // 员工已有的课程安排
+	Overlap      Overlap `json:"overlap"`
+}
+
+// maxWarningLength 课程安排上保存的冲突警告最大长度（字符）
+const maxWarningLength = 1000
+
+// employeeBooking 员工参加的课程安排
+type employeeBooking struct {
+	Booking
+	PersonID   int64
+	PersonName string
+}
+
+// employeeBookingsOf 在 query 限定的课程安排中查询 personIDs 中的员工参加的（按员工、日期、开始时间排序）
+// personIDs 可以是 ID 列表或子查询
+func employeeBookingsOf(query *gorm.DB, personIDs interface{}) *gorm.DB {
+	return query.
+		Select(bookingsColumns()+", pe.person_id, e.name AS person_name").
+		Joins("JOIN plan_employee pe ON pe.plan_id = pci.plan_id").
+		Joins("JOIN person e ON pe.person_id = e.person_id").
+		Where("pe.person_id IN (?)", personIDs).
+		Order("pe.person_id, pci.class_date, pci.class_begin_time")
+}
+
+// employeeBookings 与 item 时间段重叠、且 personIDs 中的员工参加的课程安排
+// personIDs 可以是 ID 列表或子查询
+func employeeBookings(item Booking, personIDs interface{}) ([]EmployeeConflict, error) {
+	slot := Slot{Date: item.ClassDate, Begin: item.ClassBeginTime, End: item.ClassEndTime, Timezone: item.Timezone}
+	var rows []employeeBooking
+	if err := employeeBookingsOf(overlapping(bookingsQuery(), slot, item.ItemID), personIDs).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return employeeConflicts(item, rows), nil
+}
+
+// employeeConflicts 从员工参加的课程安排中筛选与 item 时间段重叠的（不含 item 自身）
+func employeeConflicts(item Booking, rows []employeeBooking) []EmployeeConflict {
+	slot := Slot{Date: item.ClassDate, Begin: item.ClassBeginTime, End: item.ClassEndTime, Timezone: item.Timezone}
+	conflicts := make([]EmployeeConflict, 0)
+	for _, row := range rows {
+		if row.ItemID == item.ItemID || !slot.Overlaps(row.Booking) {
+			continue
+		}
+		conflicts = append(conflicts, EmployeeConflict{
+			PersonID:     row.PersonID,
+			PersonName:   row.PersonName,
+			Item:         item,
+			ConflictWith: row.Booking,
+			Overlap:      overlapOf(item, row.Booking),
+		})
+	}
+	return conflicts
+}
+
+// overlapOf 两个重叠的课程安排的重叠时间段，按 item 所在时区表示
+func overlapOf(item, other Booking) Overlap {
+	begin, end := Slot{Date: item.ClassDate, Begin: item.ClassBeginTime, End: item.ClassEndTime, Timezone: item.Timezone}.Interval()
+	otherBegin, otherEnd := Slot{Date: other.ClassDate, Begin: other.ClassBeginTime, End: other.ClassEndTime, Timezone: other.Timezone}.Interval()
+	if otherBegin.After(begin) {
+		begin = otherBegin
+	}
+	if otherEnd.Before(end) {
+		end = otherEnd
+	}
+	zone := Timezone(item.Timezone)
+	return Overlap{
+		Date:  begin.In(zone).Format("2006-01-02"),
+		Begin: begin.In(zone).Format("15:04:05"),
+		End:   end.In(zone).Format("15:04:05"),
+	}
+}
+
+// EmployeeConflicts 检查计划的参训员工在 item 的时间段是否已有其他课程
+// item.ItemID 为 0 表示新建的课程安排
+func EmployeeConflicts(item Booking) ([]EmployeeConflict, error) {
+	roster := database.DB.Model(&database.PlanEmployee{}).Select("person_id").Where("plan_id = ?", item.PlanID)
+	return employeeBookings(item, roster)
+}
+
+// RosterConflicts 检查 personIDs 中的员工在 item 的时间段是否已有其他课程
+// 用于计划的名单尚未保存时（如按模板创建计划前的检查）
+func RosterConflicts(item Booking, personIDs []int64) ([]EmployeeConflict, error) {
+	if len(personIDs) == 0 {
+		return []EmployeeConflict{}, nil
+	}
+	return employeeBookings(item, personIDs)
+}
+
+// EnrollmentConflicts 检查员工加入计划后，计划中 fromDate 及以后的课程与员工已有课程是否冲突
+func EnrollmentConflicts(planID int64, personIDs []int64, fromDate string) ([]EmployeeConflict, error) {
+	conflicts := make([]EmployeeConflict, 0)
+	if len(personIDs) == 0 {
+		return conflicts, nil
+	}
+	var items []Booking
+	err := bookingsQuery().
+		Where("pci.plan_id = ?", planID).
+		Where(database.DateFrom("pci.class_date", fromDate)).
+		Order("pci.class_date, pci.class_begin_time").
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		found, err := employeeBookings(item, personIDs)
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, found...)
+	}
+	return conflicts, nil
+}
+
+// FormatWarning 将冲突列表整理为保存在课程安排上的警告文字，过长时截断
+func FormatWarning(conflicts []EmployeeConflict) string {
+	parts := make([]string, 0, len(conflicts))
+	for _, cf := range conflicts {
+		parts = append(parts, cf.PersonName+"与「"+cf.ConflictWith.PlanName+"/"+cf.ConflictWith.CourseName+"」"+
+			cf.Overlap.Date+" "+cf.Overlap.Begin+"-"+cf.Overlap.End+"时间重叠")
+	}
+	warning := strings.Join(parts, "；")
+	if utf8.RuneCountInString(warning) > maxWarningLength {
+		warning = string([]rune(warning)[:maxWarningLength-1]) + "…"
+	}
+	return warning
+}
@@ -0,0 +1,115 @@
+package scheduling
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ICalEvent iCal 文件中的一个全天或跨天事件（结束日期为包含的最后一天）
+type ICalEvent struct {
+	UID       string
+	Summary   string
+	StartDate time.Time
+	EndDate   time.Time
+	Recurring bool // 带 RRULE 的重复事件
+}
+
+// unfoldICal 拆分 iCal 内容行并合并折行（以空格或制表符开头的行接在上一行后面）
+func unfoldICal(data string) []string {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// unescapeICalText 还原 TEXT 类型值中的转义字符
+func unescapeICalText(value string) string {
+	return strings.NewReplacer(`\\`, `\`, `\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ").Replace(value)
+}
+
+// parseICalDate 解析 DATE 或 DATE-TIME 值，返回日期以及作为结束时间时是否不包含当天
+func parseICalDate(value string) (time.Time, bool, error) {
+	if len(value) < 8 {
+		return time.Time{}, false, errors.New("日期格式错误：" + value)
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, false, errors.New("日期格式错误：" + value)
+	}
+	// 只有日期或时间为零点时，作为结束时间不包含当天
+	return date, len(value) == 8 || strings.HasPrefix(value[8:], "T000000"), nil
+}
+
+// ParseICalEvents 解析 iCal（RFC 5545）文件中的 VEVENT，只取日期部分
+// DTEND 为不包含的结束日期（全天事件）；没有 DTEND 时视为单日事件
+func ParseICalEvents(data string) ([]ICalEvent, error) {
+	lines := unfoldICal(data)
+	if len(lines) == 0 || !strings.EqualFold(strings.TrimSpace(lines[0]), "BEGIN:VCALENDAR") {
+		return nil, errors.New("不是有效的 iCal 文件")
+	}
+
+	var events []ICalEvent
+	var current *ICalEvent
+	var hasEnd, endExclusive bool
+	for _, line := range lines {
+		sep := strings.Index(line, ":")
+		if sep < 0 {
+			continue
+		}
+		nameParams, value := line[:sep], strings.TrimSpace(line[sep+1:])
+		name := strings.ToUpper(strings.SplitN(nameParams, ";", 2)[0])
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			current = &ICalEvent{}
+			hasEnd, endExclusive = false, false
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if current == nil {
+				continue
+			}
+			if current.StartDate.IsZero() {
+				return nil, errors.New("事件缺少 DTSTART：" + current.Summary)
+			}
+			if !hasEnd {
+				current.EndDate = current.StartDate
+			} else if endExclusive && current.EndDate.After(current.StartDate) {
+				current.EndDate = current.EndDate.AddDate(0, 0, -1)
+			}
+			if current.EndDate.Before(current.StartDate) {
+				current.EndDate = current.StartDate
+			}
+			events = append(events, *current)
+			current = nil
+		case current == nil:
+			continue
+		case name == "UID":
+			current.UID = value
+		case name == "SUMMARY":
+			current.Summary = unescapeICalText(value)
+		case name == "RRULE":
+			current.Recurring = true
+		case name == "DTSTART":
+			date, _, err := parseICalDate(value)
+			if err != nil {
+				return nil, err
+			}
+			current.StartDate = date
+		case name == "DTEND":
+			date, exclusive, err := parseICalDate(value)
+			if err != nil {
+				return nil, err
+			}
+			current.EndDate, hasEnd, endExclusive = date, true, exclusive
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,117 @@
+package scheduling
+
+import (
+	"backend/database"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// icalLineLimit iCal 内容行的最大长度（字节，不含换行）
+const icalLineLimit = 75
+
+// ICalFeedEvent 日历订阅中输出的一次上课
+type ICalFeedEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time // 输出时统一换算为 UTC
+	End         time.Time
+	Sequence    int
+	Cancelled   bool
+}
+
+// escapeICalText 转义 TEXT 类型值中的特殊字符
+func escapeICalText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+// foldICalLine 按 RFC 5545 把超过 75 字节的内容行折行，不拆开多字节字符
+func foldICalLine(line string) string {
+	if len(line) <= icalLineLimit {
+		return line + "\r\n"
+	}
+	var b strings.Builder
+	limit := icalLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icalLineLimit - 1 // 续行开头的空格占一个字节
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// utf8RuneStart 判断字节是否为 UTF-8 字符的第一个字节
+func utf8RuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// WriteICalFeed 生成日历订阅的 iCal 文件内容，stamp 为生成时间
+func WriteICalFeed(name string, events []ICalFeedEvent, stamp time.Time) string {
+	var b strings.Builder
+	write := func(line string) {
+		b.WriteString(foldICalLine(line))
+	}
+	write("BEGIN:VCALENDAR")
+	write("VERSION:2.0")
+	write("PRODID:-//training-system//course schedule//ZH")
+	write("CALSCALE:GREGORIAN")
+	write("METHOD:PUBLISH")
+	write("X-WR-CALNAME:" + escapeICalText(name))
+	for _, ev := range events {
+		write("BEGIN:VEVENT")
+		write("UID:" + ev.UID)
+		write("DTSTAMP:" + stamp.UTC().Format("20060102T150405Z"))
+		write("DTSTART:" + ev.Start.UTC().Format("20060102T150405Z"))
+		write("DTEND:" + ev.End.UTC().Format("20060102T150405Z"))
+		write("SEQUENCE:" + strconv.Itoa(ev.Sequence))
+		write("SUMMARY:" + escapeICalText(ev.Summary))
+		if ev.Location != "" {
+			write("LOCATION:" + escapeICalText(ev.Location))
+		}
+		if ev.Description != "" {
+			write("DESCRIPTION:" + escapeICalText(ev.Description))
+		}
+		if ev.Cancelled {
+			write("STATUS:CANCELLED")
+		} else {
+			write("STATUS:CONFIRMED")
+		}
+		write("END:VEVENT")
+	}
+	write("END:VCALENDAR")
+	return b.String()
+}
+
+// RecordCancellations 删除课程安排前保存快照，日历订阅据此通知订阅者课程已取消
+func RecordCancellations(tx *gorm.DB, items []database.PlanCourseItem) error {
+	now := time.Now()
+	for _, item := range items {
+		cancellation := database.CourseItemCancellation{
+			ItemID:         item.ItemID,
+			PlanID:         item.PlanID,
+			CourseID:       item.CourseID,
+			ClassDate:      item.ClassDate,
+			ClassBeginTime: item.ClassBeginTime,
+			ClassEndTime:   item.ClassEndTime,
+			Location:       item.Location,
+			Timezone:       item.Timezone,
+			Sequence:       item.Sequence + 1,
+			CancelledAt:    now,
+		}
+		if err := tx.Save(&cancellation).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
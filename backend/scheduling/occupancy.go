@@ -0,0 +1,141 @@
+package scheduling
+
+import (
+	"backend/database"
+	"time"
+)
+
+// Occupancy 日期范围内讲师、地点和计划参训员工的已有课程安排，以及讲师的可授课时间、不可授课时间和工作日历
+// 一次读取后在内存中逐次检查多次上课（如重复课程展开的各次），不再每次上课查询一遍数据库；
+// 检查的上课日期应在读取的日期范围内
+type Occupancy struct {
+	teachers  map[int64][]Booking
+	rooms     map[int64][]Booking
+	employees []employeeBooking
+	windows   map[int64][]database.TeacherAvailability
+	blackouts map[int64][]database.TeacherBlackout
+	calendar  WorkingCalendar
+	roster    int64
+}
+
+// LoadOccupancy 读取 startDate 到 endDate（YYYY-MM-DD）之间 teacherIDs 中的讲师、locationIDs 中的地点
+// 和计划 planID 的参训员工（新建计划时为 0，不检查员工）的占用情况
+func LoadOccupancy(planID int64, teacherIDs, locationIDs []int64, startDate, endDate string) (*Occupancy, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, err
+	}
+	// 与 overlapping 相同，不同时区的上课日期可能相差一天，多读前后一天
+	from, to := start.AddDate(0, 0, -1).Format("2006-01-02"), end.AddDate(0, 0, 1).Format("2006-01-02")
+	occ := &Occupancy{
+		teachers:  make(map[int64][]Booking),
+		rooms:     make(map[int64][]Booking),
+		windows:   make(map[int64][]database.TeacherAvailability),
+		blackouts: make(map[int64][]database.TeacherBlackout),
+	}
+
+	if len(teacherIDs) > 0 {
+		var rows []struct {
+			Booking
+			TeacherID int64
+		}
+		err := bookingsQuery().
+			Select(bookingsColumns()+", c.teacher_id").
+			Where(database.DateBetween("pci.class_date", from, to)).
+			Where("c.teacher_id IN ?", teacherIDs).
+			Order("pci.class_date, pci.class_begin_time").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			occ.teachers[row.TeacherID] = append(occ.teachers[row.TeacherID], row.Booking)
+		}
+
+		var windows []database.TeacherAvailability
+		if err := database.DB.Where("teacher_id IN ?", teacherIDs).Order("weekday, begin_time").Find(&windows).Error; err != nil {
+			return nil, err
+		}
+		for _, w := range windows {
+			occ.windows[w.TeacherID] = append(occ.windows[w.TeacherID], w)
+		}
+		blackouts, err := blackoutsBetween(teacherIDs, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range blackouts {
+			occ.blackouts[b.TeacherID] = append(occ.blackouts[b.TeacherID], b)
+		}
+	}
+
+	if len(locationIDs) > 0 {
+		bookings, err := LocationBookings(locationIDs, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range bookings {
+			occ.rooms[*b.LocationID] = append(occ.rooms[*b.LocationID], b)
+		}
+	}
+
+	if planID != 0 {
+		roster := database.DB.Model(&database.PlanEmployee{}).Select("person_id").Where("plan_id = ?", planID)
+		query := bookingsQuery().Where(database.DateBetween("pci.class_date", from, to))
+		if err := employeeBookingsOf(query, roster).Scan(&occ.employees).Error; err != nil {
+			return nil, err
+		}
+		if occ.roster, err = RosterSize(planID); err != nil {
+			return nil, err
+		}
+	}
+
+	if occ.calendar, err = LoadCalendar(startDate, endDate); err != nil {
+		return nil, err
+	}
+	return occ, nil
+}
+
+// overlappingExcept 从候选课程安排中筛选与时间段重叠的，excludeItemID 为修改中的安排自身
+func overlappingExcept(slot Slot, candidates []Booking, excludeItemID int64) []Booking {
+	bookings := []Booking{}
+	for _, b := range candidates {
+		if b.ItemID != excludeItemID && slot.Overlaps(b) {
+			bookings = append(bookings, b)
+		}
+	}
+	return bookings
+}
+
+// TeacherBookings 与 TeacherBookings 函数相同，返回同一讲师与时间段重叠的课程安排
+func (o *Occupancy) TeacherBookings(teacherID int64, slot Slot, excludeItemID int64) []Booking {
+	return overlappingExcept(slot, o.teachers[teacherID], excludeItemID)
+}
+
+// RoomBookings 与 RoomBookings 函数相同，返回同一地点与时间段重叠的课程安排
+func (o *Occupancy) RoomBookings(locationID int64, slot Slot, excludeItemID int64) []Booking {
+	return overlappingExcept(slot, o.rooms[locationID], excludeItemID)
+}
+
+// TeacherUnavailability 与 TeacherUnavailability 函数相同，检查讲师在该时间段能否授课
+func (o *Occupancy) TeacherUnavailability(teacherID int64, slot Slot) (*Unavailability, error) {
+	return unavailability(o.windows[teacherID], o.blackouts[teacherID], slot)
+}
+
+// WorkingDay 与 CheckWorkingDay 相同，返回某地点在某天生效的非工作日条目，工作日返回 nil
+func (o *Occupancy) WorkingDay(date, site string) *CalendarMark {
+	return o.calendar.Resolve(date, site)
+}
+
+// EmployeeConflicts 与 EmployeeConflicts 函数相同，检查计划的参训员工在 item 的时间段是否已有其他课程
+func (o *Occupancy) EmployeeConflicts(item Booking) []EmployeeConflict {
+	return employeeConflicts(item, o.employees)
+}
+
+// RosterSize 计划的参训员工数
+func (o *Occupancy) RosterSize() int64 {
+	return o.roster
+}
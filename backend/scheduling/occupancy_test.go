@@ -0,0 +1,116 @@
+package scheduling
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"reflect"
+	"testing"
+)
+
+// TestOccupancyMatchesQueries 一次读取的占用情况与逐次查询数据库的检查结果相同
+func TestOccupancyMatchesQueries(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", "2030-03-04", "2030-03-08", dbtest.EmployeeID)
+	other := dbtest.CreatePlan(t, "消防演练", "2030-03-04", "2030-03-08", dbtest.EmployeeID)
+	fire := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	aid := dbtest.CreateCourse(t, "急救", "安全", dbtest.Teacher2ID)
+	room := createRoom(t, "测试教室1", 30)
+
+	// 讲师周二 09:00-10:00 有课，东京时间周六零点的课是北京时间周五 23:00；地点周三被另一位讲师占用，员工周五参加另一个计划
+	for _, b := range []struct {
+		plan     database.TrainingPlan
+		course   database.Course
+		date     string
+		begin    string
+		end      string
+		timezone string
+		withRoom bool
+	}{
+		{plan, fire, "2030-03-05", "09:00:00", "10:00:00", "Asia/Shanghai", false},
+		{plan, fire, "2030-03-09", "00:00:00", "01:00:00", "Asia/Tokyo", false},
+		{other, aid, "2030-03-06", "09:00:00", "11:00:00", "Asia/Shanghai", true},
+		{other, aid, "2030-03-08", "09:30:00", "10:30:00", "Asia/Shanghai", false},
+	} {
+		item := dbtest.CreateItem(t, b.plan, b.course, b.date, b.begin, b.end, b.timezone)
+		if b.withRoom {
+			database.DB.Model(&item).Update("location_id", room.LocationID)
+		}
+	}
+	database.DB.Create(&database.TeacherAvailability{TeacherID: dbtest.TeacherID, Weekday: 1, BeginTime: "08:00:00", EndTime: "12:00:00"})
+	database.DB.Create(&database.TeacherAvailability{TeacherID: dbtest.TeacherID, Weekday: 3, BeginTime: "08:00:00", EndTime: "12:00:00"})
+	database.DB.Create(&database.TeacherAvailability{TeacherID: dbtest.TeacherID, Weekday: 5, BeginTime: "08:00:00", EndTime: "12:00:00"})
+	database.DB.Create(&database.TeacherBlackout{TeacherID: dbtest.TeacherID, StartDate: date("2030-03-06"), BeginTime: "10:00:00", EndDate: date("2030-03-06"), EndTime: "23:59:59"})
+	database.DB.Create(&database.CalendarEntry{Kind: CalendarShutdown, Name: "年度检修", StartDate: date("2030-03-04"), EndDate: date("2030-03-04"), Policy: CalendarBlock})
+
+	occ, err := LoadOccupancy(plan.PlanID, []int64{dbtest.TeacherID}, []int64{room.LocationID}, "2030-03-04", "2030-03-08")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if occ.RosterSize() != 1 {
+		t.Errorf("参训人数应为 1，实际 %d", occ.RosterSize())
+	}
+
+	// 各项检查都应有命中的时间段，避免两边都为空时比较失去意义
+	found := map[string]bool{}
+	for _, day := range []string{"2030-03-04", "2030-03-05", "2030-03-06", "2030-03-07", "2030-03-08"} {
+		for _, clock := range [][2]string{{"08:00:00", "09:00:00"}, {"09:00:00", "11:00:00"}, {"10:30:00", "11:30:00"}, {"22:30:00", "23:30:00"}} {
+			slot := Slot{Date: day, Begin: clock[0], End: clock[1], Timezone: "Asia/Shanghai"}
+			booking := Booking{PlanID: plan.PlanID, ClassDate: day, ClassBeginTime: clock[0], ClassEndTime: clock[1], Timezone: "Asia/Shanghai"}
+
+			teacher, err := TeacherBookings(dbtest.TeacherID, slot, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := occ.TeacherBookings(dbtest.TeacherID, slot, 0); !reflect.DeepEqual(got, teacher) {
+				t.Errorf("%v 讲师占用为 %+v，逐次查询为 %+v", slot, got, teacher)
+			}
+			rooms, err := RoomBookings(room.LocationID, slot, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := occ.RoomBookings(room.LocationID, slot, 0); !reflect.DeepEqual(got, rooms) {
+				t.Errorf("%v 地点占用为 %+v，逐次查询为 %+v", slot, got, rooms)
+			}
+			unavailable, err := TeacherUnavailability(dbtest.TeacherID, slot)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := occ.TeacherUnavailability(dbtest.TeacherID, slot); !reflect.DeepEqual(got, unavailable) {
+				t.Errorf("%v 讲师不可授课为 %+v，逐次查询为 %+v", slot, got, unavailable)
+			}
+			mark, err := CheckWorkingDay(day, room.Building)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := occ.WorkingDay(day, room.Building); !reflect.DeepEqual(got, mark) {
+				t.Errorf("%s 工作日历为 %+v，逐次查询为 %+v", day, got, mark)
+			}
+			conflicts, err := EmployeeConflicts(booking)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := occ.EmployeeConflicts(booking); !reflect.DeepEqual(got, conflicts) {
+				t.Errorf("%v 员工冲突为 %+v，逐次查询为 %+v", slot, got, conflicts)
+			}
+			found["teacher"] = found["teacher"] || len(teacher) > 0
+			found["room"] = found["room"] || len(rooms) > 0
+			found["unavailable"] = found["unavailable"] || unavailable != nil
+			found["calendar"] = found["calendar"] || mark != nil
+			found["employee"] = found["employee"] || len(conflicts) > 0
+		}
+	}
+	for check, ok := range found {
+		if !ok {
+			t.Errorf("%s 检查没有命中的时间段", check)
+		}
+	}
+
+	// 检查已有的课程安排自身时不算冲突
+	var own database.PlanCourseItem
+	database.DB.Where("plan_id = ? AND course_id = ?", plan.PlanID, fire.CourseID).Order("class_date").First(&own)
+	slot := Slot{Date: "2030-03-05", Begin: "09:00:00", End: "10:00:00", Timezone: "Asia/Shanghai"}
+	if got := occ.TeacherBookings(dbtest.TeacherID, slot, own.ItemID); len(got) != 0 {
+		t.Errorf("不应与自身冲突，实际 %+v", got)
+	}
+}
@@ -0,0 +1,302 @@
+package scheduling
+
+import (
+	"backend/database"
+	"time"
+)
+
+// Move 一条课程安排的改期：改期前的安排和改期后的日期、时间（时区不变）
+type Move struct {
+	Item           Booking `json:"item"` // 改期前
+	TeacherID      int64   `json:"teacherId"`
+	ClassDate      string  `json:"classDate"` // 改期后
+	ClassBeginTime string  `json:"classBeginTime"`
+	ClassEndTime   string  `json:"classEndTime"`
+	Site           string  `json:"-"` // 上课地点所在楼宇或船舶，检查工作日历时使用
+}
+
+// Slot 改期后的时间段
+func (m Move) Slot() Slot {
+	return Slot{Date: m.ClassDate, Begin: m.ClassBeginTime, End: m.ClassEndTime, Timezone: m.Item.Timezone}
+}
+
+// Booking 改期后的课程安排
+func (m Move) Booking() Booking {
+	b := m.Item
+	b.ClassDate = m.ClassDate
+	b.ClassBeginTime = m.ClassBeginTime
+	b.ClassEndTime = m.ClassEndTime
+	return b
+}
+
+// AffectedPerson 改期影响到的员工或讲师
+type AffectedPerson struct {
+	PersonID  int64  `json:"personId"`
+	Name      string `json:"name"`
+	ItemCount int    `json:"itemCount"` // 受影响的课程安排数
+}
+
+// MoveConflict 改期后与其他课程安排的讲师或地点冲突
+type MoveConflict struct {
+	ItemID    int64     `json:"itemId"`
+	ClassDate string    `json:"classDate"` // 改期后的日期
+	Conflicts []Booking `json:"conflicts"`
+}
+
+// MoveUnavailability 改期后讲师不能授课
+type MoveUnavailability struct {
+	ItemID         int64           `json:"itemId"`
+	ClassDate      string          `json:"classDate"`
+	TeacherID      int64           `json:"teacherId"`
+	TeacherName    string          `json:"teacherName"`
+	Unavailability *Unavailability `json:"unavailability"`
+}
+
+// MoveCalendar 改期后的日期按工作日历不是工作日
+type MoveCalendar struct {
+	ItemID    int64         `json:"itemId"`
+	ClassDate string        `json:"classDate"`
+	Calendar  *CalendarMark `json:"calendar"`
+}
+
+// InvalidEvaluation 改期后将失效的员工自评：自评已提交，但改期后课程尚未结束
+type InvalidEvaluation struct {
+	ItemID     int64  `json:"itemId"`
+	PersonID   int64  `json:"personId"`
+	PersonName string `json:"personName"`
+}
+
+// RescheduleImpact 改期影响报告
+type RescheduleImpact struct {
+	Moves              []Move               `json:"moves"`
+	Employees          []AffectedPerson     `json:"employees"`
+	Teachers           []AffectedPerson     `json:"teachers"`
+	TeacherConflicts   []MoveConflict       `json:"teacherConflicts"`
+	TeacherUnavailable []MoveUnavailability `json:"teacherUnavailable"`
+	RoomConflicts      []MoveConflict       `json:"roomConflicts"`
+	NonWorkingDays     []MoveCalendar       `json:"nonWorkingDays"`   // 策略为 block 的非工作日
+	CalendarWarnings   []MoveCalendar       `json:"calendarWarnings"` // 策略为 warn 的非工作日，不影响改期
+	EmployeeConflicts  []EmployeeConflict   `json:"employeeConflicts"`
+	InvalidEvaluations []InvalidEvaluation  `json:"invalidEvaluations"`
+}
+
+// Blocked 是否存在不能强制忽略的冲突：讲师或地点冲突、讲师不可授课、禁止排课的非工作日
+func (r RescheduleImpact) Blocked() bool {
+	return len(r.TeacherConflicts) > 0 || len(r.TeacherUnavailable) > 0 ||
+		len(r.RoomConflicts) > 0 || len(r.NonWorkingDays) > 0
+}
+
+// NeedsConfirmation 是否存在需要确认（force）后才能改期的影响：员工时间冲突、自评失效
+func (r RescheduleImpact) NeedsConfirmation() bool {
+	return len(r.EmployeeConflicts) > 0 || len(r.InvalidEvaluations) > 0
+}
+
+// withoutMoved 去掉同样在改期中的课程安排（它们按改期后的时间另行比较）
+func withoutMoved(bookings []Booking, moved map[int64]bool) []Booking {
+	kept := []Booking{}
+	for _, b := range bookings {
+		if !moved[b.ItemID] {
+			kept = append(kept, b)
+		}
+	}
+	return kept
+}
+
+// AnalyzeReschedule 分析一批课程安排改期的影响，不修改数据
+// 改期中的课程安排之间按改期后的时间比较，与其他课程安排按数据库中的时间比较
+func AnalyzeReschedule(moves []Move, now time.Time) (RescheduleImpact, error) {
+	impact := RescheduleImpact{
+		Moves:              moves,
+		Employees:          []AffectedPerson{},
+		Teachers:           []AffectedPerson{},
+		TeacherConflicts:   []MoveConflict{},
+		TeacherUnavailable: []MoveUnavailability{},
+		RoomConflicts:      []MoveConflict{},
+		NonWorkingDays:     []MoveCalendar{},
+		CalendarWarnings:   []MoveCalendar{},
+		EmployeeConflicts:  []EmployeeConflict{},
+		InvalidEvaluations: []InvalidEvaluation{},
+	}
+	if len(moves) == 0 {
+		return impact, nil
+	}
+
+	moved := make(map[int64]bool, len(moves))
+	itemIDs := make([]int64, 0, len(moves))
+	for _, m := range moves {
+		moved[m.Item.ItemID] = true
+		itemIDs = append(itemIDs, m.Item.ItemID)
+	}
+
+	teacherIndex := make(map[int64]int)
+	for i, m := range moves {
+		slot := m.Slot()
+
+		if idx, ok := teacherIndex[m.TeacherID]; ok {
+			impact.Teachers[idx].ItemCount++
+		} else {
+			teacherIndex[m.TeacherID] = len(impact.Teachers)
+			impact.Teachers = append(impact.Teachers, AffectedPerson{PersonID: m.TeacherID, Name: m.Item.TeacherName, ItemCount: 1})
+		}
+
+		// 讲师时间冲突：其他课程安排，以及改期中的同一讲师的其他课程安排
+		teacherBookings, err := TeacherBookings(m.TeacherID, slot, m.Item.ItemID)
+		if err != nil {
+			return impact, err
+		}
+		teacherBookings = withoutMoved(teacherBookings, moved)
+		for j, other := range moves {
+			if j != i && other.TeacherID == m.TeacherID && slot.Overlaps(other.Booking()) {
+				teacherBookings = append(teacherBookings, other.Booking())
+			}
+		}
+		if len(teacherBookings) > 0 {
+			impact.TeacherConflicts = append(impact.TeacherConflicts, MoveConflict{ItemID: m.Item.ItemID, ClassDate: m.ClassDate, Conflicts: teacherBookings})
+		}
+
+		unavailable, err := TeacherUnavailability(m.TeacherID, slot)
+		if err != nil {
+			return impact, err
+		}
+		if unavailable != nil {
+			impact.TeacherUnavailable = append(impact.TeacherUnavailable, MoveUnavailability{
+				ItemID:         m.Item.ItemID,
+				ClassDate:      m.ClassDate,
+				TeacherID:      m.TeacherID,
+				TeacherName:    m.Item.TeacherName,
+				Unavailability: unavailable,
+			})
+		}
+
+		// 地点占用
+		if m.Item.LocationID != nil {
+			roomBookings, err := RoomBookings(*m.Item.LocationID, slot, m.Item.ItemID)
+			if err != nil {
+				return impact, err
+			}
+			roomBookings = withoutMoved(roomBookings, moved)
+			for j, other := range moves {
+				if j != i && other.Item.LocationID != nil && *other.Item.LocationID == *m.Item.LocationID && slot.Overlaps(other.Booking()) {
+					roomBookings = append(roomBookings, other.Booking())
+				}
+			}
+			if len(roomBookings) > 0 {
+				impact.RoomConflicts = append(impact.RoomConflicts, MoveConflict{ItemID: m.Item.ItemID, ClassDate: m.ClassDate, Conflicts: roomBookings})
+			}
+		}
+
+		// 工作日历
+		mark, err := CheckWorkingDay(m.ClassDate, m.Site)
+		if err != nil {
+			return impact, err
+		}
+		if mark != nil {
+			entry := MoveCalendar{ItemID: m.Item.ItemID, ClassDate: m.ClassDate, Calendar: mark}
+			if mark.Policy == CalendarBlock {
+				impact.NonWorkingDays = append(impact.NonWorkingDays, entry)
+			} else {
+				impact.CalendarWarnings = append(impact.CalendarWarnings, entry)
+			}
+		}
+
+		// 参训员工与其他课程安排的时间冲突（改期中的课程安排之间在查询名单后比较）
+		conflicts, err := EmployeeConflicts(m.Booking())
+		if err != nil {
+			return impact, err
+		}
+		for _, cf := range conflicts {
+			if !moved[cf.ConflictWith.ItemID] {
+				impact.EmployeeConflicts = append(impact.EmployeeConflicts, cf)
+			}
+		}
+	}
+
+	// 受影响的参训员工
+	planItems := make(map[int64]int)
+	planIDs := []int64{}
+	for _, m := range moves {
+		if _, ok := planItems[m.Item.PlanID]; !ok {
+			planIDs = append(planIDs, m.Item.PlanID)
+		}
+		planItems[m.Item.PlanID]++
+	}
+	var roster []struct {
+		PlanID   int64
+		PersonID int64
+		Name     string
+	}
+	err := database.DB.Table("plan_employee pe").
+		Select("pe.plan_id, pe.person_id, p.name").
+		Joins("JOIN person p ON pe.person_id = p.person_id").
+		Where("pe.plan_id IN ?", planIDs).
+		Order("pe.person_id").
+		Scan(&roster).Error
+	if err != nil {
+		return impact, err
+	}
+	employeeIndex := make(map[int64]int)
+	enrolled := make(map[int64]map[int64]bool, len(planIDs))
+	for _, r := range roster {
+		if enrolled[r.PlanID] == nil {
+			enrolled[r.PlanID] = make(map[int64]bool)
+		}
+		enrolled[r.PlanID][r.PersonID] = true
+		if idx, ok := employeeIndex[r.PersonID]; ok {
+			impact.Employees[idx].ItemCount += planItems[r.PlanID]
+			continue
+		}
+		employeeIndex[r.PersonID] = len(impact.Employees)
+		impact.Employees = append(impact.Employees, AffectedPerson{PersonID: r.PersonID, Name: r.Name, ItemCount: planItems[r.PlanID]})
+	}
+
+	// 参训员工时间冲突：改期中的课程安排之间，同一员工参加的两个课程安排改期后重叠
+	for i, m := range moves {
+		for j, other := range moves {
+			if j == i || !m.Slot().Overlaps(other.Booking()) {
+				continue
+			}
+			for _, r := range roster {
+				if r.PlanID == m.Item.PlanID && enrolled[other.Item.PlanID][r.PersonID] {
+					impact.EmployeeConflicts = append(impact.EmployeeConflicts, EmployeeConflict{
+						PersonID:     r.PersonID,
+						PersonName:   r.Name,
+						Item:         m.Booking(),
+						ConflictWith: other.Booking(),
+						Overlap:      overlapOf(m.Booking(), other.Booking()),
+					})
+				}
+			}
+		}
+	}
+
+	// 已提交的自评：改期后课程尚未结束的，自评早于上课，将失效
+	var evaluations []struct {
+		ItemID     int64
+		PersonID   int64
+		PersonName string
+	}
+	err = database.DB.Table("attendance_evaluation ae").
+		Select("ae.item_id, ae.person_id, p.name AS person_name").
+		Joins("JOIN person p ON ae.person_id = p.person_id").
+		Where("ae.item_id IN ?", itemIDs).
+		Where("ae.self_comment IS NOT NULL AND ae.self_comment != ''").
+		Order("ae.item_id, ae.person_id").
+		Scan(&evaluations).Error
+	if err != nil {
+		return impact, err
+	}
+	ended := make(map[int64]bool, len(moves))
+	for _, m := range moves {
+		ended[m.Item.ItemID] = ClassEnded(m.ClassDate, m.ClassEndTime, m.Item.Timezone, now)
+	}
+	for _, e := range evaluations {
+		if !ended[e.ItemID] {
+			impact.InvalidEvaluations = append(impact.InvalidEvaluations, InvalidEvaluation{
+				ItemID:     e.ItemID,
+				PersonID:   e.PersonID,
+				PersonName: e.PersonName,
+			})
+		}
+	}
+	return impact, nil
+}
@@ -0,0 +1,49 @@
+package scheduling
+
+import (
+	"backend/database/dbtest"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeRescheduleEmployeeConflictsWithinBatch(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", dbtest.Date(1), dbtest.Date(9), dbtest.EmployeeID)
+	otherPlan := dbtest.CreatePlan(t, "船员培训", dbtest.Date(1), dbtest.Date(9), dbtest.Employee2ID)
+	fire := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	aid := dbtest.CreateCourse(t, "急救", "安全", dbtest.Teacher2ID)
+	first := dbtest.CreateItem(t, plan, fire, dbtest.Date(1), "09:00:00", "10:00:00", "Asia/Shanghai")
+	second := dbtest.CreateItem(t, plan, aid, dbtest.Date(2), "09:00:00", "10:00:00", "Asia/Shanghai")
+	other := dbtest.CreateItem(t, otherPlan, aid, dbtest.Date(2), "14:00:00", "15:00:00", "Asia/Shanghai")
+
+	booking := func(itemID, planID int64, date string) Booking {
+		return Booking{ItemID: itemID, PlanID: planID, ClassDate: date, ClassBeginTime: "09:00:00", ClassEndTime: "10:00:00", Timezone: "Asia/Shanghai"}
+	}
+	// 同一计划的两个课程安排改到重叠的时间，另一计划的课程安排改到同一时间但没有共同的员工
+	moves := []Move{
+		{Item: booking(first.ItemID, plan.PlanID, dbtest.Date(1)), TeacherID: dbtest.TeacherID, ClassDate: dbtest.Date(5), ClassBeginTime: "09:00:00", ClassEndTime: "10:00:00"},
+		{Item: booking(second.ItemID, plan.PlanID, dbtest.Date(2)), TeacherID: dbtest.Teacher2ID, ClassDate: dbtest.Date(5), ClassBeginTime: "09:30:00", ClassEndTime: "10:30:00"},
+		{Item: booking(other.ItemID, otherPlan.PlanID, dbtest.Date(2)), TeacherID: dbtest.Teacher2ID, ClassDate: dbtest.Date(6), ClassBeginTime: "09:00:00", ClassEndTime: "10:00:00"},
+	}
+	impact, err := AnalyzeReschedule(moves, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(impact.EmployeeConflicts) != 2 {
+		t.Fatalf("应有 2 条员工冲突（两个课程安排互相冲突），实际 %+v", impact.EmployeeConflicts)
+	}
+	want := map[int64]int64{first.ItemID: second.ItemID, second.ItemID: first.ItemID}
+	for _, cf := range impact.EmployeeConflicts {
+		if cf.PersonID != dbtest.EmployeeID || want[cf.Item.ItemID] != cf.ConflictWith.ItemID || cf.Item.ClassDate != dbtest.Date(5) {
+			t.Errorf("冲突应为赵员工在 %s 的课程安排 %d 与 %d，实际 %+v", dbtest.Date(5), cf.Item.ItemID, want[cf.Item.ItemID], cf)
+		}
+		if cf.Overlap != (Overlap{Date: dbtest.Date(5), Begin: "09:30:00", End: "10:00:00"}) {
+			t.Errorf("重叠时间段应为 09:30:00-10:00:00，实际 %+v", cf.Overlap)
+		}
+	}
+	if !impact.NeedsConfirmation() || impact.Blocked() {
+		t.Errorf("员工冲突只需确认，不应阻止改期")
+	}
+}
@@ -0,0 +1,321 @@
+package scheduling
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxOccurrences 一个重复规则最多展开的上课次数
+const MaxOccurrences = 366
+
+// RRule RFC 5545 重复规则（仅支持按天排课需要的部分）
+// 支持 FREQ=DAILY/WEEKLY/MONTHLY、INTERVAL、BYDAY（MONTHLY 时可带序号，如 1MO、-1FR）、BYMONTHDAY、UNTIL、COUNT
+type RRule struct {
+	Freq       string
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	Until      time.Time // 零值表示未设置
+	Count      int       // 0 表示未设置
+}
+
+// WeekdayNum BYDAY 中的一项，N 为月内序号（0 表示每个该星期几）
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// ParseRRule 解析 RRULE 文本，可带或不带 "RRULE:" 前缀
+func ParseRRule(text string) (RRule, error) {
+	rule := RRule{Interval: 1}
+	text = strings.TrimPrefix(strings.TrimSpace(text), "RRULE:")
+	if text == "" {
+		return rule, errors.New("重复规则不能为空")
+	}
+
+	for _, part := range strings.Split(text, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return rule, fmt.Errorf("重复规则格式错误：%s", part)
+		}
+		key, value := strings.ToUpper(strings.TrimSpace(kv[0])), strings.ToUpper(strings.TrimSpace(kv[1]))
+		switch key {
+		case "FREQ":
+			if value != "DAILY" && value != "WEEKLY" && value != "MONTHLY" {
+				return rule, fmt.Errorf("不支持的重复频率：%s，仅支持 DAILY、WEEKLY、MONTHLY", value)
+			}
+			rule.Freq = value
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return rule, errors.New("INTERVAL 必须为正整数")
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return rule, errors.New("COUNT 必须为正整数")
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return rule, err
+			}
+			rule.Until = until
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, err := parseWeekdayNum(code)
+				if err != nil {
+					return rule, err
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return rule, fmt.Errorf("BYMONTHDAY 取值错误：%s", v)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "WKST":
+			if value != "MO" {
+				return rule, errors.New("仅支持 WKST=MO")
+			}
+		default:
+			return rule, fmt.Errorf("不支持的重复规则字段：%s", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return rule, errors.New("重复规则缺少 FREQ")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return rule, errors.New("COUNT 和 UNTIL 不能同时使用")
+	}
+	if rule.Count == 0 && rule.Until.IsZero() {
+		return rule, errors.New("重复规则必须指定 COUNT 或 UNTIL")
+	}
+	if rule.Freq != "MONTHLY" {
+		if len(rule.ByMonthDay) > 0 {
+			return rule, errors.New("BYMONTHDAY 仅可用于 FREQ=MONTHLY")
+		}
+		for _, day := range rule.ByDay {
+			if day.N != 0 {
+				return rule, errors.New("带序号的 BYDAY 仅可用于 FREQ=MONTHLY")
+			}
+		}
+	}
+	return rule, nil
+}
+
+// parseUntil 解析 UNTIL，支持 YYYYMMDD 和 YYYYMMDDTHHMMSS[Z]，只取日期部分
+func parseUntil(value string) (time.Time, error) {
+	if len(value) >= 8 {
+		if until, err := time.Parse("20060102", value[:8]); err == nil {
+			return until, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("UNTIL 格式错误：%s", value)
+}
+
+// parseWeekdayNum 解析 BYDAY 中的一项，如 TU、1MO、-1FR
+func parseWeekdayNum(code string) (WeekdayNum, error) {
+	code = strings.TrimSpace(code)
+	if len(code) < 2 {
+		return WeekdayNum{}, fmt.Errorf("BYDAY 取值错误：%s", code)
+	}
+	weekday, ok := weekdayCodes[code[len(code)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("BYDAY 取值错误：%s", code)
+	}
+	day := WeekdayNum{Weekday: weekday}
+	if prefix := code[:len(code)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return WeekdayNum{}, fmt.Errorf("BYDAY 取值错误：%s", code)
+		}
+		day.N = n
+	}
+	return day, nil
+}
+
+// String 生成规范的 RRULE 文本（不带 "RRULE:" 前缀）
+func (r RRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			code := weekdayNames[day.Weekday]
+			if day.N != 0 {
+				code = strconv.Itoa(day.N) + code
+			}
+			codes = append(codes, code)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, d := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(d))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// EndingOn 返回截止到 until 的规则（去掉 COUNT），拆分系列时使用
+func (r RRule) EndingOn(until time.Time) RRule {
+	r.Count = 0
+	r.Until = until
+	return r
+}
+
+// Expand 从 start 起展开上课日期，跳过 exclude 中的日期（YYYY-MM-DD）
+// 与 RFC 5545 一致，COUNT 计入被排除的日期；start 不符合规则时不作为一次上课
+func (r RRule) Expand(start time.Time, exclude map[string]bool) ([]time.Time, error) {
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	// 只有 COUNT 时最多向后查找10年，防止规则永远匹配不到时死循环
+	limit := r.Until
+	if limit.IsZero() {
+		limit = start.AddDate(10, 0, 0)
+	}
+
+	var dates []time.Time
+	generated := 0
+	for period := 0; !r.periodStart(start, period).After(limit); period++ {
+		for _, d := range r.periodDates(start, period) {
+			if d.Before(start) {
+				continue
+			}
+			if d.After(limit) || (r.Count > 0 && generated >= r.Count) {
+				return dates, nil
+			}
+			generated++
+			if exclude[d.Format("2006-01-02")] {
+				continue
+			}
+			dates = append(dates, d)
+			if len(dates) > MaxOccurrences || generated > MaxOccurrences*2 {
+				return nil, fmt.Errorf("重复次数超过上限%d次", MaxOccurrences)
+			}
+		}
+	}
+	return dates, nil
+}
+
+// periodStart 第 period 个周期的起始日期
+func (r RRule) periodStart(start time.Time, period int) time.Time {
+	switch r.Freq {
+	case "WEEKLY":
+		offset := (int(start.Weekday()) + 6) % 7 // 周一为一周的开始
+		return start.AddDate(0, 0, -offset+7*r.Interval*period)
+	case "MONTHLY":
+		return time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, r.Interval*period, 0)
+	}
+	return start.AddDate(0, 0, r.Interval*period)
+}
+
+// periodDates 第 period 个周期内符合规则的日期（升序）
+func (r RRule) periodDates(start time.Time, period int) []time.Time {
+	first := r.periodStart(start, period)
+	switch r.Freq {
+	case "DAILY":
+		if len(r.ByDay) > 0 && !r.matchesWeekday(first.Weekday()) {
+			return nil
+		}
+		return []time.Time{first}
+
+	case "WEEKLY":
+		var dates []time.Time
+		for i := 0; i < 7; i++ {
+			d := first.AddDate(0, 0, i)
+			if len(r.ByDay) == 0 && d.Weekday() == start.Weekday() || r.matchesWeekday(d.Weekday()) {
+				dates = append(dates, d)
+			}
+		}
+		return dates
+
+	case "MONTHLY":
+		daysInMonth := first.AddDate(0, 1, -1).Day()
+		selected := make(map[int]bool)
+		for _, md := range r.ByMonthDay {
+			if md < 0 {
+				md = daysInMonth + md + 1
+			}
+			if md >= 1 && md <= daysInMonth {
+				selected[md] = true
+			}
+		}
+		// 同时有 BYMONTHDAY 和 BYDAY 时，BYDAY 用于筛选 BYMONTHDAY 选出的日期（取交集），如 BYDAY=FR;BYMONTHDAY=13
+		if len(r.ByDay) > 0 {
+			byDay := make(map[int]bool)
+			for day := 1; day <= daysInMonth; day++ {
+				if r.matchesMonthWeekday(first.AddDate(0, 0, day-1).Weekday(), day, daysInMonth) {
+					byDay[day] = true
+				}
+			}
+			if len(r.ByMonthDay) == 0 {
+				selected = byDay
+			} else {
+				for day := range selected {
+					if !byDay[day] {
+						delete(selected, day)
+					}
+				}
+			}
+		}
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 && start.Day() <= daysInMonth {
+			selected[start.Day()] = true
+		}
+		var dates []time.Time
+		for day := 1; day <= daysInMonth; day++ {
+			if selected[day] {
+				dates = append(dates, first.AddDate(0, 0, day-1))
+			}
+		}
+		return dates
+	}
+	return nil
+}
+
+func (r RRule) matchesWeekday(weekday time.Weekday) bool {
+	for _, day := range r.ByDay {
+		if day.Weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesMonthWeekday 当月第 day 天（星期 weekday）是否符合 BYDAY，带序号时按月内正数或倒数第几个判断
+func (r RRule) matchesMonthWeekday(weekday time.Weekday, day, daysInMonth int) bool {
+	nth := (day-1)/7 + 1
+	nthFromEnd := -((daysInMonth-day)/7 + 1)
+	for _, wd := range r.ByDay {
+		if wd.Weekday == weekday && (wd.N == 0 || wd.N == nth || wd.N == nthFromEnd) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package scheduling
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestParseRRule(t *testing.T) {
+	tests := []struct {
+		text string
+		want string // 规范格式，为空表示应解析失败
+	}{
+		{"RRULE:FREQ=WEEKLY;BYDAY=TU,TH;UNTIL=20240630", "FREQ=WEEKLY;BYDAY=TU,TH;UNTIL=20240630"},
+		{"freq=monthly;byday=-1fr;count=3", "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3"},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,-1;UNTIL=20240630T235959Z", "FREQ=MONTHLY;BYMONTHDAY=1,-1;UNTIL=20240630"},
+		{"FREQ=DAILY;INTERVAL=2;COUNT=5;WKST=MO", "FREQ=DAILY;INTERVAL=2;COUNT=5"},
+		{"", ""},
+		{"BYDAY=MO;COUNT=3", ""},
+		{"FREQ=YEARLY;COUNT=3", ""},
+		{"FREQ=DAILY", ""},
+		{"FREQ=DAILY;COUNT=3;UNTIL=20240630", ""},
+		{"FREQ=DAILY;INTERVAL=0;COUNT=3", ""},
+		{"FREQ=WEEKLY;BYDAY=XX;COUNT=3", ""},
+		{"FREQ=WEEKLY;BYDAY=1MO;COUNT=3", ""},
+		{"FREQ=WEEKLY;BYMONTHDAY=1;COUNT=3", ""},
+		{"FREQ=MONTHLY;BYMONTHDAY=32;COUNT=3", ""},
+		{"FREQ=MONTHLY;BYDAY=6MO;COUNT=3", ""},
+		{"FREQ=DAILY;COUNT=3;WKST=SU", ""},
+	}
+	for _, tt := range tests {
+		rule, err := ParseRRule(tt.text)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("ParseRRule(%q) 应返回错误，实际解析为 %s", tt.text, rule)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRRule(%q) 返回错误：%v", tt.text, err)
+			continue
+		}
+		if got := rule.String(); got != tt.want {
+			t.Errorf("ParseRRule(%q).String() = %q，应为 %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		start   string
+		exclude []string
+		want    []string
+	}{
+		{
+			name:  "每天",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: "2026-03-02",
+			want:  []string{"2026-03-02", "2026-03-03", "2026-03-04"},
+		},
+		{
+			name:    "COUNT 计入被排除的日期",
+			rule:    "FREQ=DAILY;COUNT=5",
+			start:   "2026-03-02",
+			exclude: []string{"2026-03-04"},
+			want:    []string{"2026-03-02", "2026-03-03", "2026-03-05", "2026-03-06"},
+		},
+		{
+			name:  "每月最后一天",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=3",
+			start: "2026-01-15",
+			want:  []string{"2026-01-31", "2026-02-28", "2026-03-31"},
+		},
+		{
+			name:  "每月最后一个周五",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			start: "2026-01-01",
+			want:  []string{"2026-01-30", "2026-02-27", "2026-03-27"},
+		},
+		{
+			name:  "BYDAY 筛选 BYMONTHDAY",
+			rule:  "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13;COUNT=3",
+			start: "2026-01-01",
+			want:  []string{"2026-02-13", "2026-03-13", "2026-11-13"},
+		},
+		{
+			name:  "隔周二、四",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH;COUNT=4",
+			start: "2026-03-02",
+			want:  []string{"2026-03-03", "2026-03-05", "2026-03-17", "2026-03-19"},
+		},
+		{
+			name:  "UNTIL 当天包含在内",
+			rule:  "FREQ=WEEKLY;BYDAY=MO;UNTIL=20260323",
+			start: "2026-03-02",
+			want:  []string{"2026-03-02", "2026-03-09", "2026-03-16", "2026-03-23"},
+		},
+		{
+			name:  "起始日期不符合规则时不作为一次上课",
+			rule:  "FREQ=WEEKLY;BYDAY=MO;COUNT=2",
+			start: "2026-03-03",
+			want:  []string{"2026-03-09", "2026-03-16"},
+		},
+		{
+			name:  "未指定 BYDAY 时按起始日期的星期几",
+			rule:  "FREQ=WEEKLY;UNTIL=20260318",
+			start: "2026-03-04",
+			want:  []string{"2026-03-04", "2026-03-11", "2026-03-18"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule)
+			if err != nil {
+				t.Fatalf("解析 %q 失败：%v", tt.rule, err)
+			}
+			exclude := make(map[string]bool)
+			for _, d := range tt.exclude {
+				exclude[d] = true
+			}
+			dates, err := rule.Expand(date(tt.start), exclude)
+			if err != nil {
+				t.Fatalf("展开 %q 失败：%v", tt.rule, err)
+			}
+			got := make([]string, len(dates))
+			for i, d := range dates {
+				got[i] = d.Format("2006-01-02")
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("%q 从 %s 展开为 %v，应为 %v", tt.rule, tt.start, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandTooMany(t *testing.T) {
+	rule, err := ParseRRule("FREQ=DAILY;COUNT=400")
+	if err != nil {
+		t.Fatalf("解析失败：%v", err)
+	}
+	if _, err := rule.Expand(date("2026-01-01"), nil); err == nil {
+		t.Errorf("展开超过 %d 次应返回错误", MaxOccurrences)
+	}
+}
@@ -0,0 +1,366 @@
+package scheduling
+
+import (
+	"backend/database"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// CourseDemand 排课需求：某门课程需要安排的次数和每次时长
+type CourseDemand struct {
+	CourseID        int64
+	CourseName      string
+	TeacherID       int64
+	TeacherName     string
+	Sessions        int
+	DurationMinutes int
+	LocationID      *int64 // 指定上课地点，为空时自动选择
+}
+
+// TimetableInput 自动排课的输入
+type TimetableInput struct {
+	PlanID       int64
+	StartDate    time.Time
+	EndDate      time.Time
+	DayStart     int // 每天最早开始时间（从零点起的分钟数）
+	DayEnd       int // 每天最晚结束时间
+	SlotMinutes  int // 开始时间的粒度
+	Weekdays     map[time.Weekday]bool
+	ExcludeDates map[string]bool
+	Courses      []CourseDemand
+	Rooms        []database.Location // 候选地点
+	RosterSize   int64
+	Timezone     string // 方案中日期和时间所在的时区（计划的时区），为空表示系统默认时区
+}
+
+// ProposedSession 排课方案中的一次上课
+type ProposedSession struct {
+	Index          int    `json:"index"`
+	CourseID       int64  `json:"courseId"`
+	CourseName     string `json:"courseName"`
+	TeacherID      int64  `json:"teacherId"`
+	TeacherName    string `json:"teacherName"`
+	ClassDate      string `json:"classDate"`
+	ClassBeginTime string `json:"classBeginTime"`
+	ClassEndTime   string `json:"classEndTime"`
+	LocationID     int64  `json:"locationId"`
+	Location       string `json:"location"`
+	ItemID         *int64 `json:"itemId"` // 采纳后生成的课程安排ID，未采纳为 null
+}
+
+// UnplacedDemand 无法排入的课程
+type UnplacedDemand struct {
+	CourseID   int64  `json:"courseId"`
+	CourseName string `json:"courseName"`
+	Missing    int    `json:"missing"` // 未排入的次数
+	Reason     string `json:"reason"`
+}
+
+// TimetableResult 自动排课结果
+type TimetableResult struct {
+	Sessions []ProposedSession `json:"sessions"`
+	Unplaced []UnplacedDemand  `json:"unplaced"`
+}
+
+// interval 一天内被占用的时间段（分钟）
+type interval struct{ begin, end int }
+
+// busyMap 各资源（讲师、地点、计划参训员工）每天被占用的时间段
+type busyMap map[string][]interval
+
+func busyKey(kind string, id int64, date string) string {
+	return kind + ":" + strconv.FormatInt(id, 10) + ":" + date
+}
+
+func (b busyMap) add(key string, begin, end int) {
+	b[key] = append(b[key], interval{begin, end})
+}
+
+func (b busyMap) free(key string, begin, end int) bool {
+	for _, iv := range b[key] {
+		if iv.begin < end && iv.end > begin {
+			return false
+		}
+	}
+	return true
+}
+
+// gaps 返回 from-to 之间没有被占用的时间段
+func (b busyMap) gaps(key string, from, to int) []interval {
+	ivs := append([]interval(nil), b[key]...)
+	sort.Slice(ivs, func(i, j int) bool { return ivs[i].begin < ivs[j].begin })
+	var result []interval
+	cursor := from
+	for _, iv := range ivs {
+		if iv.begin > cursor {
+			gapEnd := iv.begin
+			if gapEnd > to {
+				gapEnd = to
+			}
+			result = append(result, interval{cursor, gapEnd})
+		}
+		if iv.end > cursor {
+			cursor = iv.end
+		}
+		if cursor >= to {
+			return result
+		}
+	}
+	if cursor < to {
+		result = append(result, interval{cursor, to})
+	}
+	return result
+}
+
+// addBooking 将已有课程安排换算到 zone 时区后记为占用，跨零点时分别记入前后两天
+func (b busyMap) addBooking(kind string, id int64, bk Booking, zone *time.Location) {
+	begin, end := Slot{Date: bk.ClassDate, Begin: bk.ClassBeginTime, End: bk.ClassEndTime, Timezone: bk.Timezone}.Interval()
+	begin, end = begin.In(zone), end.In(zone)
+	for day := time.Date(begin.Year(), begin.Month(), begin.Day(), 0, 0, 0, 0, zone); day.Before(end); day = day.AddDate(0, 0, 1) {
+		from, to := 0, dayMinutes
+		if begin.After(day) {
+			from = int(begin.Sub(day).Minutes())
+		}
+		if next := day.AddDate(0, 0, 1); end.Before(next) {
+			to = int(end.Sub(day).Minutes())
+		}
+		if from < to {
+			b.add(busyKey(kind, id, day.Format("2006-01-02")), from, to)
+		}
+	}
+}
+
+// ParseClock 将 HH:mm:ss 转换为从零点起的分钟数
+func ParseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04:05", clock)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// FormatClock 将分钟数转换为 HH:mm:ss
+func FormatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d:00", minutes/60, minutes%60)
+}
+
+// loadBusy 读取日期范围内已有的课程安排：涉及的讲师、候选地点，以及与本计划有共同参训员工的计划；
+// 讲师的可授课时间和工作日历中的非工作日同样记为占用
+func loadBusy(in TimetableInput) (busyMap, error) {
+	busy := make(busyMap)
+	start, end := in.StartDate.Format("2006-01-02"), in.EndDate.Format("2006-01-02")
+
+	teacherIDs := make([]int64, 0, len(in.Courses))
+	for _, d := range in.Courses {
+		teacherIDs = append(teacherIDs, d.TeacherID)
+	}
+	roomIDs := make([]int64, 0, len(in.Rooms))
+	for _, r := range in.Rooms {
+		roomIDs = append(roomIDs, r.LocationID)
+	}
+	roster := database.DB.Model(&database.PlanEmployee{}).Select("person_id").Where("plan_id = ?", in.PlanID)
+	var sharedPlans []int64
+	if err := database.DB.Model(&database.PlanEmployee{}).Where("person_id IN (?)", roster).Distinct().Pluck("plan_id", &sharedPlans).Error; err != nil {
+		return nil, err
+	}
+	attends := map[int64]bool{in.PlanID: true}
+	for _, id := range sharedPlans {
+		attends[id] = true
+	}
+
+	// 其他时区的课程换算到本次排课的时区后可能落在前后一天
+	var rows []struct {
+		Booking
+		TeacherID int64
+	}
+	err := bookingsQuery().
+		Select(bookingsColumns()+", c.teacher_id").
+		Where(database.DateBetween("pci.class_date",
+			in.StartDate.AddDate(0, 0, -1).Format("2006-01-02"), in.EndDate.AddDate(0, 0, 1).Format("2006-01-02"))).
+		Where("(c.teacher_id IN ? OR pci.location_id IN ? OR pci.plan_id = ? OR pci.plan_id IN ?)",
+			teacherIDs, roomIDs, in.PlanID, sharedPlans).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	zone := Timezone(in.Timezone)
+	for _, row := range rows {
+		if _, err1 := ParseClock(row.ClassBeginTime); err1 != nil {
+			continue
+		}
+		if _, err2 := ParseClock(row.ClassEndTime); err2 != nil {
+			continue
+		}
+		busy.addBooking("teacher", row.TeacherID, row.Booking, zone)
+		if row.LocationID != nil {
+			busy.addBooking("room", *row.LocationID, row.Booking, zone)
+		}
+		// 本计划和有共同参训员工的计划的课程都会占用本计划参训员工的时间
+		if attends[row.PlanID] {
+			busy.addBooking("plan", in.PlanID, row.Booking, zone)
+		}
+	}
+
+	// 讲师每周可授课时间段以外的时间和不可授课时间段
+	var days []time.Time
+	for d := in.StartDate; !d.After(in.EndDate); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+	if err := addTeacherUnavailability(busy, teacherIDs, days); err != nil {
+		return nil, err
+	}
+
+	// 工作日历中的非工作日（提醒和禁止都不安排），按地点所在的楼宇或船舶解析
+	cal, err := LoadCalendar(start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, room := range in.Rooms {
+		for _, d := range days {
+			date := d.Format("2006-01-02")
+			if cal.Resolve(date, room.Building) != nil {
+				busy.add(busyKey("room", room.LocationID, date), 0, dayMinutes)
+			}
+		}
+	}
+	return busy, nil
+}
+
+// GenerateTimetable 在进程内贪心求解排课方案
+// 课程按总时长从多到少依次安排，每门课的各次上课尽量均匀分布在可用日期上，同一门课每天最多一次；
+// 每次上课选择讲师（含讲师登记的可授课时间和不可授课时间）、地点、参训员工都空闲的最早时间，地点优先选择容量够用的最小教室。
+// 不保证找到最优解，排不下的次数在 Unplaced 中返回
+func GenerateTimetable(in TimetableInput) (TimetableResult, error) {
+	result := TimetableResult{Sessions: []ProposedSession{}, Unplaced: []UnplacedDemand{}}
+
+	busy, err := loadBusy(in)
+	if err != nil {
+		return result, err
+	}
+
+	// 可用日期
+	var days []string
+	for d := in.StartDate; !d.After(in.EndDate); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		if in.Weekdays[d.Weekday()] && !in.ExcludeDates[date] {
+			days = append(days, date)
+		}
+	}
+
+	// 容量够用的地点，按容量从小到大（不限容量的放最后）
+	rooms := make([]database.Location, 0, len(in.Rooms))
+	for _, r := range in.Rooms {
+		if r.Capacity == 0 || int64(r.Capacity) >= in.RosterSize {
+			rooms = append(rooms, r)
+		}
+	}
+	sort.SliceStable(rooms, func(i, j int) bool {
+		ci, cj := rooms[i].Capacity, rooms[j].Capacity
+		if (ci == 0) != (cj == 0) {
+			return cj == 0
+		}
+		return ci < cj
+	})
+
+	demands := append([]CourseDemand(nil), in.Courses...)
+	sort.SliceStable(demands, func(i, j int) bool {
+		return demands[i].Sessions*demands[i].DurationMinutes > demands[j].Sessions*demands[j].DurationMinutes
+	})
+
+	for _, d := range demands {
+		candidates := rooms
+		if d.LocationID != nil {
+			candidates = nil
+			for _, r := range rooms {
+				if r.LocationID == *d.LocationID {
+					candidates = append(candidates, r)
+				}
+			}
+		}
+		if len(days) == 0 || len(candidates) == 0 {
+			reason := "日期范围内没有可用的上课日期"
+			if len(days) > 0 {
+				reason = "没有容量足够的上课地点"
+			}
+			result.Unplaced = append(result.Unplaced, UnplacedDemand{CourseID: d.CourseID, CourseName: d.CourseName, Missing: d.Sessions, Reason: reason})
+			continue
+		}
+
+		usedDays := make(map[string]bool)
+		placed := 0
+		for k := 0; k < d.Sessions; k++ {
+			target := k * len(days) / d.Sessions
+			ok := false
+			for offset := 0; offset < len(days) && !ok; offset++ {
+				date := days[(target+offset)%len(days)]
+				if usedDays[date] {
+					continue
+				}
+				var session ProposedSession
+				session, ok = placeSession(busy, in, d, date, candidates)
+				if ok {
+					usedDays[date] = true
+					session.Index = len(result.Sessions)
+					result.Sessions = append(result.Sessions, session)
+					placed++
+				}
+			}
+		}
+		if placed < d.Sessions {
+			result.Unplaced = append(result.Unplaced, UnplacedDemand{
+				CourseID:   d.CourseID,
+				CourseName: d.CourseName,
+				Missing:    d.Sessions - placed,
+				Reason:     "讲师、地点或参训员工在可用时间内没有足够的空闲时段",
+			})
+		}
+	}
+
+	sort.SliceStable(result.Sessions, func(i, j int) bool {
+		a, b := result.Sessions[i], result.Sessions[j]
+		if a.ClassDate != b.ClassDate {
+			return a.ClassDate < b.ClassDate
+		}
+		return a.ClassBeginTime < b.ClassBeginTime
+	})
+	for i := range result.Sessions {
+		result.Sessions[i].Index = i
+	}
+	return result, nil
+}
+
+// placeSession 在指定日期为一次上课找最早的空闲时段和地点，找到后记入占用
+func placeSession(busy busyMap, in TimetableInput, d CourseDemand, date string, rooms []database.Location) (ProposedSession, bool) {
+	teacherKey := busyKey("teacher", d.TeacherID, date)
+	planKey := busyKey("plan", in.PlanID, date)
+	for begin := in.DayStart; begin+d.DurationMinutes <= in.DayEnd; begin += in.SlotMinutes {
+		end := begin + d.DurationMinutes
+		if !busy.free(teacherKey, begin, end) || !busy.free(planKey, begin, end) {
+			continue
+		}
+		for _, room := range rooms {
+			roomKey := busyKey("room", room.LocationID, date)
+			if !busy.free(roomKey, begin, end) {
+				continue
+			}
+			busy.add(teacherKey, begin, end)
+			busy.add(planKey, begin, end)
+			busy.add(roomKey, begin, end)
+			return ProposedSession{
+				CourseID:       d.CourseID,
+				CourseName:     d.CourseName,
+				TeacherID:      d.TeacherID,
+				TeacherName:    d.TeacherName,
+				ClassDate:      date,
+				ClassBeginTime: FormatClock(begin),
+				ClassEndTime:   FormatClock(end),
+				LocationID:     room.LocationID,
+				Location:       room.LocationName,
+			}, true
+		}
+	}
+	return ProposedSession{}, false
+}
@@ -0,0 +1,180 @@
+package scheduling
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"testing"
+	"time"
+)
+
+// 2030-03-04 是星期一
+const monday = "2030-03-04"
+
+func createRoom(t *testing.T, name string, capacity int) database.Location {
+	t.Helper()
+	room := database.Location{LocationName: name, Building: "培训楼", Capacity: capacity}
+	if err := database.DB.Create(&room).Error; err != nil {
+		t.Fatal(err)
+	}
+	return room
+}
+
+// createBooking 创建占用 room 的课程安排
+func createBooking(t *testing.T, plan database.TrainingPlan, course database.Course, room database.Location, begin, end string) {
+	t.Helper()
+	item := dbtest.CreateItem(t, plan, course, monday, begin, end, "Asia/Shanghai")
+	if err := database.DB.Model(&item).Updates(map[string]interface{}{"location_id": room.LocationID, "location": room.LocationName}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// timetableInput 只在 monday 当天 08:00-18:00 排课，开始时间粒度 30 分钟
+func timetableInput(plan database.TrainingPlan, rooms []database.Location, courses ...CourseDemand) TimetableInput {
+	return TimetableInput{
+		PlanID:      plan.PlanID,
+		StartDate:   date(monday),
+		EndDate:     date(monday),
+		DayStart:    8 * 60,
+		DayEnd:      18 * 60,
+		SlotMinutes: 30,
+		Weekdays:    map[time.Weekday]bool{time.Monday: true},
+		Courses:     courses,
+		Rooms:       rooms,
+		RosterSize:  1,
+		Timezone:    "Asia/Shanghai",
+	}
+}
+
+func TestGenerateTimetableAvoidsClashes(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", monday, monday, dbtest.EmployeeID)
+	other := dbtest.CreatePlan(t, "船员培训", monday, monday, dbtest.Employee2ID)
+	shared := dbtest.CreatePlan(t, "消防演练", monday, monday, dbtest.EmployeeID)
+	fire := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	aid := dbtest.CreateCourse(t, "急救", "安全", dbtest.Teacher2ID)
+	room1, room2, room3 := createRoom(t, "测试教室1", 30), createRoom(t, "测试教室2", 30), createRoom(t, "测试教室3", 30)
+
+	// 讲师 08:00-10:00 在其他教室上课，候选教室 10:00-11:00 被占用，本计划员工 11:00-12:30 参加另一个计划的课
+	createBooking(t, other, fire, room2, "08:00:00", "10:00:00")
+	createBooking(t, other, aid, room1, "10:00:00", "11:00:00")
+	createBooking(t, shared, aid, room3, "11:00:00", "12:30:00")
+
+	demand := CourseDemand{CourseID: fire.CourseID, CourseName: fire.CourseName, TeacherID: dbtest.TeacherID, Sessions: 1, DurationMinutes: 60}
+	result, err := GenerateTimetable(timetableInput(plan, []database.Location{room1}, demand))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Sessions) != 1 || len(result.Unplaced) != 0 {
+		t.Fatalf("应排入 1 次课，实际 %+v", result)
+	}
+	if s := result.Sessions[0]; s.ClassDate != monday || s.ClassBeginTime != "12:30:00" || s.ClassEndTime != "13:30:00" || s.LocationID != room1.LocationID {
+		t.Fatalf("应避开讲师、教室和员工的占用排在 12:30-13:30，实际 %+v", s)
+	}
+}
+
+func TestGenerateTimetableWithinBatch(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", monday, monday, dbtest.EmployeeID)
+	fire := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	aid := dbtest.CreateCourse(t, "急救", "安全", dbtest.Teacher2ID)
+	small, large := createRoom(t, "测试教室1", 10), createRoom(t, "测试报告厅", 0)
+
+	// 同一方案中已排入的上课同样占用讲师、教室和员工；两门课时长相同，按输入顺序安排，都选容量够用的最小教室
+	in := timetableInput(plan, []database.Location{large, small},
+		CourseDemand{CourseID: fire.CourseID, TeacherID: dbtest.TeacherID, Sessions: 1, DurationMinutes: 90},
+		CourseDemand{CourseID: aid.CourseID, TeacherID: dbtest.Teacher2ID, Sessions: 1, DurationMinutes: 90})
+	result, err := GenerateTimetable(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Sessions) != 2 {
+		t.Fatalf("应排入 2 次课，实际 %+v", result)
+	}
+	want := []struct {
+		courseID   int64
+		begin, end string
+	}{
+		{fire.CourseID, "08:00:00", "09:30:00"},
+		{aid.CourseID, "09:30:00", "11:00:00"},
+	}
+	for i, w := range want {
+		s := result.Sessions[i]
+		if s.Index != i || s.CourseID != w.courseID || s.ClassBeginTime != w.begin || s.ClassEndTime != w.end || s.LocationID != small.LocationID {
+			t.Errorf("第 %d 次课应为课程 %d 在 %s-%s、教室 %d，实际 %+v", i, w.courseID, w.begin, w.end, small.LocationID, s)
+		}
+	}
+}
+
+func TestGenerateTimetableTeacherAvailability(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", monday, monday, dbtest.EmployeeID)
+	fire := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	room := createRoom(t, "测试教室1", 30)
+
+	// 讲师周一只能 14:00-17:00 授课，其中 14:00-15:00 请假
+	if err := database.DB.Create(&database.TeacherAvailability{TeacherID: dbtest.TeacherID, Weekday: 1, BeginTime: "14:00:00", EndTime: "17:00:00"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	blackout := database.TeacherBlackout{TeacherID: dbtest.TeacherID, StartDate: date(monday), BeginTime: "14:00:00", EndDate: date(monday), EndTime: "15:00:00", Reason: "请假"}
+	if err := database.DB.Create(&blackout).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	in := timetableInput(plan, []database.Location{room},
+		CourseDemand{CourseID: fire.CourseID, TeacherID: dbtest.TeacherID, Sessions: 1, DurationMinutes: 120})
+	result, err := GenerateTimetable(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Sessions) != 1 || result.Sessions[0].ClassBeginTime != "15:00:00" || result.Sessions[0].ClassEndTime != "17:00:00" {
+		t.Fatalf("应排在可授课时间内、请假之后的 15:00-17:00，实际 %+v", result)
+	}
+
+	// 剩余的可授课时间不够再排一次
+	in.Courses[0].DurationMinutes = 150
+	result, err = GenerateTimetable(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Sessions) != 0 || len(result.Unplaced) != 1 || result.Unplaced[0].Missing != 1 {
+		t.Fatalf("可授课时间不够时应排不下，实际 %+v", result)
+	}
+}
+
+func TestGenerateTimetablePartiallyPlaced(t *testing.T) {
+	dbtest.Setup(t)
+
+	plan := dbtest.CreatePlan(t, "安全培训", monday, monday, dbtest.EmployeeID)
+	fire := dbtest.CreateCourse(t, "消防", "安全", dbtest.TeacherID)
+	room := createRoom(t, "测试教室1", 30)
+
+	// 周一到周三可上课，周二不上课：4 次课只能排在周一、周三，同一门课每天最多一次
+	in := timetableInput(plan, []database.Location{room},
+		CourseDemand{CourseID: fire.CourseID, CourseName: fire.CourseName, TeacherID: dbtest.TeacherID, Sessions: 4, DurationMinutes: 60})
+	in.EndDate = date("2030-03-06")
+	in.Weekdays = map[time.Weekday]bool{time.Monday: true, time.Tuesday: true, time.Wednesday: true}
+	in.ExcludeDates = map[string]bool{"2030-03-05": true}
+	result, err := GenerateTimetable(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Sessions) != 2 || result.Sessions[0].ClassDate != monday || result.Sessions[1].ClassDate != "2030-03-06" {
+		t.Fatalf("应在周一、周三各排 1 次，实际 %+v", result.Sessions)
+	}
+	if len(result.Unplaced) != 1 || result.Unplaced[0].CourseID != fire.CourseID || result.Unplaced[0].Missing != 2 {
+		t.Fatalf("应有 2 次未排入，实际 %+v", result.Unplaced)
+	}
+
+	// 教室容量不够时整门课都排不下
+	in.RosterSize = 31
+	result, err = GenerateTimetable(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Sessions) != 0 || len(result.Unplaced) != 1 || result.Unplaced[0].Missing != 4 || result.Unplaced[0].Reason != "没有容量足够的上课地点" {
+		t.Fatalf("容量不够时应全部未排入，实际 %+v", result.Unplaced)
+	}
+}
@@ -0,0 +1,189 @@
+package scheduling
+
+import (
+	"backend/config"
+	"backend/database"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// timezones 已加载的时区，避免重复读取时区数据库
+var timezones sync.Map
+
+// loadTimezone 按 IANA 名称加载时区并缓存
+func loadTimezone(name string) (*time.Location, error) {
+	if loc, ok := timezones.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	timezones.Store(name, loc)
+	return loc, nil
+}
+
+// ValidTimezone 检查 IANA 时区名称，空字符串表示未设置，视为有效
+func ValidTimezone(name string) bool {
+	if name == "" {
+		return true
+	}
+	_, err := loadTimezone(name)
+	return err == nil
+}
+
+// DefaultTimezoneName 系统默认时区的 IANA 名称（配置项 DEFAULT_TIMEZONE），
+// 为 Local 或无法识别时使用服务器所在时区的名称
+func DefaultTimezoneName() string {
+	if config.AppConfig != nil && config.AppConfig.DefaultTimezone != "" && config.AppConfig.DefaultTimezone != "Local" {
+		if _, err := loadTimezone(config.AppConfig.DefaultTimezone); err == nil {
+			return config.AppConfig.DefaultTimezone
+		}
+	}
+	return localTimezoneName()
+}
+
+// localTimezoneName 服务器所在时区的 IANA 名称：依次取 TZ 环境变量和 /etc/localtime 指向的时区文件，都无法确定时为 UTC
+func localTimezoneName() string {
+	if name := strings.TrimPrefix(os.Getenv("TZ"), ":"); name != "" && name != "Local" {
+		if _, err := loadTimezone(name); err == nil {
+			return name
+		}
+	}
+	if target, err := os.Readlink("/etc/localtime"); err == nil {
+		if i := strings.Index(target, "zoneinfo/"); i >= 0 {
+			name := target[i+len("zoneinfo/"):]
+			if _, err := loadTimezone(name); err == nil {
+				return name
+			}
+		}
+	}
+	return "UTC"
+}
+
+// DefaultTimezone 系统默认时区
+func DefaultTimezone() *time.Location {
+	if loc, err := loadTimezone(DefaultTimezoneName()); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// Timezone 返回时区，空字符串或无法识别的名称返回系统默认时区
+func Timezone(name string) *time.Location {
+	if name == "" {
+		return DefaultTimezone()
+	}
+	if loc, err := loadTimezone(name); err == nil {
+		return loc
+	}
+	return DefaultTimezone()
+}
+
+// TimezoneName 时区的 IANA 名称，未设置或无法识别时为系统默认时区的名称
+func TimezoneName(name string) string {
+	if name != "" && ValidTimezone(name) {
+		return name
+	}
+	return DefaultTimezoneName()
+}
+
+// ResolveTimezone 课程安排的时区：地点设置了时区时用地点的，否则用计划的，都未设置时用系统默认时区，
+// 总是返回具体的 IANA 名称，课程安排保存后不随默认时区配置的变化而改变
+func ResolveTimezone(loc *database.Location, plan database.TrainingPlan) string {
+	if loc != nil && loc.Timezone != "" {
+		return loc.Timezone
+	}
+	if plan.Timezone != "" {
+		return plan.Timezone
+	}
+	return DefaultTimezoneName()
+}
+
+// BackfillTimezones 为未保存时区的课程安排和已取消课程快照补上系统默认时区
+// 迁移 0019 已按地点和计划的时区补齐，剩下的只能在知道默认时区配置后补齐，每次启动时执行
+func BackfillTimezones() error {
+	name := DefaultTimezoneName()
+	if err := database.DB.Model(&database.PlanCourseItem{}).Where("timezone = ''").
+		Update("timezone", name).Error; err != nil {
+		return err
+	}
+	return database.DB.Model(&database.CourseItemCancellation{}).Where("timezone = ''").
+		Update("timezone", name).Error
+}
+
+// ClassTime 把上课日期（YYYY-MM-DD）和时间（HH:mm:ss）解释为所在时区的时刻
+func ClassTime(date, clock, tz string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", date+" "+clock, Timezone(tz))
+	if err != nil {
+		t, _ = time.ParseInLocation("2006-01-02", date, Timezone(tz))
+	}
+	return t
+}
+
+// Interval 时间段的开始和结束时刻
+func (s Slot) Interval() (time.Time, time.Time) {
+	return ClassTime(s.Date, s.Begin, s.Timezone), ClassTime(s.Date, s.End, s.Timezone)
+}
+
+// In 把时间段换算为另一个时区的日期和时间
+func (s Slot) In(tz string) Slot {
+	if s.Timezone == tz {
+		return s
+	}
+	begin, end := s.Interval()
+	zone := Timezone(tz)
+	return Slot{
+		Date:     begin.In(zone).Format("2006-01-02"),
+		Begin:    begin.In(zone).Format("15:04:05"),
+		End:      end.In(zone).Format("15:04:05"),
+		Timezone: tz,
+	}
+}
+
+// ItemInterval 课程安排的开始和结束时刻
+func ItemInterval(item database.PlanCourseItem) (time.Time, time.Time) {
+	return Slot{
+		Date:     item.ClassDate.Format("2006-01-02"),
+		Begin:    item.ClassBeginTime,
+		End:      item.ClassEndTime,
+		Timezone: item.Timezone,
+	}.Interval()
+}
+
+// ClassEnded 判断上课是否已经结束（按课程安排所在时区）
+func ClassEnded(date, endClock, tz string, now time.Time) bool {
+	return !now.Before(ClassTime(date, endClock, tz))
+}
+
+// EndedBefore 可能已经结束的上课日期上限：任何时区的上课日期都不会晚于 UTC 日期的后一天
+// 查询已结束的课程时先按此筛选，再用 ClassEnded 逐条判断
+func EndedBefore(now time.Time) string {
+	return now.UTC().AddDate(0, 0, 1).Format("2006-01-02")
+}
+
+// ViewerTimezone 解析查看者指定的时区，未指定时为系统默认时区，无法识别时返回 false
+func ViewerTimezone(name string) (*time.Location, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return DefaultTimezone(), true
+	}
+	loc, err := loadTimezone(name)
+	if err != nil {
+		return nil, false
+	}
+	return loc, true
+}
+
+// ViewerSlot 把课程安排换算为查看者时区的日期和时间
+func ViewerSlot(date, begin, end, tz string, viewer *time.Location) Slot {
+	start, finish := Slot{Date: date, Begin: begin, End: end, Timezone: tz}.Interval()
+	return Slot{
+		Date:     start.In(viewer).Format("2006-01-02"),
+		Begin:    start.In(viewer).Format("15:04:05"),
+		End:      finish.In(viewer).Format("15:04:05"),
+		Timezone: viewer.String(),
+	}
+}
@@ -0,0 +1,81 @@
+package scoring
+
+import (
+	"sync"
+	"time"
+)
+
+// circuitBreaker 简单熔断器
+// 连续失败达到阈值后熔断，冷却期内拒绝所有调用；冷却结束后放行一次试探调用，
+// 试探成功则恢复，失败则重新熔断
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool // 半开状态下是否已有试探调用在执行
+}
+
+// newCircuitBreaker 创建熔断器
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// Allow 判断当前是否允许调用，不允许时返回建议的重试时间
+func (b *circuitBreaker) Allow() (bool, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true, time.Time{}
+	}
+
+	now := time.Now()
+	if now.Before(b.openUntil) {
+		return false, b.openUntil
+	}
+
+	// 半开：只放行一个试探调用
+	if b.probing {
+		return false, now.Add(b.cooldown)
+	}
+	b.probing = true
+	return true, time.Time{}
+}
+
+// Success 记录一次成功调用
+func (b *circuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure 记录一次失败调用
+func (b *circuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// State 返回熔断器状态：closed / open / half-open
+func (b *circuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case b.failures < b.threshold:
+		return "closed"
+	case time.Now().Before(b.openUntil):
+		return "open"
+	default:
+		return "half-open"
+	}
+}
@@ -0,0 +1,349 @@
+package scoring
+
+import (
+	"backend/config"
+	"backend/database"
+	"backend/utils"
+	"context"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 队列参数
+const (
+	pollInterval     = 2 * time.Second  // 轮询待执行任务的间隔
+	recoverInterval  = time.Minute      // 检查卡住任务的间隔
+	backoffBase      = 2 * time.Second  // 指数退避基数
+	backoffMax       = 5 * time.Minute  // 指数退避上限
+	breakerThreshold = 5                // 连续失败多少次后熔断
+	breakerCooldown  = 60 * time.Second // 熔断冷却时间
+)
+
+// queue 异步评分队列（任务持久化在 ai_scoring_job 表，多实例部署时通过条件更新抢占任务）
+type queue struct {
+	timeout     time.Duration
+	workers     int
+	maxAttempts int
+	breaker     *circuitBreaker
+	wake        chan struct{}
+	stop        chan struct{}
+	wg          sync.WaitGroup
+}
+
+var q *queue
+
+// Start 启动评分 worker（启动时调用）
+func Start(cfg *config.Config) {
+	workers := cfg.AIWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	maxAttempts := cfg.AIMaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	q = &queue{
+		timeout:     cfg.AITimeout(),
+		workers:     workers,
+		maxAttempts: maxAttempts,
+		breaker:     newCircuitBreaker(breakerThreshold, breakerCooldown),
+		wake:        make(chan struct{}, workers),
+		stop:        make(chan struct{}),
+	}
+
+	q.recoverStuckJobs()
+
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	q.wg.Add(1)
+	go q.recoverLoop()
+
+	log.Printf("AI评分队列已启动，并发数: %d，最大尝试次数: %d", workers, maxAttempts)
+}
+
+// Stop 停止评分 worker，等待执行中的任务完成
+func Stop() {
+	if q == nil {
+		return
+	}
+	close(q.stop)
+	q.wg.Wait()
+}
+
+// Notify 唤醒空闲 worker（在提交任务的事务提交后调用）
+func Notify() {
+	if q == nil {
+		return
+	}
+	for i := 0; i < q.workers; i++ {
+		select {
+		case q.wake <- struct{}{}:
+		default:
+			return
+		}
+	}
+}
+
+// BreakerState 返回AI调用熔断器状态
+func BreakerState() string {
+	if q == nil {
+		return "closed"
+	}
+	return q.breaker.State()
+}
+
+// EnqueueSelfScoring 在事务中创建员工自评评分任务，并作废该评价之前未完成的自评任务
+func EnqueueSelfScoring(tx *gorm.DB, personID, itemID int64, selfComment string, understanding, difficulty, satisfaction int, courseName string) (*database.AIScoringJob, error) {
+	job := &database.AIScoringJob{
+		PersonID:      personID,
+		ItemID:        itemID,
+		ScoreType:     utils.ScoreTypeSelf,
+		CourseName:    courseName,
+		Comment:       selfComment,
+		Understanding: understanding,
+		Difficulty:    difficulty,
+		Satisfaction:  satisfaction,
+	}
+	return job, enqueue(tx, job)
+}
+
+// EnqueueTeacherScoring 在事务中创建讲师评语评分任务，并作废该评价之前未完成的讲师评分任务
+func EnqueueTeacherScoring(tx *gorm.DB, personID, itemID int64, teacherComment string, courseName string) (*database.AIScoringJob, error) {
+	job := &database.AIScoringJob{
+		PersonID:   personID,
+		ItemID:     itemID,
+		ScoreType:  utils.ScoreTypeTeacher,
+		CourseName: courseName,
+		Comment:    teacherComment,
+	}
+	return job, enqueue(tx, job)
+}
+
+// CancelPending 作废某条评价上指定类型的未完成任务（如讲师改为直接打分）
+func CancelPending(tx *gorm.DB, personID, itemID int64, scoreType string) error {
+	return tx.Model(&database.AIScoringJob{}).
+		Where("person_id = ? AND item_id = ? AND score_type = ? AND status IN ?",
+			personID, itemID, scoreType, []string{database.JobStatusPending, database.JobStatusRunning}).
+		Updates(map[string]interface{}{
+			"status":      database.JobStatusCancelled,
+			"finished_at": time.Now(),
+		}).Error
+}
+
+// enqueue 写入任务
+func enqueue(tx *gorm.DB, job *database.AIScoringJob) error {
+	if err := CancelPending(tx, job.PersonID, job.ItemID, job.ScoreType); err != nil {
+		return err
+	}
+	job.Status = database.JobStatusPending
+	job.NextRunAt = time.Now()
+	return tx.Create(job).Error
+}
+
+// work worker 主循环：被唤醒或定时轮询时，持续领取到期任务直到队列为空
+func (q *queue) work() {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-q.wake:
+		case <-ticker.C:
+		}
+
+		for {
+			select {
+			case <-q.stop:
+				return
+			default:
+			}
+
+			job, found := q.claim()
+			if !found {
+				break
+			}
+			if job != nil {
+				q.process(job)
+			}
+		}
+	}
+}
+
+// claim 领取一个到期任务；found 为 false 表示当前没有到期任务，
+// job 为 nil 且 found 为 true 表示任务被其他 worker 抢先领取
+func (q *queue) claim() (job *database.AIScoringJob, found bool) {
+	var candidates []database.AIScoringJob
+	err := database.DB.
+		Where("status = ? AND next_run_at <= ?", database.JobStatusPending, time.Now()).
+		Order("next_run_at ASC").
+		Limit(1).
+		Find(&candidates).Error
+	if err != nil || len(candidates) == 0 {
+		return nil, false
+	}
+	candidate := candidates[0]
+
+	result := database.DB.Model(&database.AIScoringJob{}).
+		Where("job_id = ? AND status = ?", candidate.JobID, database.JobStatusPending).
+		Update("status", database.JobStatusRunning)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, true
+	}
+
+	candidate.Status = database.JobStatusRunning
+	return &candidate, true
+}
+
+// process 执行一个评分任务
+func (q *queue) process(job *database.AIScoringJob) {
+	// 熔断期间不消耗尝试次数，直接推迟到冷却结束
+	if ok, retryAt := q.breaker.Allow(); !ok {
+		q.reschedule(job, retryAt, "AI服务熔断中")
+		return
+	}
+
+	var prompt string
+	if job.ScoreType == utils.ScoreTypeTeacher {
+		prompt = utils.BuildTeacherPrompt(job.Comment, job.CourseName)
+	} else {
+		prompt = utils.BuildEvaluationPrompt(job.Comment, job.Understanding, job.Difficulty, job.Satisfaction, job.CourseName)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), q.timeout)
+	result := utils.CallAIProvider(ctx, prompt)
+	cancel()
+	job.Attempts++
+
+	if result.Error == "" {
+		q.breaker.Success()
+		q.complete(job, result, database.JobStatusDone, database.ScoringStatusDone)
+		return
+	}
+
+	q.breaker.Failure()
+
+	if job.Attempts >= q.maxAttempts {
+		// 重试次数用尽，使用备用评分；本次失败的调用由 complete 连同备用评分一起记录
+		if job.ScoreType == utils.ScoreTypeTeacher {
+			utils.ApplyTeacherFallback(result)
+		} else {
+			utils.ApplyEvaluationFallback(result, job.Comment, job.Understanding, job.Difficulty, job.Satisfaction)
+		}
+		q.complete(job, result, database.JobStatusFailed, database.ScoringStatusFallback)
+		return
+	}
+
+	// 记录本次失败的调用
+	auditLog := result.AuditLog(job.PersonID, job.ItemID, job.ScoreType)
+	if err := database.DB.Create(&auditLog).Error; err != nil {
+		log.Printf("AI评分任务 %d 记录调用日志失败: %v", job.JobID, err)
+	}
+
+	q.reschedule(job, time.Now().Add(backoff(job.Attempts)), result.Error)
+}
+
+// complete 写回评分结果并结束任务
+func (q *queue) complete(job *database.AIScoringJob, result *utils.ScoringResult, jobStatus, scoringStatus string) {
+	scoreColumn, rationaleColumn, statusColumn := "self_score", "self_score_rationale", "self_scoring_status"
+	if job.ScoreType == utils.ScoreTypeTeacher {
+		scoreColumn, rationaleColumn, statusColumn = "teacher_score", "teacher_score_rationale", "teacher_scoring_status"
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// 任务可能在执行期间被作废，此时丢弃结果
+		update := tx.Model(&database.AIScoringJob{}).
+			Where("job_id = ? AND status = ?", job.JobID, database.JobStatusRunning).
+			Updates(map[string]interface{}{
+				"status":      jobStatus,
+				"attempts":    job.Attempts,
+				"last_error":  result.Error,
+				"finished_at": time.Now(),
+			})
+		if update.Error != nil {
+			return update.Error
+		}
+		if update.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&database.AttendanceEvaluation{}).
+			Where("person_id = ? AND item_id = ?", job.PersonID, job.ItemID).
+			Updates(map[string]interface{}{
+				scoreColumn:     result.Score,
+				rationaleColumn: result.Rationale,
+				statusColumn:    scoringStatus,
+			}).Error; err != nil {
+			return err
+		}
+
+		auditLog := result.AuditLog(job.PersonID, job.ItemID, job.ScoreType)
+		return tx.Create(&auditLog).Error
+	})
+	if err != nil {
+		log.Printf("AI评分任务 %d 写回结果失败: %v", job.JobID, err)
+	}
+}
+
+// reschedule 将任务放回队列，在 runAt 之后重试
+func (q *queue) reschedule(job *database.AIScoringJob, runAt time.Time, reason string) {
+	database.DB.Model(&database.AIScoringJob{}).
+		Where("job_id = ? AND status = ?", job.JobID, database.JobStatusRunning).
+		Updates(map[string]interface{}{
+			"status":      database.JobStatusPending,
+			"attempts":    job.Attempts,
+			"last_error":  reason,
+			"next_run_at": runAt,
+		})
+}
+
+// recoverLoop 定期回收卡住的任务
+func (q *queue) recoverLoop() {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(recoverInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-ticker.C:
+			q.recoverStuckJobs()
+		}
+	}
+}
+
+// recoverStuckJobs 将长时间处于执行中的任务（实例崩溃或重启导致）放回队列
+func (q *queue) recoverStuckJobs() {
+	staleBefore := time.Now().Add(-2*q.timeout - time.Minute)
+	result := database.DB.Model(&database.AIScoringJob{}).
+		Where("status = ? AND updated_at < ?", database.JobStatusRunning, staleBefore).
+		Updates(map[string]interface{}{
+			"status":      database.JobStatusPending,
+			"next_run_at": time.Now(),
+		})
+	if result.RowsAffected > 0 {
+		log.Printf("回收了 %d 个卡住的AI评分任务", result.RowsAffected)
+	}
+}
+
+// backoff 计算第 attempts 次失败后的重试间隔（指数退避，带 ±20% 抖动）
+func backoff(attempts int) time.Duration {
+	delay := backoffBase << uint(attempts-1)
+	if delay <= 0 || delay > backoffMax {
+		delay = backoffMax
+	}
+	jitter := time.Duration(rand.Int63n(int64(delay)/5*2+1)) - delay/5
+	return delay + jitter
+}
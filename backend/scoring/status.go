@@ -0,0 +1,83 @@
+package scoring
+
+import (
+	"backend/database"
+)
+
+// JobStatus 评分任务状态（供前端轮询）
+type JobStatus struct {
+	JobID     int64   `json:"jobId"`
+	ScoreType string  `json:"scoreType"`
+	Status    string  `json:"status"`
+	Attempts  int     `json:"attempts"`
+	LastError string  `json:"lastError"`
+	NextRunAt string  `json:"nextRunAt"`
+	CreatedAt string  `json:"createdAt"`
+	Finished  *string `json:"finishedAt"`
+}
+
+// EvaluationStatus 一条评价的AI评分状态
+type EvaluationStatus struct {
+	PersonID              int64       `json:"personId"`
+	ItemID                int64       `json:"itemId"`
+	SelfScoringStatus     string      `json:"selfScoringStatus"`
+	SelfScore             *float64    `json:"selfScore"`
+	SelfScoreRationale    string      `json:"selfScoreRationale"`
+	TeacherScoringStatus  string      `json:"teacherScoringStatus"`
+	TeacherScore          *float64    `json:"teacherScore"`
+	TeacherScoreRationale string      `json:"teacherScoreRationale"`
+	BreakerState          string      `json:"breakerState"`
+	Jobs                  []JobStatus `json:"jobs"`
+}
+
+// GetEvaluationStatus 查询评价的AI评分状态及最近的评分任务
+func GetEvaluationStatus(personID, itemID int64) (*EvaluationStatus, error) {
+	var evaluation database.AttendanceEvaluation
+	if err := database.DB.Where("person_id = ? AND item_id = ?", personID, itemID).First(&evaluation).Error; err != nil {
+		return nil, err
+	}
+
+	status := &EvaluationStatus{
+		PersonID:              personID,
+		ItemID:                itemID,
+		SelfScoringStatus:     evaluation.SelfScoringStatus,
+		SelfScoreRationale:    evaluation.SelfScoreRationale,
+		TeacherScoringStatus:  evaluation.TeacherScoringStatus,
+		TeacherScoreRationale: evaluation.TeacherScoreRationale,
+		BreakerState:          BreakerState(),
+		Jobs:                  []JobStatus{},
+	}
+
+	// 评分完成前不返回分数
+	if evaluation.SelfScoringStatus != database.ScoringStatusPending && evaluation.SelfComment != "" {
+		status.SelfScore = &evaluation.SelfScore
+	}
+	if evaluation.TeacherScoringStatus != database.ScoringStatusPending && evaluation.TeacherComment != "" {
+		status.TeacherScore = &evaluation.TeacherScore
+	}
+
+	var jobs []database.AIScoringJob
+	database.DB.Where("person_id = ? AND item_id = ?", personID, itemID).
+		Order("job_id DESC").
+		Limit(10).
+		Find(&jobs)
+
+	for _, job := range jobs {
+		js := JobStatus{
+			JobID:     job.JobID,
+			ScoreType: job.ScoreType,
+			Status:    job.Status,
+			Attempts:  job.Attempts,
+			LastError: job.LastError,
+			NextRunAt: job.NextRunAt.Format("2006-01-02 15:04:05"),
+			CreatedAt: job.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if job.FinishedAt != nil {
+			finished := job.FinishedAt.Format("2006-01-02 15:04:05")
+			js.Finished = &finished
+		}
+		status.Jobs = append(status.Jobs, js)
+	}
+
+	return status, nil
+}
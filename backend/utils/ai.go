@@ -1,22 +1,16 @@
 package utils
 
 import (
-	"bytes"
+	"backend/database"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// DeepSeek API配置
-const (
-	DeepSeekAPIURL = "https://api.deepseek.com/chat/completions"
-)
-
-// AIRequest DeepSeek API请求结构
+// AIRequest 对话接口请求结构（OpenAI 兼容）
 type AIRequest struct {
 	Model    string      `json:"model"`
 	Messages []AIMessage `json:"messages"`
@@ -29,25 +23,57 @@ type AIMessage struct {
 	Content string `json:"content"`
 }
 
-// AIResponse DeepSeek API响应结构
+// AIResponse 对话接口响应结构（OpenAI 兼容）
 type AIResponse struct {
 	Choices []struct {
 		Message AIMessage `json:"message"`
 	} `json:"choices"`
 }
 
-// GenerateEvaluationScore 调用AI生成评价分数（员工自评）
-// 参数：
-//   - selfComment: 员工的自评内容/学习心得
-//   - understanding: 理解程度（1-5）
-//   - difficulty: 难度感受（1-5）
-//   - satisfaction: 满意度（1-5）
-//   - courseName: 课程名称（用于上下文）
-//
-// 返回：0-100的分数
-func GenerateEvaluationScore(selfComment string, understanding, difficulty, satisfaction int, courseName string) (float64, error) {
-	// 构建提示词
-	prompt := fmt.Sprintf(`你是一个专业的培训评估专家。请根据学员对课程《%s》的自评内容，评估其学习掌握程度，给出0-100的分数。
+// 评分类型（用于审计日志）
+const (
+	ScoreTypeSelf    = "self"    // 员工自评分数
+	ScoreTypeTeacher = "teacher" // 讲师评分（AI 辅助）
+)
+
+// ScoringResult AI评分结果，包含评分理由与审计所需的全部信息
+type ScoringResult struct {
+	Score        float64  // 最终采用的分数
+	ParsedScore  *float64 // 从模型输出中解析出的分数（解析失败为 nil）
+	Rationale    string   // 评分理由
+	Provider     string   // AI 提供方
+	Prompt       string   // 发送给模型的提示词
+	RawOutput    string   // 模型原始输出
+	LatencyMs    int64    // 调用耗时（毫秒）
+	UsedFallback bool     // 是否使用了备用评分
+	Error        string   // 调用或解析失败的原因
+}
+
+// AuditLog 将评分结果转换为审计日志记录
+func (r *ScoringResult) AuditLog(personID, itemID int64, scoreType string) database.AIScoringLog {
+	return database.AIScoringLog{
+		PersonID:     personID,
+		ItemID:       itemID,
+		ScoreType:    scoreType,
+		Provider:     r.Provider,
+		Prompt:       r.Prompt,
+		RawOutput:    r.RawOutput,
+		ParsedScore:  r.ParsedScore,
+		FinalScore:   r.Score,
+		Rationale:    r.Rationale,
+		UsedFallback: r.UsedFallback,
+		LatencyMs:    r.LatencyMs,
+		ErrorMessage: r.Error,
+	}
+}
+
+// 要求模型返回的输出格式
+const aiOutputFormat = `请严格按照以下JSON格式返回，不要包含任何其他文字：
+{"score": 0-100之间的整数分数, "rationale": "不超过100字的评分理由"}`
+
+// BuildEvaluationPrompt 构建员工自评评分提示词
+func BuildEvaluationPrompt(selfComment string, understanding, difficulty, satisfaction int, courseName string) string {
+	return fmt.Sprintf(`你是一个专业的培训评估专家。请根据学员对课程《%s》的自评内容，评估其学习掌握程度，给出0-100的分数。
 
 评分标准：
 - 90-100分：深刻理解课程内容，能够举一反三，有独到见解
@@ -64,28 +90,13 @@ func GenerateEvaluationScore(selfComment string, understanding, difficulty, sati
 - 难度感受：%d/5
 - 满意度：%d/5
 
-请只返回一个0-100之间的整数分数，不要包含任何其他文字、符号或解释。`, 
-		courseName, selfComment, understanding, difficulty, satisfaction)
-
-	// 调用AI接口
-	score, err := callDeepSeekAPI(prompt)
-	if err != nil {
-		// AI调用失败时，使用简单算法估算分数
-		return calculateFallbackScore(selfComment, understanding, difficulty, satisfaction), nil
-	}
-
-	return score, nil
+%s`,
+		courseName, selfComment, understanding, difficulty, satisfaction, aiOutputFormat)
 }
 
-// GenerateTeacherScore 调用AI生成教师评分（讲师对学员的评价）
-// 参数：
-//   - teacherComment: 讲师的评价内容
-//   - courseName: 课程名称
-//
-// 返回：0-100的分数
-func GenerateTeacherScore(teacherComment string, courseName string) (float64, error) {
-	// 构建提示词
-	prompt := fmt.Sprintf(`你是一个专业的培训评估专家。请根据讲师对学员在课程《%s》中表现的评价，给出0-100的分数。
+// BuildTeacherPrompt 构建讲师评价评分提示词
+func BuildTeacherPrompt(teacherComment string, courseName string) string {
+	return fmt.Sprintf(`你是一个专业的培训评估专家。请根据讲师对学员在课程《%s》中表现的评价，给出0-100的分数。
 
 评分标准：
 - 90-100分：表现优异，积极参与，掌握全面
@@ -97,88 +108,83 @@ func GenerateTeacherScore(teacherComment string, courseName string) (float64, er
 讲师评价内容：
 %s
 
-请只返回一个0-100之间的整数分数，不要包含任何其他文字、符号或解释。`, 
-		courseName, teacherComment)
-
-	// 调用AI接口
-	score, err := callDeepSeekAPI(prompt)
-	if err != nil {
-		// AI调用失败时，使用默认分数
-		return 75.0, nil
-	}
+%s`,
+		courseName, teacherComment, aiOutputFormat)
+}
 
-	return score, nil
+// ApplyEvaluationFallback AI评分失败时，使用备用算法填充自评分数
+func ApplyEvaluationFallback(result *ScoringResult, selfComment string, understanding, difficulty, satisfaction int) {
+	result.Score = calculateFallbackScore(selfComment, understanding, difficulty, satisfaction)
+	result.Rationale = fmt.Sprintf("AI评分暂不可用，按备用算法估算：理解程度%d/5、难度感受%d/5、满意度%d/5，学习心得%d字",
+		understanding, difficulty, satisfaction, len([]rune(selfComment)))
+	result.UsedFallback = true
 }
 
-// callDeepSeekAPI 调用DeepSeek API
-func callDeepSeekAPI(prompt string) (float64, error) {
-	// 获取API Key
-	apiKey := os.Getenv("DEEPSEEK_API_KEY")
-	if apiKey == "" {
-		return 0, fmt.Errorf("DEEPSEEK_API_KEY未设置")
-	}
+// ApplyTeacherFallback AI评分失败时，使用默认分数填充讲师评分
+func ApplyTeacherFallback(result *ScoringResult) {
+	result.Score = 75.0
+	result.Rationale = "AI评分暂不可用，使用默认分数75分"
+	result.UsedFallback = true
+}
 
-	// 构建请求体
-	reqBody := AIRequest{
-		Model: "deepseek-chat",
-		Messages: []AIMessage{
-			{
-				Role:    "system",
-				Content: "你是一个专业的教育评估专家，擅长根据学习内容评估学员的掌握程度。",
-			},
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Stream: false,
-	}
+// aiSystemPrompt 评分使用的系统提示词
+const aiSystemPrompt = "你是一个专业的教育评估专家，擅长根据学习内容评估学员的掌握程度。"
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return 0, fmt.Errorf("序列化请求失败: %v", err)
+// CallAIProvider 调用当前配置的AI提供方并解析分数，失败原因记录在 Error 中
+func CallAIProvider(ctx context.Context, prompt string) *ScoringResult {
+	result := &ScoringResult{Prompt: prompt}
+	if currentProvider == nil {
+		result.Error = "AI提供方未初始化"
+		return result
 	}
+	result.Provider = currentProvider.Name()
 
-	// 创建HTTP请求
-	req, err := http.NewRequest("POST", DeepSeekAPIURL, bytes.NewBuffer(jsonData))
+	start := time.Now()
+	content, err := currentProvider.Complete(ctx, aiSystemPrompt, prompt)
+	result.LatencyMs = time.Since(start).Milliseconds()
 	if err != nil {
-		return 0, fmt.Errorf("创建请求失败: %v", err)
+		result.Error = err.Error()
+		return result
 	}
+	result.RawOutput = content
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-
-	// 发送请求
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	score, rationale, err := parseAIOutput(content)
 	if err != nil {
-		return 0, fmt.Errorf("请求失败: %v", err)
+		result.Error = err.Error()
+		return result
 	}
-	defer resp.Body.Close()
+	result.Score = score
+	result.ParsedScore = &score
+	result.Rationale = rationale
 
-	// 读取响应
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return 0, fmt.Errorf("读取响应失败: %v", err)
-	}
-
-	// 检查HTTP状态码
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("API返回错误: %s", string(body))
-	}
+	return result
+}
 
-	// 解析响应
-	var aiResp AIResponse
-	if err := json.Unmarshal(body, &aiResp); err != nil {
-		return 0, fmt.Errorf("解析响应失败: %v", err)
+// parseAIOutput 解析模型输出，优先按JSON解析，兼容只返回分数的旧格式
+func parseAIOutput(content string) (float64, string, error) {
+	// 模型可能用 ```json 代码块包裹，截取第一个 { 到最后一个 } 之间的内容
+	if start, end := strings.Index(content, "{"), strings.LastIndex(content, "}"); start >= 0 && end > start {
+		var output struct {
+			Score     *float64 `json:"score"`
+			Rationale string   `json:"rationale"`
+		}
+		if err := json.Unmarshal([]byte(content[start:end+1]), &output); err == nil {
+			// 缺少 score 或为 null 时不能当作 0 分
+			if output.Score == nil {
+				return 0, "", fmt.Errorf("AI输出缺少分数, 原始内容: %s", content)
+			}
+			return clampScore(*output.Score), strings.TrimSpace(output.Rationale), nil
+		}
 	}
 
-	if len(aiResp.Choices) == 0 {
-		return 0, fmt.Errorf("API未返回结果")
-	}
+	score, err := parseScore(content)
+	return score, "", err
+}
 
+// parseScore 从模型输出中提取0-100的分数
+func parseScore(content string) (float64, error) {
 	// 提取分数（去除可能的非数字字符）
-	scoreStr := strings.TrimSpace(aiResp.Choices[0].Message.Content)
+	scoreStr := strings.TrimSpace(content)
 	scoreStr = strings.Trim(scoreStr, "分.")
 	
 	score, err := strconv.ParseFloat(scoreStr, 64)
@@ -186,14 +192,17 @@ func callDeepSeekAPI(prompt string) (float64, error) {
 		return 0, fmt.Errorf("解析分数失败: %v, 原始内容: %s", err, scoreStr)
 	}
 
-	// 确保分数在0-100范围内
+	return clampScore(score), nil
+}
+
+// clampScore 确保分数在0-100范围内
+func clampScore(score float64) float64 {
 	if score < 0 {
-		score = 0
+		return 0
 	} else if score > 100 {
-		score = 100
+		return 100
 	}
-
-	return score, nil
+	return score
 }
 
 // calculateFallbackScore AI调用失败时的备用评分算法
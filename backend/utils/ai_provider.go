@@ -0,0 +1,249 @@
+package utils
+
+import (
+	"backend/config"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// AI 服务提供方名称（对应配置项 AI_PROVIDER）
+const (
+	ProviderOpenAI = "openai" // OpenAI 兼容接口（DeepSeek、vLLM、通义等）
+	ProviderOllama = "ollama" // 本地 Ollama 服务
+	ProviderMock   = "mock"   // 确定性模拟实现，用于测试与离线开发
+)
+
+// 各提供方的默认配置
+const (
+	defaultOpenAIBaseURL = "https://api.deepseek.com"
+	defaultOpenAIModel   = "deepseek-chat"
+	defaultOllamaBaseURL = "http://localhost:11434"
+	defaultOllamaModel   = "qwen2.5:7b"
+)
+
+// AIProvider 大模型服务提供方接口
+// Complete 接收系统提示词和用户提示词，返回模型输出的原始文本
+type AIProvider interface {
+	Name() string
+	Complete(ctx context.Context, systemPrompt, userPrompt string) (string, error)
+}
+
+// currentProvider 当前使用的提供方，在启动时由 InitAIProvider 设置
+var currentProvider AIProvider
+
+// InitAIProvider 根据配置初始化 AI 提供方（启动时调用）
+func InitAIProvider(cfg *config.Config) error {
+	provider, err := NewAIProvider(cfg)
+	if err != nil {
+		return err
+	}
+	currentProvider = provider
+	return nil
+}
+
+// NewAIProvider 根据配置创建 AI 提供方
+func NewAIProvider(cfg *config.Config) (AIProvider, error) {
+	// HTTP 客户端超时，防止外部服务无响应时长期占用连接
+	client := &http.Client{Timeout: cfg.AITimeout()}
+
+	switch strings.ToLower(cfg.AIProvider) {
+	case "", ProviderOpenAI:
+		return &OpenAIProvider{
+			BaseURL: withDefault(cfg.AIBaseURL, defaultOpenAIBaseURL),
+			Model:   withDefault(cfg.AIModel, defaultOpenAIModel),
+			APIKey:  cfg.AIAPIKey,
+			Client:  client,
+		}, nil
+	case ProviderOllama:
+		return &OllamaProvider{
+			BaseURL: withDefault(cfg.AIBaseURL, defaultOllamaBaseURL),
+			Model:   withDefault(cfg.AIModel, defaultOllamaModel),
+			Client:  client,
+		}, nil
+	case ProviderMock:
+		return &MockProvider{}, nil
+	default:
+		return nil, fmt.Errorf("不支持的AI提供方: %s", cfg.AIProvider)
+	}
+}
+
+// SetAIProvider 替换当前使用的 AI 提供方（测试时注入 MockProvider）
+func SetAIProvider(provider AIProvider) {
+	currentProvider = provider
+}
+
+// GetAIProvider 获取当前使用的 AI 提供方
+func GetAIProvider() AIProvider {
+	return currentProvider
+}
+
+// ==================== OpenAI 兼容接口 ====================
+
+// OpenAIProvider OpenAI 兼容的 Chat Completions 接口
+type OpenAIProvider struct {
+	BaseURL string
+	Model   string
+	APIKey  string
+	Client  *http.Client
+}
+
+// Name 提供方名称
+func (p *OpenAIProvider) Name() string {
+	return ProviderOpenAI
+}
+
+// Complete 调用 {BaseURL}/chat/completions
+func (p *OpenAIProvider) Complete(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	if p.APIKey == "" {
+		return "", fmt.Errorf("AI_API_KEY未设置")
+	}
+
+	reqBody := AIRequest{
+		Model: p.Model,
+		Messages: []AIMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt},
+		},
+		Stream: false,
+	}
+
+	headers := map[string]string{"Authorization": "Bearer " + p.APIKey}
+	body, err := postJSON(ctx, p.Client, strings.TrimRight(p.BaseURL, "/")+"/chat/completions", reqBody, headers)
+	if err != nil {
+		return "", err
+	}
+
+	var aiResp AIResponse
+	if err := json.Unmarshal(body, &aiResp); err != nil {
+		return "", fmt.Errorf("解析响应失败: %v", err)
+	}
+	if len(aiResp.Choices) == 0 {
+		return "", fmt.Errorf("API未返回结果")
+	}
+
+	return aiResp.Choices[0].Message.Content, nil
+}
+
+// ==================== Ollama 本地服务 ====================
+
+// OllamaProvider 本地 Ollama 服务（/api/chat 接口）
+type OllamaProvider struct {
+	BaseURL string
+	Model   string
+	Client  *http.Client
+}
+
+// ollamaResponse Ollama /api/chat 非流式响应结构
+type ollamaResponse struct {
+	Message AIMessage `json:"message"`
+}
+
+// Name 提供方名称
+func (p *OllamaProvider) Name() string {
+	return ProviderOllama
+}
+
+// Complete 调用 {BaseURL}/api/chat
+func (p *OllamaProvider) Complete(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	reqBody := AIRequest{
+		Model: p.Model,
+		Messages: []AIMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt},
+		},
+		Stream: false,
+	}
+
+	body, err := postJSON(ctx, p.Client, strings.TrimRight(p.BaseURL, "/")+"/api/chat", reqBody, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var resp ollamaResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("解析响应失败: %v", err)
+	}
+	if resp.Message.Content == "" {
+		return "", fmt.Errorf("API未返回结果")
+	}
+
+	return resp.Message.Content, nil
+}
+
+// ==================== 模拟实现 ====================
+
+// MockProvider 确定性模拟实现：相同的提示词总是得到相同的分数（60-100）和理由
+// 如果设置了 Response，则直接返回该内容；设置了 Err 则返回该错误
+type MockProvider struct {
+	Response string
+	Err      error
+}
+
+// Name 提供方名称
+func (p *MockProvider) Name() string {
+	return ProviderMock
+}
+
+// Complete 根据提示词哈希生成固定分数
+func (p *MockProvider) Complete(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	if p.Err != nil {
+		return "", p.Err
+	}
+	if p.Response != "" {
+		return p.Response, nil
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(userPrompt))
+	return fmt.Sprintf(`{"score": %d, "rationale": "模拟评分结果"}`, 60+h.Sum32()%41), nil
+}
+
+// ==================== 辅助函数 ====================
+
+// postJSON 发送 JSON POST 请求并返回响应体
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}, headers map[string]string) ([]byte, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求失败: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API返回错误: %s", string(body))
+	}
+
+	return body, nil
+}
+
+// withDefault 值为空时返回默认值
+func withDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
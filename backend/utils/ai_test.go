@@ -0,0 +1,32 @@
+package utils
+
+import "testing"
+
+func TestParseAIOutput(t *testing.T) {
+	tests := []struct {
+		content   string
+		score     float64
+		rationale string
+		wantErr   bool
+	}{
+		{`{"score": 86, "rationale": " 理解到位 "}`, 86, "理解到位", false},
+		{"```json\n{\"score\": 0, \"rationale\": \"未作答\"}\n```", 0, "未作答", false},
+		{`{"score": 120, "rationale": "超出范围"}`, 100, "超出范围", false},
+		{"85分", 85, "", false},
+		{`{"rationale": "缺少分数"}`, 0, "", true},
+		{`{"score": null, "rationale": "分数为空"}`, 0, "", true},
+		{"无法评分", 0, "", true},
+	}
+	for _, tt := range tests {
+		score, rationale, err := parseAIOutput(tt.content)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseAIOutput(%q) 应返回错误，实际 %v、%q", tt.content, score, rationale)
+			}
+			continue
+		}
+		if err != nil || score != tt.score || rationale != tt.rationale {
+			t.Errorf("parseAIOutput(%q) = %v、%q、%v，应为 %v、%q", tt.content, score, rationale, err, tt.score, tt.rationale)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package utils
+
+import "strconv"
+
+// RatingStat 单项课后反馈评分（1-5）的统计
+type RatingStat struct {
+	Count        int            `json:"count"`
+	Average      float64        `json:"average"`
+	Distribution map[string]int `json:"distribution"` // "1"-"5" 各分值人次
+	sum          int
+}
+
+// RatingSummary 课后反馈评分汇总（理解程度/难度感受/满意度），独立于综合得分
+type RatingSummary struct {
+	ResponseCount int        `json:"responseCount"`
+	Understanding RatingStat `json:"understanding"`
+	Difficulty    RatingStat `json:"difficulty"`
+	Satisfaction  RatingStat `json:"satisfaction"`
+}
+
+// NewRatingSummary 创建空的评分汇总
+func NewRatingSummary() *RatingSummary {
+	return &RatingSummary{
+		Understanding: newRatingStat(),
+		Difficulty:    newRatingStat(),
+		Satisfaction:  newRatingStat(),
+	}
+}
+
+// Add 累加一条反馈，未填写（0）的记录会被忽略
+func (s *RatingSummary) Add(understanding, difficulty, satisfaction int) {
+	if understanding == 0 && difficulty == 0 && satisfaction == 0 {
+		return
+	}
+	s.ResponseCount++
+	s.Understanding.add(understanding)
+	s.Difficulty.add(difficulty)
+	s.Satisfaction.add(satisfaction)
+}
+
+// newRatingStat 创建包含1-5全部分值的统计
+func newRatingStat() RatingStat {
+	distribution := make(map[string]int, 5)
+	for i := 1; i <= 5; i++ {
+		distribution[strconv.Itoa(i)] = 0
+	}
+	return RatingStat{Distribution: distribution}
+}
+
+// add 累加一个分值并更新平均值
+func (r *RatingStat) add(value int) {
+	if value < 1 || value > 5 {
+		return
+	}
+	r.Count++
+	r.sum += value
+	r.Distribution[strconv.Itoa(value)]++
+	r.Average = float64(r.sum) / float64(r.Count)
+}